@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"releasenojutsu/internal/config"
+	"releasenojutsu/internal/db"
+)
+
+// runHistoryCommand prints the scheduler run history: `releasenojutsu history [-db path] [-n 20] [-run id]`.
+// The database is opened read-only and never migrated, so it can run next to the bot.
+func runHistoryCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dbPath := fs.String("db", config.DefaultDatabasePath, "path to the SQLite database")
+	limit := fs.Int("n", 20, "number of runs to list")
+	runID := fs.Int64("run", 0, "show per-title outcomes for this run")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// Opening a missing path would silently create an empty database.
+	if _, err := os.Stat(*dbPath); err != nil {
+		return fmt.Errorf("database not found: %w", err)
+	}
+	database, err := db.OpenReadOnly(*dbPath)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer func() { _ = database.Close() }()
+
+	if *runID > 0 {
+		return printRunDetail(database, *runID, out)
+	}
+	return printRunList(database, *limit, out)
+}
+
+func printRunList(database *db.DB, limit int, out io.Writer) error {
+	runs, err := database.ListSchedulerRuns(limit)
+	if err != nil {
+		return fmt.Errorf("list runs: %w", err)
+	}
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(out, "No runs recorded yet.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTARTED\tTRIGGER\tDURATION\tCHECKED\tNEW\tSENT\tFAILED\tERROR")
+	for _, r := range runs {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			r.ID, r.StartedAt.Local().Format(time.DateTime), r.Trigger, runDuration(r),
+			r.TitlesChecked, r.NewChapters, r.NotificationsSent, r.Failures, r.Error)
+	}
+	return tw.Flush()
+}
+
+func printRunDetail(database *db.DB, id int64, out io.Writer) error {
+	run, err := database.GetSchedulerRun(id)
+	if err != nil {
+		return fmt.Errorf("load run %d: %w", id, err)
+	}
+	titles, err := database.ListSchedulerRunTitles(id)
+	if err != nil {
+		return fmt.Errorf("load run %d titles: %w", id, err)
+	}
+
+	fmt.Fprintf(out, "Run %d (%s, run_id=%s)\n", run.ID, run.Trigger, run.RunID)
+	fmt.Fprintf(out, "Started %s, duration %s\n", run.StartedAt.Local().Format(time.DateTime), runDuration(run))
+	fmt.Fprintf(out, "Checked %d, new %d, sent %d, failed %d\n", run.TitlesChecked, run.NewChapters, run.NotificationsSent, run.Failures)
+	if run.Error != "" {
+		fmt.Fprintf(out, "Error: %s\n", run.Error)
+	}
+	if len(titles) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MANGA\tUSER\tTITLE\tNEW\tDURATION\tERROR")
+	for _, t := range titles {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%s\t%s\n", t.MangaID, t.UserID, t.Title, t.NewChapters, t.Duration.Round(time.Millisecond), t.Error)
+	}
+	return tw.Flush()
+}
+
+func runDuration(r db.SchedulerRun) string {
+	if !r.IsFinished {
+		return "running"
+	}
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestRunHistoryCommand_PrintsRunsAndDetail(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := db.New(dbPath)
+	if err != nil {
+		t.Fatalf("db.New(): %v", err)
+	}
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	start := time.Now().Add(-time.Minute)
+	id, err := database.StartSchedulerRun("r1", "startup", start)
+	if err != nil {
+		t.Fatalf("StartSchedulerRun(): %v", err)
+	}
+	titles := []db.SchedulerRunTitle{{MangaID: 7, UserID: 1, Title: "Alpha", Error: "boom"}}
+	if err := database.FinishSchedulerRun(id, start.Add(time.Second), 0, "", titles); err != nil {
+		t.Fatalf("FinishSchedulerRun(): %v", err)
+	}
+	_ = database.Close()
+
+	var out bytes.Buffer
+	if err := runHistoryCommand([]string{"-db", dbPath}, &out); err != nil {
+		t.Fatalf("runHistoryCommand(list): %v", err)
+	}
+	if !strings.Contains(out.String(), "startup") {
+		t.Fatalf("list output=%q", out.String())
+	}
+
+	out.Reset()
+	if err := runHistoryCommand([]string{"-db", dbPath, "-run", strconv.FormatInt(id, 10)}, &out); err != nil {
+		t.Fatalf("runHistoryCommand(detail): %v", err)
+	}
+	if !strings.Contains(out.String(), "Alpha") || !strings.Contains(out.String(), "boom") {
+		t.Fatalf("detail output=%q", out.String())
+	}
+}
+
+func TestRunHistoryCommand_MissingDatabase(t *testing.T) {
+	var out bytes.Buffer
+	err := runHistoryCommand([]string{"-db", filepath.Join(t.TempDir(), "missing.db")}, &out)
+	if err == nil || !strings.Contains(err.Error(), "database not found") {
+		t.Fatalf("err=%v, want database not found", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
@@ -16,12 +17,19 @@ import (
 	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
 	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/metrics"
 	"releasenojutsu/internal/notify"
+	"releasenojutsu/internal/source"
 	"releasenojutsu/internal/updater"
 )
 
 func main() {
-	logger.InitLogger()
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		if err := runHistoryCommand(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("history: %v", err)
+		}
+		return
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -30,6 +38,13 @@ func main() {
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("Invalid config: %v", err)
 	}
+	logOpts, err := cfg.LoggerOptions()
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	if err := logger.Init(logOpts); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -78,14 +93,39 @@ func main() {
 		return
 	}
 
-	upd := updater.New(database, mdUpdateClient, mdSyncClient)
+	// Titles can come from sources other than MangaDex; the ID column holds the feed URL.
+	chapterRules, err := source.ParseChapterRules(cfg.RSSChapterPatterns)
+	if err != nil {
+		logger.LogMsg(logger.LogError, "Invalid RSS chapter patterns: %v", err)
+		return
+	}
+	rssSource := source.NewRSS(chapterRules...)
+	upd := updater.New(database, mdUpdateClient, mdSyncClient, rssSource)
 	notifier := notify.NewTelegramNotifier(api)
 
 	appBot := bot.New(api, database, mdUpdateClient, cfg, upd)
+	appBot.SetSources(source.NewRegistry(source.NewMangaDex(mdUpdateClient), rssSource))
+	appBot.SetUsername(api.Self.UserName)
 
 	scheduler := cron.NewScheduler(database, notifier, upd)
+	scheduler.HistoryRetention = time.Duration(cfg.RunHistoryDays) * 24 * time.Hour
+	scheduler.FailureAlerts = appBot
+	scheduler.MergeSuggestions = appBot
+	scheduler.ChapterAlerts = appBot
+	scheduler.YearRecaps = appBot
+	appBot.SetUpdateRunner(scheduler)
 	go scheduler.Run(ctx)
 
+	if cfg.MetricsListenAddr != "" {
+		// Allow for the run's own timeout on top of the interval before reporting not ready.
+		metricsServer := metrics.NewServer(database, cron.UpdateInterval+30*time.Minute)
+		go func() {
+			if err := metricsServer.ListenAndServe(ctx, cfg.MetricsListenAddr); err != nil {
+				logger.LogMsg(logger.LogError, "Metrics server exited with error: %v", err)
+			}
+		}()
+	}
+
 	if err := appBot.Run(ctx); err != nil {
 		logger.LogMsg(logger.LogError, "Bot exited with error: %v", err)
 	}
@@ -12,259 +12,793 @@ type BotCopy struct {
 }
 
 type BotCommandsCopy struct {
-	Start       string
-	Help        string
-	Status      string
-	GenPair     string
-	StartDesc   string
-	HelpDesc    string
-	StatusDesc  string
-	GenPairDesc string
+	Start         string
+	Help          string
+	Status        string
+	GenPair       string
+	Pairings      string
+	Runs          string
+	UpdateAll     string
+	Stats         string
+	Export        string
+	Import        string
+	Add           string
+	Read          string
+	Unread        string
+	List          string
+	Check         string
+	Remove        string
+	GroupCode     string
+	Link          string
+	Unlink        string
+	StartDesc     string
+	HelpDesc      string
+	StatusDesc    string
+	GenPairDesc   string
+	PairingsDesc  string
+	RunsDesc      string
+	UpdateAllDesc string
+	StatsDesc     string
+	ExportDesc    string
+	ImportDesc    string
+	AddDesc       string
+	ReadDesc      string
+	UnreadDesc    string
+	ListDesc      string
+	CheckDesc     string
+	RemoveDesc    string
+	GroupCodeDesc string
+	LinkDesc      string
+	UnlinkDesc    string
+	// Descriptions shown in group chats.
+	GroupListDesc   string
+	GroupAddDesc    string
+	GroupRemoveDesc string
 }
 
 type BotButtonsCopy struct {
-	AddManga            string
-	ListManga           string
-	CheckNew            string
-	MarkRead            string
-	MarkUnread          string
-	SyncAll             string
-	RemoveManga         string
-	GeneratePairingCode string
-	MainMenu            string
-	ToggleMangaPlus     string
-	Details             string
-	MarkAllRead         string
-	Cancel              string
-	CheckNewShort       string
-	SyncAllShort        string
-	MarkReadShort       string
-	MarkUnreadShort     string
-	YesMangaPlus        string
-	NoMangaPlus         string
-	YesDelete           string
-	YesConfirm          string
-	Back                string
-	BackToManga         string
-	BackToList          string
-	CancelAdd           string
-	Prev                string
-	Next                string
+	AddManga              string
+	ListManga             string
+	CheckNew              string
+	MarkRead              string
+	MarkUnread            string
+	SyncAll               string
+	RemoveManga           string
+	GeneratePairingCode   string
+	PairingCodes          string
+	RevokePairingCode     string
+	RunHistory            string
+	RunDetail             string
+	CheckAll              string
+	UpdateAll             string
+	YesRevoke             string
+	MainMenu              string
+	ToggleMangaPlus       string
+	Details               string
+	MarkAllRead           string
+	Cancel                string
+	CheckNewShort         string
+	SyncAllShort          string
+	MarkReadShort         string
+	MarkUnreadShort       string
+	ConfirmAdd            string
+	YesDelete             string
+	YesConfirm            string
+	Back                  string
+	BackToManga           string
+	BackToList            string
+	CancelAdd             string
+	RetryNow              string
+	ReplaceMangaDexID     string
+	MigrateManga          string
+	KeepCurrentEntry      string
+	Prev                  string
+	Next                  string
+	Settings              string
+	UnreadWarning         string
+	WarningPreset         string
+	Selected              string
+	WarningOff            string
+	UseMyDefault          string
+	CustomValue           string
+	BacklogReportOn       string
+	BacklogReportOff      string
+	ShowBacklog           string
+	BackToSettings        string
+	Category              string
+	CategoryNotifications string
+	Alerts                string
+	MuteAlerts            string
+	UnmuteAlerts          string
+	SnoozeDays            string
+	SnoozeDate            string
+	EndSnooze             string
+	NotifyEvery           string
+	AlertSettings         string
+	ListSort              string
+	SearchLibrary         string
+	SearchAgain           string
+	ListIndex             string
+	Tracking              string
+	Extras                string
+	UseExactTracking      string
+	Undo                  string
+	History               string
+	UndoLastChange        string
+	Stats                 string
+	YearRecap             string
+	Aliases               string
+	AddAlias              string
+	RemoveAlias           string
+	OpenOnMangaDex        string
+	Recommend             string
+	Friends               string
+	ShareLibraryOn        string
+	ShareLibraryOff       string
+	ShareProgressOn       string
+	ShareProgressOff      string
+	DisablePairingUsers   string
+	YesDisable            string
 }
 
 type BotPromptsCopy struct {
-	AddMangaTitle          string
-	AddMangaTitlePlain     string
-	AddMangaPlaceholder    string
-	MangaPlusQuestion      string
-	ConfirmDelete          string
-	ConfirmMarkAllRead     string
-	PairingPrivateOnly     string
-	PairingAlreadyAuth     string
-	PairingInvalid         string
-	PairingSuccess         string
-	PairingCodeGenerated   string
-	AdminOnly              string
-	PrivateChatOnly        string
-	Unauthorized           string
-	UnknownCommand         string
-	UnknownMessage         string
-	UnknownReply           string
-	NoAccessToManga        string
-	CannotAccessManga      string
-	CannotLoadManga        string
-	CannotLoadMangaDetails string
-	AddMangaCancelled      string
-	TitleNotAvailable      string
+	AddMangaTitle              string
+	AddMangaTitlePlain         string
+	AddMangaPlaceholder        string
+	ConfirmAddManga            string
+	ConfirmDelete              string
+	ConfirmMarkAllRead         string
+	PairingPrivateOnly         string
+	PairingAlreadyAuth         string
+	PairingInvalid             string
+	PairingSuccess             string
+	PairingCodeGenerated       string
+	PairingCodeLabelLine       string
+	PairingCodeSingleUse       string
+	PairingCodeMultiUse        string
+	PairingCodeStarterLine     string
+	PairingCodeHowToJoin       string
+	PairingArgsInvalid         string
+	PairingStarterAdded        string
+	ConfirmRevokePairing       string
+	AdminOnly                  string
+	RunHistoryAdminOnly        string
+	UpdateAllAdminOnly         string
+	CheckAllCooldown           string
+	UpdateAlreadyRunning       string
+	CheckAllNoManga            string
+	ReplaceMangaDexID          string
+	ReplaceSameMangaDexID      string
+	PrivateChatOnly            string
+	Unauthorized               string
+	UnknownCommand             string
+	UnknownMessage             string
+	UnknownReply               string
+	NoAccessToManga            string
+	CannotAccessManga          string
+	CannotLoadManga            string
+	CannotLoadMangaDetails     string
+	AddMangaCancelled          string
+	TitleNotAvailable          string
+	UnreadWarningCustom        string
+	SnoozeDate                 string
+	ListSearch                 string
+	ListSearchNoMatch          string
+	TitleNoMatch               string
+	TitleChoose                string
+	TitleChooseMore            string
+	ChapterNotFound            string
+	ReadUsage                  string
+	RemoveUsage                string
+	AliasPrompt                string
+	AliasTaken                 string
+	AliasLimit                 string
+	InlineUnauthorized         string
+	PairingCodeLink            string
+	PairingCodeHowToJoinLink   string
+	PairingQRCaption           string
+	GroupCodeGenerated         string
+	GroupCodeLink              string
+	GroupCodeAdminOnly         string
+	GroupNotLinked             string
+	GroupLinkUsage             string
+	GroupLinkInvalid           string
+	GroupLinked                string
+	GroupAlreadyLinked         string
+	GroupAdminsOnly            string
+	GroupHelp                  string
+	GroupListEmpty             string
+	GroupListHeader            string
+	GroupListItem              string
+	GroupListLatest            string
+	GroupAddUsage              string
+	GroupTitleAdded            string
+	GroupTitleExists           string
+	GroupRemoveUsage           string
+	GroupTitleNoMatch          string
+	GroupTitleAmbiguous        string
+	GroupTitleRemoved          string
+	GroupUnlinked              string
+	RecommendChoose            string
+	RecommendNoFriends         string
+	RecommendMangaDexOnly      string
+	RecommendUnknownFriend     string
+	RecommendLimitReached      string
+	RecommendSent              string
+	RecommendationReceived     string
+	RecommendationAdded        string
+	RecommendationAlreadyAdded string
+	RecommendationNotFound     string
+	ConfirmDisablePairingUsers string
+	LibraryImportHowTo         string
 }
 
 type BotErrorsCopy struct {
-	CouldNotRetrieveManga string
-	CouldNotAddManga      string
-	SyncFailed            string
-	SyncFailedSimple      string
-	CannotCheckUpdates    string
-	CannotUpdateChapter   string
-	CannotLoadUnread      string
-	CannotLoadRead        string
-	CannotUpdateProgress  string
-	CannotUpdateMangaPlus string
-	CannotRemoveManga     string
-	CannotRetrieveManga   string
-	CannotRetrieveStatus  string
-	CannotGeneratePair    string
-	CannotStorePair       string
+	CouldNotRetrieveManga  string
+	CouldNotRetrieveFeed   string
+	CouldNotAddManga       string
+	SyncFailed             string
+	SyncFailedSimple       string
+	CannotCheckUpdates     string
+	CannotUpdateChapter    string
+	CannotLoadUnread       string
+	CannotLoadRead         string
+	CannotUpdateProgress   string
+	CannotUpdateMangaPlus  string
+	CannotRemoveManga      string
+	CannotRetrieveManga    string
+	CannotRetrieveStatus   string
+	CannotGeneratePair     string
+	CannotStorePair        string
+	CannotLoadPairings     string
+	CannotRevokePair       string
+	CannotLoadRunHistory   string
+	CannotRunUpdate        string
+	CannotReplaceManga     string
+	CannotMigrateManga     string
+	CannotLoadStarter      string
+	CannotLoadSettings     string
+	CannotSaveSettings     string
+	CannotLoadBacklog      string
+	CannotUpdateCategory   string
+	CannotUpdateAlerts     string
+	CannotUpdateTracking   string
+	CannotUndo             string
+	CannotLoadHistory      string
+	CannotLoadStats        string
+	CannotUpdateAliases    string
+	CannotLoadGroupList    string
+	CannotUpdateGroup      string
+	CannotRecommend        string
+	CannotLoadFriends      string
+	CannotDisablePairUsers string
+	CannotExportLibrary    string
+	LibraryImportInvalid   string
 }
 
 type BotInfoCopy struct {
-	WelcomeTitle                string
-	HelpText                    string
-	StatusTitle                 string
-	StatusTracked               string
-	StatusChaptersStored        string
-	StatusRegisteredChats       string
-	StatusTotalUnread           string
-	StatusLastRun               string
-	StatusCronNever             string
-	StatusInterval              string
-	ListHeader                  string
-	ListEmpty                   string
-	ListTotal                   string
-	NoNewChapters               string
-	SyncStart                   string
-	SyncStartWithPlus           string
-	SyncComplete                string
-	SyncCompleteWithHint        string
-	MarkReadResult              string
-	MarkUnreadResult            string
-	MarkAllReadDone             string
-	MangaDetails                string
-	MangaPlusYes                string
-	MangaPlusNo                 string
-	UnreadLine                  string
-	ReadLine                    string
-	UpToDate                    string
-	NothingToUnread             string
-	PickRangeUnread             string
-	PickRangeRead               string
-	PickRangeUnreadWithBucket   string
-	PickRangeReadWithBucket     string
-	PickChapterRead             string
-	PickChapterUnread           string
-	UnreadSummary               string
-	ReadSummary                 string
-	MangaPlusStatus             string
-	MangaPlusEnabled            string
-	MangaPlusDisabled           string
-	MangaRemoved                string
-	ActionMenuHeader            string
-	ActionMenuUnread            string
-	ActionMenuPrompt            string
-	DetailsTitleLine            string
-	DetailsMangaDexLine         string
-	DetailsChaptersLine         string
-	DetailsRangeLine            string
-	DetailsLastReadLine         string
-	DetailsLastReadNoneLine     string
-	DetailsUnreadLine           string
-	DetailsLastSeenLine         string
-	DetailsLastCheckedLine      string
-	DetailsNote                 string
-	LastReadNone                string
-	LastReadNoTitle             string
-	LastReadWithTitle           string
-	LastReadNoneHTML            string
-	LastReadNoTitleHTML         string
-	LastReadWithTitleHTML       string
-	MangaPlusYesLabel           string
-	MangaPlusNoLabel            string
-	NewChapterAlertTitle        string
-	NewChapterAlertHeader       string
-	NewChapterAlertItem         string
-	NewChapterAlertUnread       string
-	NewChapterAlertWarning      string
-	NewChapterAlertFooter       string
-	NewChapterAlertTitlePlain   string
-	NewChapterAlertHeaderPlain  string
-	NewChapterAlertItemPlain    string
-	NewChapterAlertUnreadPlain  string
-	NewChapterAlertWarningPlain string
-	NewChapterAlertFooterPlain  string
-	BreadcrumbPathFormat        string
-	BreadcrumbUnreadRoot        string
-	BreadcrumbReadRoot          string
+	WelcomeTitle                 string
+	HelpText                     string
+	StatusTitle                  string
+	StatusTracked                string
+	StatusChaptersStored         string
+	StatusRegisteredChats        string
+	StatusTotalUnread            string
+	StatusLastRun                string
+	StatusCronNever              string
+	StatusInterval               string
+	ListHeader                   string
+	ListEmpty                    string
+	ListTotal                    string
+	NoNewChapters                string
+	SyncStart                    string
+	SyncStartAdded               string
+	SyncStartFromSource          string
+	SyncComplete                 string
+	SyncCompleteWithHint         string
+	MarkReadResult               string
+	MarkUnreadResult             string
+	MarkAllReadDone              string
+	MangaDetails                 string
+	MangaPlusYes                 string
+	MangaPlusNo                  string
+	MangaPlusWindowLine          string
+	MangaPlusDetected            string
+	UnreadLine                   string
+	ReadLine                     string
+	UpToDate                     string
+	NothingToUnread              string
+	PickRangeUnread              string
+	PickRangeRead                string
+	PickRangeUnreadWithBucket    string
+	PickRangeReadWithBucket      string
+	PickChapterRead              string
+	PickChapterUnread            string
+	UnreadSummary                string
+	ReadSummary                  string
+	MangaPlusStatus              string
+	MangaPlusEnabled             string
+	MangaPlusDisabled            string
+	MangaRemoved                 string
+	ActionMenuHeader             string
+	ActionMenuUnread             string
+	ActionMenuPrompt             string
+	DetailsTitleLine             string
+	DetailsMangaDexLine          string
+	DetailsChaptersLine          string
+	DetailsRangeLine             string
+	DetailsLastReadLine          string
+	DetailsLastReadNoneLine      string
+	DetailsUnreadLine            string
+	DetailsLastSeenLine          string
+	DetailsLastCheckedLine       string
+	DetailsNote                  string
+	DetailsHealthOK              string
+	DetailsHealthFailing         string
+	DetailsHealthLastError       string
+	DetailsHealthNextCheck       string
+	TitleFailingAlert            string
+	ReplaceMangaDexDone          string
+	DetailsMergeCandidate        string
+	DetailsSourceLine            string
+	MergeSuggestion              string
+	MigrateMangaDone             string
+	NoMergePending               string
+	LastReadNone                 string
+	LastReadNoTitle              string
+	LastReadWithTitle            string
+	LastReadNoneHTML             string
+	LastReadNoTitleHTML          string
+	LastReadWithTitleHTML        string
+	NewChapterAlertTitle         string
+	NewChapterAlertHeader        string
+	NewChapterAlertItem          string
+	NewChapterAlertUnread        string
+	NewChapterAlertWarning       string
+	NewChapterAlertMangaPlus     string
+	NewChapterAlertExternal      string
+	NewChapterAlertExpiring      string
+	NewChapterAlertPaywalled     string
+	NewChapterAlertFooter        string
+	NewChapterAlertGroupFooter   string
+	NewChapterAlertTitlePlain    string
+	NewChapterAlertHeaderPlain   string
+	NewChapterAlertItemPlain     string
+	NewChapterAlertUnreadPlain   string
+	NewChapterAlertWarningPlain  string
+	NewChapterAlertFooterPlain   string
+	BreadcrumbPathFormat         string
+	BreadcrumbUnreadRoot         string
+	BreadcrumbReadRoot           string
+	PairingListTitle             string
+	PairingListEmpty             string
+	PairingListItem              string
+	PairingListItemLabel         string
+	PairingListUsage             string
+	PairingListExpires           string
+	PairingListExpired           string
+	PairingListRevoked           string
+	PairingListJoined            string
+	PairingRevoked               string
+	RunHistoryTitle              string
+	RunHistoryEmpty              string
+	RunHistoryItem               string
+	RunHistoryRunning            string
+	RunHistoryError              string
+	RunDetailTitle               string
+	RunDetailSummary             string
+	RunDetailNoTitles            string
+	RunDetailTitleItem           string
+	RunDetailTitleNew            string
+	RunDetailTitleError          string
+	RunDetailMore                string
+	CheckAllStarting             string
+	CheckAllProgress             string
+	CheckAllDone                 string
+	UpdateAllStarting            string
+	UpdateAllDone                string
+	SettingsText                 string
+	UnreadWarningMenu            string
+	BacklogReportTitle           string
+	BacklogReportItem            string
+	BacklogReportEmpty           string
+	DetailsUnreadWarningLine     string
+	CategoryMenu                 string
+	CategoryNotificationsText    string
+	ListFilterLine               string
+	ListCategoryEmpty            string
+	ListPage                     string
+	StatusCategoriesTitle        string
+	StatusCategoryLine           string
+	DetailsCategoryLine          string
+	AlertSettingsMenu            string
+	DetailsAlertsLine            string
+	ListSortLine                 string
+	ListFilterEmpty              string
+	ListSearchResults            string
+	ListSearchMore               string
+	ListIndexText                string
+	MarkReadResultExact          string
+	PickChapterReadExact         string
+	PickChapterUnreadExact       string
+	ExtrasText                   string
+	ExtrasTextWatermark          string
+	ExtrasEmpty                  string
+	DetailsTrackingLine          string
+	DetailsReadCountLine         string
+	HistoryTitle                 string
+	HistoryEmpty                 string
+	HistoryItem                  string
+	UndoDone                     string
+	UndoUnavailable              string
+	StatsTitle                   string
+	StatsEmpty                   string
+	StatsPeriods                 string
+	StatsWeeks                   string
+	StatsMonths                  string
+	StatsStreak                  string
+	StatsBacklog                 string
+	StatsBacklogNone             string
+	StatsTopTitle                string
+	StatsTopItem                 string
+	StatsLagTitle                string
+	StatsLagItem                 string
+	RecapTitle                   string
+	RecapTotals                  string
+	RecapMonths                  string
+	RecapBusiestMonth            string
+	RecapLongestStreak           string
+	RecapEmpty                   string
+	AliasesTitle                 string
+	AliasesEmpty                 string
+	AliasItem                    string
+	AliasesHint                  string
+	InlineCardCover              string
+	InlineCardTitle              string
+	InlineCardStatus             string
+	InlineCardLatest             string
+	InlineCardLink               string
+	DetailsShareLine             string
+	FriendsReadingTitle          string
+	FriendsReadingOptIn          string
+	FriendsReadingEmpty          string
+	FriendsReadingLine           string
+	FriendsReadingMore           string
+	FriendsReadingProgressHidden string
+	FriendsReadingProgressShared string
+	PairingUsersDisabled         string
+	LibraryExported              string
+	LibraryImported              string
 }
 
 type BotLabelsCopy struct {
-	ChapterPrefix      string
-	ChapterWithTitle   string
-	MangaPlusPrefix    string
-	ListItemFormat     string
-	ListUnreadSuffix   string
-	ExtraChapterNumber string
+	ChapterPrefix              string
+	ChapterWithTitle           string
+	MangaPlusPrefix            string
+	ListItemFormat             string
+	ListUnreadSuffix           string
+	ExtraChapterNumber         string
+	UnreadWarningAt            string
+	UnreadWarningOff           string
+	UnreadWarningDefault       string
+	BacklogReportWeekly        string
+	BacklogReportDisabled      string
+	CategoryAll                string
+	CategoryReading            string
+	CategoryPlanToRead         string
+	CategoryOnHold             string
+	CategoryDropped            string
+	CategoryCompleted          string
+	CategoryTab                string
+	CategoryMuted              string
+	CategoryUnmuted            string
+	AlertsOn                   string
+	AlertsMuted                string
+	AlertsSnoozed              string
+	NotifyEveryChapter         string
+	NotifyEveryN               string
+	NotifyEveryPending         string
+	SnoozeOneDay               string
+	SnoozeOneWeek              string
+	SnoozeOneMonth             string
+	SortTitle                  string
+	SortUnread                 string
+	SortLastRelease            string
+	SortRecent                 string
+	FilterUnread               string
+	FilterMangaPlus            string
+	FilterFailing              string
+	ListFilterJoin             string
+	ListIndexOther             string
+	TrackingWatermark          string
+	TrackingExact              string
+	ExtraRead                  string
+	ExtraUnread                string
+	ExtraUntitled              string
+	HistoryRead                string
+	HistoryUnread              string
+	HistoryReadAll             string
+	HistoryUndo                string
+	HistoryDelta               string
+	HistoryVia                 string
+	HistoryUndone              string
+	LagUnderHour               string
+	LagHours                   string
+	LagDays                    string
+	PubStatusOngoing           string
+	PubStatusCompleted         string
+	PubStatusHiatus            string
+	PubStatusCancelled         string
+	InlineInLibrary            string
+	InlineFromMangaDex         string
+	InlineLatest               string
+	FriendUnnamed              string
+	FriendProgress             string
+	UnreadWarningMangaPlusOnly string
+	HistoryTracking            string
+	HistoryAdded               string
 }
 
 var Copy = BotCopy{
 	Commands: BotCommandsCopy{
-		Start:       "start",
-		Help:        "help",
-		Status:      "status",
-		GenPair:     "genpair",
-		StartDesc:   "Return to the main menu",
-		HelpDesc:    "Show help information",
-		StatusDesc:  "Show bot status",
-		GenPairDesc: "Generate a pairing code",
+		Start:           "start",
+		Help:            "help",
+		Status:          "status",
+		GenPair:         "genpair",
+		Pairings:        "pairings",
+		Runs:            "runs",
+		UpdateAll:       "updateall",
+		Stats:           "stats",
+		Export:          "export",
+		Import:          "import",
+		Add:             "add",
+		Read:            "read",
+		Unread:          "unread",
+		List:            "list",
+		Check:           "check",
+		Remove:          "remove",
+		GroupCode:       "groupcode",
+		Link:            "link",
+		Unlink:          "unlink",
+		StartDesc:       "Return to the main menu",
+		HelpDesc:        "Show help information",
+		StatusDesc:      "Show bot status",
+		GenPairDesc:     "Generate a pairing code",
+		PairingsDesc:    "List and revoke pairing codes",
+		RunsDesc:        "Show recent update runs",
+		UpdateAllDesc:   "Check every user's manga now",
+		StatsDesc:       "Show your reading statistics",
+		ExportDesc:      "Download your library and categories as a file",
+		ImportDesc:      "Restore a library file from /export",
+		AddDesc:         "Add a manga: /add <MangaDex URL or ID>",
+		ReadDesc:        "Mark read: /read <title> [chapter]",
+		UnreadDesc:      "Titles with unread chapters, or /unread <title> [chapter]",
+		ListDesc:        "Your library, or search it: /list <title>",
+		CheckDesc:       "Check for new chapters: /check [title]",
+		RemoveDesc:      "Stop tracking: /remove <title>",
+		GroupCodeDesc:   "Generate a code to link a group chat",
+		LinkDesc:        "Link this group: /link <code>",
+		UnlinkDesc:      "Unlink this group and delete its list",
+		GroupListDesc:   "This group's shared list",
+		GroupAddDesc:    "Add a title: /add <MangaDex URL or ID>",
+		GroupRemoveDesc: "Remove a title: /remove <title>",
 	},
 	Buttons: BotButtonsCopy{
-		AddManga:            "➕ Add Manga",
-		ListManga:           "📚 My Mangas",
-		CheckNew:            "🔍 Check for New Chapters",
-		MarkRead:            "✅ Mark as Read",
-		MarkUnread:          "↩️ Mark as Unread",
-		SyncAll:             "🔄 Import All Chapters",
-		RemoveManga:         "🗑️ Remove Manga",
-		GeneratePairingCode: "🔑 Generate Pairing Code",
-		MainMenu:            "🏠 Main Menu",
-		ToggleMangaPlus:     "⭐ Toggle Manga Plus",
-		Details:             "ℹ️ View Details",
-		MarkAllRead:         "✅ Mark All as Read",
-		Cancel:              "❌ Cancel",
-		CheckNewShort:       "🔍 Check New",
-		SyncAllShort:        "🔄 Import",
-		MarkReadShort:       "✅ Read",
-		MarkUnreadShort:     "↩️ Unread",
-		YesMangaPlus:        "⭐ Yes",
-		NoMangaPlus:         "❌ No",
-		YesDelete:           "✅ Yes, Delete",
-		YesConfirm:          "✅ Yes",
-		Back:                "⬅️ Back",
-		BackToManga:         "⬅️ Back to Manga",
-		BackToList:          "⬅️ Back to My Mangas",
-		CancelAdd:           "❌ Cancel Add",
-		Prev:                "⬅️ Prev",
-		Next:                "Next ➡️",
+		AddManga:              "➕ Add Manga",
+		ListManga:             "📚 My Mangas",
+		CheckNew:              "🔍 Check for New Chapters",
+		MarkRead:              "✅ Mark as Read",
+		MarkUnread:            "↩️ Mark as Unread",
+		SyncAll:               "🔄 Import All Chapters",
+		RemoveManga:           "🗑️ Remove Manga",
+		GeneratePairingCode:   "🔑 Generate Pairing Code",
+		PairingCodes:          "📋 Pairing Codes",
+		RevokePairingCode:     "🚫 Revoke %s",
+		RunHistory:            "🕑 Run History",
+		RunDetail:             "🔎 Run #%d",
+		CheckAll:              "⚡ Check All My Manga Now",
+		UpdateAll:             "🌐 Run Global Update Now",
+		YesRevoke:             "✅ Yes, Revoke",
+		MainMenu:              "🏠 Main Menu",
+		ToggleMangaPlus:       "⭐ Toggle Manga Plus",
+		Details:               "ℹ️ View Details",
+		MarkAllRead:           "✅ Mark All as Read",
+		Cancel:                "❌ Cancel",
+		CheckNewShort:         "🔍 Check New",
+		SyncAllShort:          "🔄 Import",
+		MarkReadShort:         "✅ Read",
+		MarkUnreadShort:       "↩️ Unread",
+		ConfirmAdd:            "➕ Add to My List",
+		YesDelete:             "✅ Yes, Delete",
+		YesConfirm:            "✅ Yes",
+		Back:                  "⬅️ Back",
+		BackToManga:           "⬅️ Back to Manga",
+		BackToList:            "⬅️ Back to My Mangas",
+		CancelAdd:             "❌ Cancel Add",
+		RetryNow:              "🔁 Retry Now",
+		ReplaceMangaDexID:     "🔀 Replace MangaDex ID",
+		MigrateManga:          "➡️ Move to New Entry",
+		KeepCurrentEntry:      "Keep Current Entry",
+		Prev:                  "⬅️ Prev",
+		Next:                  "Next ➡️",
+		Settings:              "⚙️ Settings",
+		UnreadWarning:         "⚠️ Unread Warning",
+		WarningPreset:         "%d+",
+		Selected:              "✅ %s",
+		WarningOff:            "🔕 Off",
+		UseMyDefault:          "↩️ My Default",
+		CustomValue:           "✏️ Custom",
+		BacklogReportOn:       "📬 Weekly Backlog Report: On",
+		BacklogReportOff:      "📭 Weekly Backlog Report: Off",
+		ShowBacklog:           "📋 Show Backlog Now",
+		BackToSettings:        "⬅️ Back to Settings",
+		Category:              "🗂️ Category: %s",
+		CategoryNotifications: "🔔 Notifications by Category",
+		Alerts:                "🔔 Alerts",
+		MuteAlerts:            "🔕 Mute",
+		UnmuteAlerts:          "🔔 Unmute",
+		SnoozeDays:            "😴 %s",
+		SnoozeDate:            "📅 Until a Date…",
+		EndSnooze:             "⏰ End Snooze",
+		NotifyEvery:           "Every %d",
+		AlertSettings:         "⚙️ Alert Settings",
+		ListSort:              "🔃 Sort: %s",
+		SearchLibrary:         "🔎 Search",
+		SearchAgain:           "🔎 Search Again",
+		ListIndex:             "🔤 A–Z",
+		Tracking:              "🎯 Tracking: %s",
+		Extras:                "📎 Extras",
+		UseExactTracking:      "🎯 Use Exact Tracking",
+		Undo:                  "↩️ Undo",
+		History:               "🕑 History",
+		UndoLastChange:        "↩️ Undo Last Change",
+		Stats:                 "📊 Stats",
+		YearRecap:             "🎉 %d Recap",
+		Aliases:               "🏷 Aliases",
+		AddAlias:              "➕ Add Alias",
+		RemoveAlias:           "✖️ %s",
+		OpenOnMangaDex:        "🔗 Open on MangaDex",
+		Recommend:             "💌 Recommend",
+		Friends:               "👥 Friends",
+		ShareLibraryOn:        "👀 Sharing My List: On",
+		ShareLibraryOff:       "🙈 Sharing My List: Off",
+		ShareProgressOn:       "📖 Sharing Progress: On",
+		ShareProgressOff:      "📕 Sharing Progress: Off",
+		DisablePairingUsers:   "⛔ Disable %d user(s) of %s",
+		YesDisable:            "✅ Yes, Disable",
 	},
 	Prompts: BotPromptsCopy{
-		AddMangaTitle:          "📚 *Add a New Manga*\n\nWhich manga should I track?\n\nJust send me the MangaDex URL or ID.\n\nExample: https://mangadex.org/title/40bc649f-7b49-4645-859e-6cd94136e722/dragon-ball",
-		AddMangaTitlePlain:     "📚 Add a New Manga\n\nWhich manga should I track?\n\nJust send me the MangaDex URL or ID.\n\nExample: https://mangadex.org/title/40bc649f-7b49-4645-859e-6cd94136e722/dragon-ball",
-		AddMangaPlaceholder:    "MangaDex URL or ID",
-		MangaPlusQuestion:      "📚 <b>%s</b>\n\nIs this from <b>Manga Plus by Shueisha</b>?\n\n(This helps me know whether to warn you about piling up unread chapters.)",
-		ConfirmDelete:          "🗑️ Remove <b>%s</b> from your tracking list?\n\nThis will stop tracking it and clear all saved chapters.",
-		ConfirmMarkAllRead:     "✅ Mark <b>all chapters</b> as read for <b>%s</b>?\n\nThis will update your progress to the latest chapter.",
-		PairingPrivateOnly:     "⚠️ Pairing codes only work in private chats. Message me directly!",
-		PairingAlreadyAuth:     "✅ You're already authorized and ready to go!",
-		PairingInvalid:         "❌ That pairing code is invalid or expired. Ask the admin for a new one.",
-		PairingSuccess:         "✅ You're now authorized! Use /start to open the menu.",
-		PairingCodeGenerated:   "🔑 Pairing code: <b>%s</b>\n⏳ Valid until: <b>%s</b>\n♻️ One-time use\n\n<b>How to join:</b>\n1. Open the bot: https://t.me/ReleaseNoJutsuBot\n2. Press Start\n3. Send the pairing code above exactly as shown\n4. You're paired and ready to use the bot",
-		AdminOnly:              "🚫 Only the admin can generate pairing codes.",
-		PrivateChatOnly:        "🚫 I only work in private chats. Message me directly!",
-		Unauthorized:           "🚫 I need to verify you first.\n\nAsk the admin for a pairing code and send it here (format: XXXX-XXXX).",
-		UnknownCommand:         "❓ Unknown command. Use /start or /help to see what I can do.",
-		UnknownMessage:         "I'm not sure what you mean. Use /start to see what I can help with!",
-		UnknownReply:           "I didn't understand that. Try /start for the menu.",
-		NoAccessToManga:        "🚫 You don't have access to that manga.",
-		CannotAccessManga:      "❌ I couldn't access that manga right now. Try again in a moment.",
-		CannotLoadManga:        "❌ I couldn't load that manga right now. Try again in a moment.",
-		CannotLoadMangaDetails: "❌ I couldn't load the manga details. Try again in a moment.",
-		AddMangaCancelled:      "✅ Add manga canceled.",
-		TitleNotAvailable:      "Title not available",
+		AddMangaTitle:              "📚 *Add a New Manga*\n\nWhich manga should I track?\n\nJust send me the MangaDex URL or ID, or the link to an RSS/Atom chapter feed.\n\nExample: https://mangadex.org/title/40bc649f-7b49-4645-859e-6cd94136e722/dragon-ball",
+		AddMangaTitlePlain:         "📚 Add a New Manga\n\nWhich manga should I track?\n\nJust send me the MangaDex URL or ID, or the link to an RSS/Atom chapter feed.\n\nExample: https://mangadex.org/title/40bc649f-7b49-4645-859e-6cd94136e722/dragon-ball",
+		AddMangaPlaceholder:        "MangaDex URL or ID",
+		ConfirmAddManga:            "📚 <b>%s</b>\n\nAdd this to your list?\n\nIf it's published on <b>MANGA Plus</b>, I'll notice from its chapters and warn you before unread ones leave the free window.",
+		ConfirmDelete:              "🗑️ Remove <b>%s</b> from your tracking list?\n\nThis will stop tracking it and clear all saved chapters.",
+		ConfirmMarkAllRead:         "✅ Mark <b>all chapters</b> as read for <b>%s</b>?\n\nThis will update your progress to the latest chapter.",
+		PairingPrivateOnly:         "⚠️ Pairing codes only work in private chats. Message me directly!",
+		PairingAlreadyAuth:         "✅ You're already authorized and ready to go!",
+		PairingInvalid:             "❌ That pairing code is invalid or expired. Ask the admin for a new one.",
+		PairingSuccess:             "✅ You're now authorized! Use /start to open the menu.",
+		PairingCodeGenerated:       "🔑 Pairing code: <b>%s</b>\n⏳ Valid until: <b>%s</b>\n",
+		PairingCodeLabelLine:       "🏷️ Label: <b>%s</b>\n",
+		PairingCodeSingleUse:       "♻️ One-time use\n",
+		PairingCodeMultiUse:        "♻️ Can be used <b>%d</b> times\n",
+		PairingCodeStarterLine:     "📚 Starter library: %s\n",
+		PairingCodeHowToJoin:       "\n<b>How to join:</b>\n1. Open the bot: https://t.me/ReleaseNoJutsuBot\n2. Press Start\n3. Send the pairing code above exactly as shown\n4. You're paired and ready to use the bot",
+		PairingArgsInvalid:         "❌ %s\n\nUsage: /genpair [uses=N] [ttl=48h|7d] [starter=&lt;MangaDex URL or ID&gt;,...] [label]\n\nExample: /genpair uses=5 ttl=7d Discord friends",
+		PairingStarterAdded:        "📚 Your starter library is ready! I've added:\n%s\nI'm importing their chapters in the background.",
+		ConfirmRevokePairing:       "🚫 Revoke pairing code <b>%s</b>?\n\nNobody else will be able to join with it. The <b>%d</b> user(s) who already joined keep their access; you can disable them separately from the code list.",
+		AdminOnly:                  "🚫 Only the admin can generate pairing codes.",
+		RunHistoryAdminOnly:        "🚫 Only the admin can view the run history.",
+		UpdateAllAdminOnly:         "🚫 Only the admin can run a global update.",
+		CheckAllCooldown:           "⏳ You checked all your manga recently. Try again in %d min.",
+		UpdateAlreadyRunning:       "⏳ An update is already running. You'll get new chapters as soon as it finishes.",
+		CheckAllNoManga:            "You're not tracking any manga yet, so there's nothing to check.",
+		ReplaceMangaDexID:          "🔀 Send the new MangaDex URL or ID for <b>%s</b>.\n\nYour reading progress and stored chapters are kept.",
+		ReplaceSameMangaDexID:      "That's the MangaDex ID this manga already uses. Send a different URL or ID.",
+		PrivateChatOnly:            "🚫 I only work in private chats. Message me directly!",
+		Unauthorized:               "🚫 I need to verify you first.\n\nAsk the admin for a pairing code and send it here (format: XXXX-XXXX).",
+		UnknownCommand:             "❓ Unknown command. Use /start or /help to see what I can do.",
+		UnknownMessage:             "I'm not sure what you mean. Use /start to see what I can help with!\n\nTo log progress, send something like \"read one piece 1130\" or \"caught up frieren\".",
+		UnknownReply:               "I didn't understand that. Try /start for the menu.",
+		NoAccessToManga:            "🚫 You don't have access to that manga.",
+		CannotAccessManga:          "❌ I couldn't access that manga right now. Try again in a moment.",
+		CannotLoadManga:            "❌ I couldn't load that manga right now. Try again in a moment.",
+		CannotLoadMangaDetails:     "❌ I couldn't load the manga details. Try again in a moment.",
+		AddMangaCancelled:          "✅ Add manga canceled.",
+		TitleNotAvailable:          "Title not available",
+		UnreadWarningCustom:        "✏️ Send me the number of unread chapters that should trigger a warning (1-999), or 0 to turn it off.",
+		SnoozeDate:                 "📅 Until when should I keep quiet about <b>%s</b>?\n\nSend a date as YYYY-MM-DD (up to a year ahead). Alerts resume on that day.",
+		ListSearch:                 "🔎 Send part of a title to search your library.",
+		ListSearchNoMatch:          "No titles match <b>%s</b>. Send another search or go back to your list.",
+		TitleNoMatch:               "🤷 Nothing in your library matches <b>%s</b>.",
+		TitleChoose:                "🤔 Several titles match <b>%s</b>. Which one did you mean?",
+		TitleChooseMore:            "\n\nShowing the first %d. Send more of the title to narrow it down.",
+		ChapterNotFound:            "🤷 <b>%s</b> has no chapter <b>%s</b> yet. If it just came out, try Sync All Chapters.",
+		ReadUsage:                  "✍️ Usage: /read <title> [chapter]\nExample: /read frieren 120",
+		RemoveUsage:                "✍️ Usage: /remove <title>\nExample: /remove one piece",
+		AliasPrompt:                "✍️ Send a short name for <b>%s</b>, e.g. <code>op</code>. Letters and numbers, up to %d characters.",
+		AliasTaken:                 "⚠️ <code>%s</code> is already an alias of <b>%s</b>. Pick another name.",
+		AliasLimit:                 "⚠️ A title can have at most %d aliases. Remove one first.",
+		InlineUnauthorized:         "🔒 Pair with the bot to search",
+		PairingCodeLink:            "🔗 Invite link: %s\n",
+		PairingCodeHowToJoinLink:   "\n<b>How to join:</b>\n1. Open the invite link above, or scan the QR code\n2. Press Start - the code is redeemed automatically\n3. You're paired and ready to use the bot\n\nThe code can also be sent to the bot by hand.",
+		PairingQRCaption:           "📷 Scan to pair (code %s)",
+		GroupCodeGenerated:         "👥 Group link code: <b>%s</b>\n⏳ Valid until: <b>%s</b>\n♻️ One-time use\n\n<b>How to link a group:</b>\n1. Add me to the group\n2. A group admin sends <code>/link %s</code> there\n\nThe group gets its own shared list and its new-chapter alerts. Nobody's personal library or progress is shown there.",
+		GroupCodeLink:              "\n🔗 Or open %s, pick the group and I'll link it right away.",
+		GroupCodeAdminOnly:         "🚫 Only the admin can generate group link codes.",
+		GroupNotLinked:             "👥 This group isn't linked yet. A group admin can link it with <code>/link CODE</code>, using a code from the bot admin.\n\nFor your own library, message me directly.",
+		GroupLinkUsage:             "Usage: <code>/link CODE</code>\n\nAsk the bot admin for a group link code.",
+		GroupLinkInvalid:           "❌ That group link code is invalid, used or expired. Ask the bot admin for a new one.",
+		GroupLinked:                "✅ This group is linked! Group admins can add titles with /%s, and I'll announce their new chapters here.\n\nOnly this group's shared list is shown here. Personal libraries and reading progress stay private.",
+		GroupAlreadyLinked:         "✅ This group is already linked. /%s shows its titles.",
+		GroupAdminsOnly:            "🚫 Only group admins can change this group's list.",
+		GroupHelp:                  "👥 <b>Group commands</b>\n/list – this group's shared list\n/add &lt;MangaDex URL or ID&gt; – add a title (group admins)\n/remove &lt;title&gt; – remove a title (group admins)\n/unlink – unlink the group and delete its list (group admins)\n\nNew chapters of these titles are announced here. For your own library, message me directly.",
+		GroupListEmpty:             "📚 This group's list is empty. Group admins can add titles with /%s.",
+		GroupListHeader:            "📚 <b>%s</b> – shared list (%d)\n\n",
+		GroupListItem:              "• <b>%s</b>%s\n",
+		GroupListLatest:            " – ch. %s",
+		GroupAddUsage:              "Usage: <code>/add &lt;MangaDex URL or ID&gt;</code>",
+		GroupTitleAdded:            "✅ Added <b>%s</b> to the group list. I'll announce its new chapters here.",
+		GroupTitleExists:           "<b>%s</b> is already on the group list.",
+		GroupRemoveUsage:           "Usage: <code>/remove &lt;title&gt;</code>",
+		GroupTitleNoMatch:          "❓ No title on the group list matches <b>%s</b>.",
+		GroupTitleAmbiguous:        "Several titles match. Be more specific:\n%s",
+		GroupTitleRemoved:          "🗑️ Removed <b>%s</b> from the group list.",
+		GroupUnlinked:              "👋 This group is unlinked and its list was deleted. Ask the bot admin for a new code to link it again.",
+		RecommendChoose:            "💌 Who should I recommend <b>%s</b> to?",
+		RecommendNoFriends:         "💌 Nobody else shares their list with me yet, so there's no one to recommend <b>%s</b> to.",
+		RecommendMangaDexOnly:      "💌 Only MangaDex titles can be recommended.",
+		RecommendUnknownFriend:     "❌ I couldn't find that person. They may have stopped sharing their list or no longer be paired with me.",
+		RecommendLimitReached:      "⏳ You've already sent them %d recommendations today. Try again tomorrow.",
+		RecommendSent:              "💌 Sent <b>%s</b> to %s.",
+		RecommendationReceived:     "💌 %s recommends <b>%s</b>!\n\nWant to follow it?",
+		RecommendationAdded:        "🎉 %s added <b>%s</b>, which you recommended.",
+		RecommendationAlreadyAdded: "✅ <b>%s</b> is already in your list.",
+		RecommendationNotFound:     "❌ That recommendation isn't available anymore.",
+		ConfirmDisablePairingUsers: "⛔ Disable the <b>%d</b> user(s) who joined with <b>%s</b>?\n\nThey lose access and their titles stop being checked. Nothing is deleted: redeeming a new pairing code enables them again with their list intact.",
+		LibraryImportHowTo:         "📥 Send me the file /export gave you, as a document. I'll add its titles with their categories and category mutes. Titles you already track keep their progress and move to the exported category.",
 	},
 	Errors: BotErrorsCopy{
-		CouldNotRetrieveManga: "❌ I couldn't find that manga. Double-check the MangaDex ID or URL and try again!",
-		CouldNotAddManga:      "❌ I couldn't add that manga. It might already be in your list, or the ID is invalid. Open \"My Mangas\" from the main menu to check your current list.",
-		SyncFailed:            "❌ Import failed for <b>%s</b>.\n\nYou can try again from the main menu using \"Import All Chapters\".",
-		SyncFailedSimple:      "❌ Import failed for <b>%s</b>. Try again in a moment.",
-		CannotCheckUpdates:    "❌ I couldn't check MangaDex for updates right now. Try again in a bit!",
-		CannotUpdateChapter:   "❌ I couldn't update the chapter status. Try again in a moment.",
-		CannotLoadUnread:      "❌ I couldn't load unread chapters right now. Try again in a moment.",
-		CannotLoadRead:        "❌ I couldn't load read chapters right now. Try again in a moment.",
-		CannotUpdateProgress:  "❌ I couldn't update your progress right now. Try again in a moment.",
-		CannotUpdateMangaPlus: "❌ I couldn't update the Manga Plus status. Try again in a moment.",
-		CannotRemoveManga:     "❌ I couldn't remove that manga. Try again in a moment.",
-		CannotRetrieveManga:   "❌ I couldn't retrieve manga details for removal. Try again in a moment.",
-		CannotRetrieveStatus:  "❌ I couldn't retrieve status right now. Try again in a moment.",
-		CannotGeneratePair:    "❌ I couldn't generate a pairing code right now. Try again in a moment.",
-		CannotStorePair:       "❌ I couldn't store the pairing code right now. Try again in a moment.",
+		CouldNotRetrieveManga:  "❌ I couldn't find that manga. Double-check the MangaDex ID or URL and try again!",
+		CouldNotRetrieveFeed:   "❌ I couldn't read a chapter feed at that link. Make sure it points to an RSS or Atom feed and try again!",
+		CouldNotAddManga:       "❌ I couldn't add that manga. It might already be in your list, or the ID is invalid. Open \"My Mangas\" from the main menu to check your current list.",
+		SyncFailed:             "❌ Import failed for <b>%s</b>.\n\nYou can try again from the main menu using \"Import All Chapters\".",
+		SyncFailedSimple:       "❌ Import failed for <b>%s</b>. Try again in a moment.",
+		CannotCheckUpdates:     "❌ I couldn't check MangaDex for updates right now. Try again in a bit!",
+		CannotUpdateChapter:    "❌ I couldn't update the chapter status. Try again in a moment.",
+		CannotLoadUnread:       "❌ I couldn't load unread chapters right now. Try again in a moment.",
+		CannotLoadRead:         "❌ I couldn't load read chapters right now. Try again in a moment.",
+		CannotUpdateProgress:   "❌ I couldn't update your progress right now. Try again in a moment.",
+		CannotUpdateMangaPlus:  "❌ I couldn't update the Manga Plus status. Try again in a moment.",
+		CannotRemoveManga:      "❌ I couldn't remove that manga. Try again in a moment.",
+		CannotRetrieveManga:    "❌ I couldn't retrieve manga details for removal. Try again in a moment.",
+		CannotRetrieveStatus:   "❌ I couldn't retrieve status right now. Try again in a moment.",
+		CannotGeneratePair:     "❌ I couldn't generate a pairing code right now. Try again in a moment.",
+		CannotStorePair:        "❌ I couldn't store the pairing code right now. Try again in a moment.",
+		CannotLoadPairings:     "❌ I couldn't load pairing codes right now. Try again in a moment.",
+		CannotRevokePair:       "❌ I couldn't revoke that pairing code. Try again in a moment.",
+		CannotLoadRunHistory:   "❌ I couldn't load the run history right now. Try again in a moment.",
+		CannotRunUpdate:        "❌ I couldn't finish the update right now. Try again in a moment.",
+		CannotReplaceManga:     "❌ I couldn't switch that manga to the new ID. Try again in a moment.",
+		CannotMigrateManga:     "❌ I couldn't move that manga to the new entry. Try again in a moment.",
+		CannotLoadStarter:      "❌ I couldn't find starter title %s on MangaDex.",
+		CannotLoadSettings:     "❌ I couldn't load your settings. Please try again.",
+		CannotSaveSettings:     "❌ I couldn't save that setting. Please try again.",
+		CannotLoadBacklog:      "❌ I couldn't load your backlog. Please try again.",
+		CannotUpdateCategory:   "❌ I couldn't change the category. Please try again.",
+		CannotUpdateAlerts:     "❌ I couldn't change the alert settings. Please try again.",
+		CannotUpdateTracking:   "❌ I couldn't change the tracking mode. Please try again.",
+		CannotUndo:             "❌ I couldn't undo that change. Please try again.",
+		CannotLoadHistory:      "❌ I couldn't load the history. Please try again.",
+		CannotLoadStats:        "❌ I couldn't load your stats. Please try again.",
+		CannotUpdateAliases:    "❌ I couldn't update the aliases. Please try again.",
+		CannotLoadGroupList:    "❌ I couldn't load the group list right now. Try again in a moment.",
+		CannotUpdateGroup:      "❌ I couldn't update the group right now. Try again in a moment.",
+		CannotRecommend:        "❌ I couldn't send that recommendation. Try again in a moment.",
+		CannotLoadFriends:      "❌ I couldn't load what your friends are reading. Try again in a moment.",
+		CannotDisablePairUsers: "❌ I couldn't disable those users. Try again in a moment.",
+		CannotExportLibrary:    "❌ I couldn't export your library. Please try again.",
+		LibraryImportInvalid:   "❌ That file isn't a library export I can read. Send the .json file /export gave you.",
 	},
 	Info: BotInfoCopy{
 		WelcomeTitle: "👋 *Welcome to ReleaseNoJutsu!*",
@@ -278,7 +812,20 @@ I automatically check for updates every 6 hours, but you can also check manually
 • /start - Return to the main menu
 • /help - Show this help message
 • /status - Show bot status
+• /stats - Show your reading stats, streaks and year recap
+• /export - Download your library, with categories, as a file
+• /import - Restore a file from /export
+• /add <url> - Add a manga by MangaDex URL/ID or feed link
+• /read <title> \[chapter] - Mark a chapter as read, or pick one
+• /unread \[title] \[chapter] - List titles with unread chapters, or mark one unread
+• /list \[title] - Show your library, or search it
+• /check \[title] - Check one title, or all of them, for new chapters
+• /remove <title> - Stop tracking a title
 • /genpair - Generate a pairing code (admin only)
+• /groupcode - Generate a code to link a group chat (admin only)
+• /pairings - List and revoke pairing codes (admin only)
+• /runs - Show recent update runs (admin only)
+• /updateall - Check every user's manga now (admin only)
 
 *What I Can Do:*
 • *Add manga* - Start tracking a series by sending its MangaDex URL or ID
@@ -288,6 +835,11 @@ I automatically check for updates every 6 hours, but you can also check manually
 • *Import all chapters* - Pull the full chapter history from MangaDex (useful when you're starting fresh)
 • *Mark as unread* - Move your progress back to a selected chapter
 • *Remove manga* - Stop tracking a series you're no longer reading
+• *Recommend* - Send a title from its menu to someone else using the bot, who can add it with one tap
+• *Friends* - See what the others are reading once you share your own list (your progress stays private unless you share it too)
+
+*Quick Progress Messages:*
+Send things like "read op 1130", "frieren 120 done" or "caught up blue lock" and I'll update that title. Give a title short names under *Aliases* in its menu. Every change comes with an Undo button, or send "undo frieren" to take back that title's latest change.
 
 *How to Add a Manga:*
 Just send me the MangaDex URL or ID directly.
@@ -298,87 +850,262 @@ Example: https://mangadex.org/title/40bc649f-7b49-4645-859e-6cd94136e722/dragon-
 Ask the admin for a pairing code and send it to me in a private chat.
 
 Use /start anytime to explore the menu!`,
-		StatusTitle:                 "ReleaseNoJutsu Status",
-		StatusTracked:               "Tracked manga: <b>%d</b>\n",
-		StatusChaptersStored:        "Chapters stored: <b>%d</b>\n",
-		StatusRegisteredChats:       "Total authorized accounts: <b>%d</b>\n",
-		StatusTotalUnread:           "Total unread: <b>%d</b>\n",
-		StatusLastRun:               "Last update check: <b>%s</b>\n",
-		StatusCronNever:             "Last update check: <b>never</b>\n",
-		StatusInterval:              "\nUpdate interval: every 6 hours\n",
-		ListHeader:                  "📚 <b>Your Manga Collection</b>\n\n",
-		ListEmpty:                   "You're not tracking any manga yet. Let's add your first series!",
-		ListTotal:                   "Total: <b>%d</b>",
-		NoNewChapters:               "✅ No new chapters for <b>%s</b>. You're all caught up!",
-		SyncStart:                   "🔄 Importing all chapters for <b>%s</b> from MangaDex - this might take a minute...",
-		SyncStartWithPlus:           "✅ Added <b>%s</b>!\nManga Plus: <b>%s</b>\n\n🔄 Now importing all chapters from MangaDex - this might take a minute...",
-		SyncComplete:                "✅ Import complete for <b>%s</b>!\nImported/updated %d chapters.\nUnread chapters: %d.",
-		SyncCompleteWithHint:        "✅ Import complete for <b>%s</b>!\nImported/updated %d chapters.\nUnread chapters: %d.\n\nUse \"Mark as Read\" to update your progress.",
-		MarkReadResult:              "✅ Nice! You're now caught up through Chapter <b>%s</b> of <b>%s</b>.",
-		MarkUnreadResult:            "✅ Chapter <b>%s</b> of <b>%s</b> is now marked as unread.",
-		MarkAllReadDone:             "✅ Updated <b>%s</b>!\n\n%s\nUnread: <b>%d</b>",
-		MangaDetails:                "<b>Manga Details</b>\n\n",
-		MangaPlusYes:                "Manga Plus: <b>yes</b>\n",
-		MangaPlusNo:                 "Manga Plus: <b>no</b>\n",
-		UnreadLine:                  "Unread: <b>%d</b>\n\n",
-		ReadLine:                    "Read: %d\n\n",
-		UpToDate:                    "📖 %s\n\n%s\nUnread: 0\n\n✅ You're all caught up!",
-		NothingToUnread:             "📖 %s\n\n%s\nRead: 0\n\nNothing to mark unread yet.",
-		PickRangeUnread:             "📖 %s\n\n%s\nUnread: %d\n\nSelect a range:",
-		PickRangeRead:               "📖 %s\n\n%s\nRead: %d\n\nSelect a range:",
-		PickRangeUnreadWithBucket:   "📖 %s\n\n%s\nUnread: %d\nRange: %s\n\nSelect a range:",
-		PickRangeReadWithBucket:     "📖 %s\n\n%s\nRead: %d\nRange: %s\n\nSelect a range:",
-		PickChapterRead:             "📖 %s\n\n%s\nUnread: %d\n\nSelect a chapter to mark it (and all previous ones) as read:",
-		PickChapterUnread:           "📖 %s\n\n%s\nRead: %d\n\nSelect a chapter to mark it (and all following ones) as unread:",
-		UnreadSummary:               "Unread: %d\n\n",
-		ReadSummary:                 "Read: %d\n\n",
-		MangaPlusStatus:             "✅ Manga Plus is now <b>%s</b> for <b>%s</b>.",
-		MangaPlusEnabled:            "enabled",
-		MangaPlusDisabled:           "disabled",
-		MangaRemoved:                "✅ <b>%s</b> has been removed from your tracking list.",
-		ActionMenuHeader:            "📖 <b>%s</b>\n\n",
-		ActionMenuUnread:            "Unread: <b>%d</b>\n\n",
-		ActionMenuPrompt:            "What would you like to do?",
-		DetailsTitleLine:            "Title: <b>%s</b>\n",
-		DetailsMangaDexLine:         "MangaDex: <a href=\"https://mangadex.org/title/%s\">Open</a>\n",
-		DetailsChaptersLine:         "Chapters stored: <b>%d</b> (numeric: <b>%d</b>)\n",
-		DetailsRangeLine:            "Numeric range: <b>%.1f</b> → <b>%.1f</b>\n",
-		DetailsLastReadLine:         "Last read: <b>%.1f</b>\n",
-		DetailsLastReadNoneLine:     "Last read: <b>(none)</b>\n",
-		DetailsUnreadLine:           "Unread: <b>%d</b>\n",
-		DetailsLastSeenLine:         "Last seen at: <b>%s</b>\n",
-		DetailsLastCheckedLine:      "Last checked: <b>%s</b>\n",
-		DetailsNote:                 "\nNote: I track unread/read status based on numeric chapter numbers. Non-numeric extras are excluded from progress.",
-		LastReadNone:                "Last read: (none)",
-		LastReadNoTitle:             "Last read: Ch. %s",
-		LastReadWithTitle:           "Last read: Ch. %s — %s",
-		LastReadNoneHTML:            "Last read: <b>(none)</b>",
-		LastReadNoTitleHTML:         "Last read: <b>Ch. %s</b>",
-		LastReadWithTitleHTML:       "Last read: <b>Ch. %s</b> — %s",
-		MangaPlusYesLabel:           "yes",
-		MangaPlusNoLabel:            "no",
-		NewChapterAlertTitle:        "📢 <b>New Chapter Alert!</b>\n\n",
-		NewChapterAlertHeader:       "<b>%s</b> has new chapters:\n",
-		NewChapterAlertItem:         "• <b>%s</b>: %s\n",
-		NewChapterAlertUnread:       "\nYou now have <b>%d</b> unread chapter(s) for this series.\n",
-		NewChapterAlertWarning:      "\n⚠️ <b>Heads up:</b> You have 3+ unread chapters piling up for this manga!",
-		NewChapterAlertFooter:       "\nUse /%s to open the menu, then mark chapters as read or explore other options.",
-		NewChapterAlertTitlePlain:   "📢 New Chapter Alert!\n\n",
-		NewChapterAlertHeaderPlain:  "%s has new chapters:\n",
-		NewChapterAlertItemPlain:    "• %s: %s\n",
-		NewChapterAlertUnreadPlain:  "\nYou now have %d unread chapter(s) for this series.\n",
-		NewChapterAlertWarningPlain: "\n⚠️ Heads up: you have 3+ unread chapters piling up for this manga!",
-		NewChapterAlertFooterPlain:  "\nUse /%s to open the menu, then mark chapters as read or explore other options.",
-		BreadcrumbPathFormat:        "Path: %s",
-		BreadcrumbUnreadRoot:        "Unread",
-		BreadcrumbReadRoot:          "Read",
+		StatusTitle:                  "ReleaseNoJutsu Status",
+		StatusTracked:                "Tracked manga: <b>%d</b>\n",
+		StatusChaptersStored:         "Chapters stored: <b>%d</b>\n",
+		StatusRegisteredChats:        "Total authorized accounts: <b>%d</b>\n",
+		StatusTotalUnread:            "Total unread: <b>%d</b>\n",
+		StatusLastRun:                "Last update check: <b>%s</b>\n",
+		StatusCronNever:              "Last update check: <b>never</b>\n",
+		StatusInterval:               "\nUpdate interval: every 6 hours\n",
+		ListHeader:                   "📚 <b>Your Manga Collection</b>\n\n",
+		ListEmpty:                    "You're not tracking any manga yet. Let's add your first series!",
+		ListTotal:                    "Total: <b>%d</b>",
+		NoNewChapters:                "✅ No new chapters for <b>%s</b>. You're all caught up!",
+		SyncStart:                    "🔄 Importing all chapters for <b>%s</b> from MangaDex - this might take a minute...",
+		SyncStartAdded:               "✅ Added <b>%s</b>!\n\n🔄 Now importing all chapters from MangaDex - this might take a minute...",
+		SyncStartFromSource:          "✅ Added <b>%s</b>!\n\n🔄 Now importing all chapters from its %s feed - this might take a minute...",
+		SyncComplete:                 "✅ Import complete for <b>%s</b>!\nImported/updated %d chapters.\nUnread chapters: %d.",
+		SyncCompleteWithHint:         "✅ Import complete for <b>%s</b>!\nImported/updated %d chapters.\nUnread chapters: %d.\n\nUse \"Mark as Read\" to update your progress.",
+		MarkReadResult:               "✅ Nice! You're now caught up through Chapter <b>%s</b> of <b>%s</b>.",
+		MarkUnreadResult:             "✅ Chapter <b>%s</b> of <b>%s</b> is now marked as unread.",
+		MarkAllReadDone:              "✅ Updated <b>%s</b>!\n\n%s\nUnread: <b>%d</b>",
+		MangaDetails:                 "<b>Manga Details</b>\n\n",
+		MangaPlusYes:                 "Manga Plus: <b>yes</b>\n",
+		MangaPlusNo:                  "Manga Plus: <b>no</b>\n",
+		MangaPlusWindowLine:          "Free on MANGA Plus: <b>%s</b>\n",
+		MangaPlusDetected:            "\n\n⭐ Its chapters are published on MANGA Plus, so I'll warn you before unread ones leave the free window.",
+		UnreadLine:                   "Unread: <b>%d</b>\n\n",
+		ReadLine:                     "Read: %d\n\n",
+		UpToDate:                     "📖 %s\n\n%s\nUnread: 0\n\n✅ You're all caught up!",
+		NothingToUnread:              "📖 %s\n\n%s\nRead: 0\n\nNothing to mark unread yet.",
+		PickRangeUnread:              "📖 %s\n\n%s\nUnread: %d\n\nSelect a range:",
+		PickRangeRead:                "📖 %s\n\n%s\nRead: %d\n\nSelect a range:",
+		PickRangeUnreadWithBucket:    "📖 %s\n\n%s\nUnread: %d\nRange: %s\n\nSelect a range:",
+		PickRangeReadWithBucket:      "📖 %s\n\n%s\nRead: %d\nRange: %s\n\nSelect a range:",
+		PickChapterRead:              "📖 %s\n\n%s\nUnread: %d\n\nSelect a chapter to mark it (and all previous ones) as read:",
+		PickChapterUnread:            "📖 %s\n\n%s\nRead: %d\n\nSelect a chapter to mark it (and all following ones) as unread:",
+		UnreadSummary:                "Unread: %d\n\n",
+		ReadSummary:                  "Read: %d\n\n",
+		MangaPlusStatus:              "✅ Manga Plus is now <b>%s</b> for <b>%s</b>.",
+		MangaPlusEnabled:             "enabled",
+		MangaPlusDisabled:            "disabled",
+		MangaRemoved:                 "✅ <b>%s</b> has been removed from your tracking list.",
+		ActionMenuHeader:             "📖 <b>%s</b>\n\n",
+		ActionMenuUnread:             "Unread: <b>%d</b>\n\n",
+		ActionMenuPrompt:             "What would you like to do?",
+		DetailsTitleLine:             "Title: <b>%s</b>\n",
+		DetailsMangaDexLine:          "MangaDex: <a href=\"https://mangadex.org/title/%s\">Open</a>\n",
+		DetailsChaptersLine:          "Chapters stored: <b>%d</b> (numeric: <b>%d</b>)\n",
+		DetailsRangeLine:             "Numeric range: <b>%.1f</b> → <b>%.1f</b>\n",
+		DetailsLastReadLine:          "Last read: <b>%.1f</b>\n",
+		DetailsLastReadNoneLine:      "Last read: <b>(none)</b>\n",
+		DetailsUnreadLine:            "Unread: <b>%d</b>\n",
+		DetailsLastSeenLine:          "Last seen at: <b>%s</b>\n",
+		DetailsLastCheckedLine:       "Last checked: <b>%s</b>\n",
+		DetailsNote:                  "\nNote: I track unread/read status based on numeric chapter numbers. Non-numeric extras are excluded from progress.",
+		DetailsHealthOK:              "Health: ✅ OK\n",
+		DetailsHealthFailing:         "Health: ⚠️ <b>%d</b> failed check(s) in a row\n",
+		DetailsHealthLastError:       "Last error (%s): <code>%s</code>\n",
+		DetailsHealthNextCheck:       "Next automatic check: <b>%s</b>\n",
+		TitleFailingAlert:            "⚠️ <b>%s</b> failed its last <b>%d</b> update checks.\n\nLast error: <code>%s</code>\n\nIt may have been removed or moved on MangaDex. I'll keep retrying, but less often. What would you like to do?",
+		ReplaceMangaDexDone:          "✅ <b>%s</b> now follows MangaDex ID <code>%s</code>.",
+		DetailsSourceLine:            "Source: <b>%s</b> (<a href=\"%s\">Open</a>)\n",
+		DetailsMergeCandidate:        "Moved on MangaDex: looks merged into <code>%s</code>\n",
+		MergeSuggestion:              "🔀 <b>%s</b> looks like it was merged into another MangaDex entry:\n<b>%s</b> (<code>%s</code>)\n\nMove it there? Your reading progress and stored chapters are kept, and links to the old entry will point to the new one.",
+		MigrateMangaDone:             "✅ <b>%s</b> moved to the new MangaDex entry <code>%s</code>.",
+		NoMergePending:               "There's no pending move for this manga anymore.",
+		LastReadNone:                 "Last read: (none)",
+		LastReadNoTitle:              "Last read: Ch. %s",
+		LastReadWithTitle:            "Last read: Ch. %s — %s",
+		LastReadNoneHTML:             "Last read: <b>(none)</b>",
+		LastReadNoTitleHTML:          "Last read: <b>Ch. %s</b>",
+		LastReadWithTitleHTML:        "Last read: <b>Ch. %s</b> — %s",
+		NewChapterAlertTitle:         "📢 <b>New Chapter Alert!</b>\n\n",
+		NewChapterAlertHeader:        "<b>%s</b> has new chapters:\n",
+		NewChapterAlertItem:          "• <b>%s</b>: %s%s\n",
+		NewChapterAlertUnread:        "\nYou now have <b>%d</b> unread chapter(s) for this series.\n",
+		NewChapterAlertWarning:       "\n⚠️ <b>Heads up:</b> You have %d unread chapters piling up for this manga!",
+		NewChapterAlertMangaPlus:     " — <a href=\"%s\">available on MANGA Plus</a>",
+		NewChapterAlertExternal:      " — <a href=\"%s\">read it here</a>",
+		NewChapterAlertExpiring:      "\n⏳ <b>Heads up:</b> Chapter <b>%s</b> is the oldest free chapter on MANGA Plus. It goes behind the paywall with the next release.",
+		NewChapterAlertPaywalled:     "\n🔒 %d unread chapter(s) already left the MANGA Plus free window.",
+		NewChapterAlertFooter:        "\nUse /%s to open the menu, then mark chapters as read or explore other options.",
+		NewChapterAlertGroupFooter:   "\n📚 From this group's shared list. /%s shows every title in it.",
+		NewChapterAlertTitlePlain:    "📢 New Chapter Alert!\n\n",
+		NewChapterAlertHeaderPlain:   "%s has new chapters:\n",
+		NewChapterAlertItemPlain:     "• %s: %s\n",
+		NewChapterAlertUnreadPlain:   "\nYou now have %d unread chapter(s) for this series.\n",
+		NewChapterAlertWarningPlain:  "\n⚠️ Heads up: you have 3+ unread chapters piling up for this manga!",
+		NewChapterAlertFooterPlain:   "\nUse /%s to open the menu, then mark chapters as read or explore other options.",
+		BreadcrumbPathFormat:         "Path: %s",
+		BreadcrumbUnreadRoot:         "Unread",
+		BreadcrumbReadRoot:           "Read",
+		PairingListTitle:             "🔑 <b>Pairing Codes</b>\n\n",
+		PairingListEmpty:             "No pairing codes yet.",
+		PairingListItem:              "<b>%s</b>",
+		PairingListItemLabel:         " — %s",
+		PairingListUsage:             "\nUsed <b>%d</b>/<b>%d</b>",
+		PairingListExpires:           " · expires %s",
+		PairingListExpired:           " · expired",
+		PairingListRevoked:           " · revoked",
+		PairingListJoined:            "\nJoined: %s",
+		PairingRevoked:               "✅ Pairing code <b>%s</b> revoked. Users who already joined keep their access.",
+		RunHistoryTitle:              "🕑 <b>Recent Update Runs</b>\n\n",
+		RunHistoryEmpty:              "No runs recorded yet.",
+		RunHistoryItem:               "<b>#%d</b> %s · %s · %s\n%d checked · %d new · %d sent · %d failed",
+		RunHistoryRunning:            "running",
+		RunHistoryError:              "\n❌ %s",
+		RunDetailTitle:               "🔎 <b>Run #%d</b> (%s)\n",
+		RunDetailSummary:             "Started: %s\nDuration: %s\nChecked <b>%d</b> · New <b>%d</b> · Sent <b>%d</b> · Failed <b>%d</b>\n",
+		RunDetailNoTitles:            "\nNo titles were checked in this run.",
+		RunDetailTitleItem:           "\n• %s (%s)",
+		RunDetailTitleNew:            " · <b>%d</b> new",
+		RunDetailTitleError:          "\n  ❌ %s",
+		RunDetailMore:                "\n\n…and %d more.",
+		CheckAllStarting:             "⏳ Checking all your manga…",
+		CheckAllProgress:             "⏳ <b>%d/%d</b> checked…",
+		CheckAllDone:                 "✅ All done! Checked <b>%d</b> manga · <b>%d</b> new chapter(s) · <b>%d</b> failed.",
+		UpdateAllStarting:            "⏳ Starting a global update…",
+		UpdateAllDone:                "✅ Global update finished. Checked <b>%d</b> · New <b>%d</b> · Notified <b>%d</b> · Failed <b>%d</b>.",
+		SettingsText:                 "⚙️ <b>Settings</b>\n\nUnread warning: <b>%s</b>\nWeekly backlog report: <b>%s</b>\n\nWarn me when a title has this many unread chapters. Each title can override it from its menu.",
+		UnreadWarningMenu:            "⚠️ <b>Unread warning for %s</b>\n\nCurrently: <b>%s</b>\nYour default: <b>%s</b>\n\nWarn me when unread chapters reach:",
+		BacklogReportTitle:           "📋 <b>Your Backlog</b>\n\nThese titles are at or above their unread warning:\n\n",
+		BacklogReportItem:            "• <b>%s</b>: %d unread (warns at %d)\n",
+		BacklogReportEmpty:           "📋 <b>Your Backlog</b>\n\nNothing is piling up. Nice work! 🎉",
+		DetailsUnreadWarningLine:     "Unread warning: <b>%s</b>\n",
+		CategoryMenu:                 "🗂️ <b>%s</b>\n\nCurrently in: <b>%s</b>\n\nMove it to:",
+		CategoryNotificationsText:    "🔔 <b>Notifications by Category</b>\n\nTap a category to mute or unmute new-chapter messages for its titles. Muted titles are still checked and their unread counts stay up to date.",
+		ListFilterLine:               "Showing: <b>%s</b>\n\n",
+		ListCategoryEmpty:            "Nothing in this category yet.\n\n",
+		ListPage:                     " · Page <b>%d/%d</b>",
+		StatusCategoriesTitle:        "\n<b>By category</b>\n",
+		StatusCategoryLine:           "%s: <b>%d</b>\n",
+		DetailsCategoryLine:          "Category: <b>%s</b>\n",
+		AlertSettingsMenu:            "🔔 <b>Alerts for %s</b>\n\nStatus: <b>%s</b>\nAlert me: <b>%s</b>\n\nMuted and snoozed titles are still checked and their new chapters counted as unread.",
+		DetailsAlertsLine:            "Alerts: <b>%s</b>, %s\n",
+		ListSortLine:                 "Sorted by: <b>%s</b>\n\n",
+		ListFilterEmpty:              "Nothing matches this filter.\n\n",
+		ListSearchResults:            "🔎 <b>Results for “%s”</b>\n\nFound: <b>%d</b>",
+		ListSearchMore:               "\nShowing the first %d — type more of the title to narrow it down.",
+		ListIndexText:                "🔤 <b>Jump to a letter</b>\n\nShowing: <b>%s</b>",
+		MarkReadResultExact:          "✅ Chapter <b>%s</b> of <b>%s</b> is now marked as read.",
+		PickChapterReadExact:         "📖 %s\n\n%s\nUnread: %d\n\nSelect a chapter to mark just that one as read:",
+		PickChapterUnreadExact:       "📖 %s\n\n%s\nRead: %d\n\nSelect a chapter to mark just that one as unread:",
+		ExtrasText:                   "📎 <b>Extras — %s</b>\n\nOneshots and side stories without a chapter number. Tap one to mark it read or unread.",
+		ExtrasTextWatermark:          "📎 <b>Extras — %s</b>\n\nExtras are only tracked with exact tracking. Turn it on to mark them read.",
+		ExtrasEmpty:                  "\n\nNo extras found for this title.",
+		DetailsTrackingLine:          "Tracking: <b>%s</b>\n",
+		DetailsReadCountLine:         "Read: <b>%d</b>\n",
+		HistoryTitle:                 "🕑 <b>History — %s</b>\n\n",
+		HistoryEmpty:                 "No progress changes yet.",
+		HistoryItem:                  "• %s · %s\n",
+		UndoDone:                     "↩️ Undone for <b>%s</b>.\n\n%s\nUnread: <b>%d</b>",
+		UndoUnavailable:              "ℹ️ That change can't be undone anymore: it was already undone, or the progress changed since.",
+		StatsTitle:                   "📊 <b>Your Reading Stats</b>\n\n",
+		StatsEmpty:                   "No reading recorded yet. Mark chapters as read and your stats show up here.",
+		StatsPeriods:                 "This week: <b>%d</b> · This month: <b>%d</b> chapters\n",
+		StatsWeeks:                   "Last %d weeks: <code>%s</code>\n",
+		StatsMonths:                  "Last %d months: <code>%s</code>\n",
+		StatsStreak:                  "🔥 Streak: <b>%d</b> days (longest <b>%d</b>)\n",
+		StatsBacklog:                 "📚 Backlog: <b>%d</b> unread in <b>%d</b> of <b>%d</b> titles (avg <b>%.1f</b> per title behind)\n",
+		StatsBacklogNone:             "📚 Backlog: caught up on all <b>%d</b> titles\n",
+		StatsTopTitle:                "\n<b>Most read</b>\n",
+		StatsTopItem:                 "%d. %s — %d\n",
+		StatsLagTitle:                "\n<b>Release → read</b>\n",
+		StatsLagItem:                 "• %s — %s (%d chapters)\n",
+		RecapTitle:                   "🎉 <b>Your %d in manga</b>\n\n",
+		RecapTotals:                  "You read <b>%d</b> chapters across <b>%d</b> titles.\n\n",
+		RecapMonths:                  "Jan–Dec: <code>%s</code>\n",
+		RecapBusiestMonth:            "Busiest month: <b>%s</b> (%d chapters)\n",
+		RecapLongestStreak:           "Longest streak: <b>%d</b> days\n",
+		RecapEmpty:                   "🎉 <b>Your %d in manga</b>\n\nNo chapters marked as read this year.",
+		AliasesTitle:                 "🏷 <b>Aliases — %s</b>\n\n",
+		AliasesEmpty:                 "No aliases yet.\n",
+		AliasItem:                    "• <code>%s</code>\n",
+		AliasesHint:                  "\nAliases are extra names for quick messages such as <code>read op 1130</code> and for commands like /read. Tap an alias to remove it.",
+		InlineCardCover:              "<a href=\"%s\">\u200b</a>",
+		InlineCardTitle:              "📖 <b>%s</b>\n",
+		InlineCardStatus:             "Status: %s\n",
+		InlineCardLatest:             "Latest chapter: <b>%s</b>\n",
+		InlineCardLink:               "\n<a href=\"%s\">Open on MangaDex</a>",
+		DetailsShareLine:             "Share: <a href=\"%s\">add link</a>\n",
+		FriendsReadingTitle:          "👥 <b>Friends Are Reading</b>\n\n",
+		FriendsReadingOptIn:          "👥 <b>Friends Are Reading</b>\n\nSee which titles the other people paired with me follow. It works both ways: once you share your list, they see your titles too and can recommend titles to you.\n\nYour reading progress stays private unless you also choose to share it.",
+		FriendsReadingEmpty:          "Nobody else is sharing their list yet.\n",
+		FriendsReadingLine:           "• <b>%s</b> — %s\n",
+		FriendsReadingMore:           "…and %d more.\n",
+		FriendsReadingProgressHidden: "\n<i>You're sharing your list. Your progress is hidden.</i>",
+		FriendsReadingProgressShared: "\n<i>You're sharing your list and your progress.</i>",
+		PairingUsersDisabled:         "⛔ Disabled <b>%d</b> user(s) who joined with <b>%s</b>.",
+		LibraryExported:              "📤 Your library: %d titles with their categories. Send this file back with /import any time to restore it.",
+		LibraryImported:              "📥 Library imported: <b>%d</b> added, <b>%d</b> refiled, <b>%d</b> skipped.\nI'm importing the new titles' chapters in the background.",
 	},
 	Labels: BotLabelsCopy{
-		ChapterPrefix:      "Ch. %s",
-		ChapterWithTitle:   "Ch. %s: %s",
-		MangaPlusPrefix:    "⭐ ",
-		ListItemFormat:     "%d. %s",
-		ListUnreadSuffix:   " (%d unread)",
-		ExtraChapterNumber: "Extra",
+		ChapterPrefix:              "Ch. %s",
+		ChapterWithTitle:           "Ch. %s: %s",
+		MangaPlusPrefix:            "⭐ ",
+		ListItemFormat:             "%d. %s",
+		ListUnreadSuffix:           " (%d unread)",
+		ExtraChapterNumber:         "Extra",
+		UnreadWarningAt:            "at %d+ unread",
+		UnreadWarningOff:           "off",
+		UnreadWarningDefault:       "%s (your default)",
+		BacklogReportWeekly:        "on, every Monday",
+		BacklogReportDisabled:      "off",
+		CategoryAll:                "📚 All",
+		CategoryReading:            "📖 Reading",
+		CategoryPlanToRead:         "🗓️ Plan to Read",
+		CategoryOnHold:             "⏸️ On Hold",
+		CategoryDropped:            "🛑 Dropped",
+		CategoryCompleted:          "🏁 Completed",
+		CategoryTab:                "%s (%d)",
+		CategoryMuted:              "🔕 %s",
+		CategoryUnmuted:            "🔔 %s",
+		AlertsOn:                   "on",
+		AlertsMuted:                "muted",
+		AlertsSnoozed:              "snoozed until %s",
+		NotifyEveryChapter:         "for every new chapter",
+		NotifyEveryN:               "once %d new chapters pile up",
+		NotifyEveryPending:         " (%d so far)",
+		SnoozeOneDay:               "1 Day",
+		SnoozeOneWeek:              "1 Week",
+		SnoozeOneMonth:             "1 Month",
+		SortTitle:                  "Title",
+		SortUnread:                 "Most unread",
+		SortLastRelease:            "Latest release",
+		SortRecent:                 "Recently added",
+		FilterUnread:               "📬 Has unread",
+		FilterMangaPlus:            "⭐ MANGA Plus",
+		FilterFailing:              "⚠️ Failing",
+		ListFilterJoin:             "%s · %s",
+		ListIndexOther:             "#",
+		TrackingWatermark:          "Up to last read",
+		TrackingExact:              "Exact",
+		ExtraRead:                  "✅ %s",
+		ExtraUnread:                "⬜ %s",
+		ExtraUntitled:              "Untitled extra",
+		HistoryRead:                "Read ch. %s",
+		HistoryUnread:              "Unread ch. %s",
+		HistoryReadAll:             "Read all",
+		HistoryUndo:                "Undo",
+		HistoryDelta:               " (%+d)",
+		HistoryVia:                 " · via %s",
+		HistoryUndone:              " · undone",
+		LagUnderHour:               "under an hour",
+		LagHours:                   "%d hours",
+		LagDays:                    "%.1f days",
+		PubStatusOngoing:           "Ongoing",
+		PubStatusCompleted:         "Completed",
+		PubStatusHiatus:            "On hiatus",
+		PubStatusCancelled:         "Cancelled",
+		InlineInLibrary:            "In your library · %d unread",
+		InlineFromMangaDex:         "MangaDex",
+		InlineLatest:               "Latest: ch. %s",
+		FriendUnnamed:              "User %d",
+		FriendProgress:             "%s (ch. %s)",
+		UnreadWarningMangaPlusOnly: "%s, MANGA Plus titles only",
+		HistoryTracking:            "Tracking: %s",
+		HistoryAdded:               "Added",
 	},
 }
@@ -0,0 +1,236 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+const pendingStateSnoozeDate = "snooze_date"
+
+// snoozeDateLayout is the format users type a snooze date in and see it back.
+const snoozeDateLayout = "2006-01-02"
+
+// maxSnooze is how far ahead a title can be snoozed.
+const maxSnooze = 366 * 24 * time.Hour
+
+var (
+	snoozePresets      = []int{1, 7, 30}
+	notifyEveryPresets = []int{1, 3, 5, 10}
+)
+
+func snoozePresetLabel(days int) string {
+	switch days {
+	case 1:
+		return appcopy.Copy.Labels.SnoozeOneDay
+	case 7:
+		return appcopy.Copy.Labels.SnoozeOneWeek
+	default:
+		return appcopy.Copy.Labels.SnoozeOneMonth
+	}
+}
+
+// alertTarget keeps notifications intact: buttons on them answer with a new message.
+func alertTarget(payload callbackPayload, target *callbackEditTarget) *callbackEditTarget {
+	if payload.FromAlert {
+		return nil
+	}
+	return target
+}
+
+// alertStatusLabels describes the title's alert settings for menus and the details view.
+func alertStatusLabels(s db.NotifySettings, now time.Time) (status, cadence string) {
+	switch {
+	case s.Muted:
+		status = appcopy.Copy.Labels.AlertsMuted
+	case s.IsSnoozed(now):
+		status = fmt.Sprintf(appcopy.Copy.Labels.AlertsSnoozed, s.SnoozedUntil.Local().Format(snoozeDateLayout))
+	default:
+		status = appcopy.Copy.Labels.AlertsOn
+	}
+	cadence = appcopy.Copy.Labels.NotifyEveryChapter
+	if s.Every > 1 {
+		cadence = fmt.Sprintf(appcopy.Copy.Labels.NotifyEveryN, s.Every)
+		if s.Pending > 0 {
+			cadence += fmt.Sprintf(appcopy.Copy.Labels.NotifyEveryPending, s.Pending)
+		}
+	}
+	return status, cadence
+}
+
+// AlertNewChapters sends a new-chapters notification with buttons to mute or snooze the
+// title, or without buttons to a group. It implements cron.ChapterAlerter.
+func (b *Bot) AlertNewChapters(userID int64, mangaID int, text string) error {
+	msg := tgbotapi.NewMessage(userID, text)
+	msg.ParseMode = "HTML"
+	if db.IsGroupChatID(userID) {
+		// Alert settings are changed in private chats, so group alerts get no buttons.
+		_, err := b.api.Send(msg)
+		return err
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MuteAlerts, cbSetMangaMute(mangaID, true, true)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.SnoozeDays, appcopy.Copy.Labels.SnoozeOneWeek), cbSnoozeManga(mangaID, 7, true)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.AlertSettings, cbAlertSettings(mangaID, true)),
+		),
+	)
+	_, err := b.api.Send(msg)
+	return err
+}
+
+func (b *Bot) sendAlertSettings(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	settings, err := b.db.GetMangaNotifySettings(mangaID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading alert settings", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	now := time.Now()
+	status, cadence := alertStatusLabels(settings, now)
+
+	muteButton := tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MuteAlerts, cbSetMangaMute(mangaID, true, false))
+	if settings.Muted {
+		muteButton = tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.UnmuteAlerts, cbSetMangaMute(mangaID, false, false))
+	}
+	keyboard := [][]tgbotapi.InlineKeyboardButton{{muteButton}}
+
+	var snooze []tgbotapi.InlineKeyboardButton
+	for _, days := range snoozePresets {
+		label := fmt.Sprintf(appcopy.Copy.Buttons.SnoozeDays, snoozePresetLabel(days))
+		snooze = append(snooze, tgbotapi.NewInlineKeyboardButtonData(label, cbSnoozeManga(mangaID, days, false)))
+	}
+	keyboard = append(keyboard, snooze)
+	dateRow := []tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.SnoozeDate, cbSnoozeDatePrompt(mangaID))}
+	if settings.IsSnoozed(now) {
+		dateRow = append(dateRow, tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.EndSnooze, cbSnoozeManga(mangaID, 0, false)))
+	}
+	keyboard = append(keyboard, dateRow)
+
+	var every []tgbotapi.InlineKeyboardButton
+	for _, n := range notifyEveryPresets {
+		label := fmt.Sprintf(appcopy.Copy.Buttons.NotifyEvery, n)
+		if n == settings.Every {
+			label = fmt.Sprintf(appcopy.Copy.Buttons.Selected, label)
+		}
+		every = append(every, tgbotapi.NewInlineKeyboardButtonData(label, cbSetNotifyEvery(mangaID, n)))
+	}
+	keyboard = append(keyboard, every)
+	keyboard = appendBackToMangaRow(keyboard, mangaID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.AlertSettingsMenu, html.EscapeString(title), status, cadence))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// applyAlertChange runs update and re-renders the alert settings, reporting a missing or
+// foreign title the same way as other manga actions.
+func (b *Bot) applyAlertChange(chatID int64, userID int64, mangaID int, cbTarget *callbackEditTarget, update func() error) {
+	if err := update(); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg, cbTarget)
+			return
+		}
+		userLog(userID, mangaID).Error("Error updating alert settings", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateAlerts)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.sendAlertSettings(chatID, userID, mangaID, cbTarget)
+}
+
+func (b *Bot) handleSetMangaMute(chatID int64, userID int64, mangaID int, muted bool, target ...*callbackEditTarget) {
+	b.logAction(userID, "Set manga mute", fmt.Sprintf("Manga ID: %d, Muted: %t", mangaID, muted))
+	b.applyAlertChange(chatID, userID, mangaID, firstCallbackTarget(target...), func() error {
+		return b.db.SetMangaMuted(mangaID, userID, muted)
+	})
+}
+
+// handleSnoozeManga snoozes alerts for days; 0 ends the snooze.
+func (b *Bot) handleSnoozeManga(chatID int64, userID int64, mangaID int, days int, target ...*callbackEditTarget) {
+	var until time.Time
+	if days > 0 {
+		until = time.Now().Add(min(time.Duration(days)*24*time.Hour, maxSnooze))
+	}
+	b.snoozeUntil(chatID, userID, mangaID, until, firstCallbackTarget(target...))
+}
+
+func (b *Bot) snoozeUntil(chatID int64, userID int64, mangaID int, until time.Time, cbTarget *callbackEditTarget) {
+	b.logAction(userID, "Snooze manga", fmt.Sprintf("Manga ID: %d, Until: %s", mangaID, until.Format(time.RFC3339)))
+	b.applyAlertChange(chatID, userID, mangaID, cbTarget, func() error {
+		return b.db.SetMangaSnooze(mangaID, userID, until)
+	})
+}
+
+func (b *Bot) handleSetNotifyEvery(chatID int64, userID int64, mangaID int, n int, target ...*callbackEditTarget) {
+	b.logAction(userID, "Set notify every", fmt.Sprintf("Manga ID: %d, Every: %d", mangaID, n))
+	b.applyAlertChange(chatID, userID, mangaID, firstCallbackTarget(target...), func() error {
+		return b.db.SetMangaNotifyEvery(mangaID, userID, n)
+	})
+}
+
+func (b *Bot) sendSnoozeDatePrompt(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if err := b.db.SetUserPendingState(userID, pendingStateSnoozeDate, strconv.Itoa(mangaID)); err != nil {
+		userLog(userID, mangaID).Warn("Failed to set pending state", "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.SnoozeDate, html.EscapeString(title)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbAlertSettings(mangaID, false)),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// consumeSnoozeDateInput handles the reply to sendSnoozeDatePrompt.
+func (b *Bot) consumeSnoozeDateInput(message *tgbotapi.Message, payload string) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	mangaID, err := strconv.Atoi(payload)
+	if err != nil {
+		userLog(userID).Warn("Invalid snooze_date payload", "payload", payload)
+		b.clearPendingState(userID)
+		return
+	}
+
+	now := time.Now()
+	until, err := time.ParseInLocation(snoozeDateLayout, strings.TrimSpace(message.Text), time.Local)
+	if err != nil || !until.After(now) || until.Sub(now) > maxSnooze {
+		// Keep pending state until the user sends a usable date.
+		b.sendSnoozeDatePrompt(chatID, userID, mangaID)
+		return
+	}
+	b.clearPendingState(userID)
+	b.snoozeUntil(chatID, userID, mangaID, until, nil)
+}
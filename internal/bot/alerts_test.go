@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestAlertNewChapters_ButtonsLeaveNotificationInPlace(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Alert Manga", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+
+	if err := b.AlertNewChapters(userID, mangaID, "new chapters"); err != nil {
+		t.Fatalf("AlertNewChapters(): %v", err)
+	}
+	msg := api.lastMessageConfig(t)
+	callbacks := messageCallbacks(t, msg)
+	for _, want := range []string{cbSetMangaMute(mangaID, true, true), cbSnoozeManga(mangaID, 7, true), cbAlertSettings(mangaID, true)} {
+		if !hasCallback(callbacks, want) {
+			t.Fatalf("missing %q in %v", want, callbacks)
+		}
+	}
+
+	sends := len(api.sent)
+	b.handleCallbackQuery(&tgbotapi.CallbackQuery{
+		ID:      "cb-snooze",
+		Data:    cbSnoozeManga(mangaID, 7, true),
+		From:    &tgbotapi.User{ID: userID},
+		Message: &tgbotapi.Message{MessageID: 200, Chat: &tgbotapi.Chat{ID: userID}},
+	})
+	if len(api.sent) != sends+1 {
+		t.Fatalf("sends=%d, want the settings as a new message", len(api.sent)-sends)
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "snoozed until") {
+		t.Fatalf("settings=%q, want snoozed", got)
+	}
+	settings, err := database.GetMangaNotifySettings(mangaID)
+	if err != nil || !settings.IsSnoozed(time.Now().Add(6*24*time.Hour)) || settings.IsSnoozed(time.Now().Add(8*24*time.Hour)) {
+		t.Fatalf("settings=%+v err=%v, want snoozed for a week", settings, err)
+	}
+}
+
+func TestAlertSettings_MuteEveryAndSnoozeDate(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Alert Manga", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+
+	b.handleMangaSelection(userID, userID, mangaID, "alerts")
+	if got := api.lastMessageText(t); !strings.Contains(got, "Status: <b>on</b>") {
+		t.Fatalf("alerts=%q, want on", got)
+	}
+
+	b.handleSetMangaMute(userID, userID, mangaID, true)
+	b.handleSetNotifyEvery(userID, userID, mangaID, 5)
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "muted") || !strings.Contains(msg.Text, "once 5 new chapters pile up") {
+		t.Fatalf("alerts=%q, want muted and every 5", msg.Text)
+	}
+	if !hasCallback(messageCallbacks(t, msg), cbSetMangaMute(mangaID, false, false)) {
+		t.Fatal("muted title should offer unmute")
+	}
+
+	b.sendSnoozeDatePrompt(userID, userID, mangaID)
+	input := func(text string) {
+		t.Helper()
+		msg := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}, From: &tgbotapi.User{ID: userID}, Text: text}
+		if !b.consumePendingInput(msg) {
+			t.Fatalf("consumePendingInput(%q) = false", text)
+		}
+	}
+	input("2001-01-01")
+	if _, _, ok, _ := database.GetUserPendingState(userID); !ok {
+		t.Fatal("a past date should keep the pending state")
+	}
+	date := time.Now().AddDate(0, 0, 10).Format(snoozeDateLayout)
+	input(date)
+	if _, _, ok, _ := database.GetUserPendingState(userID); ok {
+		t.Fatal("pending state should be cleared")
+	}
+	settings, _ := database.GetMangaNotifySettings(mangaID)
+	if settings.SnoozedUntil.Local().Format(snoozeDateLayout) != date {
+		t.Fatalf("snoozed until %s, want %s", settings.SnoozedUntil, date)
+	}
+
+	// Someone else's title is left alone.
+	b.handleSetMangaMute(99, 99, mangaID, false)
+	if settings, _ := database.GetMangaNotifySettings(mangaID); !settings.Muted {
+		t.Fatal("another user unmuted the title")
+	}
+}
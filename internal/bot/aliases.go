@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+const pendingStateTitleAlias = "title_alias"
+
+// Limits on aliases. maxAliasLength keeps the remove button's callback data within
+// Telegram's 64 bytes.
+const (
+	maxAliasesPerTitle = 10
+	maxAliasLength     = 32
+)
+
+func (b *Bot) sendTitleAliases(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	var aliases []string
+	if err == nil {
+		aliases, err = b.db.ListTitleAliases(mangaID)
+	}
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading aliases", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.AliasesTitle, html.EscapeString(title)))
+	if len(aliases) == 0 {
+		bld.WriteString(appcopy.Copy.Info.AliasesEmpty)
+	}
+	for _, alias := range aliases {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.AliasItem, html.EscapeString(alias)))
+	}
+	bld.WriteString(appcopy.Copy.Info.AliasesHint)
+
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, len(aliases)+1)
+	for _, alias := range aliases {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.RemoveAlias, alias), cbRemoveTitleAlias(mangaID, alias)),
+		))
+	}
+	if len(aliases) < maxAliasesPerTitle {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.AddAlias, cbMangaAction(mangaID, "alias_add")),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+}
+
+func (b *Bot) sendTitleAliasPrompt(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if err := b.db.SetUserPendingState(userID, pendingStateTitleAlias, strconv.Itoa(mangaID)); err != nil {
+		userLog(userID, mangaID).Warn("Failed to set pending state", "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.AliasPrompt, html.EscapeString(title), maxAliasLength))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbMangaAction(mangaID, "aliases")),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// consumeTitleAliasInput handles the reply to sendTitleAliasPrompt. Aliases are stored
+// normalized, the way quick input is compared.
+func (b *Bot) consumeTitleAliasInput(message *tgbotapi.Message, payload string) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	mangaID, err := strconv.Atoi(payload)
+	if err != nil {
+		userLog(userID).Warn("Invalid title_alias payload", "payload", payload)
+		b.clearPendingState(userID)
+		return
+	}
+
+	alias := normalizeTitle(message.Text)
+	if alias == "" || len(alias) > maxAliasLength {
+		// Keep pending state until the user sends a usable alias.
+		b.sendTitleAliasPrompt(chatID, userID, mangaID)
+		return
+	}
+	b.clearPendingState(userID)
+	b.logAction(userID, "Add alias", fmt.Sprintf("Manga ID: %d, Alias: %q", mangaID, alias))
+
+	existing, err := b.db.ListTitleAliasesByUser(userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading aliases", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateAliases)
+		b.sendMangaScopedMessage(msg, mangaID)
+		return
+	}
+	for otherID, aliases := range existing {
+		if otherID == mangaID {
+			continue
+		}
+		for _, other := range aliases {
+			if other != alias {
+				continue
+			}
+			otherTitle, _ := b.db.GetMangaTitle(otherID, userID)
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.AliasTaken, html.EscapeString(alias), html.EscapeString(otherTitle)))
+			msg.ParseMode = "HTML"
+			b.sendMangaScopedMessage(msg, mangaID)
+			return
+		}
+	}
+	if len(existing[mangaID]) >= maxAliasesPerTitle {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.AliasLimit, maxAliasesPerTitle))
+		b.sendMangaScopedMessage(msg, mangaID)
+		return
+	}
+
+	if _, err := b.db.AddTitleAlias(mangaID, userID, alias); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg)
+			return
+		}
+		userLog(userID, mangaID).Error("Error adding alias", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateAliases)
+		b.sendMangaScopedMessage(msg, mangaID)
+		return
+	}
+	b.sendTitleAliases(chatID, userID, mangaID)
+}
+
+func (b *Bot) handleRemoveTitleAlias(chatID int64, userID int64, mangaID int, alias string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	b.logAction(userID, "Remove alias", fmt.Sprintf("Manga ID: %d, Alias: %q", mangaID, alias))
+	if err := b.db.RemoveTitleAlias(mangaID, userID, alias); err != nil {
+		userLog(userID, mangaID).Error("Error removing alias", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateAliases)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.sendTitleAliases(chatID, userID, mangaID, cbTarget)
+}
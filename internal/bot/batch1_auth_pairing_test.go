@@ -2,6 +2,9 @@ package bot
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"strings"
 	"sync"
@@ -163,6 +166,43 @@ func TestRun_NonPrivateCallbackGetsPrivateOnlyWarning(t *testing.T) {
 	}
 }
 
+func TestRegisterCommands_AdminCommandsOnlyInAdminChat(t *testing.T) {
+	api := &runTelegramAPI{}
+	b, _ := setupBotForRunTests(t, api)
+	b.registerCommands()
+
+	listed := func(cfg tgbotapi.SetMyCommandsConfig, command string) bool {
+		for _, c := range cfg.Commands {
+			if c.Command == command {
+				return true
+			}
+		}
+		return false
+	}
+	var sawDefault, sawAdmin bool
+	for _, c := range api.requested {
+		cfg, ok := c.(tgbotapi.SetMyCommandsConfig)
+		if !ok {
+			continue
+		}
+		switch {
+		case cfg.Scope == nil:
+			sawDefault = true
+			if listed(cfg, appcopy.Copy.Commands.GenPair) || listed(cfg, appcopy.Copy.Commands.UpdateAll) {
+				t.Fatalf("default commands list admin commands: %+v", cfg.Commands)
+			}
+		case cfg.Scope.Type == "chat" && cfg.Scope.ChatID == 1:
+			sawAdmin = true
+			if !listed(cfg, appcopy.Copy.Commands.GenPair) || !listed(cfg, appcopy.Copy.Commands.Read) {
+				t.Fatalf("admin commands=%+v, want everything plus admin commands", cfg.Commands)
+			}
+		}
+	}
+	if !sawDefault || !sawAdmin {
+		t.Fatalf("requests=%+v, want default and admin command lists", api.requested)
+	}
+}
+
 func TestIsPrivateChat(t *testing.T) {
 	if !isPrivateChat(&tgbotapi.Chat{ID: 42}, &tgbotapi.User{ID: 42}) {
 		t.Fatal("expected private chat to be true")
@@ -444,3 +484,186 @@ func TestGeneratePairingCode_Format(t *testing.T) {
 		t.Fatalf("generated code should be parseable, got %q", code)
 	}
 }
+
+func TestParsePairingArgs(t *testing.T) {
+	toID := func(raw string) (string, bool) {
+		if raw == "bad" {
+			return "", false
+		}
+		return "id-" + raw, true
+	}
+
+	req, err := parsePairingArgs("", toID)
+	if err != nil {
+		t.Fatalf("parsePairingArgs(empty): %v", err)
+	}
+	if req.MaxUses != 1 || req.TTL != defaultPairingTTL || req.Label != "" || len(req.Starters) != 0 {
+		t.Fatalf("defaults=%+v", req)
+	}
+
+	req, err = parsePairingArgs("uses=5 ttl=7d starter=a,b Discord friends", toID)
+	if err != nil {
+		t.Fatalf("parsePairingArgs(full): %v", err)
+	}
+	if req.MaxUses != 5 || req.TTL != 7*24*time.Hour || req.Label != "Discord friends" {
+		t.Fatalf("req=%+v", req)
+	}
+	if len(req.Starters) != 2 || req.Starters[0] != "id-a" || req.Starters[1] != "id-b" {
+		t.Fatalf("starters=%v", req.Starters)
+	}
+
+	for _, bad := range []string{"uses=0", "uses=1000", "ttl=forever", "ttl=365d", "starter=bad", "label=" + strings.Repeat("x", 80)} {
+		if _, err := parsePairingArgs(bad, toID); err == nil {
+			t.Fatalf("parsePairingArgs(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestHandleGeneratePairingCodeCommand_MultiUseWithLabel(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	b.handleGeneratePairingCodeCommand(1, 1, "uses=3 ttl=12h Book club")
+
+	got := api.lastMessageText(t)
+	if !strings.Contains(got, "Book club") || !strings.Contains(got, "<b>3</b> times") {
+		t.Fatalf("message=%q, want label and use count", got)
+	}
+	code := regexp.MustCompile(`[A-F0-9]{4}-[A-F0-9]{4}`).FindString(got)
+
+	var maxUses int
+	var label string
+	if err := database.QueryRow("SELECT max_uses, label FROM pairing_codes WHERE code = ?", code).Scan(&maxUses, &label); err != nil {
+		t.Fatalf("pairing code lookup: %v", err)
+	}
+	if maxUses != 3 || label != "Book club" {
+		t.Fatalf("stored max_uses=%d label=%q", maxUses, label)
+	}
+}
+
+func TestHandleGeneratePairingCodeCommand_InvalidArgs(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+	b.handleGeneratePairingCodeCommand(1, 1, "uses=abc")
+	if got := api.lastMessageText(t); !strings.Contains(got, "Usage: /genpair") {
+		t.Fatalf("message=%q, want usage hint", got)
+	}
+}
+
+func TestTryHandlePairingCode_AddsStarterLibrary(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+
+	const code = "ABCD-1234"
+	opts := db.PairingCodeOptions{
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		MaxUses:   2,
+		Starters:  []db.PairingStarter{{MangaDexID: "md-starter", Title: "Starter Manga"}},
+	}
+	if err := database.CreatePairingCodeWithOptions(code, 1, opts); err != nil {
+		t.Fatalf("CreatePairingCodeWithOptions(): %v", err)
+	}
+
+	ok := b.tryHandlePairingCode(&tgbotapi.Message{
+		Text: code,
+		From: &tgbotapi.User{ID: 42},
+		Chat: &tgbotapi.Chat{ID: 42, Type: "private"},
+	})
+	if !ok {
+		t.Fatal("expected pairing message to be consumed")
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "Starter Manga") {
+		t.Fatalf("message=%q, want starter library confirmation", got)
+	}
+
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM manga WHERE user_id = 42 AND mangadex_id = 'md-starter'").Scan(&count); err != nil {
+		t.Fatalf("count manga: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("starter manga rows=%d, want 1", count)
+	}
+	var source string
+	if err := database.QueryRow("SELECT source FROM reading_history WHERE user_id = 42 AND action = ?", db.HistoryActionAdd).Scan(&source); err != nil || source != db.HistorySourceImport {
+		t.Fatalf("starter add entry source=%q err=%v, want %q", source, err, db.HistorySourceImport)
+	}
+}
+
+func TestRevokePairingCode_AdminFlow(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+
+	const code = "ABCD-1234"
+	if err := database.CreatePairingCode(code, 1, time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("CreatePairingCode(): %v", err)
+	}
+	if ok, err := database.RedeemPairingCode(code, 42); err != nil || !ok {
+		t.Fatalf("RedeemPairingCode() = (%v,%v), want (true,nil)", ok, err)
+	}
+	b.authorizedCache[42] = struct{}{}
+
+	b.sendPairingCodesList(1, 1)
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbPairRevoke(code)) {
+		t.Fatal("pairing list missing revoke button")
+	}
+
+	b.handleRevokePairingCode(42, 42, code)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.AdminOnly {
+		t.Fatalf("message=%q, want %q", got, appcopy.Copy.Prompts.AdminOnly)
+	}
+
+	b.handleRevokePairingCode(1, 1, code)
+	if got := api.lastMessageText(t); !strings.Contains(got, "revoked") {
+		t.Fatalf("message=%q, want revoke confirmation", got)
+	}
+	if !b.isAuthorized(42) {
+		t.Fatal("revoking the code took access away from a user who already joined")
+	}
+	if ok, err := database.RedeemPairingCode(code, 43); err != nil || ok {
+		t.Fatalf("RedeemPairingCode(revoked) = (%v,%v), want (false,nil)", ok, err)
+	}
+
+	b.sendPairingCodesList(1, 1)
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbPairDisable(code)) {
+		t.Fatal("pairing list missing disable users button")
+	}
+	b.sendDisablePairingUsersConfirm(1, 1, code)
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbPairDisableYes(code)) {
+		t.Fatal("disable users prompt missing confirm button")
+	}
+	b.handleDisablePairingUsers(1, 1, code)
+	if _, ok := b.authorizedCache[42]; ok {
+		t.Fatal("expected disabled user to be evicted from the auth cache")
+	}
+	if b.isAuthorized(42) {
+		t.Fatal("disabled user is still authorized")
+	}
+}
+
+func TestResolvePairingStarters_DetectsMangaPlus(t *testing.T) {
+	const (
+		plusID  = "40bc649f-7b49-4645-859e-6cd94136e722"
+		plainID = "0aea9f43-d4a9-4bf7-bebc-550a512f9b95"
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/feed") {
+			feed := mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{{ID: "c1"}}}
+			if strings.Contains(r.URL.Path, plusID) {
+				feed.Data[0].Attributes.ExternalURL = "https://mangaplus.shueisha.co.jp/viewer/1"
+			}
+			_ = json.NewEncoder(w).Encode(feed)
+			return
+		}
+		data := mangadex.MangaData{Id: strings.TrimPrefix(r.URL.Path, "/manga/")}
+		data.Attributes.Title = map[string]string{"en": "Starter " + data.Id[:4]}
+		_ = json.NewEncoder(w).Encode(mangadex.MangaResponse{Data: data})
+	}))
+	t.Cleanup(srv.Close)
+
+	b, _, _ := setupBotForMessageTests(t)
+	b.mdClient.BaseURL = srv.URL
+
+	starters, ok := b.resolvePairingStarters(1, []string{plusID, plainID}, nil)
+	if !ok || len(starters) != 2 {
+		t.Fatalf("resolvePairingStarters() = %+v, %v", starters, ok)
+	}
+	if !starters[0].IsMangaPlus || starters[1].IsMangaPlus {
+		t.Fatalf("starters=%+v, want only the first flagged as MANGA Plus", starters)
+	}
+}
@@ -1,6 +1,10 @@
 package bot
 
-import "testing"
+import (
+	"testing"
+
+	"releasenojutsu/internal/db"
+)
 
 func TestParseCallbackData_BuilderRoundTrip(t *testing.T) {
 	tests := []struct {
@@ -9,16 +13,23 @@ func TestParseCallbackData_BuilderRoundTrip(t *testing.T) {
 		want callbackPayload
 	}{
 		{
-			name: "add confirm plus",
-			raw:  cbAddConfirm("40bc649f-7b49-4645-859e-6cd94136e722", true),
-			want: callbackPayload{Kind: callbackAddConfirm, MangaDexID: "40bc649f-7b49-4645-859e-6cd94136e722", IsMangaPlus: true},
+			name: "add confirm",
+			raw:  cbAddConfirm("40bc649f-7b49-4645-859e-6cd94136e722"),
+			want: callbackPayload{Kind: callbackAddConfirm, MangaDexID: "40bc649f-7b49-4645-859e-6cd94136e722"},
+		},
+		{
+			name: "add confirm with legacy plus flag",
+			raw:  "add_confirm:40bc649f-7b49-4645-859e-6cd94136e722:1",
+			want: callbackPayload{Kind: callbackAddConfirm, MangaDexID: "40bc649f-7b49-4645-859e-6cd94136e722"},
 		},
 		{
-			name: "add confirm no plus",
-			raw:  cbAddConfirm("40bc649f-7b49-4645-859e-6cd94136e722", false),
-			want: callbackPayload{Kind: callbackAddConfirm, MangaDexID: "40bc649f-7b49-4645-859e-6cd94136e722", IsMangaPlus: false},
+			name: "add confirm with legacy no plus flag",
+			raw:  "add_confirm:40bc649f-7b49-4645-859e-6cd94136e722:0",
+			want: callbackPayload{Kind: callbackAddConfirm, MangaDexID: "40bc649f-7b49-4645-859e-6cd94136e722"},
 		},
 		{name: "gen pair", raw: cbGenPair(), want: callbackPayload{Kind: callbackGenPair}},
+		{name: "pair disable", raw: cbPairDisable("ABCD-1234"), want: callbackPayload{Kind: callbackPairDisable, PairingCode: "ABCD-1234"}},
+		{name: "pair disable yes", raw: cbPairDisableYes("ABCD-1234"), want: callbackPayload{Kind: callbackPairDisableYes, PairingCode: "ABCD-1234"}},
 		{name: "main menu", raw: cbMainMenu(), want: callbackPayload{Kind: callbackMainMenu}},
 		{name: "cancel pending", raw: cbCancelPending(), want: callbackPayload{Kind: callbackCancelPending}},
 		{name: "manga action", raw: cbMangaAction(12, "menu"), want: callbackPayload{Kind: callbackMangaAction, MangaID: 12, NextAction: "menu"}},
@@ -36,6 +47,38 @@ func TestParseCallbackData_BuilderRoundTrip(t *testing.T) {
 		{name: "mu back root", raw: cbMarkUnreadBackRoot(5), want: callbackPayload{Kind: callbackMarkUnreadBackRoot, MangaID: 5}},
 		{name: "mu back hundreds", raw: cbMarkUnreadBackHundreds(5, 200), want: callbackPayload{Kind: callbackMarkUnreadBackHundreds, MangaID: 5, Start: 200}},
 		{name: "mu back tens", raw: cbMarkUnreadBackTens(5, 210), want: callbackPayload{Kind: callbackMarkUnreadBackTens, MangaID: 5, Start: 210}},
+		{name: "settings", raw: cbSettings(), want: callbackPayload{Kind: callbackSettings}},
+		{name: "stats", raw: cbStats(), want: callbackPayload{Kind: callbackStats}},
+		{name: "year recap", raw: cbYearRecap(2026), want: callbackPayload{Kind: callbackYearRecap, Value: 2026}},
+		{name: "warn set default", raw: cbSetUnreadWarning(0, 5), want: callbackPayload{Kind: callbackSetUnreadWarning, Value: 5}},
+		{name: "warn set title default", raw: cbSetUnreadWarning(7, -1), want: callbackPayload{Kind: callbackSetUnreadWarning, MangaID: 7, Value: -1}},
+		{name: "warn custom", raw: cbCustomUnreadWarning(7), want: callbackPayload{Kind: callbackCustomUnreadWarning, MangaID: 7}},
+		{name: "backlog toggle", raw: cbToggleBacklogReport(), want: callbackPayload{Kind: callbackToggleBacklogReport}},
+		{name: "backlog show", raw: cbShowBacklog(), want: callbackPayload{Kind: callbackShowBacklog}},
+		{name: "list all", raw: cbListCategory("", "", 2), want: callbackPayload{Kind: callbackListCategory, Page: 2}},
+		{name: "list category", raw: cbListCategory(db.CategoryOnHold, "", 0), want: callbackPayload{Kind: callbackListCategory, Category: db.CategoryOnHold}},
+		{name: "list filter", raw: cbListCategory(db.CategoryReading, db.FilterFailing, 1), want: callbackPayload{Kind: callbackListCategory, Category: db.CategoryReading, Filter: db.FilterFailing, Page: 1}},
+		{name: "list sort", raw: cbCycleListSort("", db.FilterUnread), want: callbackPayload{Kind: callbackCycleListSort, Filter: db.FilterUnread}},
+		{name: "list search", raw: cbListSearch(), want: callbackPayload{Kind: callbackListSearch}},
+		{name: "list back", raw: cbBackToList(), want: callbackPayload{Kind: callbackListBack}},
+		{name: "list index", raw: cbListIndex(db.CategoryDropped, db.FilterMangaPlus), want: callbackPayload{Kind: callbackListIndex, Category: db.CategoryDropped, Filter: db.FilterMangaPlus}},
+		{name: "set category", raw: cbSetCategory(7, db.CategoryDropped), want: callbackPayload{Kind: callbackSetCategory, MangaID: 7, Category: db.CategoryDropped}},
+		{name: "category mutes", raw: cbCategoryMutes(), want: callbackPayload{Kind: callbackCategoryMutes}},
+		{name: "toggle category mute", raw: cbToggleCategoryMute(db.CategoryCompleted), want: callbackPayload{Kind: callbackToggleCategoryMute, Category: db.CategoryCompleted}},
+		{name: "mute", raw: cbSetMangaMute(4, true, false), want: callbackPayload{Kind: callbackSetMangaMute, MangaID: 4, Value: 1}},
+		{name: "unmute from alert", raw: cbSetMangaMute(4, false, true), want: callbackPayload{Kind: callbackSetMangaMute, MangaID: 4, FromAlert: true}},
+		{name: "snooze from alert", raw: cbSnoozeManga(4, 7, true), want: callbackPayload{Kind: callbackSnoozeManga, MangaID: 4, Value: 7, FromAlert: true}},
+		{name: "snooze date", raw: cbSnoozeDatePrompt(4), want: callbackPayload{Kind: callbackSnoozeDatePrompt, MangaID: 4}},
+		{name: "notify every", raw: cbSetNotifyEvery(4, 5), want: callbackPayload{Kind: callbackSetNotifyEvery, MangaID: 4, Value: 5}},
+		{name: "toggle extra", raw: cbToggleExtra(4, 12), want: callbackPayload{Kind: callbackToggleExtra, MangaID: 4, Value: 12}},
+		{name: "undo progress", raw: cbUndoProgress(4, 31), want: callbackPayload{Kind: callbackUndoProgress, MangaID: 4, Value: 31}},
+		{name: "remove alias", raw: cbRemoveTitleAlias(4, "one piece"), want: callbackPayload{Kind: callbackRemoveTitleAlias, MangaID: 4, Alias: "one piece"}},
+		{name: "recommend to", raw: cbRecommendTo(4, 5551234567), want: callbackPayload{Kind: callbackRecommendTo, MangaID: 4, FriendID: 5551234567}},
+		{name: "add recommendation", raw: cbAddRecommendation(9), want: callbackPayload{Kind: callbackAddRecommendation, RecommendationID: 9}},
+		{name: "friends reading", raw: cbFriendsReading(), want: callbackPayload{Kind: callbackFriendsReading}},
+		{name: "share library", raw: cbToggleShareLibrary(), want: callbackPayload{Kind: callbackToggleShareLibrary}},
+		{name: "share progress", raw: cbToggleShareProgress(), want: callbackPayload{Kind: callbackToggleShareProgress}},
+		{name: "alert settings", raw: cbAlertSettings(4, true), want: callbackPayload{Kind: callbackAlertSettings, MangaID: 4, FromAlert: true}},
 	}
 
 	for _, tc := range tests {
@@ -71,8 +114,33 @@ func TestParseCallbackData_InvalidShapes(t *testing.T) {
 		"mu_page:a:1",
 		"mu_chpage:1:10:x:1",
 		"mu_back_tens:1",
-		"add_confirm:only-id",
+		"add_confirm",
+		"add_confirm:",
 		"add_confirm:some-id:bad",
+		"add_confirm:some-id:1:extra",
+		"list_cat:someday:0",
+		"list_cat:all:x",
+		"list_cat:all:0:someday",
+		"list_sort",
+		"list_sort:all:someday",
+		"list_az:someday",
+		"cat_set:1:someday",
+		"cat_mute:someday",
+		"ntf_mute:4:1:x",
+		"x_toggle:4",
+		"x_toggle:4:x",
+		"undo:4",
+		"alias_rm:4",
+		"alias_rm:4:",
+		"alias_rm:x:op",
+		"rec_to:4",
+		"rec_to:4:x",
+		"rec_add",
+		"rec_add:x",
+		"stats_recap",
+		"stats_recap:x",
+		"ntf_snooze:4",
+		"ntf_menu:4:x",
 	}
 	for _, raw := range tests {
 		t.Run(raw, func(t *testing.T) {
@@ -32,23 +32,9 @@ func setupBotWithMangaDexServer(t *testing.T) (*Bot, *db.DB, *fakeTelegramAPI, s
 			})
 			return
 		}
-		_ = json.NewEncoder(w).Encode(mangadex.MangaResponse{
-			Data: struct {
-				Id         string `json:"id"`
-				Attributes struct {
-					Title map[string]string `json:"title"`
-				} `json:"attributes"`
-			}{
-				Id: mdID,
-				Attributes: struct {
-					Title map[string]string `json:"title"`
-				}{
-					Title: map[string]string{
-						"en": "Dragon Ball Super",
-					},
-				},
-			},
-		})
+		data := mangadex.MangaData{Id: mdID}
+		data.Attributes.Title = map[string]string{"en": "Dragon Ball Super"}
+		_ = json.NewEncoder(w).Encode(mangadex.MangaResponse{Data: data})
 	}))
 	t.Cleanup(srv.Close)
 
@@ -72,7 +58,7 @@ func setupBotWithMangaDexServer(t *testing.T) (*Bot, *db.DB, *fakeTelegramAPI, s
 	return New(api, database, mdClient, cfg, upd), database, api, mdID
 }
 
-func TestHandleAddManga_ShowsMangaPlusQuestion(t *testing.T) {
+func TestHandleAddManga_ShowsConfirmPrompt(t *testing.T) {
 	b, database, api, mdID := setupBotWithMangaDexServer(t)
 	userID := int64(42)
 	if err := database.EnsureUser(userID, false); err != nil {
@@ -97,8 +83,9 @@ func TestHandleAddManga_ShowsMangaPlusQuestion(t *testing.T) {
 			}
 		}
 	}
-	if !hasCallback(callbacks, cbAddConfirm(mdID, true)) || !hasCallback(callbacks, cbAddConfirm(mdID, false)) {
-		t.Fatalf("missing add confirm callbacks in %v", callbacks)
+	// MANGA Plus is detected from the chapters, so there is no yes/no question any more.
+	if !hasCallback(callbacks, cbAddConfirm(mdID)) {
+		t.Fatalf("want a single add confirm callback, got %v", callbacks)
 	}
 }
 
@@ -109,7 +96,7 @@ func TestConfirmAddManga_InsertsRowAndStartsSync(t *testing.T) {
 		t.Fatalf("EnsureUser(): %v", err)
 	}
 
-	b.confirmAddManga(userID, userID, mdID, true)
+	b.confirmAddManga(userID, userID, mdID)
 
 	waitUntil(t, 2*time.Second, func() bool {
 		var count int
@@ -121,8 +108,9 @@ func TestConfirmAddManga_InsertsRowAndStartsSync(t *testing.T) {
 	if err := database.QueryRow("SELECT is_manga_plus FROM manga WHERE user_id = ? AND mangadex_id = ?", userID, mdID).Scan(&isPlus); err != nil {
 		t.Fatalf("select is_manga_plus: %v", err)
 	}
-	if isPlus != 1 {
-		t.Fatalf("is_manga_plus=%d, want 1", isPlus)
+	// MANGA Plus is only ever detected from the chapters, and this feed has no such links.
+	if isPlus != 0 {
+		t.Fatalf("is_manga_plus=%d, want 0", isPlus)
 	}
 
 	waitUntil(t, 2*time.Second, func() bool {
@@ -232,7 +220,7 @@ func TestConfirmAddManga_FetchFailureSendsError(t *testing.T) {
 	upd := updater.New(database, mdClient, mdClient)
 	b := New(api, database, mdClient, &config.Config{AdminUserID: 1}, upd)
 
-	b.confirmAddManga(42, 42, "40bc649f-7b49-4645-859e-6cd94136e722", false)
+	b.confirmAddManga(42, 42, "40bc649f-7b49-4645-859e-6cd94136e722")
 	if got := api.lastMessageText(t); got == "" {
 		t.Fatal("expected non-empty error message")
 	}
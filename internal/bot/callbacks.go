@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"releasenojutsu/internal/db"
 )
 
 type callbackKind int
@@ -36,19 +38,71 @@ const (
 	callbackRemoveManga
 	callbackMainMenu
 	callbackCancelPending
+	callbackPairList
+	callbackPairRevoke
+	callbackPairRevokeYes
+	callbackPairDisable
+	callbackPairDisableYes
+	callbackRunHistory
+	callbackRunDetail
+	callbackCheckAll
+	callbackUpdateAll
+	callbackSettings
+	callbackSetUnreadWarning
+	callbackCustomUnreadWarning
+	callbackToggleBacklogReport
+	callbackShowBacklog
+	callbackListCategory
+	callbackSetCategory
+	callbackCategoryMutes
+	callbackToggleCategoryMute
+	callbackSetMangaMute
+	callbackSnoozeManga
+	callbackSnoozeDatePrompt
+	callbackSetNotifyEvery
+	callbackAlertSettings
+	callbackCycleListSort
+	callbackListSearch
+	callbackListBack
+	callbackListIndex
+	callbackToggleExtra
+	callbackUndoProgress
+	callbackStats
+	callbackYearRecap
+	callbackRemoveTitleAlias
+	callbackRecommendTo
+	callbackAddRecommendation
+	callbackFriendsReading
+	callbackToggleShareLibrary
+	callbackToggleShareProgress
 )
 
 type callbackPayload struct {
 	Kind          callbackKind
 	MangaID       int
 	MangaDexID    string
-	IsMangaPlus   bool
 	NextAction    string
 	ChapterNumber string
 	Scale         int
 	Start         int
 	Page          int
 	Root          bool
+	PairingCode   string
+	RunID         int64
+	// Value is a threshold picked from a menu (-1 means "use my default").
+	Value int
+	// Category is a reading category; empty in list callbacks means every category.
+	Category string
+	// Filter is one of db.ListFilters in list callbacks; empty shows every title.
+	Filter string
+	// FromAlert marks buttons on a new-chapter notification, which is left in place.
+	FromAlert bool
+	// Alias is a title alias, already normalized.
+	Alias string
+	// FriendID is the paired user a title is recommended to.
+	FriendID int64
+	// RecommendationID is a recommendation the user received.
+	RecommendationID int64
 }
 
 func parseCallbackData(raw string) (callbackPayload, error) {
@@ -59,17 +113,18 @@ func parseCallbackData(raw string) (callbackPayload, error) {
 
 	switch parts[0] {
 	case "add_confirm":
-		if len(parts) != 3 {
+		// Buttons sent before MANGA Plus was detected from the chapters still carry a
+		// trailing 0/1 flag; it is accepted and ignored.
+		if len(parts) == 3 {
+			if _, err := strconv.Atoi(parts[2]); err != nil {
+				return callbackPayload{}, fmt.Errorf("invalid add_confirm flag: %w", err)
+			}
+		} else if len(parts) != 2 || parts[1] == "" {
 			return callbackPayload{}, fmt.Errorf("invalid add_confirm callback: %s", raw)
 		}
-		isPlusInt, err := strconv.Atoi(parts[2])
-		if err != nil {
-			return callbackPayload{}, fmt.Errorf("invalid add_confirm flag: %w", err)
-		}
 		return callbackPayload{
-			Kind:        callbackAddConfirm,
-			MangaDexID:  parts[1],
-			IsMangaPlus: isPlusInt != 0,
+			Kind:       callbackAddConfirm,
+			MangaDexID: parts[1],
 		}, nil
 	case "add_manga":
 		return callbackPayload{Kind: callbackAddManga}, nil
@@ -170,11 +225,225 @@ func parseCallbackData(raw string) (callbackPayload, error) {
 		return callbackPayload{Kind: callbackMainMenu}, nil
 	case "cancel_pending":
 		return callbackPayload{Kind: callbackCancelPending}, nil
+	case "pair_list":
+		return callbackPayload{Kind: callbackPairList}, nil
+	case "pair_revoke":
+		return parsePairCallback(raw, parts, callbackPairRevoke)
+	case "pair_revoke_yes":
+		return parsePairCallback(raw, parts, callbackPairRevokeYes)
+	case "pair_disable":
+		return parsePairCallback(raw, parts, callbackPairDisable)
+	case "pair_disable_yes":
+		return parsePairCallback(raw, parts, callbackPairDisableYes)
+	case "check_all":
+		return callbackPayload{Kind: callbackCheckAll}, nil
+	case "update_all":
+		return callbackPayload{Kind: callbackUpdateAll}, nil
+	case "run_history":
+		return callbackPayload{Kind: callbackRunHistory}, nil
+	case "run_detail":
+		if len(parts) != 2 {
+			return callbackPayload{}, fmt.Errorf("invalid run_detail callback: %s", raw)
+		}
+		runID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid run id: %w", err)
+		}
+		return callbackPayload{Kind: callbackRunDetail, RunID: runID}, nil
+	case "settings":
+		return callbackPayload{Kind: callbackSettings}, nil
+	case "stats":
+		return callbackPayload{Kind: callbackStats}, nil
+	case "stats_recap":
+		if len(parts) != 2 {
+			return callbackPayload{}, fmt.Errorf("invalid stats_recap callback: %s", raw)
+		}
+		year, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid year: %w", err)
+		}
+		return callbackPayload{Kind: callbackYearRecap, Value: year}, nil
+	case "warn_set":
+		if len(parts) != 3 {
+			return callbackPayload{}, fmt.Errorf("invalid warn_set callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		value, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid threshold: %w", err)
+		}
+		return callbackPayload{Kind: callbackSetUnreadWarning, MangaID: mangaID, Value: value}, nil
+	case "warn_custom":
+		if len(parts) != 2 {
+			return callbackPayload{}, fmt.Errorf("invalid warn_custom callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		return callbackPayload{Kind: callbackCustomUnreadWarning, MangaID: mangaID}, nil
+	case "backlog_toggle":
+		return callbackPayload{Kind: callbackToggleBacklogReport}, nil
+	case "backlog_show":
+		return callbackPayload{Kind: callbackShowBacklog}, nil
+	case "list_cat":
+		if len(parts) != 3 && len(parts) != 4 {
+			return callbackPayload{}, fmt.Errorf("invalid list_cat callback: %s", raw)
+		}
+		category, filter, err := parseListView(parts[1], parts[3:])
+		if err != nil {
+			return callbackPayload{}, err
+		}
+		page, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid page: %w", err)
+		}
+		return callbackPayload{Kind: callbackListCategory, Category: category, Filter: filter, Page: page}, nil
+	case "list_sort":
+		if len(parts) != 2 && len(parts) != 3 {
+			return callbackPayload{}, fmt.Errorf("invalid list_sort callback: %s", raw)
+		}
+		category, filter, err := parseListView(parts[1], parts[2:])
+		if err != nil {
+			return callbackPayload{}, err
+		}
+		return callbackPayload{Kind: callbackCycleListSort, Category: category, Filter: filter}, nil
+	case "list_search":
+		return callbackPayload{Kind: callbackListSearch}, nil
+	case "list_back":
+		return callbackPayload{Kind: callbackListBack}, nil
+	case "list_az":
+		if len(parts) != 2 && len(parts) != 3 {
+			return callbackPayload{}, fmt.Errorf("invalid list_az callback: %s", raw)
+		}
+		category, filter, err := parseListView(parts[1], parts[2:])
+		if err != nil {
+			return callbackPayload{}, err
+		}
+		return callbackPayload{Kind: callbackListIndex, Category: category, Filter: filter}, nil
+	case "cat_set":
+		if len(parts) != 3 || !db.IsCategory(parts[2]) {
+			return callbackPayload{}, fmt.Errorf("invalid cat_set callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		return callbackPayload{Kind: callbackSetCategory, MangaID: mangaID, Category: parts[2]}, nil
+	case "ntf_mute":
+		return parseMangaValue(raw, parts, callbackSetMangaMute)
+	case "ntf_snooze":
+		return parseMangaValue(raw, parts, callbackSnoozeManga)
+	case "ntf_every":
+		return parseMangaValue(raw, parts, callbackSetNotifyEvery)
+	case "x_toggle":
+		return parseMangaValue(raw, parts, callbackToggleExtra)
+	case "undo":
+		return parseMangaValue(raw, parts, callbackUndoProgress)
+	case "alias_rm":
+		if len(parts) != 3 || parts[2] == "" {
+			return callbackPayload{}, fmt.Errorf("invalid alias_rm callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		return callbackPayload{Kind: callbackRemoveTitleAlias, MangaID: mangaID, Alias: parts[2]}, nil
+	case "rec_to":
+		if len(parts) != 3 {
+			return callbackPayload{}, fmt.Errorf("invalid rec_to callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		friendID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid friend id: %w", err)
+		}
+		return callbackPayload{Kind: callbackRecommendTo, MangaID: mangaID, FriendID: friendID}, nil
+	case "rec_add":
+		if len(parts) != 2 {
+			return callbackPayload{}, fmt.Errorf("invalid rec_add callback: %s", raw)
+		}
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid recommendation id: %w", err)
+		}
+		return callbackPayload{Kind: callbackAddRecommendation, RecommendationID: id}, nil
+	case "friends":
+		return callbackPayload{Kind: callbackFriendsReading}, nil
+	case "share_lib":
+		return callbackPayload{Kind: callbackToggleShareLibrary}, nil
+	case "share_progress":
+		return callbackPayload{Kind: callbackToggleShareProgress}, nil
+	case "ntf_menu":
+		if len(parts) != 2 && (len(parts) != 3 || parts[2] != alertSuffix) {
+			return callbackPayload{}, fmt.Errorf("invalid ntf_menu callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		return callbackPayload{Kind: callbackAlertSettings, MangaID: mangaID, FromAlert: len(parts) == 3}, nil
+	case "ntf_snooze_date":
+		if len(parts) != 2 {
+			return callbackPayload{}, fmt.Errorf("invalid ntf_snooze_date callback: %s", raw)
+		}
+		mangaID, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+		}
+		return callbackPayload{Kind: callbackSnoozeDatePrompt, MangaID: mangaID}, nil
+	case "cat_mutes":
+		return callbackPayload{Kind: callbackCategoryMutes}, nil
+	case "cat_mute":
+		if len(parts) != 2 || !db.IsCategory(parts[1]) {
+			return callbackPayload{}, fmt.Errorf("invalid cat_mute callback: %s", raw)
+		}
+		return callbackPayload{Kind: callbackToggleCategoryMute, Category: parts[1]}, nil
 	default:
 		return callbackPayload{Kind: callbackUnknown}, fmt.Errorf("unknown callback action: %s", parts[0])
 	}
 }
 
+// parseListView reads the category and optional filter of a list callback.
+func parseListView(category string, rest []string) (string, string, error) {
+	if category == categoryAll {
+		category = ""
+	} else if !db.IsCategory(category) {
+		return "", "", fmt.Errorf("invalid category: %s", category)
+	}
+	filter := ""
+	if len(rest) > 0 {
+		filter = rest[0]
+		if !db.IsListFilter(filter) {
+			return "", "", fmt.Errorf("invalid list filter: %s", filter)
+		}
+	}
+	return category, filter, nil
+}
+
+// parseMangaValue parses "<action>:<mangaID>:<value>" with an optional ":a" suffix for
+// buttons on a notification.
+func parseMangaValue(raw string, parts []string, kind callbackKind) (callbackPayload, error) {
+	if len(parts) != 3 && (len(parts) != 4 || parts[3] != alertSuffix) {
+		return callbackPayload{}, fmt.Errorf("invalid callback: %s", raw)
+	}
+	mangaID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return callbackPayload{}, fmt.Errorf("invalid manga id: %w", err)
+	}
+	value, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return callbackPayload{}, fmt.Errorf("invalid value: %w", err)
+	}
+	return callbackPayload{Kind: kind, MangaID: mangaID, Value: value, FromAlert: len(parts) == 4}, nil
+}
+
 func parsePick(raw string, parts []string, kind callbackKind) (callbackPayload, error) {
 	if len(parts) != 4 {
 		return callbackPayload{}, fmt.Errorf("invalid pick callback: %s", raw)
@@ -280,11 +549,18 @@ func parseStartBack(raw string, parts []string, kind callbackKind) (callbackPayl
 	}, nil
 }
 
-func cbAddConfirm(mangaDexID string, isMangaPlus bool) string {
-	if isMangaPlus {
-		return fmt.Sprintf("add_confirm:%s:1", mangaDexID)
+func parsePairCallback(raw string, parts []string, kind callbackKind) (callbackPayload, error) {
+	if len(parts) != 2 || parts[1] == "" {
+		return callbackPayload{}, fmt.Errorf("invalid pairing callback: %s", raw)
 	}
-	return fmt.Sprintf("add_confirm:%s:0", mangaDexID)
+	return callbackPayload{
+		Kind:        kind,
+		PairingCode: parts[1],
+	}, nil
+}
+
+func cbAddConfirm(mangaDexID string) string {
+	return fmt.Sprintf("add_confirm:%s", mangaDexID)
 }
 
 func cbAddManga() string {
@@ -299,6 +575,175 @@ func cbGenPair() string {
 	return "gen_pair"
 }
 
+func cbPairList() string {
+	return "pair_list"
+}
+
+func cbPairRevoke(code string) string {
+	return "pair_revoke:" + code
+}
+
+func cbPairRevokeYes(code string) string {
+	return "pair_revoke_yes:" + code
+}
+
+func cbPairDisable(code string) string {
+	return "pair_disable:" + code
+}
+
+func cbPairDisableYes(code string) string {
+	return "pair_disable_yes:" + code
+}
+
+func cbCheckAll() string {
+	return "check_all"
+}
+
+func cbUpdateAll() string {
+	return "update_all"
+}
+
+func cbRunHistory() string {
+	return "run_history"
+}
+
+func cbRunDetail(runID int64) string {
+	return fmt.Sprintf("run_detail:%d", runID)
+}
+
+func cbSettings() string {
+	return "settings"
+}
+
+func cbStats() string {
+	return "stats"
+}
+
+func cbYearRecap(year int) string {
+	return fmt.Sprintf("stats_recap:%d", year)
+}
+
+// cbSetUnreadWarning sets a title's threshold, or the user's default when mangaID is 0.
+func cbSetUnreadWarning(mangaID, value int) string {
+	return fmt.Sprintf("warn_set:%d:%d", mangaID, value)
+}
+
+func cbCustomUnreadWarning(mangaID int) string {
+	return fmt.Sprintf("warn_custom:%d", mangaID)
+}
+
+func cbToggleBacklogReport() string {
+	return "backlog_toggle"
+}
+
+func cbShowBacklog() string {
+	return "backlog_show"
+}
+
+// categoryAll stands in for "every category" in list callbacks.
+const categoryAll = "all"
+
+// cbListCategory opens a page of the list filtered to category and filter (either ""
+// shows everything).
+func cbListCategory(category string, filter string, page int) string {
+	if category == "" {
+		category = categoryAll
+	}
+	data := fmt.Sprintf("list_cat:%s:%d", category, page)
+	if filter != "" {
+		data += ":" + filter
+	}
+	return data
+}
+
+// cbCycleListSort switches to the next sort order and reopens the list on its first page.
+func cbCycleListSort(category string, filter string) string {
+	return listViewData("list_sort", category, filter)
+}
+
+// cbListIndex opens the jump-to-letter index for the list narrowed to category and filter.
+func cbListIndex(category string, filter string) string {
+	return listViewData("list_az", category, filter)
+}
+
+// cbBackToList reopens the list where the user last left it.
+func cbBackToList() string {
+	return "list_back"
+}
+
+func listViewData(action string, category string, filter string) string {
+	if category == "" {
+		category = categoryAll
+	}
+	data := action + ":" + category
+	if filter != "" {
+		data += ":" + filter
+	}
+	return data
+}
+
+func cbListSearch() string {
+	return "list_search"
+}
+
+func cbSetCategory(mangaID int, category string) string {
+	return fmt.Sprintf("cat_set:%d:%s", mangaID, category)
+}
+
+func cbCategoryMutes() string {
+	return "cat_mutes"
+}
+
+func cbToggleCategoryMute(category string) string {
+	return "cat_mute:" + category
+}
+
+// cbToggleExtra flips the read state of an extra, referenced by its chapter row ID to
+// keep the callback short.
+func cbToggleExtra(mangaID int, chapterID int) string {
+	return fmt.Sprintf("x_toggle:%d:%d", mangaID, chapterID)
+}
+
+// cbUndoProgress undoes one reading history entry; the title's ID keeps the reply scoped.
+func cbUndoProgress(mangaID int, historyID int64) string {
+	return fmt.Sprintf("undo:%d:%d", mangaID, historyID)
+}
+
+// alertSuffix marks callbacks sent from a new-chapter notification.
+const alertSuffix = "a"
+
+func withAlertSuffix(data string, fromAlert bool) string {
+	if fromAlert {
+		return data + ":" + alertSuffix
+	}
+	return data
+}
+
+func cbSetMangaMute(mangaID int, muted bool, fromAlert bool) string {
+	val := 0
+	if muted {
+		val = 1
+	}
+	return withAlertSuffix(fmt.Sprintf("ntf_mute:%d:%d", mangaID, val), fromAlert)
+}
+
+// cbSnoozeManga snoozes alerts for days (0 ends the snooze).
+func cbSnoozeManga(mangaID int, days int, fromAlert bool) string {
+	return withAlertSuffix(fmt.Sprintf("ntf_snooze:%d:%d", mangaID, days), fromAlert)
+}
+
+func cbAlertSettings(mangaID int, fromAlert bool) string {
+	return withAlertSuffix(fmt.Sprintf("ntf_menu:%d", mangaID), fromAlert)
+}
+
+func cbSnoozeDatePrompt(mangaID int) string {
+	return fmt.Sprintf("ntf_snooze_date:%d", mangaID)
+}
+
+func cbSetNotifyEvery(mangaID int, n int) string {
+	return fmt.Sprintf("ntf_every:%d:%d", mangaID, n)
+}
+
 func cbMainMenu() string {
 	return "main_menu"
 }
@@ -319,6 +764,30 @@ func cbMarkChapterUnread(mangaID int, chapterNumber string) string {
 	return fmt.Sprintf("unread_chapter:%d:%s", mangaID, chapterNumber)
 }
 
+func cbRemoveTitleAlias(mangaID int, alias string) string {
+	return fmt.Sprintf("alias_rm:%d:%s", mangaID, alias)
+}
+
+func cbRecommendTo(mangaID int, friendID int64) string {
+	return fmt.Sprintf("rec_to:%d:%d", mangaID, friendID)
+}
+
+func cbAddRecommendation(id int64) string {
+	return fmt.Sprintf("rec_add:%d", id)
+}
+
+func cbFriendsReading() string {
+	return "friends"
+}
+
+func cbToggleShareLibrary() string {
+	return "share_lib"
+}
+
+func cbToggleShareProgress() string {
+	return "share_progress"
+}
+
 func cbMarkReadPick(mangaID, scale, start int) string {
 	return fmt.Sprintf("mr_pick:%d:%d:%d", mangaID, scale, start)
 }
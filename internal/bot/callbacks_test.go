@@ -39,3 +39,29 @@ func TestParseCallbackData_SelectMangaBackCompat(t *testing.T) {
 		t.Fatalf("payload mismatch: %+v", payload)
 	}
 }
+
+func TestParseCallbackData_PairRevoke(t *testing.T) {
+	payload, err := parseCallbackData(cbPairRevokeYes("ABCD-1234"))
+	if err != nil {
+		t.Fatalf("parseCallbackData(pair_revoke_yes): %v", err)
+	}
+	if payload.Kind != callbackPairRevokeYes || payload.PairingCode != "ABCD-1234" {
+		t.Fatalf("payload mismatch: %+v", payload)
+	}
+	if _, err := parseCallbackData("pair_revoke"); err == nil {
+		t.Fatal("expected error for pair_revoke without code")
+	}
+}
+
+func TestParseCallbackData_RunDetail(t *testing.T) {
+	payload, err := parseCallbackData(cbRunDetail(42))
+	if err != nil {
+		t.Fatalf("parseCallbackData(): %v", err)
+	}
+	if payload.Kind != callbackRunDetail || payload.RunID != 42 {
+		t.Fatalf("payload=%+v", payload)
+	}
+	if _, err := parseCallbackData("run_detail:abc"); err == nil {
+		t.Fatal("expected error for non-numeric run id")
+	}
+}
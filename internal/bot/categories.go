@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+// categoryLabel returns the display name of a reading category ("" is every category).
+func categoryLabel(category string) string {
+	switch category {
+	case "":
+		return appcopy.Copy.Labels.CategoryAll
+	case db.CategoryReading:
+		return appcopy.Copy.Labels.CategoryReading
+	case db.CategoryPlanToRead:
+		return appcopy.Copy.Labels.CategoryPlanToRead
+	case db.CategoryOnHold:
+		return appcopy.Copy.Labels.CategoryOnHold
+	case db.CategoryDropped:
+		return appcopy.Copy.Labels.CategoryDropped
+	case db.CategoryCompleted:
+		return appcopy.Copy.Labels.CategoryCompleted
+	default:
+		return category
+	}
+}
+
+// categoryTabs renders the category tabs: "All" followed by every category with its
+// count. Switching tabs keeps the view's filter.
+func categoryTabs(view listView, counts map[string]int, total int) []tgbotapi.InlineKeyboardButton {
+	tab := func(category string, n int) tgbotapi.InlineKeyboardButton {
+		label := fmt.Sprintf(appcopy.Copy.Labels.CategoryTab, categoryLabel(category), n)
+		if category == view.Category {
+			label = fmt.Sprintf(appcopy.Copy.Buttons.Selected, label)
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(label, cbListCategory(category, view.Filter, 0))
+	}
+
+	tabs := []tgbotapi.InlineKeyboardButton{tab("", total)}
+	for _, category := range db.Categories {
+		tabs = append(tabs, tab(category, counts[category]))
+	}
+	return tabs
+}
+
+func (b *Bot) sendCategoryMenu(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	current, err := b.db.GetMangaCategory(mangaID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga category", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(db.Categories))
+	for _, category := range db.Categories {
+		label := categoryLabel(category)
+		if category == current {
+			label = fmt.Sprintf(appcopy.Copy.Buttons.Selected, label)
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(label, cbSetCategory(mangaID, category)))
+	}
+	keyboard := appendButtonsInRows(nil, buttons, 2)
+	keyboard = appendBackToMangaRow(keyboard, mangaID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.CategoryMenu, html.EscapeString(title), categoryLabel(current)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) handleSetCategory(chatID int64, userID int64, mangaID int, category string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	b.logAction(userID, "Set category", fmt.Sprintf("Manga ID: %d, Category: %s", mangaID, category))
+
+	if err := b.db.SetMangaCategory(mangaID, userID, category); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg, cbTarget)
+			return
+		}
+		userLog(userID, mangaID).Error("Error setting category", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateCategory)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.sendMangaActionMenu(chatID, userID, mangaID, cbTarget)
+}
+
+// sendCategoryMutes lets the user mute new-chapter notifications per category.
+func (b *Bot) sendCategoryMutes(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	muted, err := b.db.MutedCategories(userID)
+	if err != nil {
+		userLog(userID).Error("Error loading muted categories", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(db.Categories))
+	for _, category := range db.Categories {
+		format := appcopy.Copy.Labels.CategoryUnmuted
+		if muted[category] {
+			format = appcopy.Copy.Labels.CategoryMuted
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(format, categoryLabel(category)), cbToggleCategoryMute(category)))
+	}
+	keyboard := appendButtonsInRows(nil, buttons, 1)
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToSettings, cbSettings()),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Info.CategoryNotificationsText)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) handleToggleCategoryMute(chatID int64, userID int64, category string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	muted, err := b.db.MutedCategories(userID)
+	if err == nil {
+		err = b.db.SetCategoryMuted(userID, category, !muted[category])
+	}
+	if err != nil {
+		userLog(userID).Error("Error toggling category mute", "category", category, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Toggle category mute", fmt.Sprintf("Category: %s, Muted: %t", category, !muted[category]))
+	b.sendCategoryMutes(chatID, userID, cbTarget)
+}
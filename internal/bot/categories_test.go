@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestMangaList_FiltersAndPagesByCategory(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	var ids []int
+	for i := 1; i <= listPageSize+2; i++ {
+		id, err := database.AddManga(fmt.Sprintf("md-%d", i), fmt.Sprintf("Title %02d", i), userID)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		ids = append(ids, int(id))
+	}
+
+	b.handleListManga(userID, userID)
+	msg := api.lastMessageConfig(t)
+	callbacks := messageCallbacks(t, msg)
+	if !hasCallback(callbacks, cbListCategory("", "", 1)) || hasCallback(callbacks, cbMangaAction(ids[listPageSize], "menu")) {
+		t.Fatalf("first page callbacks=%v, want %d titles and a next page", callbacks, listPageSize)
+	}
+	if !strings.Contains(msg.Text, "Page <b>1/2</b>") {
+		t.Fatalf("list=%q, want the page indicator", msg.Text)
+	}
+
+	b.sendMangaList(userID, userID, listView{Page: 1})
+	if callbacks := messageCallbacks(t, api.lastMessageConfig(t)); !hasCallback(callbacks, cbMangaAction(ids[listPageSize+1], "menu")) || !hasCallback(callbacks, cbListCategory("", "", 0)) {
+		t.Fatalf("second page callbacks=%v", callbacks)
+	}
+
+	// Move one title on hold from its menu and filter on it.
+	b.handleMangaSelection(userID, userID, ids[3], "category")
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbSetCategory(ids[3], db.CategoryOnHold)) {
+		t.Fatal("category menu should offer On Hold")
+	}
+	b.handleSetCategory(userID, userID, ids[3], db.CategoryOnHold)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Title 04") {
+		t.Fatalf("after moving=%q, want the action menu", got)
+	}
+
+	b.sendMangaList(userID, userID, listView{Category: db.CategoryOnHold})
+	msg = api.lastMessageConfig(t)
+	callbacks = messageCallbacks(t, msg)
+	if !hasCallback(callbacks, cbMangaAction(ids[3], "menu")) || hasCallback(callbacks, cbMangaAction(ids[0], "menu")) {
+		t.Fatalf("on hold callbacks=%v, want only the moved title", callbacks)
+	}
+	if !strings.Contains(msg.Text, "On Hold") {
+		t.Fatalf("list=%q, want the filter line", msg.Text)
+	}
+
+	b.sendStatusMessage(userID, userID)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Reading: <b>11</b>") || !strings.Contains(got, "On Hold: <b>1</b>") {
+		t.Fatalf("status=%q, want per-category counts", got)
+	}
+}
+
+func TestCategoryMutes_Toggle(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+
+	b.handleToggleCategoryMute(userID, userID, db.CategoryDropped)
+	muted, err := database.MutedCategories(userID)
+	if err != nil || !muted[db.CategoryDropped] || len(muted) != 1 {
+		t.Fatalf("muted=%v err=%v, want dropped", muted, err)
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "Notifications by Category") {
+		t.Fatalf("mutes=%q", got)
+	}
+
+	b.handleToggleCategoryMute(userID, userID, db.CategoryDropped)
+	if muted, _ := database.MutedCategories(userID); len(muted) != 0 {
+		t.Fatalf("muted=%v, want none after toggling back", muted)
+	}
+}
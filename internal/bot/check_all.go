@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/cron"
+)
+
+const (
+	defaultCheckAllCooldown = 15 * time.Minute
+	// progressEditInterval keeps progress edits well inside Telegram's per-chat rate limit.
+	progressEditInterval = 2 * time.Second
+	manualRunTimeout     = 15 * time.Minute
+)
+
+// handleCheckAllManga checks every title the user follows, subject to a per-user cooldown.
+func (b *Bot) handleCheckAllManga(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	b.logAction(userID, "Check all manga", "")
+
+	if b.runner == nil {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRunUpdate)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	count, err := b.db.CountMangaByUser(userID)
+	if err != nil {
+		userLog(userID).Error("Failed counting manga", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRunUpdate)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	if count == 0 {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CheckAllNoManga)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	if wait := b.reserveCheckAll(userID); wait > 0 {
+		minutes := int(math.Ceil(wait.Minutes()))
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.CheckAllCooldown, minutes))
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	progress := b.startProgressMessage(chatID, appcopy.Copy.Info.CheckAllStarting, cbTarget)
+	go b.runUpdateWithProgress(chatID, progress, cron.RunOptions{Trigger: cron.TriggerUser, UserID: userID}, func(s cron.RunSummary) string {
+		return fmt.Sprintf(appcopy.Copy.Info.CheckAllDone, s.TitlesChecked, s.NewChapters, s.Failures)
+	})
+}
+
+// handleGlobalUpdate runs the same update as the scheduler for every user (admin only).
+func (b *Bot) handleGlobalUpdate(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.UpdateAllAdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Global update", "")
+
+	if b.runner == nil {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRunUpdate)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	progress := b.startProgressMessage(chatID, appcopy.Copy.Info.UpdateAllStarting, cbTarget)
+	go b.runUpdateWithProgress(chatID, progress, cron.RunOptions{Trigger: cron.TriggerManual}, func(s cron.RunSummary) string {
+		return fmt.Sprintf(appcopy.Copy.Info.UpdateAllDone, s.TitlesChecked, s.NewChapters, s.NotificationsSent, s.Failures)
+	})
+}
+
+// reserveCheckAll records a check-all for userID and returns how long they still have to
+// wait if one ran too recently. Admins are never throttled.
+func (b *Bot) reserveCheckAll(userID int64) time.Duration {
+	if b.isAdmin(userID) {
+		return 0
+	}
+	b.checkAllMu.Lock()
+	defer b.checkAllMu.Unlock()
+	now := time.Now()
+	if last, ok := b.lastCheckAll[userID]; ok {
+		if wait := b.checkAllCooldown - now.Sub(last); wait > 0 {
+			return wait
+		}
+	}
+	b.lastCheckAll[userID] = now
+	return 0
+}
+
+func (b *Bot) releaseCheckAll(userID int64) {
+	b.checkAllMu.Lock()
+	defer b.checkAllMu.Unlock()
+	delete(b.lastCheckAll, userID)
+}
+
+// startProgressMessage shows text in the message the user tapped, or sends a new one, and
+// returns the target later progress edits should go to (nil if nothing can be edited).
+func (b *Bot) startProgressMessage(chatID int64, text string, target *callbackEditTarget) *callbackEditTarget {
+	msg := tgbotapi.NewMessage(chatID, text)
+	if b.tryEditTarget(msg, target) {
+		return target
+	}
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		userLog(chatID).Warn("Failed sending progress message", "error", err)
+		return nil
+	}
+	return &callbackEditTarget{chatID: chatID, messageID: sent.MessageID}
+}
+
+func (b *Bot) runUpdateWithProgress(chatID int64, progress *callbackEditTarget, opts cron.RunOptions, doneText func(cron.RunSummary) string) {
+	ctx, cancel := context.WithTimeout(context.Background(), manualRunTimeout)
+	defer cancel()
+
+	var lastEdit time.Time
+	opts.Progress = func(done, total int) {
+		if done < total && time.Since(lastEdit) < progressEditInterval {
+			return
+		}
+		lastEdit = time.Now()
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.CheckAllProgress, done, total))
+		msg.ParseMode = "HTML"
+		b.tryEditTarget(msg, progress)
+	}
+
+	summary, err := b.runner.RunNow(ctx, opts)
+	var text string
+	switch {
+	case errors.Is(err, cron.ErrRunInProgress):
+		// The running update covers this user's titles too, so don't charge the cooldown.
+		if opts.UserID != 0 {
+			b.releaseCheckAll(opts.UserID)
+		}
+		text = appcopy.Copy.Prompts.UpdateAlreadyRunning
+	case err != nil:
+		userLog(opts.UserID).Error("Manual update failed", "trigger", opts.Trigger, "error", err)
+		text = appcopy.Copy.Errors.CannotRunUpdate
+	default:
+		text = doneText(summary)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	b.sendMessageWithMainMenuButton(msg, progress)
+}
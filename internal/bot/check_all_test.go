@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/cron"
+)
+
+type fakeUpdateRunner struct {
+	calls   chan cron.RunOptions
+	summary cron.RunSummary
+	err     error
+}
+
+func (r *fakeUpdateRunner) RunNow(_ context.Context, opts cron.RunOptions) (cron.RunSummary, error) {
+	if r.err == nil && opts.Progress != nil {
+		for done := 0; done <= r.summary.TitlesChecked; done++ {
+			opts.Progress(done, r.summary.TitlesChecked)
+		}
+	}
+	r.calls <- opts
+	return r.summary, r.err
+}
+
+func sentTextsContain(api *fakeTelegramAPI, want string) bool {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	for _, m := range api.outboundMessages {
+		if strings.Contains(m.Text, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHandleCheckAllManga_RunsForUserWithProgressAndCooldown(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(2)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if _, err := database.AddManga("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Dragon Ball Super", userID); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	runner := &fakeUpdateRunner{calls: make(chan cron.RunOptions, 1), summary: cron.RunSummary{TitlesChecked: 2, NewChapters: 3}}
+	b.SetUpdateRunner(runner)
+
+	b.handleCheckAllManga(userID, userID)
+
+	opts := <-runner.calls
+	if opts.UserID != userID || opts.Trigger != cron.TriggerUser {
+		t.Fatalf("opts=%+v, want user-scoped run", opts)
+	}
+	done := fmt.Sprintf(appcopy.Copy.Info.CheckAllDone, 2, 3, 0)
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, done) })
+	if !sentTextsContain(api, "<b>2/2</b> checked") {
+		t.Fatal("expected the final progress edit")
+	}
+
+	b.handleCheckAllManga(userID, userID)
+	if got := api.lastMessageText(t); got != fmt.Sprintf(appcopy.Copy.Prompts.CheckAllCooldown, 15) {
+		t.Fatalf("second check message=%q, want cooldown", got)
+	}
+	select {
+	case <-runner.calls:
+		t.Fatal("runner should not be called during the cooldown")
+	default:
+	}
+}
+
+func TestHandleCheckAllManga_AlreadyRunningReleasesCooldown(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(2)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if _, err := database.AddManga("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Dragon Ball Super", userID); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	runner := &fakeUpdateRunner{calls: make(chan cron.RunOptions, 2), err: cron.ErrRunInProgress}
+	b.SetUpdateRunner(runner)
+
+	b.handleCheckAllManga(userID, userID)
+	<-runner.calls
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, appcopy.Copy.Prompts.UpdateAlreadyRunning) })
+
+	b.handleCheckAllManga(userID, userID)
+	select {
+	case <-runner.calls:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a skipped run should not start the cooldown")
+	}
+}
+
+func TestHandleCheckAllManga_NoManga(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+	b.SetUpdateRunner(&fakeUpdateRunner{calls: make(chan cron.RunOptions, 1)})
+
+	b.handleCheckAllManga(1, 1)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.CheckAllNoManga {
+		t.Fatalf("message=%q, want no-manga prompt", got)
+	}
+}
+
+func TestHandleGlobalUpdate_AdminOnly(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+	runner := &fakeUpdateRunner{calls: make(chan cron.RunOptions, 1), summary: cron.RunSummary{TitlesChecked: 1, NotificationsSent: 1}}
+	b.SetUpdateRunner(runner)
+
+	b.handleGlobalUpdate(2, 2)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.UpdateAllAdminOnly {
+		t.Fatalf("message=%q, want admin-only prompt", got)
+	}
+
+	b.handleGlobalUpdate(1, 1)
+	opts := <-runner.calls
+	if opts.UserID != 0 || opts.Trigger != cron.TriggerManual {
+		t.Fatalf("opts=%+v, want global manual run", opts)
+	}
+	done := fmt.Sprintf(appcopy.Copy.Info.UpdateAllDone, 1, 0, 1, 0)
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, done) })
+}
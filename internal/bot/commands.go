@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+// maxTitleChoices caps the disambiguation keyboard shown when a title is ambiguous.
+const maxTitleChoices = 8
+
+// chapterArgPattern is a chapter number as typed after a title: plain decimal digits, so
+// "NaN", "Inf" and "1e5" stay part of the title. maxChapterArgLen keeps the callback data
+// built from it under Telegram's 64-byte limit.
+var chapterArgPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+const maxChapterArgLen = 16
+
+// splitChapterArg splits "<title> <chapter>" arguments. The last word is taken as the
+// chapter only when it is a number, something is left for the title, and isTitle (when
+// set) doesn't recognise the whole argument as a title, so "kaiju no 8" can name Kaiju No. 8.
+func splitChapterArg(args string, isTitle func(string) bool) (title string, chapter string) {
+	fields := strings.Fields(args)
+	full := strings.Join(fields, " ")
+	if len(fields) < 2 || (isTitle != nil && isTitle(full)) {
+		return full, ""
+	}
+	last := fields[len(fields)-1]
+	if len(last) > maxChapterArgLen || !chapterArgPattern.MatchString(last) {
+		return full, ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), last
+}
+
+// exactTitleMatcher reports whether a query names one of the user's titles or aliases
+// exactly. It returns nil when the library can't be loaded; resolveTitle reports that.
+func (b *Bot) exactTitleMatcher(userID int64) func(string) bool {
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{})
+	var aliases map[int][]string
+	if err == nil {
+		aliases, err = b.db.ListTitleAliasesByUser(userID)
+	}
+	if err != nil {
+		return nil
+	}
+	return func(query string) bool {
+		for _, m := range manga {
+			if titleMatchScore(query, m.Title) == matchExact {
+				return true
+			}
+			for _, alias := range aliases[m.ID] {
+				if titleMatchScore(query, alias) == matchExact {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// resolveTitle finds the one title in the user's library that query names. When it
+// matches nothing or several titles, the user is told so (with a button per candidate
+// built by choice) and ok is false.
+func (b *Bot) resolveTitle(chatID int64, userID int64, query string, choice func(db.Manga) string) (db.Manga, bool) {
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{})
+	var aliases map[int][]string
+	if err == nil {
+		aliases, err = b.db.ListTitleAliasesByUser(userID)
+	}
+	if err != nil {
+		userLog(userID).Error("Error loading manga for title lookup", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMessageWithMainMenuButton(msg)
+		return db.Manga{}, false
+	}
+
+	matches := matchTitles(query, manga, aliases)
+	switch len(matches) {
+	case 0:
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.TitleNoMatch, html.EscapeString(query)))
+		msg.ParseMode = "HTML"
+		b.sendListScopedMessage(msg)
+		return db.Manga{}, false
+	case 1:
+		return matches[0], true
+	}
+
+	text := fmt.Sprintf(appcopy.Copy.Prompts.TitleChoose, html.EscapeString(query))
+	if len(matches) > maxTitleChoices {
+		text += fmt.Sprintf(appcopy.Copy.Prompts.TitleChooseMore, maxTitleChoices)
+		matches = matches[:maxTitleChoices]
+	}
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, len(matches))
+	for i, m := range matches {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(listItemLabel(i+1, m), choice(m)),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(appendBackToMangaListRow(keyboard)...)
+	b.sendMessageWithMainMenuButton(msg)
+	return db.Manga{}, false
+}
+
+// mangaActionChoice makes the disambiguation buttons run action on the chosen title.
+func mangaActionChoice(action string) func(db.Manga) string {
+	return func(m db.Manga) string { return cbMangaAction(m.ID, action) }
+}
+
+func (b *Bot) sendCommandUsage(chatID int64, usage string) {
+	msg := tgbotapi.NewMessage(chatID, usage)
+	b.sendMessageWithMainMenuButton(msg)
+}
+
+// handleAddCommand adds the title args points at, or asks for one like the Add button.
+func (b *Bot) handleAddCommand(chatID int64, userID int64, args string) {
+	if strings.TrimSpace(args) != "" && b.handleAddInput(chatID, userID, args) {
+		return
+	}
+	if err := b.db.SetUserPendingState(userID, pendingStateAddManga, ""); err != nil {
+		userLog(userID).Warn("Failed to set pending state", "error", err)
+	}
+	b.sendAddMangaPrompt(chatID)
+}
+
+// handleReadCommand handles "/read <title> [chapter]". Without a chapter it opens the
+// title's mark-read menu.
+func (b *Bot) handleReadCommand(chatID int64, userID int64, args string) {
+	query, chapter := splitChapterArg(args, b.exactTitleMatcher(userID))
+	if query == "" {
+		b.sendCommandUsage(chatID, appcopy.Copy.Prompts.ReadUsage)
+		return
+	}
+	b.logAction(userID, "Read command", fmt.Sprintf("Query: %q, Chapter: %q", query, chapter))
+
+	choice := mangaActionChoice("mark_read")
+	if chapter != "" {
+		choice = func(m db.Manga) string { return cbMarkChapterRead(m.ID, chapter) }
+	}
+	m, ok := b.resolveTitle(chatID, userID, query, choice)
+	if !ok {
+		return
+	}
+	if chapter == "" {
+		b.handleMangaSelection(chatID, userID, m.ID, "mark_read")
+		return
+	}
+	if b.chapterExists(chatID, userID, m, chapter) {
+		b.markChapterAsRead(chatID, userID, m.ID, chapter, db.HistorySourceCommand, nil)
+	}
+}
+
+// handleUnreadCommand handles "/unread [title] [chapter]". Without arguments it lists
+// the titles with unread chapters.
+func (b *Bot) handleUnreadCommand(chatID int64, userID int64, args string) {
+	query, chapter := splitChapterArg(args, b.exactTitleMatcher(userID))
+	if query == "" {
+		b.sendMangaList(chatID, userID, listView{Filter: db.FilterUnread})
+		return
+	}
+	b.logAction(userID, "Unread command", fmt.Sprintf("Query: %q, Chapter: %q", query, chapter))
+
+	choice := mangaActionChoice("mark_unread")
+	if chapter != "" {
+		choice = func(m db.Manga) string { return cbMarkChapterUnread(m.ID, chapter) }
+	}
+	m, ok := b.resolveTitle(chatID, userID, query, choice)
+	if !ok {
+		return
+	}
+	if chapter == "" {
+		b.handleMangaSelection(chatID, userID, m.ID, "mark_unread")
+		return
+	}
+	if b.chapterExists(chatID, userID, m, chapter) {
+		b.markChapterAsUnread(chatID, userID, m.ID, chapter, db.HistorySourceCommand, nil)
+	}
+}
+
+// chapterExists reports whether m has the chapter, telling the user when it doesn't.
+func (b *Bot) chapterExists(chatID int64, userID int64, m db.Manga, chapter string) bool {
+	_, err := b.db.IsChapterRead(m.ID, chapter)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, sql.ErrNoRows):
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ChapterNotFound, html.EscapeString(m.Title), html.EscapeString(chapter)))
+		msg.ParseMode = "HTML"
+		b.sendMangaScopedMessage(msg, m.ID)
+	default:
+		userLog(userID, m.ID).Error("Error looking up chapter", "chapter", chapter, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateChapter)
+		b.sendMangaScopedMessage(msg, m.ID)
+	}
+	return false
+}
+
+// handleListCommand handles "/list [query]": the whole list, or the titles matching
+// query. Query matching falls back to aliases and typo-tolerant matching when nothing
+// contains it.
+func (b *Bot) handleListCommand(chatID int64, userID int64, args string) {
+	query := strings.TrimSpace(args)
+	if query == "" {
+		b.handleListManga(chatID, userID)
+		return
+	}
+	b.logAction(userID, "List command", fmt.Sprintf("Query: %q", query))
+
+	sort, err := b.db.GetUserListSort(userID)
+	if err != nil {
+		userLog(userID).Warn("Failed loading list sort", "error", err)
+		sort = db.SortTitle
+	}
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{Query: query, Sort: sort})
+	if err == nil && len(manga) == 0 {
+		var aliases map[int][]string
+		manga, err = b.db.ListMangaByUser(userID, db.ListOptions{Sort: sort})
+		if err == nil {
+			aliases, err = b.db.ListTitleAliasesByUser(userID)
+		}
+		manga = matchTitles(query, manga, aliases)
+	}
+	if err != nil {
+		userLog(userID).Error("Error searching manga", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendListScopedMessage(msg)
+		return
+	}
+	if len(manga) == 0 {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.TitleNoMatch, html.EscapeString(query)))
+		msg.ParseMode = "HTML"
+		b.sendListScopedMessage(msg)
+		return
+	}
+	b.sendListSearchResults(chatID, query, manga)
+}
+
+// handleCheckCommand handles "/check [title]": one title, or the whole library.
+func (b *Bot) handleCheckCommand(chatID int64, userID int64, args string) {
+	query := strings.TrimSpace(args)
+	if query == "" {
+		b.handleCheckAllManga(chatID, userID)
+		return
+	}
+	if m, ok := b.resolveTitle(chatID, userID, query, mangaActionChoice("check_new")); ok {
+		b.handleMangaSelection(chatID, userID, m.ID, "check_new")
+	}
+}
+
+// handleRemoveCommand handles "/remove <title>". It only asks for confirmation, the same
+// way the Remove button does.
+func (b *Bot) handleRemoveCommand(chatID int64, userID int64, args string) {
+	query := strings.TrimSpace(args)
+	if query == "" {
+		b.sendCommandUsage(chatID, appcopy.Copy.Prompts.RemoveUsage)
+		return
+	}
+	if m, ok := b.resolveTitle(chatID, userID, query, mangaActionChoice("remove_manga")); ok {
+		b.handleMangaSelection(chatID, userID, m.ID, "remove_manga")
+	}
+}
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/db"
+)
+
+func commandMessage(userID int64, text string) *tgbotapi.Message {
+	command, _, _ := strings.Cut(text, " ")
+	return &tgbotapi.Message{
+		Text: text,
+		Entities: []tgbotapi.MessageEntity{
+			{Type: "bot_command", Offset: 0, Length: len(command)},
+		},
+		From: &tgbotapi.User{ID: userID},
+		Chat: &tgbotapi.Chat{ID: userID},
+	}
+}
+
+func TestMatchTitles(t *testing.T) {
+	library := []db.Manga{
+		{ID: 1, Title: "Frieren: Beyond Journey's End"},
+		{ID: 2, Title: "One Piece"},
+		{ID: 3, Title: "One-Punch Man"},
+		{ID: 4, Title: "Chainsaw Man"},
+		{ID: 5, Title: "Dandadan"},
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "one piece", want: []int{2}},
+		{query: "ONE-PIECE", want: []int{2}},
+		{query: "one", want: []int{2, 3}},
+		{query: "onepunch", want: []int{3}},
+		{query: "frieren", want: []int{1}},
+		{query: "journey end", want: []int{1}},
+		{query: "man", want: []int{3, 4}},
+		{query: "freiren", want: []int{1}},
+		{query: "chainsow", want: []int{4}},
+		{query: "dan da dan", want: []int{5}},
+		{query: "berserk", want: nil},
+		{query: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, m := range matchTitles(tt.query, library, nil) {
+			got = append(got, m.ID)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("matchTitles(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("matchTitles(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSplitChapterArg(t *testing.T) {
+	tests := []struct {
+		args, title, chapter string
+	}{
+		{args: "frieren 120", title: "frieren", chapter: "120"},
+		{args: "one piece 1100.5", title: "one piece", chapter: "1100.5"},
+		{args: "kaiju no 8", title: "kaiju no 8", chapter: ""},
+		{args: "kaiju no 8 12", title: "kaiju no 8", chapter: "12"},
+		{args: "Kaiju No. 8", title: "Kaiju No. 8", chapter: ""},
+		{args: "86", title: "86", chapter: ""},
+		{args: "  one piece ", title: "one piece", chapter: ""},
+		{args: "", title: "", chapter: ""},
+		{args: "one piece NaN", title: "one piece NaN", chapter: ""},
+		{args: "one piece Inf", title: "one piece Inf", chapter: ""},
+		{args: "one piece 1e5", title: "one piece 1e5", chapter: ""},
+		{args: "one piece -3", title: "one piece -3", chapter: ""},
+		{args: "one piece 12345678901234567", title: "one piece 12345678901234567", chapter: ""},
+	}
+	isTitle := func(query string) bool { return titleMatchScore(query, "Kaiju No. 8") == matchExact }
+	for _, tt := range tests {
+		title, chapter := splitChapterArg(tt.args, isTitle)
+		if title != tt.title || chapter != tt.chapter {
+			t.Errorf("splitChapterArg(%q) = (%q, %q), want (%q, %q)", tt.args, title, chapter, tt.title, tt.chapter)
+		}
+	}
+}
+
+func TestReadCommand(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	now := time.Now()
+	ids := make(map[string]int)
+	for _, title := range []string{"One Piece", "One-Punch Man"} {
+		id64, err := database.AddManga("md-"+title, title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(%q): %v", title, err)
+		}
+		ids[title] = int(id64)
+		for _, number := range []string{"1", "2", "3"} {
+			if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+				t.Fatalf("AddChapter(%q): %v", number, err)
+			}
+		}
+	}
+
+	b.handleMessage(commandMessage(userID, "/read one pice 2"))
+	if got := api.lastMessageText(t); !strings.Contains(got, "One Piece") {
+		t.Fatalf("reply=%q, want a mark-read confirmation", got)
+	}
+	if read, err := database.IsChapterRead(ids["One Piece"], "2"); err != nil || !read {
+		t.Fatalf("IsChapterRead(2) = %v, %v; want true", read, err)
+	}
+	if history, err := database.ListReadingHistory(ids["One Piece"], 1); err != nil || len(history) != 1 || history[0].Source != db.HistorySourceCommand {
+		t.Fatalf("history=%+v err=%v, want the mark recorded as a command", history, err)
+	}
+
+	b.handleMessage(commandMessage(userID, "/read one 3"))
+	callbacks := messageCallbacks(t, api.lastMessageConfig(t))
+	for _, title := range []string{"One Piece", "One-Punch Man"} {
+		if want := cbMarkChapterRead(ids[title], "3"); !hasCallback(callbacks, want) {
+			t.Fatalf("choices=%v, want %q", callbacks, want)
+		}
+	}
+	if read, _ := database.IsChapterRead(ids["One Piece"], "3"); read {
+		t.Fatalf("ambiguous /read marked a chapter")
+	}
+
+	b.handleMessage(commandMessage(userID, "/read one piece 9"))
+	if got := api.lastMessageText(t); !strings.Contains(got, "no chapter <b>9</b>") {
+		t.Fatalf("reply=%q, want chapter not found", got)
+	}
+
+	b.handleMessage(commandMessage(userID, "/read berserk"))
+	if got := api.lastMessageText(t); !strings.Contains(got, "Nothing in your library matches") {
+		t.Fatalf("reply=%q, want no match", got)
+	}
+}
+
+func TestRemoveCommandAsksForConfirmation(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga("md-1", "Dandadan", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	b.handleMessage(commandMessage(userID, "/remove dandadan"))
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbMangaAction(int(id64), "remove_manga_yes")) {
+		t.Fatalf("reply=%q, want a removal confirmation", api.lastMessageText(t))
+	}
+	if ok, err := database.MangaBelongsToUser(int(id64), userID); err != nil || !ok {
+		t.Fatalf("title removed without confirmation: %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+// deepLinkAddPrefix starts a /start payload that shares a MangaDex title:
+// "add_<mangadex uuid>".
+const deepLinkAddPrefix = "add_"
+
+// pairingQRSize is the side of the pairing QR code image, in pixels.
+const pairingQRSize = 512
+
+// SetUsername sets the bot's @username, used to build t.me deep links. Without it no
+// links are offered.
+func (b *Bot) SetUsername(username string) {
+	b.username = strings.TrimPrefix(username, "@")
+}
+
+// deepLink returns the t.me link that opens the bot with /start payload, or "" when the
+// bot's username is unknown.
+func (b *Bot) deepLink(payload string) string {
+	if b.username == "" {
+		return ""
+	}
+	return "https://t.me/" + url.PathEscape(b.username) + "?start=" + url.QueryEscape(payload)
+}
+
+// groupLink returns the t.me link that adds the bot to a group and links it with code,
+// or "" when the bot's username is unknown.
+func (b *Bot) groupLink(code string) string {
+	if b.username == "" {
+		return ""
+	}
+	return "https://t.me/" + url.PathEscape(b.username) + "?startgroup=" + url.QueryEscape(code)
+}
+
+// addTitleLink returns the deep link that offers to add a MangaDex title, or "".
+func (b *Bot) addTitleLink(mangaDexID string) string {
+	return b.deepLink(deepLinkAddPrefix + mangaDexID)
+}
+
+// startPayload returns the text to look for a pairing code in: the /start parameter of a
+// deep link, or the message itself.
+func startPayload(message *tgbotapi.Message) string {
+	if message.IsCommand() && message.Command() == appcopy.Copy.Commands.Start {
+		return message.CommandArguments()
+	}
+	return message.Text
+}
+
+// handleStart answers /start, acting on a deep-link payload when there is one. Unknown
+// payloads just open the main menu.
+func (b *Bot) handleStart(chatID int64, userID int64, payload string) {
+	payload = strings.TrimSpace(payload)
+	if payload != "" {
+		b.logAction(userID, "Start payload", payload)
+	}
+	if _, ok := parsePairingCode(payload); ok {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.PairingAlreadyAuth)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+	if id, ok := strings.CutPrefix(payload, deepLinkAddPrefix); ok && b.looksLikeMangaDexID(id) {
+		b.handleAddManga(chatID, userID, strings.ToLower(id))
+		return
+	}
+	b.sendMainMenu(chatID)
+}
+
+// sendPairingQR sends a QR code of the pairing link so the friend can scan it off the
+// admin's screen.
+func (b *Bot) sendPairingQR(chatID int64, code string, link string) {
+	png, err := qrcode.Encode(link, qrcode.Medium, pairingQRSize)
+	if err != nil {
+		userLog(chatID).Warn("Failed encoding pairing QR code", "error", err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "pairing-" + code + ".png", Bytes: png})
+	photo.Caption = fmt.Sprintf(appcopy.Copy.Prompts.PairingQRCaption, code)
+	if _, err := b.api.Send(photo); err != nil {
+		userLog(chatID).Warn("Failed sending pairing QR code", "error", err)
+	}
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+func startMessage(userID int64, payload string) *tgbotapi.Message {
+	msg := commandMessage(userID, "/start "+payload)
+	msg.Chat.Type = "private"
+	return msg
+}
+
+func TestHandleUpdate_StartPayloadRedeemsPairingCode(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	const (
+		userID = int64(42)
+		code   = "ABCD-1234"
+	)
+	if err := database.CreatePairingCode(code, 1, time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("CreatePairingCode(): %v", err)
+	}
+
+	b.handleUpdate(tgbotapi.Update{Message: startMessage(userID, code)})
+
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.PairingSuccess {
+		t.Fatalf("message=%q, want %q", got, appcopy.Copy.Prompts.PairingSuccess)
+	}
+	if authorized, _, err := database.IsUserAuthorized(userID); err != nil || !authorized {
+		t.Fatalf("authorized=%v err=%v, want a paired user", authorized, err)
+	}
+
+	b.handleUpdate(tgbotapi.Update{Message: startMessage(userID, code)})
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.PairingAlreadyAuth {
+		t.Fatalf("second /start message=%q, want %q", got, appcopy.Copy.Prompts.PairingAlreadyAuth)
+	}
+}
+
+func TestHandleStart_AddPayloadOffersTitle(t *testing.T) {
+	b, database, api, mdID := setupBotWithMangaDexServer(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+
+	b.handleStart(userID, userID, deepLinkAddPrefix+strings.ToUpper(mdID))
+
+	if got := api.lastMessageText(t); !strings.Contains(got, "Dragon Ball Super") || !strings.Contains(got, "Add this to your list?") {
+		t.Fatalf("message=%q, want the add confirmation", got)
+	}
+
+	b.handleStart(userID, userID, "add_not-a-uuid")
+	if got := api.lastMessageText(t); strings.Contains(got, "Add this to your list?") {
+		t.Fatalf("message=%q, want the main menu for a bad payload", got)
+	}
+}
+
+func TestHandleGeneratePairingCode_SendsDeepLinkAndQR(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+	b.SetUsername("@ExampleBot")
+
+	b.handleGeneratePairingCode(1, 1)
+
+	if len(api.sent) < 2 {
+		t.Fatalf("sent=%d, want the code message and a QR photo", len(api.sent))
+	}
+	msg, ok := api.sent[len(api.sent)-2].(tgbotapi.MessageConfig)
+	if !ok {
+		t.Fatalf("sent %T, want the code message before the photo", api.sent[len(api.sent)-2])
+	}
+	code := regexp.MustCompile(`[A-F0-9]{4}-[A-F0-9]{4}`).FindString(msg.Text)
+	if code == "" || !strings.Contains(msg.Text, "https://t.me/ExampleBot?start="+code) {
+		t.Fatalf("message=%q, want a deep link with the code", msg.Text)
+	}
+	photo, ok := api.sent[len(api.sent)-1].(tgbotapi.PhotoConfig)
+	if !ok {
+		t.Fatalf("last sent %T, want a QR photo", api.sent[len(api.sent)-1])
+	}
+	if !strings.Contains(photo.Caption, code) {
+		t.Fatalf("caption=%q, want the code", photo.Caption)
+	}
+}
+
+func TestDeepLink(t *testing.T) {
+	b, _, _ := setupBotForMessageTests(t)
+	if got := b.addTitleLink("abc"); got != "" {
+		t.Fatalf("link without username=%q, want none", got)
+	}
+	b.SetUsername("ExampleBot")
+	if got, want := b.addTitleLink("abc"), "https://t.me/ExampleBot?start=add_abc"; got != want {
+		t.Fatalf("link=%q, want %q", got, want)
+	}
+}
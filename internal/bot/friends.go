@@ -0,0 +1,361 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/mangadex"
+)
+
+// maxFriendsReading caps the "friends are reading" view so it fits in one message.
+const maxFriendsReading = 30
+
+// maxRecommendationsPerDay caps how many titles one user can send another in 24 hours.
+const maxRecommendationsPerDay = 3
+
+// displayNameOf is the name the other paired users see for u: the first name, or the
+// @username when Telegram has no first name.
+func displayNameOf(u *tgbotapi.User) string {
+	if u == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(u.FirstName); name != "" {
+		return name
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return ""
+}
+
+// friendName is how a paired user is shown to the others. Users who haven't written to
+// the bot since names were stored only have their ID.
+func friendName(chatID int64, name string) string {
+	if name == "" {
+		return fmt.Sprintf(appcopy.Copy.Labels.FriendUnnamed, chatID)
+	}
+	return name
+}
+
+// rememberDisplayName keeps the user's stored name in line with their Telegram profile.
+func (b *Bot) rememberDisplayName(chatID int64, from *tgbotapi.User) {
+	if from == nil || chatID <= 0 || chatID != from.ID {
+		return
+	}
+	name := displayNameOf(from)
+	if name == "" {
+		return
+	}
+	if err := b.db.SetUserDisplayName(chatID, name); err != nil {
+		userLog(chatID).Warn("Failed storing display name", "error", err)
+	}
+}
+
+// sendRecommendMenu lists the other paired users who share their list, the only ones a
+// title can be recommended to.
+func (b *Bot) sendRecommendMenu(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	d, err := b.db.GetMangaDetails(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga details", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if d.MangaDexID == "" {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RecommendMangaDexOnly)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	friends, err := b.db.ListFriends(userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error listing friends", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRecommend)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if len(friends) == 0 {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendNoFriends, html.EscapeString(d.Title)))
+		msg.ParseMode = "HTML"
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(friends))
+	for _, f := range friends {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(friendName(f.ChatID, f.Name), cbRecommendTo(mangaID, f.ChatID)),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendChoose, html.EscapeString(d.Title)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+}
+
+// handleRecommend sends one of the user's titles to another paired user who shares their
+// list, with a button that adds it to their own list. Users who stop sharing their list
+// stop getting recommendations.
+func (b *Bot) handleRecommend(chatID int64, userID int64, mangaID int, friendID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	d, err := b.db.GetMangaDetails(mangaID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+		b.sendListScopedMessage(msg, cbTarget)
+		return
+	}
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga details", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if d.MangaDexID == "" {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RecommendMangaDexOnly)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	known, err := b.db.IsFriend(userID, friendID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error looking up recommendation recipient", "to", friendID, "error", err)
+	}
+	if !known {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RecommendUnknownFriend)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	sent, err := b.db.CountRecommendationsSince(userID, friendID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		userLog(userID, mangaID).Error("Error counting recommendations", "to", friendID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRecommend)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if sent >= maxRecommendationsPerDay {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendLimitReached, maxRecommendationsPerDay))
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	recID, err := b.db.CreateRecommendation(userID, friendID, d.MangaDexID, d.Title, d.IsMangaPlus)
+	var rec db.Recommendation
+	if err == nil {
+		rec, err = b.db.GetRecommendation(recID)
+	}
+	if err != nil {
+		userLog(userID, mangaID).Error("Error saving recommendation", "to", friendID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRecommend)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	offer := tgbotapi.NewMessage(friendID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendationReceived,
+		html.EscapeString(friendName(userID, rec.FromName)), html.EscapeString(rec.Title)))
+	offer.ParseMode = "HTML"
+	offer.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ConfirmAdd, cbAddRecommendation(recID)),
+			tgbotapi.NewInlineKeyboardButtonURL(appcopy.Copy.Buttons.OpenOnMangaDex, mangadex.TitleURL(rec.MangaDexID)),
+		),
+	)
+	if _, err := b.api.Send(offer); err != nil {
+		userLog(userID, mangaID).Error("Error sending recommendation", "to", friendID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRecommend)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.logAction(userID, "Recommended manga", fmt.Sprintf("Manga ID: %d, To: %d", mangaID, friendID))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendSent,
+		html.EscapeString(rec.Title), html.EscapeString(friendName(friendID, rec.ToName))))
+	msg.ParseMode = "HTML"
+	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+}
+
+// handleAddRecommendation adds a title someone recommended to the user's own list and
+// lets the recommender know.
+func (b *Bot) handleAddRecommendation(chatID int64, userID int64, recID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	rec, err := b.db.GetRecommendation(recID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		userLog(userID).Error("Error loading recommendation", "recommendation", recID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotAddManga)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	if err != nil || rec.ToUserID != userID {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RecommendationNotFound)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	mangaDexID := b.resolveMangaDexID(userID, rec.MangaDexID)
+	library, err := b.db.ListMangaByUser(userID, db.ListOptions{})
+	if err != nil {
+		userLog(userID).Error("Error listing manga", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotAddManga)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	for _, m := range library {
+		if m.MangaDexID == mangaDexID {
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendationAlreadyAdded, html.EscapeString(m.Title)))
+			msg.ParseMode = "HTML"
+			b.sendMangaScopedMessage(msg, m.ID, cbTarget)
+			return
+		}
+	}
+
+	mangaDBID, err := b.db.AddMangaWithMangaPlus(mangaDexID, rec.Title, rec.IsMangaPlus, userID)
+	if err != nil {
+		userLog(userID).Error("Error adding recommended manga", logger.KeyMangaDexID, mangaDexID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotAddManga)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.recordMangaAdded(userID, int(mangaDBID), db.HistorySourceNotification)
+	if err := b.db.MarkRecommendationAdded(recID); err != nil {
+		userLog(userID).Warn("Failed marking recommendation added", "recommendation", recID, "error", err)
+	}
+	b.logAction(userID, "Added recommended manga", fmt.Sprintf("%s from %d", mangaDexID, rec.FromUserID))
+
+	if !rec.Added {
+		thanks := tgbotapi.NewMessage(rec.FromUserID, fmt.Sprintf(appcopy.Copy.Prompts.RecommendationAdded,
+			html.EscapeString(friendName(userID, rec.ToName)), html.EscapeString(rec.Title)))
+		thanks.ParseMode = "HTML"
+		if _, err := b.api.Send(thanks); err != nil {
+			userLog(rec.FromUserID).Warn("Failed telling sender their recommendation was added", "error", err)
+		}
+	}
+
+	startText := fmt.Sprintf(appcopy.Copy.Info.SyncStartAdded, html.EscapeString(rec.Title))
+	b.importNewManga(chatID, userID, mangaDBID, mangaDexID, rec.Title, rec.IsMangaPlus, startText, cbTarget)
+}
+
+// sendFriendsReading shows which titles the other users who share their list follow.
+// Only users who share their own list get to see it.
+func (b *Bot) sendFriendsReading(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	settings, err := b.db.GetUserSettings(userID)
+	if err != nil {
+		userLog(userID).Error("Error loading settings", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	if !settings.ShareLibrary {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Info.FriendsReadingOptIn)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(sharingRows(settings)...)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	titles, err := b.db.ListFriendsReading(userID)
+	if err != nil {
+		userLog(userID).Error("Error loading friends reading", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadFriends)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatFriendsReading(titles, settings.ShareProgress))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(sharingRows(settings)...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func formatFriendsReading(titles []db.FriendTitle, shareProgress bool) string {
+	var bld strings.Builder
+	bld.WriteString(appcopy.Copy.Info.FriendsReadingTitle)
+	if len(titles) == 0 {
+		bld.WriteString(appcopy.Copy.Info.FriendsReadingEmpty)
+	}
+	for i, t := range titles {
+		if i == maxFriendsReading {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.FriendsReadingMore, len(titles)-i))
+			break
+		}
+		readers := make([]string, 0, len(t.Readers))
+		for _, r := range t.Readers {
+			name := html.EscapeString(friendName(r.ChatID, r.Name))
+			if r.HasLastRead {
+				name = fmt.Sprintf(appcopy.Copy.Labels.FriendProgress, name, strconv.FormatFloat(r.LastRead, 'f', -1, 64))
+			}
+			readers = append(readers, name)
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.FriendsReadingLine, html.EscapeString(t.Title), strings.Join(readers, ", ")))
+	}
+	if shareProgress {
+		bld.WriteString(appcopy.Copy.Info.FriendsReadingProgressShared)
+	} else {
+		bld.WriteString(appcopy.Copy.Info.FriendsReadingProgressHidden)
+	}
+	return bld.String()
+}
+
+// sharingRows are the opt-in toggles of the friends view. Sharing progress is only
+// offered once the list itself is shared.
+func sharingRows(settings db.UserSettings) [][]tgbotapi.InlineKeyboardButton {
+	libraryButton := appcopy.Copy.Buttons.ShareLibraryOff
+	if settings.ShareLibrary {
+		libraryButton = appcopy.Copy.Buttons.ShareLibraryOn
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(libraryButton, cbToggleShareLibrary()),
+		),
+	}
+	if settings.ShareLibrary {
+		progressButton := appcopy.Copy.Buttons.ShareProgressOff
+		if settings.ShareProgress {
+			progressButton = appcopy.Copy.Buttons.ShareProgressOn
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(progressButton, cbToggleShareProgress()),
+		))
+	}
+	return rows
+}
+
+func (b *Bot) handleToggleShareLibrary(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	settings, err := b.db.GetUserSettings(userID)
+	if err == nil {
+		err = b.db.SetUserShareLibrary(userID, !settings.ShareLibrary)
+	}
+	if err != nil {
+		userLog(userID).Error("Error toggling library sharing", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Toggle library sharing", fmt.Sprintf("Enabled: %t", !settings.ShareLibrary))
+	b.sendFriendsReading(chatID, userID, cbTarget)
+}
+
+func (b *Bot) handleToggleShareProgress(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	settings, err := b.db.GetUserSettings(userID)
+	if err == nil {
+		err = b.db.SetUserShareProgress(userID, !settings.ShareProgress)
+	}
+	if err != nil {
+		userLog(userID).Error("Error toggling progress sharing", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Toggle progress sharing", fmt.Sprintf("Enabled: %t", !settings.ShareProgress))
+	b.sendFriendsReading(chatID, userID, cbTarget)
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/db"
+)
+
+// messagesTo returns what the bot sent to chatID so far.
+func (f *fakeTelegramAPI) messagesTo(chatID int64) []tgbotapi.MessageConfig {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []tgbotapi.MessageConfig
+	for _, msg := range f.outboundMessages {
+		if msg.ChatID == chatID {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+func TestRecommend_SendsTitleAndAddsItForTheFriend(t *testing.T) {
+	b, database, api, mdID := setupBotWithMangaDexServer(t)
+	const (
+		alice = int64(42)
+		bob   = int64(43)
+	)
+	for _, u := range []tgbotapi.User{{ID: alice, FirstName: "Alice"}, {ID: bob, UserName: "bob"}} {
+		if err := database.EnsureUser(u.ID, false); err != nil {
+			t.Fatalf("EnsureUser(): %v", err)
+		}
+		b.rememberDisplayName(u.ID, &u)
+	}
+	id64, err := database.AddMangaWithMangaPlus(mdID, "Dragon Ball Super", true, alice)
+	if err != nil {
+		t.Fatalf("AddMangaWithMangaPlus(): %v", err)
+	}
+	mangaID := int(id64)
+
+	// Only users who share their list can be picked.
+	b.handleMangaSelection(alice, alice, mangaID, "recommend")
+	if got := api.lastMessageText(t); !strings.Contains(got, "Nobody else shares their list") {
+		t.Fatalf("recommend menu=%q, want nobody to pick before Bob opts in", got)
+	}
+	b.handleRecommend(alice, alice, mangaID, bob)
+	if len(api.messagesTo(bob)) != 0 {
+		t.Fatalf("Bob got a recommendation before opting in")
+	}
+	if err := database.SetUserShareLibrary(bob, true); err != nil {
+		t.Fatalf("SetUserShareLibrary(): %v", err)
+	}
+
+	b.handleMangaSelection(alice, alice, mangaID, "recommend")
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbRecommendTo(mangaID, bob)) {
+		t.Fatalf("recommend menu=%q, want a button for Bob", api.lastMessageText(t))
+	}
+
+	b.handleRecommend(alice, alice, mangaID, bob)
+	offers := api.messagesTo(bob)
+	if len(offers) != 1 || !strings.Contains(offers[0].Text, "Alice recommends <b>Dragon Ball Super</b>") {
+		t.Fatalf("messages to Bob=%+v, want one recommendation from Alice", offers)
+	}
+	if !hasCallback(messageCallbacks(t, offers[0]), cbAddRecommendation(1)) {
+		t.Fatalf("recommendation has no add button")
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "to @bob") {
+		t.Fatalf("confirmation=%q, want it sent to @bob", got)
+	}
+
+	b.handleAddRecommendation(alice, alice, 1)
+	if got := api.lastMessageText(t); !strings.Contains(got, "isn't available") {
+		t.Fatalf("reply to the recommender=%q, want the recommendation refused", got)
+	}
+	if n := len(api.messagesTo(alice)); n != 5 {
+		t.Fatalf("messages to Alice=%d, want 5", n)
+	}
+
+	b.handleAddRecommendation(bob, bob, 1)
+	var isPlus int
+	if err := database.QueryRow("SELECT is_manga_plus FROM manga WHERE user_id = ? AND mangadex_id = ?", bob, mdID).Scan(&isPlus); err != nil || isPlus != 1 {
+		t.Fatalf("Bob's row is_manga_plus=%d err=%v, want a MANGA Plus row", isPlus, err)
+	}
+	var source string
+	if err := database.QueryRow("SELECT source FROM reading_history WHERE user_id = ? AND action = ?", bob, db.HistoryActionAdd).Scan(&source); err != nil || source != db.HistorySourceNotification {
+		t.Fatalf("Bob's add entry source=%q err=%v, want %q", source, err, db.HistorySourceNotification)
+	}
+	alerts := api.messagesTo(alice)
+	if !strings.Contains(alerts[len(alerts)-1].Text, "@bob added <b>Dragon Ball Super</b>") {
+		t.Fatalf("last message to Alice=%q, want the added notice", alerts[len(alerts)-1].Text)
+	}
+	// Wait for the import to report back so it doesn't outlive the test database.
+	waitUntil(t, 2*time.Second, func() bool { return len(api.messagesTo(bob)) >= 3 })
+
+	b.handleAddRecommendation(bob, bob, 1)
+	if got := api.lastMessageText(t); !strings.Contains(got, "already in your list") {
+		t.Fatalf("second add=%q, want already in your list", got)
+	}
+
+	// A sender can only send the same person a few titles a day.
+	before := len(api.messagesTo(bob))
+	for i := 1; i < maxRecommendationsPerDay; i++ {
+		b.handleRecommend(alice, alice, mangaID, bob)
+	}
+	b.handleRecommend(alice, alice, mangaID, bob)
+	if got := api.lastMessageText(t); !strings.Contains(got, "recommendations today") {
+		t.Fatalf("reply=%q, want the daily limit", got)
+	}
+	if got := len(api.messagesTo(bob)) - before; got != maxRecommendationsPerDay-1 {
+		t.Fatalf("recommendations delivered=%d, want %d", got, maxRecommendationsPerDay-1)
+	}
+}
+
+func TestFriendsReading_RequiresOptInAndHidesProgress(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	const (
+		alice = int64(42)
+		bob   = int64(43)
+	)
+	for _, u := range []tgbotapi.User{{ID: alice, FirstName: "Alice"}, {ID: bob, FirstName: "Bob"}} {
+		if err := database.EnsureUser(u.ID, false); err != nil {
+			t.Fatalf("EnsureUser(): %v", err)
+		}
+		b.rememberDisplayName(u.ID, &u)
+	}
+	now := time.Now()
+	bobManga, err := database.AddManga("md-frieren", "Frieren", bob)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if err := database.AddChapter(bobManga, "120", "", now, now, now, now); err != nil {
+		t.Fatalf("AddChapter(): %v", err)
+	}
+	if err := database.MarkChapterAsRead(int(bobManga), "120"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	if err := database.SetUserShareLibrary(bob, true); err != nil {
+		t.Fatalf("SetUserShareLibrary(): %v", err)
+	}
+
+	b.sendFriendsReading(alice, alice)
+	msg := api.lastMessageConfig(t)
+	if strings.Contains(msg.Text, "Frieren") || !hasCallback(messageCallbacks(t, msg), cbToggleShareLibrary()) {
+		t.Fatalf("friends view before opting in=%q, want only the opt-in", msg.Text)
+	}
+
+	b.handleToggleShareLibrary(alice, alice)
+	msg = api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "<b>Frieren</b> — Bob") || strings.Contains(msg.Text, "ch. 120") {
+		t.Fatalf("friends view=%q, want Bob's title without his progress", msg.Text)
+	}
+	if !hasCallback(messageCallbacks(t, msg), cbToggleShareProgress()) {
+		t.Fatalf("friends view has no progress sharing toggle")
+	}
+
+	if err := database.SetUserShareProgress(bob, true); err != nil {
+		t.Fatalf("SetUserShareProgress(): %v", err)
+	}
+	b.sendFriendsReading(alice, alice)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Bob (ch. 120)") {
+		t.Fatalf("friends view=%q, want Bob's progress once he shares it", got)
+	}
+}
@@ -0,0 +1,320 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+)
+
+// A group chat only ever sees its own shared library, which the group chat ID owns like a
+// user owns theirs. Group messages never carry buttons: callbacks stay private-only.
+
+// groupLinkTTL is how long a group link code stays valid.
+const groupLinkTTL = 48 * time.Hour
+
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// handleGroupCodeCommand generates a code an admin of a group uses to link it.
+func (b *Bot) handleGroupCodeCommand(chatID int64, userID int64) {
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.GroupCodeAdminOnly)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+	code, err := generatePairingCode()
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotGeneratePair)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+	expiresAt := time.Now().UTC().Add(groupLinkTTL)
+	if err := b.db.CreateGroupLinkCode(code, userID, expiresAt); err != nil {
+		userLog(userID).Error("Error storing group link code", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotStorePair)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+	b.logAction(userID, "Generated group link code", "")
+
+	text := fmt.Sprintf(appcopy.Copy.Prompts.GroupCodeGenerated, html.EscapeString(code), html.EscapeString(expiresAt.Format(time.RFC1123)), html.EscapeString(code))
+	if link := b.groupLink(code); link != "" {
+		text += fmt.Sprintf(appcopy.Copy.Prompts.GroupCodeLink, html.EscapeString(link))
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	b.sendMessageWithMainMenuButton(msg)
+}
+
+// handleGroupMessage answers a message in a group chat. Only commands addressed to this
+// bot are answered; unlinked groups keep getting the private-only notice. A group
+// upgraded to a supergroup takes its list along to the new chat ID.
+func (b *Bot) handleGroupMessage(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if message.MigrateToChatID != 0 {
+		if err := b.db.MoveGroupChat(chatID, message.MigrateToChatID); err != nil {
+			userLog(chatID).Error("Error moving group chat", "new_chat_id", message.MigrateToChatID, "error", err)
+		}
+		return
+	}
+	linked, err := b.db.IsGroupChatLinked(chatID)
+	if err != nil {
+		userLog(chatID).Error("Error loading group chat", "error", err)
+		return
+	}
+	command, ok := b.groupCommand(message)
+	if !linked {
+		switch {
+		case ok && (command == appcopy.Copy.Commands.Link || command == appcopy.Copy.Commands.Start && message.CommandArguments() != ""):
+			b.linkGroup(message)
+		case ok:
+			b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupNotLinked)
+		case !message.IsCommand():
+			b.sendPrivateOnlyMessage(chatID)
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+
+	args := strings.TrimSpace(message.CommandArguments())
+	switch command {
+	case appcopy.Copy.Commands.List:
+		b.sendGroupList(message.Chat)
+	case appcopy.Copy.Commands.Add:
+		if b.requireGroupAdmin(message) {
+			b.addGroupTitle(chatID, args)
+		}
+	case appcopy.Copy.Commands.Remove:
+		if b.requireGroupAdmin(message) {
+			b.removeGroupTitle(chatID, args)
+		}
+	case appcopy.Copy.Commands.Unlink:
+		if b.requireGroupAdmin(message) {
+			b.unlinkGroup(chatID, message)
+		}
+	case appcopy.Copy.Commands.Link:
+		b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupAlreadyLinked, appcopy.Copy.Commands.List))
+	default:
+		b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupHelp)
+	}
+}
+
+// groupCommand returns the command in message, unless it is addressed to another bot
+// ("/list@otherbot").
+func (b *Bot) groupCommand(message *tgbotapi.Message) (string, bool) {
+	if !message.IsCommand() {
+		return "", false
+	}
+	command, at, _ := strings.Cut(message.CommandWithAt(), "@")
+	if at != "" && b.username != "" && !strings.EqualFold(at, b.username) {
+		return "", false
+	}
+	return command, true
+}
+
+// requireGroupAdmin reports whether the sender may change the group's list, telling the
+// group when they can't.
+func (b *Bot) requireGroupAdmin(message *tgbotapi.Message) bool {
+	if b.isGroupAdmin(message) {
+		return true
+	}
+	b.sendGroupMessage(message.Chat.ID, appcopy.Copy.Prompts.GroupAdminsOnly)
+	return false
+}
+
+func (b *Bot) isGroupAdmin(message *tgbotapi.Message) bool {
+	// Anonymous group admins post on behalf of the group itself.
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true
+	}
+	if message.From == nil {
+		return false
+	}
+	resp, err := b.api.Request(tgbotapi.GetChatMemberConfig{ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+		ChatID: message.Chat.ID,
+		UserID: message.From.ID,
+	}})
+	if err != nil {
+		userLog(message.Chat.ID).Warn("Failed loading group member", "member_id", message.From.ID, "error", err)
+		return false
+	}
+	var member tgbotapi.ChatMember
+	if err := json.Unmarshal(resp.Result, &member); err != nil {
+		userLog(message.Chat.ID).Warn("Failed decoding group member", "member_id", message.From.ID, "error", err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+func (b *Bot) linkGroup(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	code, ok := parsePairingCode(message.CommandArguments())
+	if !ok {
+		b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupLinkUsage)
+		return
+	}
+	if !b.requireGroupAdmin(message) {
+		return
+	}
+	var linkedBy int64
+	if message.From != nil {
+		linkedBy = message.From.ID
+	}
+	linked, err := b.db.LinkGroupChat(code, chatID, message.Chat.Title, linkedBy)
+	if err != nil {
+		userLog(chatID).Error("Error linking group", "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CannotUpdateGroup)
+		return
+	}
+	if !linked {
+		b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupLinkInvalid)
+		return
+	}
+	b.logAction(linkedBy, "Linked group", fmt.Sprintf("Group: %d (%s)", chatID, message.Chat.Title))
+	b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupLinked, appcopy.Copy.Commands.Add))
+}
+
+func (b *Bot) unlinkGroup(chatID int64, message *tgbotapi.Message) {
+	if err := b.db.UnlinkGroupChat(chatID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		userLog(chatID).Error("Error unlinking group", "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CannotUpdateGroup)
+		return
+	}
+	if message.From != nil {
+		b.logAction(message.From.ID, "Unlinked group", fmt.Sprintf("Group: %d", chatID))
+	}
+	b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupUnlinked)
+}
+
+func (b *Bot) sendGroupList(chat *tgbotapi.Chat) {
+	manga, err := b.db.ListMangaByUser(chat.ID, db.ListOptions{Sort: db.SortTitle})
+	if err != nil {
+		userLog(chat.ID).Error("Error listing group library", "error", err)
+		b.sendGroupMessage(chat.ID, appcopy.Copy.Errors.CannotLoadGroupList)
+		return
+	}
+	if len(manga) == 0 {
+		b.sendGroupMessage(chat.ID, fmt.Sprintf(appcopy.Copy.Prompts.GroupListEmpty, appcopy.Copy.Commands.Add))
+		return
+	}
+
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.GroupListHeader, html.EscapeString(chat.Title), len(manga)))
+	for _, m := range manga {
+		latest := ""
+		if number := b.latestChapter(m.ID); number != "" {
+			latest = fmt.Sprintf(appcopy.Copy.Prompts.GroupListLatest, html.EscapeString(number))
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.GroupListItem, html.EscapeString(m.Title), latest))
+	}
+	b.sendGroupMessage(chat.ID, bld.String())
+}
+
+// addGroupTitle adds a MangaDex title to the group's list and imports its chapters in the
+// background, so only chapters released after this are announced.
+func (b *Bot) addGroupTitle(chatID int64, args string) {
+	mangaDexID, ok := b.mangaInputToID(args)
+	if !ok {
+		b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupAddUsage)
+		return
+	}
+	mangaDexID = b.resolveMangaDexID(chatID, strings.ToLower(mangaDexID))
+
+	manga, err := b.db.ListMangaByUser(chatID, db.ListOptions{})
+	if err != nil {
+		userLog(chatID).Error("Error listing group library", "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CannotLoadGroupList)
+		return
+	}
+	for _, m := range manga {
+		if m.MangaDexID == mangaDexID {
+			b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupTitleExists, html.EscapeString(m.Title)))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	mangaData, err := b.mdClient.GetManga(ctx, mangaDexID)
+	if err != nil {
+		userLog(chatID).Error("Error fetching manga for group", logger.KeyMangaDexID, mangaDexID, "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveManga)
+		return
+	}
+	title := mangaTitle(mangaData)
+	mangaID, err := b.db.AddManga(mangaDexID, title, chatID)
+	if err != nil {
+		userLog(chatID).Error("Error adding manga to group", logger.KeyMangaDexID, mangaDexID, "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CouldNotAddManga)
+		return
+	}
+	b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupTitleAdded, html.EscapeString(title)))
+
+	if b.updater == nil {
+		return
+	}
+	go func() {
+		syncCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		syncCtx = logger.WithContext(syncCtx, logger.KeyUserID, chatID, logger.KeyMangaID, int(mangaID), logger.KeyMangaDexID, mangaDexID)
+		if _, _, err := b.updater.SyncAll(syncCtx, int(mangaID)); err != nil {
+			logger.FromContext(syncCtx).Error("Error syncing group title", "title", title, "error", err)
+		}
+	}()
+}
+
+func (b *Bot) removeGroupTitle(chatID int64, query string) {
+	if query == "" {
+		b.sendGroupMessage(chatID, appcopy.Copy.Prompts.GroupRemoveUsage)
+		return
+	}
+	manga, err := b.db.ListMangaByUser(chatID, db.ListOptions{Sort: db.SortTitle})
+	if err != nil {
+		userLog(chatID).Error("Error listing group library", "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CannotLoadGroupList)
+		return
+	}
+	matches := matchTitles(query, manga, nil)
+	switch {
+	case len(matches) == 0:
+		b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupTitleNoMatch, html.EscapeString(query)))
+		return
+	case len(matches) > 1:
+		titles := make([]string, 0, len(matches))
+		for _, m := range matches {
+			titles = append(titles, "• <b>"+html.EscapeString(m.Title)+"</b>")
+		}
+		b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupTitleAmbiguous, strings.Join(titles, "\n")))
+		return
+	}
+
+	m := matches[0]
+	if err := b.db.DeleteManga(m.ID, chatID); err != nil {
+		userLog(chatID, m.ID).Error("Error removing manga from group", "error", err)
+		b.sendGroupMessage(chatID, appcopy.Copy.Errors.CannotUpdateGroup)
+		return
+	}
+	b.sendGroupMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.GroupTitleRemoved, html.EscapeString(m.Title)))
+}
+
+func (b *Bot) sendGroupMessage(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if _, err := b.api.Send(msg); err != nil {
+		userLog(chatID).Warn("Failed sending message to group", "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+func groupCommandMessage(groupID, userID int64, text string) *tgbotapi.Message {
+	msg := commandMessage(userID, text)
+	msg.Chat = &tgbotapi.Chat{ID: groupID, Type: "supergroup", Title: "Reading Club"}
+	return msg
+}
+
+func TestHandleUpdate_GroupLibrary(t *testing.T) {
+	b, database, api, mdID := setupBotWithMangaDexServer(t)
+	const (
+		groupID = int64(-1001)
+		adminID = int64(7)
+		member  = int64(8)
+		code    = "ABCD-1234"
+	)
+	api.chatMemberStatus = map[int64]string{adminID: "administrator"}
+	send := func(userID int64, text string) string {
+		b.handleUpdate(tgbotapi.Update{Message: groupCommandMessage(groupID, userID, text)})
+		return api.lastMessageText(t)
+	}
+
+	// A member's personal library never shows up in the group.
+	if err := database.EnsureUser(member, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if _, err := database.AddManga("md-secret", "Secret Personal Title", member); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	if got := send(member, "/list"); got != appcopy.Copy.Prompts.GroupNotLinked {
+		t.Fatalf("unlinked /list=%q, want the not-linked notice", got)
+	}
+	if err := database.CreateGroupLinkCode(code, 1, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateGroupLinkCode(): %v", err)
+	}
+	if got := send(member, "/link "+code); got != appcopy.Copy.Prompts.GroupAdminsOnly {
+		t.Fatalf("member /link=%q, want admins only", got)
+	}
+	b.SetUsername("ExampleBot")
+	before := len(api.outboundMessages)
+	b.handleUpdate(tgbotapi.Update{Message: groupCommandMessage(groupID, adminID, "/link@OtherBot "+code)})
+	if len(api.outboundMessages) != before {
+		t.Fatalf("command for another bot got a reply: %q", api.lastMessageText(t))
+	}
+	if got := send(adminID, "/start@ExampleBot "+code); !strings.Contains(got, "This group is linked") {
+		t.Fatalf("admin /start payload=%q, want the group linked", got)
+	}
+
+	if got := send(member, "/add "+mdID); got != appcopy.Copy.Prompts.GroupAdminsOnly {
+		t.Fatalf("member /add=%q, want admins only", got)
+	}
+	if got := send(adminID, "/add https://mangadex.org/title/"+mdID); !strings.Contains(got, "Added <b>Dragon Ball Super</b>") {
+		t.Fatalf("admin /add=%q, want the title added", got)
+	}
+	if got := send(adminID, "/add "+mdID); !strings.Contains(got, "already on the group list") {
+		t.Fatalf("second /add=%q, want a duplicate notice", got)
+	}
+
+	got := send(member, "/list")
+	if !strings.Contains(got, "Reading Club") || !strings.Contains(got, "Dragon Ball Super") || strings.Contains(got, "Secret") {
+		t.Fatalf("group /list=%q, want only the shared title", got)
+	}
+	for _, m := range api.outboundMessages {
+		if m.ChatID == groupID && m.ReplyMarkup != nil {
+			t.Fatalf("group message %q has a keyboard", m.Text)
+		}
+	}
+
+	if got := send(adminID, "/remove dragon"); !strings.Contains(got, "Removed <b>Dragon Ball Super</b>") {
+		t.Fatalf("admin /remove=%q, want the title removed", got)
+	}
+	if got := send(adminID, "/unlink"); got != appcopy.Copy.Prompts.GroupUnlinked {
+		t.Fatalf("admin /unlink=%q, want unlinked", got)
+	}
+	if linked, _ := database.IsGroupChatLinked(groupID); linked {
+		t.Fatal("group still linked after /unlink")
+	}
+}
+
+func TestAlertNewChapters_GroupHasNoButtons(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+
+	if err := b.AlertNewChapters(-1001, 5, "new chapters"); err != nil {
+		t.Fatalf("AlertNewChapters(): %v", err)
+	}
+	if msg := api.lastMessageConfig(t); msg.ChatID != -1001 || msg.ReplyMarkup != nil {
+		t.Fatalf("group alert=%+v, want no keyboard", msg)
+	}
+}
+
+func TestHandleGroupCodeCommand(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+
+	b.handleGroupCodeCommand(42, 42)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.GroupCodeAdminOnly {
+		t.Fatalf("non-admin message=%q, want admin only", got)
+	}
+
+	b.SetUsername("ExampleBot")
+	b.handleGroupCodeCommand(1, 1)
+	got := api.lastMessageText(t)
+	if !strings.Contains(got, "Group link code") || !strings.Contains(got, "https://t.me/ExampleBot?startgroup=") {
+		t.Fatalf("message=%q, want a code and a startgroup link", got)
+	}
+}
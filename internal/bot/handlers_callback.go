@@ -29,7 +29,7 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 		b.clearPendingState(query.From.ID)
 	}
 	if query.Message == nil || query.Message.Chat == nil {
-		logger.LogMsg(logger.LogWarning, "Callback query without chat message is unsupported: user=%d data=%q", query.From.ID, query.Data)
+		userLog(query.From.ID).Warn("Callback query without chat message is unsupported", "data", query.Data)
 		callback := tgbotapi.NewCallback(query.ID, "Action unavailable here.")
 		if _, reqErr := b.api.Request(callback); reqErr != nil {
 			logger.LogMsg(logger.LogError, "Error answering callback query: %v", reqErr)
@@ -39,10 +39,10 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 
 	switch payload.Kind {
 	case callbackAddConfirm:
-		b.confirmAddManga(query.Message.Chat.ID, query.From.ID, payload.MangaDexID, payload.IsMangaPlus, target)
+		b.confirmAddManga(query.Message.Chat.ID, query.From.ID, payload.MangaDexID, target)
 	case callbackAddManga:
 		if err := b.db.SetUserPendingState(query.From.ID, pendingStateAddManga, ""); err != nil {
-			logger.LogMsg(logger.LogWarning, "Failed to set pending state for user %d: %v", query.From.ID, err)
+			userLog(query.From.ID).Warn("Failed to set pending state", "error", err)
 		}
 		b.sendAddMangaPrompt(query.Message.Chat.ID, target)
 	case callbackListManga:
@@ -105,6 +105,80 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 	case callbackCancelPending:
 		msg := tgbotapi.NewMessage(query.Message.Chat.ID, appcopy.Copy.Prompts.AddMangaCancelled)
 		b.sendMessageWithMainMenuButton(msg, target)
+	case callbackPairList:
+		b.sendPairingCodesList(query.Message.Chat.ID, query.From.ID, target)
+	case callbackPairRevoke:
+		b.sendRevokePairingConfirm(query.Message.Chat.ID, query.From.ID, payload.PairingCode, target)
+	case callbackPairRevokeYes:
+		b.handleRevokePairingCode(query.Message.Chat.ID, query.From.ID, payload.PairingCode, target)
+	case callbackPairDisable:
+		b.sendDisablePairingUsersConfirm(query.Message.Chat.ID, query.From.ID, payload.PairingCode, target)
+	case callbackPairDisableYes:
+		b.handleDisablePairingUsers(query.Message.Chat.ID, query.From.ID, payload.PairingCode, target)
+	case callbackCheckAll:
+		b.handleCheckAllManga(query.Message.Chat.ID, query.From.ID, target)
+	case callbackUpdateAll:
+		b.handleGlobalUpdate(query.Message.Chat.ID, query.From.ID, target)
+	case callbackRunHistory:
+		b.sendRunHistory(query.Message.Chat.ID, query.From.ID, target)
+	case callbackRunDetail:
+		b.sendRunDetail(query.Message.Chat.ID, query.From.ID, payload.RunID, target)
+	case callbackSettings:
+		b.sendSettings(query.Message.Chat.ID, query.From.ID, target)
+	case callbackStats:
+		b.sendStats(query.Message.Chat.ID, query.From.ID, target)
+	case callbackYearRecap:
+		b.sendYearRecap(query.Message.Chat.ID, query.From.ID, payload.Value, target)
+	case callbackSetUnreadWarning:
+		b.handleSetUnreadWarning(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Value, target)
+	case callbackCustomUnreadWarning:
+		b.sendCustomUnreadWarningPrompt(query.Message.Chat.ID, query.From.ID, payload.MangaID, target)
+	case callbackToggleBacklogReport:
+		b.handleToggleBacklogReport(query.Message.Chat.ID, query.From.ID, target)
+	case callbackShowBacklog:
+		b.sendBacklog(query.Message.Chat.ID, query.From.ID, target)
+	case callbackListCategory:
+		b.sendMangaList(query.Message.Chat.ID, query.From.ID, listView{Category: payload.Category, Filter: payload.Filter, Page: payload.Page}, target)
+	case callbackCycleListSort:
+		b.handleCycleListSort(query.Message.Chat.ID, query.From.ID, listView{Category: payload.Category, Filter: payload.Filter}, target)
+	case callbackListSearch:
+		b.sendListSearchPrompt(query.Message.Chat.ID, query.From.ID, target)
+	case callbackListBack:
+		b.handleBackToList(query.Message.Chat.ID, query.From.ID, target)
+	case callbackToggleExtra:
+		b.handleToggleExtra(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Value, target)
+	case callbackUndoProgress:
+		b.handleUndoProgress(query.Message.Chat.ID, query.From.ID, payload.MangaID, int64(payload.Value), target)
+	case callbackRemoveTitleAlias:
+		b.handleRemoveTitleAlias(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Alias, target)
+	case callbackRecommendTo:
+		b.handleRecommend(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.FriendID, target)
+	case callbackAddRecommendation:
+		b.handleAddRecommendation(query.Message.Chat.ID, query.From.ID, payload.RecommendationID, target)
+	case callbackFriendsReading:
+		b.sendFriendsReading(query.Message.Chat.ID, query.From.ID, target)
+	case callbackToggleShareLibrary:
+		b.handleToggleShareLibrary(query.Message.Chat.ID, query.From.ID, target)
+	case callbackToggleShareProgress:
+		b.handleToggleShareProgress(query.Message.Chat.ID, query.From.ID, target)
+	case callbackListIndex:
+		b.sendListIndex(query.Message.Chat.ID, query.From.ID, listView{Category: payload.Category, Filter: payload.Filter}, target)
+	case callbackSetCategory:
+		b.handleSetCategory(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Category, target)
+	case callbackCategoryMutes:
+		b.sendCategoryMutes(query.Message.Chat.ID, query.From.ID, target)
+	case callbackToggleCategoryMute:
+		b.handleToggleCategoryMute(query.Message.Chat.ID, query.From.ID, payload.Category, target)
+	case callbackSetMangaMute:
+		b.handleSetMangaMute(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Value != 0, alertTarget(payload, target))
+	case callbackSnoozeManga:
+		b.handleSnoozeManga(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Value, alertTarget(payload, target))
+	case callbackAlertSettings:
+		b.sendAlertSettings(query.Message.Chat.ID, query.From.ID, payload.MangaID, alertTarget(payload, target))
+	case callbackSnoozeDatePrompt:
+		b.sendSnoozeDatePrompt(query.Message.Chat.ID, query.From.ID, payload.MangaID, target)
+	case callbackSetNotifyEvery:
+		b.handleSetNotifyEvery(query.Message.Chat.ID, query.From.ID, payload.MangaID, payload.Value, target)
 	default:
 		logger.LogMsg(logger.LogError, "Unhandled callback kind: %d", payload.Kind)
 	}
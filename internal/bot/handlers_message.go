@@ -8,6 +8,7 @@ import (
 
 	"releasenojutsu/internal/appcopy"
 	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/source"
 )
 
 const pendingStateAddManga = "add_manga"
@@ -21,31 +22,61 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 
 	if message.IsCommand() {
 		if err := b.db.ClearUserPendingState(message.From.ID); err != nil {
-			logger.LogMsg(logger.LogWarning, "Failed clearing pending state for %d: %v", message.From.ID, err)
+			userLog(message.From.ID).Warn("Failed clearing pending state", "error", err)
 		}
 
 		switch message.Command() {
 		case appcopy.Copy.Commands.Start:
-			b.sendMainMenu(message.Chat.ID)
+			b.handleStart(message.Chat.ID, message.From.ID, message.CommandArguments())
 		case appcopy.Copy.Commands.Help:
 			b.sendHelpMessage(message.Chat.ID)
+		case appcopy.Copy.Commands.Add:
+			b.handleAddCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.Read:
+			b.handleReadCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.Unread:
+			b.handleUnreadCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.List:
+			b.handleListCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.Check:
+			b.handleCheckCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.Remove:
+			b.handleRemoveCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
 		case appcopy.Copy.Commands.Status:
 			b.sendStatusMessage(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.Stats:
+			b.sendStats(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.Export:
+			b.sendLibraryExport(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.Import:
+			b.sendLibraryImportPrompt(message.Chat.ID)
 		case appcopy.Copy.Commands.GenPair:
-			b.handleGeneratePairingCode(message.Chat.ID, message.From.ID)
+			b.handleGeneratePairingCodeCommand(message.Chat.ID, message.From.ID, message.CommandArguments())
+		case appcopy.Copy.Commands.Pairings:
+			b.sendPairingCodesList(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.GroupCode:
+			b.handleGroupCodeCommand(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.UpdateAll:
+			b.handleGlobalUpdate(message.Chat.ID, message.From.ID)
+		case appcopy.Copy.Commands.Runs:
+			b.sendRunHistory(message.Chat.ID, message.From.ID)
 		default:
 			msg := tgbotapi.NewMessage(message.Chat.ID, appcopy.Copy.Prompts.UnknownCommand)
 			if _, err := b.api.Send(msg); err != nil {
 				logger.LogMsg(logger.LogWarning, "Failed sending message to %d: %v", message.Chat.ID, err)
 			}
 		}
+	} else if message.Document != nil {
+		b.handleLibraryImport(message)
 	} else if b.consumePendingInput(message) {
 		return
 	} else if message.ReplyToMessage != nil && message.ReplyToMessage.Text != "" {
 		b.handleReply(message)
 	} else {
-		if mangaID, ok := b.mangaInputToID(message.Text); ok {
-			b.handleAddManga(message.Chat.ID, message.From.ID, mangaID)
+		if b.handleAddInput(message.Chat.ID, message.From.ID, message.Text) {
+			return
+		}
+		if b.handleQuickInput(message.Chat.ID, message.From.ID, message.Text) {
 			return
 		}
 
@@ -57,9 +88,9 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 }
 
 func (b *Bot) consumePendingInput(message *tgbotapi.Message) bool {
-	state, _, hasState, err := b.db.GetUserPendingState(message.From.ID)
+	state, payload, hasState, err := b.db.GetUserPendingState(message.From.ID)
 	if err != nil {
-		logger.LogMsg(logger.LogWarning, "Failed loading pending state for %d: %v", message.From.ID, err)
+		userLog(message.From.ID).Warn("Failed loading pending state", "error", err)
 		return false
 	}
 	if !hasState {
@@ -68,19 +99,33 @@ func (b *Bot) consumePendingInput(message *tgbotapi.Message) bool {
 
 	switch state {
 	case pendingStateAddManga:
-		mangaID, ok := b.mangaInputToID(message.Text)
-		if !ok {
-			// Keep pending state until the user sends a valid MangaDex URL/ID.
+		if _, _, ok := b.resolveAddInput(message.Text); !ok {
+			// Keep pending state until the user sends a valid MangaDex URL/ID or feed URL.
 			b.sendAddMangaPrompt(message.Chat.ID)
 			return true
 		}
 		if err := b.db.ClearUserPendingState(message.From.ID); err != nil {
 			logger.LogMsg(logger.LogWarning, "Failed clearing pending state for %d: %v", message.From.ID, err)
 		}
-		b.handleAddManga(message.Chat.ID, message.From.ID, mangaID)
+		b.handleAddInput(message.Chat.ID, message.From.ID, message.Text)
+		return true
+	case pendingStateReplaceManga:
+		b.consumeReplaceMangaInput(message, payload)
+		return true
+	case pendingStateUnreadWarning:
+		b.consumeUnreadWarningInput(message, payload)
+		return true
+	case pendingStateSnoozeDate:
+		b.consumeSnoozeDateInput(message, payload)
+		return true
+	case pendingStateListSearch:
+		b.consumeListSearchInput(message)
+		return true
+	case pendingStateTitleAlias:
+		b.consumeTitleAliasInput(message, payload)
 		return true
 	default:
-		logger.LogMsg(logger.LogWarning, "Unknown pending state %q for user %d", state, message.From.ID)
+		userLog(message.From.ID).Warn("Unknown pending state", "state", state)
 		return false
 	}
 }
@@ -110,6 +155,33 @@ func (b *Bot) mangaInputToID(text string) (string, bool) {
 	return "", false
 }
 
+// resolveAddInput finds the source a pasted URL or ID belongs to. MangaDex input is
+// recognised even without a configured registry.
+func (b *Bot) resolveAddInput(text string) (sourceName string, id string, ok bool) {
+	if mangaID, ok := b.mangaInputToID(text); ok {
+		return source.MangaDex, mangaID, true
+	}
+	if src, id, ok := b.sources.Resolve(strings.TrimSpace(text)); ok {
+		return src.Name(), id, true
+	}
+	return "", "", false
+}
+
+// handleAddInput starts adding the title text points at; it reports whether text was
+// recognised.
+func (b *Bot) handleAddInput(chatID int64, userID int64, text string) bool {
+	sourceName, id, ok := b.resolveAddInput(text)
+	if !ok {
+		return false
+	}
+	if sourceName == source.MangaDex {
+		b.handleAddManga(chatID, userID, id)
+	} else {
+		b.handleAddFromSource(chatID, userID, sourceName, id)
+	}
+	return true
+}
+
 func (b *Bot) sendAddMangaPrompt(chatID int64, target ...*callbackEditTarget) {
 	msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AddMangaTitle)
 	msg.ParseMode = "Markdown"
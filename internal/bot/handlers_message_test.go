@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"encoding/json"
 	"errors"
 	"path/filepath"
 	"sync"
@@ -18,7 +19,10 @@ type fakeTelegramAPI struct {
 	mu               sync.Mutex
 	sent             []tgbotapi.Chattable
 	outboundMessages []tgbotapi.MessageConfig
+	inlineAnswers    []tgbotapi.InlineConfig
 	failEditRequests bool
+	// chatMemberStatus answers getChatMember by user ID ("member" when unset).
+	chatMemberStatus map[int64]string
 }
 
 func (f *fakeTelegramAPI) GetUpdatesChan(_ tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
@@ -40,6 +44,15 @@ func (f *fakeTelegramAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse,
 			msg.ReplyMarkup = *cfg.ReplyMarkup
 		}
 		f.outboundMessages = append(f.outboundMessages, msg)
+	case tgbotapi.InlineConfig:
+		f.inlineAnswers = append(f.inlineAnswers, cfg)
+	case tgbotapi.GetChatMemberConfig:
+		status := f.chatMemberStatus[cfg.UserID]
+		if status == "" {
+			status = "member"
+		}
+		result, _ := json.Marshal(tgbotapi.ChatMember{User: &tgbotapi.User{ID: cfg.UserID}, Status: status})
+		return &tgbotapi.APIResponse{Ok: true, Result: result}, nil
 	}
 	return &tgbotapi.APIResponse{Ok: true}, nil
 }
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+// historyLimit is how many progress changes the history view lists.
+const historyLimit = 15
+
+// withUndoButton adds an Undo button for the history entry to msg; an ID of 0 means
+// nothing changed and there is nothing to undo.
+func withUndoButton(msg tgbotapi.MessageConfig, mangaID int, historyID int64) tgbotapi.MessageConfig {
+	if historyID == 0 {
+		return msg
+	}
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Undo, cbUndoProgress(mangaID, historyID)),
+		),
+	)
+	return msg
+}
+
+// historyItemLabel describes one entry, e.g. "Read ch. 12 (+1) · via text · undone".
+func historyItemLabel(e db.HistoryEntry) string {
+	var label string
+	switch e.Action {
+	case db.HistoryActionRead:
+		label = fmt.Sprintf(appcopy.Copy.Labels.HistoryRead, html.EscapeString(e.ChapterNumber))
+	case db.HistoryActionUnread:
+		label = fmt.Sprintf(appcopy.Copy.Labels.HistoryUnread, html.EscapeString(e.ChapterNumber))
+	case db.HistoryActionReadAll:
+		label = appcopy.Copy.Labels.HistoryReadAll
+	case db.HistoryActionExact:
+		label = fmt.Sprintf(appcopy.Copy.Labels.HistoryTracking, appcopy.Copy.Labels.TrackingExact)
+	case db.HistoryActionWatermark:
+		label = fmt.Sprintf(appcopy.Copy.Labels.HistoryTracking, appcopy.Copy.Labels.TrackingWatermark)
+	case db.HistoryActionAdd:
+		label = appcopy.Copy.Labels.HistoryAdded
+	default:
+		label = appcopy.Copy.Labels.HistoryUndo
+	}
+	if e.ReadDelta != 0 {
+		label += fmt.Sprintf(appcopy.Copy.Labels.HistoryDelta, e.ReadDelta)
+	}
+	if e.Source != db.HistorySourceButton {
+		label += fmt.Sprintf(appcopy.Copy.Labels.HistoryVia, html.EscapeString(e.Source))
+	}
+	if e.Undone {
+		label += appcopy.Copy.Labels.HistoryUndone
+	}
+	return label
+}
+
+// recordMangaAdded starts a new title's history with how it was added. The title is
+// already stored, so a failure is only logged.
+func (b *Bot) recordMangaAdded(userID int64, mangaID int, source string) {
+	if _, err := b.db.RecordMangaAdded(mangaID, source); err != nil {
+		userLog(userID, mangaID).Warn("Failed recording added title in history", "source", source, "error", err)
+	}
+}
+
+func (b *Bot) sendReadingHistory(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	entries, err := b.db.ListReadingHistory(mangaID, historyLimit)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error listing reading history", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadHistory)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.HistoryTitle, html.EscapeString(title)))
+	if len(entries) == 0 {
+		bld.WriteString(appcopy.Copy.Info.HistoryEmpty)
+	}
+	for _, e := range entries {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.HistoryItem, e.CreatedAt.Local().Format("Jan 2 15:04"), historyItemLabel(e)))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	// Only the newest change can be undone, and never an undo or a marker.
+	if len(entries) > 0 && entries[0].Undoable() {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.UndoLastChange, cbUndoProgress(mangaID, entries[0].ID)),
+		))
+	}
+	keyboard = appendBackToMangaRow(keyboard, mangaID)
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) handleUndoProgress(chatID int64, userID int64, mangaID int, historyID int64, target ...*callbackEditTarget) {
+	b.undoProgress(chatID, userID, mangaID, historyID, db.HistorySourceButton, firstCallbackTarget(target...))
+}
+
+// undoLatestProgress undoes the title's newest change, for "undo <title>" messages.
+func (b *Bot) undoLatestProgress(chatID int64, userID int64, mangaID int, source string) {
+	entries, err := b.db.ListReadingHistory(mangaID, 1)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error listing reading history", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUndo)
+		b.sendMangaScopedMessage(msg, mangaID, nil)
+		return
+	}
+	if len(entries) == 0 {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Info.UndoUnavailable)
+		b.sendMangaScopedMessage(msg, mangaID, nil)
+		return
+	}
+	b.undoProgress(chatID, userID, mangaID, entries[0].ID, source, nil)
+}
+
+// undoProgress undoes one history entry, recording source for the undo.
+func (b *Bot) undoProgress(chatID int64, userID int64, mangaID int, historyID int64, source string, cbTarget *callbackEditTarget) {
+	b.logAction(userID, "Undo progress", fmt.Sprintf("Manga ID: %d, History ID: %d, Source: %s", mangaID, historyID, source))
+
+	undoneManga, ok, err := b.db.UndoProgress(historyID, userID, source)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg, cbTarget)
+			return
+		}
+		userLog(userID, mangaID).Error("Error undoing progress", "history_id", historyID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUndo)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Info.UndoUnavailable)
+		b.sendMangaScopedMessage(msg, undoneManga, cbTarget)
+		return
+	}
+
+	title, _ := b.db.GetMangaTitle(undoneManga, userID)
+	unread, _ := b.db.CountUnreadChapters(undoneManga)
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.UndoDone, html.EscapeString(title), b.lastReadLineHTML(undoneManga), unread))
+	msg.ParseMode = "HTML"
+	b.sendMangaScopedMessage(msg, undoneManga, cbTarget)
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadingHistory_UndoMarkAllReadFromConfirmation(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga("md-1", "Mis-tap", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3", "4"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%q): %v", number, err)
+		}
+	}
+	if err := database.MarkChapterAsRead(mangaID, "1"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+
+	b.handleMarkAllRead(userID, userID, mangaID)
+	var undo string
+	for _, cb := range messageCallbacks(t, api.lastMessageConfig(t)) {
+		if strings.HasPrefix(cb, "undo:") {
+			undo = cb
+		}
+	}
+	if undo == "" {
+		t.Fatalf("mark all read confirmation has no Undo button")
+	}
+	payload, err := parseCallbackData(undo)
+	if err != nil {
+		t.Fatalf("parseCallbackData(%q): %v", undo, err)
+	}
+
+	b.handleUndoProgress(userID, userID, payload.MangaID, int64(payload.Value))
+	if got := api.lastMessageText(t); !strings.Contains(got, "Undone") || !strings.Contains(got, "Unread: <b>3</b>") {
+		t.Fatalf("undo reply=%q, want the old position back", got)
+	}
+	b.handleUndoProgress(userID, userID, payload.MangaID, int64(payload.Value))
+	if got := api.lastMessageText(t); !strings.Contains(got, "can't be undone") {
+		t.Fatalf("second undo reply=%q, want it refused", got)
+	}
+
+	b.handleMangaSelection(userID, userID, mangaID, "history")
+	msg := api.lastMessageConfig(t)
+	for _, want := range []string{"Undo (-3)", "Read all (+3) · undone", "Read ch. 1 (+1)"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Fatalf("history=%q, want %q", msg.Text, want)
+		}
+	}
+	for _, cb := range messageCallbacks(t, msg) {
+		if strings.HasPrefix(cb, "undo:") {
+			t.Fatalf("history offers undoing an undo: %v", cb)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/mangadex"
+)
+
+// Inline query limits. Telegram gives up on an answer after about ten seconds, so the
+// MangaDex lookups get well under that.
+const (
+	inlineLibraryResults = 5
+	inlineSearchResults  = 10
+	inlineSearchTimeout  = 5 * time.Second
+	inlineCacheSeconds   = 30
+	// Shorter queries only search the library; Telegram sends one per keystroke.
+	inlineMinSearchLen = 3
+	// MangaDex search results are reused across users for a while.
+	inlineSearchCacheTTL  = 5 * time.Minute
+	inlineSearchCacheSize = 256
+	// inlinePMParameter is the /start payload of the "pair with the bot" button.
+	inlinePMParameter = "inline"
+)
+
+// inlineSearchCache keeps recent MangaDex search results by normalized query.
+type inlineSearchCache struct {
+	mu      sync.Mutex
+	entries map[string]inlineSearchEntry
+}
+
+type inlineSearchEntry struct {
+	results []mangadex.MangaData
+	expires time.Time
+}
+
+func (c *inlineSearchCache) get(query string, now time.Time) ([]mangadex.MangaData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[query]
+	if !ok || now.After(e.expires) {
+		return nil, false
+	}
+	return e.results, true
+}
+
+func (c *inlineSearchCache) put(query string, results []mangadex.MangaData, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]inlineSearchEntry)
+	}
+	if len(c.entries) >= inlineSearchCacheSize {
+		for q, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, q)
+			}
+		}
+		if len(c.entries) >= inlineSearchCacheSize {
+			c.entries = make(map[string]inlineSearchEntry)
+		}
+	}
+	c.entries[query] = inlineSearchEntry{results: results, expires: now.Add(inlineSearchCacheTTL)}
+}
+
+// inlineCard is one shareable search result.
+type inlineCard struct {
+	ID          string
+	Title       string
+	Description string
+	CoverURL    string
+	Status      string
+	Latest      string
+	Link        string
+	// AddLink is the deep link that lets whoever sees the card add the title.
+	AddLink string
+}
+
+func publicationStatusLabel(status string) string {
+	switch status {
+	case "ongoing":
+		return appcopy.Copy.Labels.PubStatusOngoing
+	case "completed":
+		return appcopy.Copy.Labels.PubStatusCompleted
+	case "hiatus":
+		return appcopy.Copy.Labels.PubStatusHiatus
+	case "cancelled":
+		return appcopy.Copy.Labels.PubStatusCancelled
+	default:
+		return ""
+	}
+}
+
+// formatInlineCard renders the message a shared result posts. The cover is a hidden link
+// so Telegram shows it as the preview.
+func formatInlineCard(c inlineCard) string {
+	var bld strings.Builder
+	if c.CoverURL != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.InlineCardCover, html.EscapeString(c.CoverURL)))
+	}
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.InlineCardTitle, html.EscapeString(c.Title)))
+	if c.Status != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.InlineCardStatus, c.Status))
+	}
+	if c.Latest != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.InlineCardLatest, html.EscapeString(c.Latest)))
+	}
+	if c.Link != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.InlineCardLink, html.EscapeString(c.Link)))
+	}
+	return bld.String()
+}
+
+func inlineCardResult(c inlineCard) tgbotapi.InlineQueryResultArticle {
+	result := tgbotapi.NewInlineQueryResultArticleHTML(c.ID, c.Title, formatInlineCard(c))
+	result.Description = c.Description
+	result.ThumbURL = c.CoverURL
+	var row []tgbotapi.InlineKeyboardButton
+	if c.Link != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL(appcopy.Copy.Buttons.OpenOnMangaDex, c.Link))
+	}
+	if c.AddLink != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL(appcopy.Copy.Buttons.ConfirmAdd, c.AddLink))
+	}
+	if len(row) > 0 {
+		markup := tgbotapi.NewInlineKeyboardMarkup(row)
+		result.ReplyMarkup = &markup
+	}
+	return result
+}
+
+// handleInlineQuery answers "@bot <query>" from any chat with the user's matching titles
+// followed by MangaDex search results. An empty query lists the library, and queries
+// shorter than inlineMinSearchLen only search it. Users who aren't authorized only get a
+// button to pair with the bot.
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []interface{}{},
+		CacheTime:     inlineCacheSeconds,
+		IsPersonal:    true,
+	}
+	if !b.isAuthorized(query.From.ID) {
+		answer.SwitchPMText = appcopy.Copy.Prompts.InlineUnauthorized
+		answer.SwitchPMParameter = inlinePMParameter
+		b.answerInlineQuery(answer)
+		return
+	}
+	userID := query.From.ID
+	text := strings.TrimSpace(query.Query)
+	b.logAction(userID, "Inline query", fmt.Sprintf("Query: %q", text))
+
+	ctx, cancel := context.WithTimeout(context.Background(), inlineSearchTimeout)
+	defer cancel()
+	for _, c := range b.inlineCards(ctx, userID, text) {
+		answer.Results = append(answer.Results, inlineCardResult(c))
+	}
+	b.answerInlineQuery(answer)
+}
+
+func (b *Bot) answerInlineQuery(answer tgbotapi.InlineConfig) {
+	if _, err := b.api.Request(answer); err != nil {
+		logger.L().Warn("Failed answering inline query", "inline_query_id", answer.InlineQueryID, "error", err)
+	}
+}
+
+// inlineCards builds the results for text. MangaDex failures only cost the cover art and
+// the search results; the library part is always answered.
+func (b *Bot) inlineCards(ctx context.Context, userID int64, text string) []inlineCard {
+	library, err := b.inlineLibraryMatches(userID, text)
+	if err != nil {
+		userLog(userID).Error("Error loading library for inline query", "error", err)
+	}
+	inLibrary := make(map[string]db.Manga, len(library))
+	var ids []string
+	for _, m := range library {
+		if m.MangaDexID != "" {
+			inLibrary[m.MangaDexID] = m
+			ids = append(ids, m.MangaDexID)
+		}
+	}
+
+	remote := make(map[string]mangadex.MangaData)
+	var searched []mangadex.MangaData
+	if b.mdClient != nil {
+		if list, err := b.mdClient.GetMangaByIDs(ctx, ids); err != nil {
+			userLog(userID).Warn("Failed loading MangaDex entries for inline query", "error", err)
+		} else {
+			for _, m := range list.Data {
+				remote[m.Id] = m
+			}
+		}
+		if len([]rune(text)) >= inlineMinSearchLen {
+			searched = b.searchMangaDexInline(ctx, userID, text)
+		}
+	}
+
+	cards := make([]inlineCard, 0, len(library)+len(searched))
+	for _, m := range library {
+		c := inlineCard{
+			ID:          fmt.Sprintf("lib:%d", m.ID),
+			Title:       m.Title,
+			Description: fmt.Sprintf(appcopy.Copy.Labels.InlineInLibrary, m.UnreadCount),
+			Latest:      b.latestChapter(m.ID),
+		}
+		if m.MangaDexID != "" {
+			c.Link = mangadex.TitleURL(m.MangaDexID)
+			c.AddLink = b.addTitleLink(m.MangaDexID)
+			if data, ok := remote[m.MangaDexID]; ok {
+				c.CoverURL = data.CoverURL()
+				c.Status = publicationStatusLabel(data.Attributes.Status)
+			}
+		}
+		cards = append(cards, c)
+	}
+	for _, data := range searched {
+		if _, ok := inLibrary[data.Id]; ok {
+			continue
+		}
+		title := data.PreferredTitle()
+		if title == "" {
+			continue
+		}
+		c := inlineCard{
+			ID:          "md:" + data.Id,
+			Title:       title,
+			Description: appcopy.Copy.Labels.InlineFromMangaDex,
+			CoverURL:    data.CoverURL(),
+			Status:      publicationStatusLabel(data.Attributes.Status),
+			Latest:      data.Attributes.LastChapter,
+			Link:        mangadex.TitleURL(data.Id),
+			AddLink:     b.addTitleLink(data.Id),
+		}
+		if c.Status != "" {
+			c.Description += " · " + c.Status
+		}
+		if c.Latest != "" {
+			c.Description += " · " + fmt.Sprintf(appcopy.Copy.Labels.InlineLatest, c.Latest)
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+// searchMangaDexInline searches MangaDex for text, reusing a recent search for the same
+// query. Failures are logged and leave the search results empty.
+func (b *Bot) searchMangaDexInline(ctx context.Context, userID int64, text string) []mangadex.MangaData {
+	key := normalizeTitle(text)
+	if results, ok := b.inlineSearches.get(key, time.Now()); ok {
+		return results
+	}
+	list, err := b.mdClient.SearchMangaByTitle(ctx, text, inlineSearchResults)
+	if err != nil {
+		userLog(userID).Warn("Failed searching MangaDex for inline query", "error", err)
+		return nil
+	}
+	b.inlineSearches.put(key, list.Data, time.Now())
+	return list.Data
+}
+
+// inlineLibraryMatches returns the user's titles matching text, or the first few of the
+// library when text is empty.
+func (b *Bot) inlineLibraryMatches(userID int64, text string) ([]db.Manga, error) {
+	sort, err := b.db.GetUserListSort(userID)
+	if err != nil {
+		sort = db.SortTitle
+	}
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{Sort: sort})
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		aliases, err := b.db.ListTitleAliasesByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		manga = matchTitles(text, manga, aliases)
+	}
+	if len(manga) > inlineLibraryResults {
+		manga = manga[:inlineLibraryResults]
+	}
+	return manga, nil
+}
+
+func (b *Bot) latestChapter(mangaID int) string {
+	number, ok, err := b.db.GetLatestChapter(mangaID)
+	if err != nil || !ok {
+		return ""
+	}
+	return number
+}
+
+// handleChosenInlineResult logs which result was shared. Telegram only sends these when
+// inline feedback is enabled for the bot in BotFather.
+func (b *Bot) handleChosenInlineResult(result *tgbotapi.ChosenInlineResult) {
+	if !b.isAuthorized(result.From.ID) {
+		return
+	}
+	b.logAction(result.From.ID, "Shared inline result", fmt.Sprintf("Result: %s, Query: %q", result.ResultID, result.Query))
+}
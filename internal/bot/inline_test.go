@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/mangadex"
+)
+
+func inlineMangaData(id, title, status, lastChapter, cover string) mangadex.MangaData {
+	data := mangadex.MangaData{Id: id}
+	data.Attributes.Title = map[string]string{"en": title}
+	data.Attributes.Status = status
+	data.Attributes.LastChapter = lastChapter
+	if cover != "" {
+		rel := mangadex.Relationship{ID: "cover-" + id, Type: "cover_art"}
+		rel.Attributes.FileName = cover
+		data.Relationships = append(data.Relationships, rel)
+	}
+	return data
+}
+
+// waitForInlineAnswers waits for n inline answers; handleUpdate answers them off the
+// update loop.
+func waitForInlineAnswers(t *testing.T, api *fakeTelegramAPI, n int) []tgbotapi.InlineConfig {
+	t.Helper()
+	count := func() int {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return len(api.inlineAnswers)
+	}
+	waitUntil(t, 2*time.Second, func() bool { return count() >= n })
+	time.Sleep(20 * time.Millisecond)
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.inlineAnswers) != n {
+		t.Fatalf("inline answers=%d, want %d", len(api.inlineAnswers), n)
+	}
+	return append([]tgbotapi.InlineConfig(nil), api.inlineAnswers...)
+}
+
+func TestHandleUpdate_InlineQueryFromUnauthorizedUser(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+
+	b.handleUpdate(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q1", From: &tgbotapi.User{ID: 99}, Query: "frieren"}})
+
+	got := waitForInlineAnswers(t, api, 1)[0]
+	if len(got.Results) != 0 || got.SwitchPMText == "" || got.SwitchPMParameter != inlinePMParameter {
+		t.Fatalf("answer=%+v, want no results and a pairing button", got)
+	}
+}
+
+func TestHandleUpdate_InlineQueryCombinesLibraryAndMangaDex(t *testing.T) {
+	const (
+		libraryID = "b0b721ff-c388-4486-aa0f-c2b0bb321512"
+		searchID  = "0aea9f43-d4a9-4bf7-bebc-550a512f9b95"
+	)
+	var (
+		mu       sync.Mutex
+		searched []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("includes[]") != "cover_art" {
+			t.Errorf("request %s does not include cover art", r.URL)
+		}
+		list := mangadex.MangaListResponse{}
+		if ids := q["ids[]"]; len(ids) > 0 {
+			list.Data = append(list.Data, inlineMangaData(libraryID, "Sousou no Frieren", "ongoing", "", "lib.jpg"))
+		} else {
+			mu.Lock()
+			searched = append(searched, q.Get("title"))
+			mu.Unlock()
+			list.Data = append(list.Data,
+				inlineMangaData(libraryID, "Sousou no Frieren", "ongoing", "", "lib.jpg"),
+				inlineMangaData(searchID, "Frieren Anthology", "completed", "12", "anthology.png"),
+			)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(srv.Close)
+
+	b, database, api := setupBotForMessageTests(t)
+	b.mdClient = mangadex.NewClient()
+	b.mdClient.BaseURL = srv.URL
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga(libraryID, "Frieren: Beyond Journey's End", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	now := time.Now()
+	for _, number := range []string{"119", "120", "Extra"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%q): %v", number, err)
+		}
+	}
+
+	b.handleUpdate(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q1", From: &tgbotapi.User{ID: userID}, Query: "frieren"}})
+
+	answer := waitForInlineAnswers(t, api, 1)[0]
+	mu.Lock()
+	if len(searched) != 1 || searched[0] != "frieren" {
+		t.Fatalf("MangaDex searches=%q, want frieren", searched)
+	}
+	mu.Unlock()
+	if !answer.IsPersonal || len(answer.Results) != 2 {
+		t.Fatalf("answer personal=%v results=%d, want personal with 2 results", answer.IsPersonal, len(answer.Results))
+	}
+
+	lib := answer.Results[0].(tgbotapi.InlineQueryResultArticle)
+	card := lib.InputMessageContent.(tgbotapi.InputTextMessageContent).Text
+	for _, want := range []string{
+		"https://uploads.mangadex.org/covers/" + libraryID + "/lib.jpg.256.jpg",
+		"Frieren: Beyond Journey&#39;s End",
+		"Status: Ongoing",
+		"Latest chapter: <b>120</b>",
+		"https://mangadex.org/title/" + libraryID,
+	} {
+		if !strings.Contains(card, want) {
+			t.Fatalf("library card=%q, want %q", card, want)
+		}
+	}
+	if lib.ID != "lib:1" || lib.ReplyMarkup == nil {
+		t.Fatalf("library result id=%q markup=%v, want lib:1 with a link button", lib.ID, lib.ReplyMarkup)
+	}
+
+	md := answer.Results[1].(tgbotapi.InlineQueryResultArticle)
+	card = md.InputMessageContent.(tgbotapi.InputTextMessageContent).Text
+	if md.ID != "md:"+searchID || !strings.Contains(card, "Status: Completed") || !strings.Contains(card, "Latest chapter: <b>12</b>") {
+		t.Fatalf("MangaDex result id=%q card=%q", md.ID, card)
+	}
+	if md.ThumbURL != "https://uploads.mangadex.org/covers/"+searchID+"/anthology.png.256.jpg" {
+		t.Fatalf("thumb=%q", md.ThumbURL)
+	}
+
+	// The same query again is answered from the search cache, and a short one only
+	// searches the library.
+	b.handleUpdate(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q2", From: &tgbotapi.User{ID: userID}, Query: "Frieren"}})
+	waitForInlineAnswers(t, api, 2)
+	b.handleUpdate(tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{ID: "q3", From: &tgbotapi.User{ID: userID}, Query: "fr"}})
+	answers := waitForInlineAnswers(t, api, 3)
+	if got := answers[1].Results; len(got) != 2 {
+		t.Fatalf("cached answer results=%d, want 2", len(got))
+	}
+	if got := answers[2].Results; len(got) != 1 {
+		t.Fatalf("short query results=%d, want only the library title", len(got))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(searched) != 1 {
+		t.Fatalf("MangaDex searches=%q, want only the first query sent", searched)
+	}
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/source"
+)
+
+const (
+	libraryExportFileName = "releasenojutsu-library.json"
+	libraryImportMaxBytes = 1 << 20
+)
+
+// FileAPI is implemented by *tgbotapi.BotAPI. It turns an uploaded document into a URL
+// the file can be downloaded from.
+type FileAPI interface {
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// sendLibraryExport sends the user's library, with categories and category mutes, as a
+// JSON document that /import reads back.
+func (b *Bot) sendLibraryExport(chatID int64, userID int64) {
+	b.logAction(userID, "Export library", "")
+
+	lib, err := b.db.ExportLibrary(userID)
+	if err != nil {
+		userLog(userID).Error("Error exporting library", "error", err)
+		b.sendMessageWithMainMenuButton(tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotExportLibrary))
+		return
+	}
+	data, err := json.MarshalIndent(lib, "", "  ")
+	if err != nil {
+		userLog(userID).Error("Error encoding library export", "error", err)
+		b.sendMessageWithMainMenuButton(tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotExportLibrary))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: libraryExportFileName, Bytes: data})
+	doc.Caption = fmt.Sprintf(appcopy.Copy.Info.LibraryExported, len(lib.Titles))
+	if _, err := b.api.Send(doc); err != nil {
+		userLog(userID).Warn("Failed sending library export", "error", err)
+	}
+}
+
+func (b *Bot) sendLibraryImportPrompt(chatID int64) {
+	b.sendMessageWithMainMenuButton(tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.LibraryImportHowTo))
+}
+
+// handleLibraryImport reads a library file sent as a document and merges it into the
+// user's library.
+func (b *Bot) handleLibraryImport(message *tgbotapi.Message) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	b.logAction(userID, "Import library", message.Document.FileName)
+
+	lib, err := b.downloadLibrary(message.Document)
+	if err != nil {
+		userLog(userID).Warn("Failed reading library import", "file", message.Document.FileName, "error", err)
+		b.sendMessageWithMainMenuButton(tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.LibraryImportInvalid))
+		return
+	}
+	b.importLibrary(chatID, userID, lib)
+}
+
+func (b *Bot) downloadLibrary(doc *tgbotapi.Document) (db.LibraryExport, error) {
+	if doc.FileSize > libraryImportMaxBytes {
+		return db.LibraryExport{}, fmt.Errorf("file too large: %d bytes", doc.FileSize)
+	}
+	api, ok := b.api.(FileAPI)
+	if !ok {
+		return db.LibraryExport{}, fmt.Errorf("telegram client does not support file downloads")
+	}
+	url, err := api.GetFileDirectURL(doc.FileID)
+	if err != nil {
+		return db.LibraryExport{}, fmt.Errorf("get file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return db.LibraryExport{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return db.LibraryExport{}, fmt.Errorf("download file: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return db.LibraryExport{}, fmt.Errorf("download file: status %d", resp.StatusCode)
+	}
+
+	var lib db.LibraryExport
+	if err := json.NewDecoder(io.LimitReader(resp.Body, libraryImportMaxBytes)).Decode(&lib); err != nil {
+		return db.LibraryExport{}, fmt.Errorf("decode library: %w", err)
+	}
+	if lib.Version != db.LibraryFormatVersion {
+		return db.LibraryExport{}, fmt.Errorf("unsupported library version %d", lib.Version)
+	}
+	return lib, nil
+}
+
+// importLibrary adds the titles the user doesn't track yet, files every title under its
+// exported category and mutes the exported categories. IDs go through the same checks as
+// /add, so a file can't point the bot at anything /add would refuse.
+func (b *Bot) importLibrary(chatID int64, userID int64, lib db.LibraryExport) {
+	var added []int
+	refiled, skipped := 0, 0
+	for _, t := range lib.Titles {
+		src, ok := b.sources.Get(t.Source)
+		if !ok {
+			skipped++
+			continue
+		}
+		id, ok := src.ResolveURL(t.ID)
+		if !ok {
+			skipped++
+			continue
+		}
+		if src.Name() == source.MangaDex {
+			id = b.resolveMangaDexID(userID, id)
+		}
+		mangaID, isNew, err := b.db.ImportLibraryTitle(userID, db.LibraryTitle{
+			Source:   src.Name(),
+			ID:       id,
+			Title:    displayTitle(t.Title),
+			Category: t.Category,
+		})
+		if err != nil {
+			userLog(userID).Warn("Failed importing title", "source", src.Name(), logger.KeyMangaDexID, id, "error", err)
+			skipped++
+			continue
+		}
+		if isNew {
+			b.recordMangaAdded(userID, int(mangaID), db.HistorySourceImport)
+			added = append(added, int(mangaID))
+		} else {
+			refiled++
+		}
+	}
+	for _, category := range lib.MutedCategories {
+		if err := b.db.SetCategoryMuted(userID, category, true); err != nil {
+			userLog(userID).Warn("Failed importing category mute", "category", category, "error", err)
+		}
+	}
+	b.logAction(userID, "Library imported", fmt.Sprintf("added=%d refiled=%d skipped=%d", len(added), refiled, skipped))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.LibraryImported, len(added), refiled, skipped))
+	msg.ParseMode = "HTML"
+	b.sendMessageWithMainMenuButton(msg)
+	b.syncNewTitles(userID, added)
+}
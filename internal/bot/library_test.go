@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+// fileTelegramAPI serves every uploaded document from url.
+type fileTelegramAPI struct {
+	*fakeTelegramAPI
+	url string
+}
+
+func (f *fileTelegramAPI) GetFileDirectURL(string) (string, error) {
+	return f.url, nil
+}
+
+func (f *fakeTelegramAPI) lastDocument(t *testing.T) tgbotapi.DocumentConfig {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.sent) - 1; i >= 0; i-- {
+		if doc, ok := f.sent[i].(tgbotapi.DocumentConfig); ok {
+			return doc
+		}
+	}
+	t.Fatal("no sent documents")
+	return tgbotapi.DocumentConfig{}
+}
+
+func documentMessage(userID int64) *tgbotapi.Message {
+	return &tgbotapi.Message{
+		From:     &tgbotapi.User{ID: userID},
+		Chat:     &tgbotapi.Chat{ID: userID, Type: "private"},
+		Document: &tgbotapi.Document{FileID: "file-1", FileName: libraryExportFileName, FileSize: 100},
+	}
+}
+
+func TestLibraryExportImport_RoundTripsCategories(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	const mdID = "40bc649f-7b49-4645-859e-6cd94136e722"
+	for _, userID := range []int64{42, 43} {
+		if err := database.EnsureUser(userID, false); err != nil {
+			t.Fatalf("EnsureUser(): %v", err)
+		}
+	}
+	mangaID, err := database.AddManga(mdID, "Dragon Ball Super", 42)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if err := database.SetMangaCategory(int(mangaID), 42, db.CategoryOnHold); err != nil {
+		t.Fatalf("SetMangaCategory(): %v", err)
+	}
+	if _, err := database.AddMangaFromSource("rss", "https://example.com/feed.xml", "Feed Only", false, 42); err != nil {
+		t.Fatalf("AddMangaFromSource(): %v", err)
+	}
+	if err := database.SetCategoryMuted(42, db.CategoryOnHold, true); err != nil {
+		t.Fatalf("SetCategoryMuted(): %v", err)
+	}
+
+	b.sendLibraryExport(42, 42)
+	doc := api.lastDocument(t)
+	file, ok := doc.File.(tgbotapi.FileBytes)
+	if !ok || file.Name != libraryExportFileName {
+		t.Fatalf("export file=%+v", doc.File)
+	}
+	if want := fmt.Sprintf(appcopy.Copy.Info.LibraryExported, 2); doc.Caption != want {
+		t.Fatalf("caption=%q, want %q", doc.Caption, want)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(file.Bytes)
+	}))
+	t.Cleanup(srv.Close)
+	b.api = &fileTelegramAPI{fakeTelegramAPI: api, url: srv.URL}
+
+	// Only MangaDex is configured here, so the feed title is skipped.
+	b.handleMessage(documentMessage(43))
+	if got, want := api.lastMessageText(t), fmt.Sprintf(appcopy.Copy.Info.LibraryImported, 1, 0, 1); got != want {
+		t.Fatalf("message=%q, want %q", got, want)
+	}
+	manga, err := database.ListMangaByUser(43, db.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListMangaByUser(): %v", err)
+	}
+	if len(manga) != 1 || manga[0].MangaDexID != mdID || manga[0].Category != db.CategoryOnHold {
+		t.Fatalf("imported manga=%+v, want Dragon Ball Super on hold", manga)
+	}
+	if muted, err := database.MutedCategories(43); err != nil || !muted[db.CategoryOnHold] {
+		t.Fatalf("muted=%v err=%v, want on hold muted", muted, err)
+	}
+	if history, err := database.ListReadingHistory(manga[0].ID, 1); err != nil || len(history) != 1 || history[0].Source != db.HistorySourceImport {
+		t.Fatalf("history=%+v err=%v, want the title recorded as imported", history, err)
+	}
+
+	// Importing over a library refiles titles it already has instead of adding them twice.
+	if err := database.SetMangaCategory(int(mangaID), 42, db.CategoryDropped); err != nil {
+		t.Fatalf("SetMangaCategory(): %v", err)
+	}
+	b.handleMessage(documentMessage(42))
+	if got, want := api.lastMessageText(t), fmt.Sprintf(appcopy.Copy.Info.LibraryImported, 0, 1, 1); got != want {
+		t.Fatalf("message=%q, want %q", got, want)
+	}
+	if category, err := database.GetMangaCategory(int(mangaID)); err != nil || category != db.CategoryOnHold {
+		t.Fatalf("category=%q err=%v, want on hold again", category, err)
+	}
+}
+
+func TestLibraryImport_RejectsUnreadableFiles(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	if err := database.EnsureUser(42, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	for _, body := range []string{`not json`, `{"version":99,"titles":[]}`} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		b.api = &fileTelegramAPI{fakeTelegramAPI: api, url: srv.URL}
+		b.handleMessage(documentMessage(42))
+		srv.Close()
+		if got := api.lastMessageText(t); got != appcopy.Copy.Errors.LibraryImportInvalid {
+			t.Fatalf("body %q: message=%q, want %q", body, got, appcopy.Copy.Errors.LibraryImportInvalid)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package bot
 
 import (
-	"database/sql"
 	"fmt"
 	"html"
 	"strings"
@@ -10,63 +9,115 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
-	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/db"
 )
 
+// listPageSize is how many titles one page of the list shows.
+const listPageSize = 10
+
 func (b *Bot) handleListManga(chatID int64, userID int64, target ...*callbackEditTarget) {
+	b.sendMangaList(chatID, userID, listView{}, target...)
+}
+
+// sendMangaList shows one page of the user's titles in their saved sort order, narrowed
+// to the view's category and filter, with category tabs and filter toggles on top. The
+// page shown is remembered so "Back to My Mangas" returns to it.
+func (b *Bot) sendMangaList(chatID int64, userID int64, view listView, target ...*callbackEditTarget) {
 	cbTarget := firstCallbackTarget(target...)
-	b.logAction(chatID, "List manga", "")
+	b.logAction(chatID, "List manga", fmt.Sprintf("Category: %q, Filter: %q, Page: %d", view.Category, view.Filter, view.Page))
 
-	rows, err := b.db.GetAllMangaByUser(userID)
+	counts, err := b.db.CountMangaByCategory(userID)
+	if err != nil {
+		userLog(userID).Error("Error counting manga by category", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	sort, err := b.db.GetUserListSort(userID)
+	if err != nil {
+		userLog(userID).Warn("Failed loading list sort", "error", err)
+		sort = db.SortTitle
+	}
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{Category: view.Category, Filter: view.Filter, Sort: sort})
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error querying manga: %v", err)
+		userLog(userID).Error("Error querying manga", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
 		b.sendMessageWithMainMenuButton(msg, cbTarget)
 		return
 	}
-	defer func() { _ = rows.Close() }()
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
 
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 	var messageBuilder strings.Builder
 	messageBuilder.WriteString(appcopy.Copy.Info.ListHeader)
-	count := 0
-	for rows.Next() {
-		var id int
-		var rowUserID int64
-		var mangadexID, title string
-		var isMangaPlus int
-		var lastChecked string
-		var lastSeenAt sql.NullTime
-		var lastReadNumber sql.NullFloat64
-		var unreadCount int
-		err := rows.Scan(&id, &rowUserID, &mangadexID, &title, &isMangaPlus, &lastChecked, &lastSeenAt, &lastReadNumber, &unreadCount)
-		if err != nil {
-			logger.LogMsg(logger.LogError, "Error scanning manga row: %v", err)
-			continue
-		}
-		count++
-
-		displayTitle := title
-		if isMangaPlus != 0 {
-			displayTitle = appcopy.Copy.Labels.MangaPlusPrefix + displayTitle
-		}
-
-		label := fmt.Sprintf(appcopy.Copy.Labels.ListItemFormat, count, displayTitle)
-		if unreadCount > 0 {
-			label = label + fmt.Sprintf(appcopy.Copy.Labels.ListUnreadSuffix, unreadCount)
-		}
+	if total == 0 {
+		messageBuilder.WriteString(appcopy.Copy.Info.ListEmpty)
 		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(label, cbMangaAction(id, "menu")),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.AddManga, cbAddManga()),
 		))
+		msg := tgbotapi.NewMessage(chatID, messageBuilder.String())
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
 	}
 
-	if count == 0 {
-		messageBuilder.WriteString(appcopy.Copy.Info.ListEmpty)
+	keyboard = appendButtonsInRows(keyboard, categoryTabs(view, counts, total), 2)
+	keyboard = append(keyboard, listFilterRow(view))
+	messageBuilder.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListFilterLine, listViewLabel(view)))
+	messageBuilder.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListSortLine, listSortLabel(sort)))
+
+	maxPage := 0
+	if len(manga) > 0 {
+		maxPage = (len(manga) - 1) / listPageSize
+	}
+	page := min(max(view.Page, 0), maxPage)
+	start := page * listPageSize
+	end := min(start+listPageSize, len(manga))
+	if err := b.db.SetUserListPosition(userID, db.ListPosition{Category: view.Category, Filter: view.Filter, Page: page}); err != nil {
+		userLog(userID).Warn("Failed saving list position", "error", err)
+	}
+
+	tools := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.ListSort, listSortLabel(sort)), cbCycleListSort(view.Category, view.Filter)),
+	}
+	if sort == db.SortTitle && maxPage > 0 {
+		// Letters only map to a page range when the list is alphabetical.
+		tools = append(tools, tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ListIndex, cbListIndex(view.Category, view.Filter)))
+	}
+	tools = append(tools, tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.SearchLibrary, cbListSearch()))
+	keyboard = append(keyboard, tools)
+
+	for i, m := range manga[start:end] {
 		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.AddManga, cbAddManga()),
+			tgbotapi.NewInlineKeyboardButtonData(listItemLabel(start+i+1, m), cbMangaAction(m.ID, "menu")),
 		))
-	} else {
-		messageBuilder.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListTotal, count))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Prev, cbListCategory(view.Category, view.Filter, page-1)))
+	}
+	if page < maxPage {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Next, cbListCategory(view.Category, view.Filter, page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	if len(manga) == 0 {
+		if view.Filter != "" {
+			messageBuilder.WriteString(appcopy.Copy.Info.ListFilterEmpty)
+		} else {
+			messageBuilder.WriteString(appcopy.Copy.Info.ListCategoryEmpty)
+		}
+	}
+	messageBuilder.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListTotal, len(manga)))
+	if maxPage > 0 {
+		messageBuilder.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListPage, page+1, maxPage+1))
 	}
 
 	msg := tgbotapi.NewMessage(chatID, messageBuilder.String())
@@ -75,11 +126,24 @@ func (b *Bot) handleListManga(chatID int64, userID int64, target ...*callbackEdi
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
 }
 
+// listItemLabel renders one numbered list entry with its MANGA Plus and unread markers.
+func listItemLabel(n int, m db.Manga) string {
+	displayTitle := m.Title
+	if m.IsMangaPlus {
+		displayTitle = appcopy.Copy.Labels.MangaPlusPrefix + displayTitle
+	}
+	label := fmt.Sprintf(appcopy.Copy.Labels.ListItemFormat, n, displayTitle)
+	if m.UnreadCount > 0 {
+		label += fmt.Sprintf(appcopy.Copy.Labels.ListUnreadSuffix, m.UnreadCount)
+	}
+	return label
+}
+
 func (b *Bot) handleMangaSelection(chatID int64, userID int64, mangaID int, nextAction string, target ...*callbackEditTarget) {
 	cbTarget := firstCallbackTarget(target...)
 	allowed, err := b.db.MangaBelongsToUser(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error checking manga ownership: %v", err)
+		userLog(userID, mangaID).Error("Error checking manga ownership", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotAccessManga)
 		b.sendListScopedMessage(msg, cbTarget)
 		return
@@ -108,12 +172,36 @@ func (b *Bot) handleMangaSelection(chatID int64, userID int64, mangaID int, next
 		b.handleMangaDetails(chatID, userID, mangaID, cbTarget)
 	case "toggle_plus":
 		b.toggleMangaPlus(chatID, userID, mangaID, cbTarget)
+	case "warn":
+		b.sendUnreadWarningMenu(chatID, userID, mangaID, cbTarget)
+	case "category":
+		b.sendCategoryMenu(chatID, userID, mangaID, cbTarget)
+	case "alerts":
+		b.sendAlertSettings(chatID, userID, mangaID, cbTarget)
+	case "track_exact":
+		b.handleSetExactTracking(chatID, userID, mangaID, true, cbTarget)
+	case "track_watermark":
+		b.handleSetExactTracking(chatID, userID, mangaID, false, cbTarget)
+	case "extras":
+		b.sendExtras(chatID, userID, mangaID, cbTarget)
+	case "history":
+		b.sendReadingHistory(chatID, userID, mangaID, cbTarget)
+	case "aliases":
+		b.sendTitleAliases(chatID, userID, mangaID, cbTarget)
+	case "alias_add":
+		b.sendTitleAliasPrompt(chatID, userID, mangaID, cbTarget)
+	case "recommend":
+		b.sendRecommendMenu(chatID, userID, mangaID, cbTarget)
 	case "remove_manga":
 		b.sendRemoveMangaConfirm(chatID, userID, mangaID, cbTarget)
 	case "remove_manga_yes":
 		b.handleRemoveManga(chatID, userID, mangaID, cbTarget)
+	case "replace_id":
+		b.sendReplaceMangaPrompt(chatID, userID, mangaID, cbTarget)
+	case "migrate":
+		b.handleMigrateManga(chatID, userID, mangaID, cbTarget)
 	default:
-		logger.LogMsg(logger.LogError, "Unknown next action: %s", nextAction)
+		userLog(userID, mangaID).Error("Unknown next action", "action", nextAction)
 	}
 }
 
@@ -121,12 +209,18 @@ func (b *Bot) sendMangaActionMenu(chatID int64, userID int64, mangaID int, targe
 	cbTarget := firstCallbackTarget(target...)
 	title, err := b.db.GetMangaTitle(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga title: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
 	}
 	unread, _ := b.db.CountUnreadChapters(mangaID)
+	category, _ := b.db.GetMangaCategory(mangaID)
+	exact, _ := b.db.IsExactTracking(mangaID)
+	trackingAction := "track_exact"
+	if exact {
+		trackingAction = "track_watermark"
+	}
 
 	detailsLine := b.lastReadLineHTML(mangaID)
 
@@ -148,16 +242,30 @@ func (b *Bot) sendMangaActionMenu(chatID int64, userID int64, mangaID int, targe
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MarkUnreadShort, cbMangaAction(mangaID, "mark_unread")),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.UnreadWarning, cbMangaAction(mangaID, "warn")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Details, cbMangaAction(mangaID, "details")),
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ToggleMangaPlus, cbMangaAction(mangaID, "toggle_plus")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.Category, categoryLabel(category)), cbMangaAction(mangaID, "category")),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Alerts, cbMangaAction(mangaID, "alerts")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.Tracking, trackingLabel(exact)), cbMangaAction(mangaID, trackingAction)),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Extras, cbMangaAction(mangaID, "extras")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.History, cbMangaAction(mangaID, "history")),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Aliases, cbMangaAction(mangaID, "aliases")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Recommend, cbMangaAction(mangaID, "recommend")),
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RemoveManga, cbMangaAction(mangaID, "remove_manga")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbListManga()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbBackToList()),
 		),
 	)
 
@@ -171,7 +279,7 @@ func (b *Bot) sendRemoveMangaConfirm(chatID int64, userID int64, mangaID int, ta
 	cbTarget := firstCallbackTarget(target...)
 	title, err := b.db.GetMangaTitle(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga title for removal: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga title for removal", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRetrieveManga)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -192,7 +300,7 @@ func (b *Bot) sendMarkAllReadConfirm(chatID int64, userID int64, mangaID int, ta
 	cbTarget := firstCallbackTarget(target...)
 	title, err := b.db.GetMangaTitle(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga title: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -210,11 +318,17 @@ func (b *Bot) sendMarkAllReadConfirm(chatID int64, userID int64, mangaID int, ta
 }
 
 func (b *Bot) handleMarkAllRead(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
-	cbTarget := firstCallbackTarget(target...)
-	b.logAction(chatID, "Mark all chapters as read", fmt.Sprintf("Manga ID: %d", mangaID))
+	b.markAllRead(chatID, userID, mangaID, db.HistorySourceButton, firstCallbackTarget(target...))
+}
 
-	if err := b.db.MarkAllChaptersAsRead(mangaID); err != nil {
-		logger.LogMsg(logger.LogError, "Error marking all chapters as read: %v", err)
+// markAllRead catches the title up, recording source in the reading history, and
+// confirms it with an Undo button.
+func (b *Bot) markAllRead(chatID int64, userID int64, mangaID int, source string, cbTarget *callbackEditTarget) {
+	b.logAction(chatID, "Mark all chapters as read", fmt.Sprintf("Manga ID: %d, Source: %s", mangaID, source))
+
+	historyID, err := b.db.MarkAllChaptersAsReadFrom(mangaID, source)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error marking all chapters as read", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateProgress)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -226,7 +340,7 @@ func (b *Bot) handleMarkAllRead(chatID int64, userID int64, mangaID int, target
 
 	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.MarkAllReadDone, html.EscapeString(title), lastReadLine, unread))
 	msg.ParseMode = "HTML"
-	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+	b.sendMangaScopedMessage(withUndoButton(msg, mangaID, historyID), mangaID, cbTarget)
 }
 
 func (b *Bot) handleMangaDetails(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
@@ -235,7 +349,7 @@ func (b *Bot) handleMangaDetails(chatID int64, userID int64, mangaID int, target
 
 	d, err := b.db.GetMangaDetails(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga details: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga details", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadMangaDetails)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -244,9 +358,21 @@ func (b *Bot) handleMangaDetails(chatID int64, userID int64, mangaID int, target
 	var bld strings.Builder
 	bld.WriteString(appcopy.Copy.Info.MangaDetails)
 	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsTitleLine, html.EscapeString(d.Title)))
-	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsMangaDexLine, html.EscapeString(d.MangaDexID)))
+	if d.SourceID == "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsMangaDexLine, html.EscapeString(d.MangaDexID)))
+		if link := b.addTitleLink(d.MangaDexID); link != "" {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsShareLine, html.EscapeString(link)))
+		}
+	} else {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsSourceLine, html.EscapeString(strings.ToUpper(d.Source)), html.EscapeString(d.SourceID)))
+	}
 	if d.IsMangaPlus {
 		bld.WriteString(appcopy.Copy.Info.MangaPlusYes)
+		if w, err := b.db.GetMangaPlusWindow(mangaID); err != nil {
+			userLog(userID, mangaID).Warn("Failed loading MANGA Plus window", "error", err)
+		} else if len(w.Free) > 0 {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.MangaPlusWindowLine, html.EscapeString(strings.Join(w.Free, ", "))))
+		}
 	} else {
 		bld.WriteString(appcopy.Copy.Info.MangaPlusNo)
 	}
@@ -254,34 +380,68 @@ func (b *Bot) handleMangaDetails(chatID int64, userID int64, mangaID int, target
 	if d.HasMinNumber && d.HasMaxNumber {
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsRangeLine, d.MinNumber, d.MaxNumber))
 	}
-	if d.HasLastReadNumber {
+	exact, _ := b.db.IsExactTracking(mangaID)
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsTrackingLine, trackingLabel(exact)))
+	switch {
+	case exact:
+		// The watermark isn't used with exact tracking, so count the read chapters instead.
+		read, _ := b.db.CountReadChapters(mangaID)
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsReadCountLine, read))
+	case d.HasLastReadNumber:
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsLastReadLine, d.LastReadNumber))
-	} else {
+	default:
 		bld.WriteString(appcopy.Copy.Info.DetailsLastReadNoneLine)
 	}
 	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsUnreadLine, d.UnreadCount))
+	if category, err := b.db.GetMangaCategory(mangaID); err == nil {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsCategoryLine, categoryLabel(category)))
+	}
+	if alerts, err := b.db.GetMangaNotifySettings(mangaID); err == nil {
+		status, cadence := alertStatusLabels(alerts, time.Now())
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsAlertsLine, status, cadence))
+	}
+	if warnAt, overridden, err := b.db.GetMangaUnreadWarning(mangaID); err == nil {
+		label := unreadWarningLabel(warnAt)
+		if !overridden {
+			label = fmt.Sprintf(appcopy.Copy.Labels.UnreadWarningDefault, label)
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsUnreadWarningLine, label))
+	}
 	if d.HasLastSeenAt {
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsLastSeenLine, html.EscapeString(d.LastSeenAt.Local().Format(time.RFC1123))))
 	}
 	if d.HasLastChecked {
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.DetailsLastCheckedLine, html.EscapeString(d.LastChecked.Local().Format(time.RFC1123))))
 	}
+	bld.WriteString(mangaHealthHTML(d))
 	bld.WriteString(appcopy.Copy.Info.DetailsNote)
 
 	msg := tgbotapi.NewMessage(chatID, bld.String())
 	msg.ParseMode = "HTML"
 	toggleLabel := appcopy.Copy.Buttons.ToggleMangaPlus
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+	rows := [][]tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(toggleLabel, cbMangaAction(mangaID, "toggle_plus")),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MarkAllRead, cbMangaAction(mangaID, "mark_all_read")),
 		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToManga, cbMangaAction(mangaID, "menu")),
-		),
-	)
+	}
+	if d.MergeCandidate != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MigrateManga, cbMangaAction(mangaID, "migrate")),
+		))
+	}
+	if d.ConsecutiveFailures > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RetryNow, cbMangaAction(mangaID, "check_new")),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ReplaceMangaDexID, cbMangaAction(mangaID, "replace_id")),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToManga, cbMangaAction(mangaID, "menu")),
+	))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
 }
 
@@ -289,14 +449,14 @@ func (b *Bot) toggleMangaPlus(chatID int64, userID int64, mangaID int, target ..
 	cbTarget := firstCallbackTarget(target...)
 	cur, err := b.db.IsMangaPlus(mangaID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga plus flag: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga plus flag", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateMangaPlus)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
 	}
 	next := !cur
 	if err := b.db.SetMangaPlus(mangaID, next); err != nil {
-		logger.LogMsg(logger.LogError, "Error setting manga plus flag: %v", err)
+		userLog(userID, mangaID).Error("Error setting manga plus flag", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateMangaPlus)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -318,7 +478,7 @@ func (b *Bot) handleRemoveManga(chatID int64, userID int64, mangaID int, target
 
 	mangaTitle, err := b.db.GetMangaTitle(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error getting manga title for removal: %v", err)
+		userLog(userID, mangaID).Error("Error getting manga title for removal", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRetrieveManga)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -326,7 +486,7 @@ func (b *Bot) handleRemoveManga(chatID int64, userID int64, mangaID int, target
 
 	err = b.db.DeleteManga(mangaID, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error deleting manga: %v", err)
+		userLog(userID, mangaID).Error("Error deleting manga", "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRemoveManga)
 		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
 		return
@@ -336,7 +496,7 @@ func (b *Bot) handleRemoveManga(chatID int64, userID int64, mangaID int, target
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbListManga()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbBackToList()),
 		),
 	)
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
@@ -11,108 +11,118 @@ import (
 
 	"releasenojutsu/internal/appcopy"
 	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/source"
 )
 
 func (b *Bot) handleAddManga(chatID int64, userID int64, mangaID string) {
 	b.logAction(chatID, "Add manga", mangaID)
+	mangaID = b.resolveMangaDexID(userID, mangaID)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
 	mangaData, err := b.mdClient.GetManga(ctx, mangaID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error fetching manga data: %v", err)
+		userLog(userID).Error("Error fetching manga data", logger.KeyMangaDexID, mangaID, "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveManga)
 		b.sendMessageWithMainMenuButton(msg)
 		return
 	}
 
-	var title string
-	if enTitle, ok := mangaData.Data.Attributes.Title["en"]; ok && enTitle != "" {
-		title = enTitle
-	} else {
-		for _, val := range mangaData.Data.Attributes.Title {
-			if val != "" {
-				title = val
-				break
-			}
-		}
-		if title == "" {
-			title = appcopy.Copy.Prompts.TitleNotAvailable
-		}
-	}
-
-	title = strings.TrimSpace(title)
-	if title == "" {
-		title = appcopy.Copy.Prompts.TitleNotAvailable
-	}
+	title := mangaTitle(mangaData)
 
+	// MANGA Plus is detected from the chapters' external links during the import.
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.YesMangaPlus, cbAddConfirm(mangaID, true)),
-			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.NoMangaPlus, cbAddConfirm(mangaID, false)),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ConfirmAdd, cbAddConfirm(mangaID)),
 		),
 	)
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.MangaPlusQuestion, html.EscapeString(title)))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ConfirmAddManga, html.EscapeString(title)))
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
 	b.sendMessageWithMainMenuButton(msg)
 }
 
-func (b *Bot) confirmAddManga(chatID int64, userID int64, mangaDexID string, isMangaPlus bool, target ...*callbackEditTarget) {
+func (b *Bot) confirmAddManga(chatID int64, userID int64, mangaDexID string, target ...*callbackEditTarget) {
 	cbTarget := firstCallbackTarget(target...)
-	b.logAction(chatID, "Confirm add manga", fmt.Sprintf("%s (MANGA Plus=%t)", mangaDexID, isMangaPlus))
+	b.logAction(chatID, "Confirm add manga", mangaDexID)
+	mangaDexID = b.resolveMangaDexID(userID, mangaDexID)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
 	mangaData, err := b.mdClient.GetManga(ctx, mangaDexID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error fetching manga data: %v", err)
+		userLog(userID).Error("Error fetching manga data", logger.KeyMangaDexID, mangaDexID, "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveManga)
 		b.sendMessageWithMainMenuButton(msg, cbTarget)
 		return
 	}
 
-	var title string
-	if enTitle, ok := mangaData.Data.Attributes.Title["en"]; ok && enTitle != "" {
-		title = enTitle
-	} else {
-		for _, val := range mangaData.Data.Attributes.Title {
-			if val != "" {
-				title = val
-				break
-			}
-		}
-		if title == "" {
-			title = appcopy.Copy.Prompts.TitleNotAvailable
-		}
+	title := mangaTitle(mangaData)
+
+	startText := fmt.Sprintf(appcopy.Copy.Info.SyncStartAdded, html.EscapeString(title))
+	b.addAndSync(chatID, userID, source.MangaDex, mangaDexID, title, startText, cbTarget)
+}
+
+// handleAddFromSource adds a title from a source other than MangaDex. Those IDs (feed URLs)
+// don't fit in callback data, so there is no confirmation step.
+func (b *Bot) handleAddFromSource(chatID int64, userID int64, sourceName string, id string) {
+	b.logAction(chatID, "Add manga from source", fmt.Sprintf("%s %s", sourceName, id))
+
+	src, ok := b.sources.Get(sourceName)
+	if !ok {
+		userLog(userID).Error("Unknown source", "source", sourceName)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveFeed)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	meta, err := src.FetchMetadata(ctx, id)
+	if err != nil {
+		userLog(userID).Error("Error fetching title metadata", "source", sourceName, logger.KeyMangaDexID, id, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveFeed)
+		b.sendMessageWithMainMenuButton(msg)
+		return
 	}
 
-	mangaDBID, err := b.db.AddMangaWithMangaPlus(mangaDexID, title, isMangaPlus, userID)
+	title := displayTitle(meta.Title)
+	startText := fmt.Sprintf(appcopy.Copy.Info.SyncStartFromSource, html.EscapeString(title), html.EscapeString(strings.ToUpper(sourceName)))
+	b.addAndSync(chatID, userID, sourceName, id, title, startText, nil)
+}
+
+// addAndSync stores a new title and imports its full chapter list in the background.
+// MANGA Plus is detected from the imported chapters.
+func (b *Bot) addAndSync(chatID int64, userID int64, sourceName, sourceID, title string, startText string, cbTarget *callbackEditTarget) {
+	mangaDBID, err := b.db.AddMangaFromSource(sourceName, sourceID, title, false, userID)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error inserting manga into database: %v", err)
+		userLog(userID).Error("Error inserting manga into database", "source", sourceName, logger.KeyMangaDexID, sourceID, "error", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotAddManga)
 		b.sendMessageWithMainMenuButton(msg, cbTarget)
 		return
 	}
+	b.importNewManga(chatID, userID, mangaDBID, sourceID, title, false, startText, cbTarget)
+}
 
+// importNewManga imports the full chapter list of a title that was just stored, reporting
+// the result to chatID when it's done.
+func (b *Bot) importNewManga(chatID int64, userID int64, mangaDBID int64, sourceID, title string, isMangaPlus bool, startText string, cbTarget *callbackEditTarget) {
 	// Full backfill so you can start from scratch (have the complete chapter list locally).
-	mangaPlusLabel := appcopy.Copy.Info.MangaPlusNoLabel
-	if isMangaPlus {
-		mangaPlusLabel = appcopy.Copy.Info.MangaPlusYesLabel
-	}
-	startMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.SyncStartWithPlus, html.EscapeString(title), html.EscapeString(mangaPlusLabel)))
+	startMsg := tgbotapi.NewMessage(chatID, startText)
 	startMsg.ParseMode = "HTML"
 	b.sendMessageWithMainMenuButton(startMsg, cbTarget)
 
 	go func() {
 		syncCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
+		syncCtx = logger.WithContext(syncCtx, logger.KeyUserID, userID, logger.KeyMangaID, int(mangaDBID), logger.KeyMangaDexID, sourceID)
 
 		synced, _, err := b.updater.SyncAll(syncCtx, int(mangaDBID))
 		if err != nil {
-			logger.LogMsg(logger.LogError, "Error syncing chapters for %s: %v", title, err)
+			logger.FromContext(syncCtx).Error("Error syncing chapters", "title", title, "error", err)
 			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Errors.SyncFailed, html.EscapeString(title)))
 			msg.ParseMode = "HTML"
 			b.sendMessageWithMainMenuButton(msg)
@@ -120,8 +130,25 @@ func (b *Bot) confirmAddManga(chatID int64, userID int64, mangaDexID string, isM
 		}
 
 		unread, _ := b.db.CountUnreadChapters(int(mangaDBID))
-		done := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.SyncCompleteWithHint, html.EscapeString(title), synced, unread))
+		text := fmt.Sprintf(appcopy.Copy.Info.SyncCompleteWithHint, html.EscapeString(title), synced, unread)
+		if detected, _ := b.db.IsMangaPlus(int(mangaDBID)); detected && !isMangaPlus {
+			text += appcopy.Copy.Info.MangaPlusDetected
+		}
+		done := tgbotapi.NewMessage(chatID, text)
 		done.ParseMode = "HTML"
 		b.sendMessageWithMainMenuButton(done)
 	}()
 }
+
+// mangaTitle picks the English title when available, falling back to any other non-empty title.
+func mangaTitle(mangaData *mangadex.MangaResponse) string {
+	return displayTitle(mangaData.Data.PreferredTitle())
+}
+
+func displayTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return appcopy.Copy.Prompts.TitleNotAvailable
+	}
+	return title
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+)
+
+const pendingStateReplaceManga = "replace_manga"
+
+// AlertTitleFailing tells the owner that a title keeps failing and offers to retry,
+// replace its MangaDex ID or remove it. It implements cron.FailureAlerter.
+func (b *Bot) AlertTitleFailing(userID int64, mangaID int, title string, failures int, lastErr string) error {
+	msg := tgbotapi.NewMessage(userID, fmt.Sprintf(appcopy.Copy.Info.TitleFailingAlert,
+		html.EscapeString(title), failures, html.EscapeString(truncateRunError(lastErr))))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RetryNow, cbMangaAction(mangaID, "check_new")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ReplaceMangaDexID, cbMangaAction(mangaID, "replace_id")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RemoveManga, cbMangaAction(mangaID, "remove_manga")),
+		),
+	)
+	_, err := b.api.Send(msg)
+	return err
+}
+
+// mangaHealthHTML renders the failure tracking part of the details view.
+func mangaHealthHTML(d db.MangaDetails) string {
+	out := appcopy.Copy.Info.DetailsHealthOK
+	if d.ConsecutiveFailures > 0 {
+		out = fmt.Sprintf(appcopy.Copy.Info.DetailsHealthFailing, d.ConsecutiveFailures)
+	}
+	if d.ConsecutiveFailures > 0 && d.LastError != "" {
+		when := ""
+		if d.HasLastErrorAt {
+			when = d.LastErrorAt.Local().Format(time.RFC1123)
+		}
+		out += fmt.Sprintf(appcopy.Copy.Info.DetailsHealthLastError, html.EscapeString(when), html.EscapeString(truncateRunError(d.LastError)))
+	}
+	if d.ConsecutiveFailures > 0 && d.HasNextCheckAt {
+		out += fmt.Sprintf(appcopy.Copy.Info.DetailsHealthNextCheck, html.EscapeString(d.NextCheckAt.Local().Format(time.RFC1123)))
+	}
+	if d.MergeCandidate != "" {
+		out += fmt.Sprintf(appcopy.Copy.Info.DetailsMergeCandidate, html.EscapeString(d.MergeCandidate))
+	}
+	return out
+}
+
+func (b *Bot) sendReplaceMangaPrompt(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if err := b.db.SetUserPendingState(userID, pendingStateReplaceManga, strconv.Itoa(mangaID)); err != nil {
+		userLog(userID, mangaID).Warn("Failed to set pending state", "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ReplaceMangaDexID, html.EscapeString(title)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbMangaAction(mangaID, "menu")),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// consumeReplaceMangaInput handles the reply to sendReplaceMangaPrompt.
+func (b *Bot) consumeReplaceMangaInput(message *tgbotapi.Message, payload string) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	mangaID, err := strconv.Atoi(payload)
+	if err != nil {
+		userLog(userID).Warn("Invalid replace_manga payload", "payload", payload)
+		b.clearPendingState(userID)
+		return
+	}
+
+	newID, ok := b.mangaInputToID(message.Text)
+	if !ok {
+		// Keep pending state until the user sends a valid MangaDex URL/ID.
+		b.sendReplaceMangaPrompt(chatID, userID, mangaID)
+		return
+	}
+	b.clearPendingState(userID)
+	b.handleReplaceMangaDexID(chatID, userID, mangaID, newID)
+}
+
+func (b *Bot) handleReplaceMangaDexID(chatID int64, userID int64, mangaID int, newID string) {
+	log := userLog(userID, mangaID)
+	b.logAction(userID, "Replace MangaDex ID", fmt.Sprintf("Manga ID: %d -> %s", mangaID, newID))
+
+	d, err := b.db.GetMangaDetails(mangaID, userID)
+	if err != nil {
+		log.Error("Error getting manga details", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+		b.sendListScopedMessage(msg, nil)
+		return
+	}
+	if d.MangaDexID == newID {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.ReplaceSameMangaDexID)
+		b.sendMangaScopedMessage(msg, mangaID, nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if _, err := b.mdClient.GetManga(ctx, newID); err != nil {
+		log.Error("Error fetching replacement manga", logger.KeyMangaDexID, newID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CouldNotRetrieveManga)
+		b.sendMangaScopedMessage(msg, mangaID, nil)
+		return
+	}
+
+	if err := b.db.ReplaceMangaDexID(mangaID, userID, newID); err != nil {
+		log.Error("Error replacing MangaDex ID", logger.KeyMangaDexID, newID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotReplaceManga)
+		b.sendMangaScopedMessage(msg, mangaID, nil)
+		return
+	}
+	log.Info("Replaced MangaDex ID", "old_mangadex_id", d.MangaDexID, logger.KeyMangaDexID, newID)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.ReplaceMangaDexDone, html.EscapeString(d.Title), html.EscapeString(newID)))
+	msg.ParseMode = "HTML"
+	if _, err := b.api.Send(msg); err != nil {
+		log.Warn("Failed sending message", "error", err)
+	}
+	// Pull the new entry's chapter list so the next check starts from a complete picture.
+	b.handleSyncAllChapters(chatID, userID, mangaID)
+}
@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+func TestAlertTitleFailing_OffersRetryReplaceRemove(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+
+	if err := b.AlertTitleFailing(42, 7, "Broken <Title>", 3, "API returned 404"); err != nil {
+		t.Fatalf("AlertTitleFailing(): %v", err)
+	}
+	msg := api.lastMessageConfig(t)
+	if msg.ChatID != 42 || !strings.Contains(msg.Text, "Broken &lt;Title&gt;") || !strings.Contains(msg.Text, "API returned 404") {
+		t.Fatalf("alert=%+v", msg)
+	}
+	callbacks := messageCallbacks(t, msg)
+	for _, want := range []string{cbMangaAction(7, "check_new"), cbMangaAction(7, "replace_id"), cbMangaAction(7, "remove_manga")} {
+		if !hasCallback(callbacks, want) {
+			t.Fatalf("missing %q in %v", want, callbacks)
+		}
+	}
+}
+
+func TestHandleMangaDetails_ShowsHealth(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	mangaID, err := database.AddManga("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Health Manga", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	b.handleMangaDetails(userID, userID, int(mangaID))
+	if got := api.lastMessageText(t); !strings.Contains(got, appcopy.Copy.Info.DetailsHealthOK) {
+		t.Fatalf("details=%q, want healthy", got)
+	}
+
+	if _, err := database.RecordMangaFailure(int(mangaID), "feed timeout", time.Now()); err != nil {
+		t.Fatalf("RecordMangaFailure(): %v", err)
+	}
+	if err := database.SetMangaNextCheckAt(int(mangaID), time.Now().Add(6*time.Hour)); err != nil {
+		t.Fatalf("SetMangaNextCheckAt(): %v", err)
+	}
+	b.handleMangaDetails(userID, userID, int(mangaID))
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "<b>1</b> failed check(s)") || !strings.Contains(msg.Text, "feed timeout") || !strings.Contains(msg.Text, "Next automatic check") {
+		t.Fatalf("details=%q, want failure health", msg.Text)
+	}
+	if !hasCallback(messageCallbacks(t, msg), cbMangaAction(int(mangaID), "replace_id")) {
+		t.Fatal("failing title should offer a replace button")
+	}
+}
+
+func TestReplaceMangaDexID_PendingFlow(t *testing.T) {
+	b, database, api, mdID := setupBotWithMangaDexServer(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	const oldID = "11111111-2222-3333-4444-555555555555"
+	mangaID, err := database.AddManga(oldID, "Moved Manga", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if _, err := database.RecordMangaFailure(int(mangaID), "404", time.Now()); err != nil {
+		t.Fatalf("RecordMangaFailure(): %v", err)
+	}
+
+	b.handleMangaSelection(userID, userID, int(mangaID), "replace_id")
+	state, payload, ok, err := database.GetUserPendingState(userID)
+	if err != nil || !ok || state != pendingStateReplaceManga || payload == "" {
+		t.Fatalf("pending state=%q payload=%q ok=%v err=%v", state, payload, ok, err)
+	}
+
+	msg := &tgbotapi.Message{
+		Chat: &tgbotapi.Chat{ID: userID},
+		From: &tgbotapi.User{ID: userID},
+		Text: "https://mangadex.org/title/" + mdID + "/new-slug",
+	}
+	if !b.consumePendingInput(msg) {
+		t.Fatal("consumePendingInput() = false, want replace input consumed")
+	}
+
+	d, err := database.GetMangaDetails(int(mangaID), userID)
+	if err != nil {
+		t.Fatalf("GetMangaDetails(): %v", err)
+	}
+	if d.MangaDexID != mdID || d.ConsecutiveFailures != 0 {
+		t.Fatalf("details=%+v, want new id and cleared failures", d)
+	}
+	if _, _, ok, _ := database.GetUserPendingState(userID); ok {
+		t.Fatal("pending state should be cleared")
+	}
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, "now follows MangaDex ID") })
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+const pendingStateListSearch = "list_search"
+
+// listSearchLimit is how many matches one search answer lists.
+const listSearchLimit = 20
+
+// listView is what the list is narrowed to and which page it shows; the sort order is
+// saved per user instead. It converts to and from db.ListPosition.
+type listView struct {
+	Category string
+	Filter   string
+	Page     int
+}
+
+func listSortLabel(sort string) string {
+	switch sort {
+	case db.SortUnread:
+		return appcopy.Copy.Labels.SortUnread
+	case db.SortLastRelease:
+		return appcopy.Copy.Labels.SortLastRelease
+	case db.SortRecent:
+		return appcopy.Copy.Labels.SortRecent
+	default:
+		return appcopy.Copy.Labels.SortTitle
+	}
+}
+
+func listFilterLabel(filter string) string {
+	switch filter {
+	case db.FilterUnread:
+		return appcopy.Copy.Labels.FilterUnread
+	case db.FilterMangaPlus:
+		return appcopy.Copy.Labels.FilterMangaPlus
+	case db.FilterFailing:
+		return appcopy.Copy.Labels.FilterFailing
+	default:
+		return filter
+	}
+}
+
+// listViewLabel names the view's category and filter, e.g. "Reading · Has unread".
+func listViewLabel(view listView) string {
+	if view.Filter == "" {
+		return categoryLabel(view.Category)
+	}
+	return fmt.Sprintf(appcopy.Copy.Labels.ListFilterJoin, categoryLabel(view.Category), listFilterLabel(view.Filter))
+}
+
+// nextListSort returns the sort order after current in db.ListSorts.
+func nextListSort(current string) string {
+	for i, sort := range db.ListSorts {
+		if sort == current {
+			return db.ListSorts[(i+1)%len(db.ListSorts)]
+		}
+	}
+	return db.ListSorts[0]
+}
+
+// listFilterRow renders one toggle per filter; tapping the active filter clears it.
+func listFilterRow(view listView) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(db.ListFilters))
+	for _, filter := range db.ListFilters {
+		label, next := listFilterLabel(filter), filter
+		if filter == view.Filter {
+			label, next = fmt.Sprintf(appcopy.Copy.Buttons.Selected, label), ""
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, cbListCategory(view.Category, next, 0)))
+	}
+	return row
+}
+
+func (b *Bot) handleCycleListSort(chatID int64, userID int64, view listView, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	current, err := b.db.GetUserListSort(userID)
+	if err == nil {
+		err = b.db.SetUserListSort(userID, nextListSort(current))
+	}
+	if err != nil {
+		userLog(userID).Error("Error saving list sort", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendListScopedMessage(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Cycle list sort", fmt.Sprintf("Sort: %s", nextListSort(current)))
+	b.sendMangaList(chatID, userID, view, cbTarget)
+}
+
+// handleBackToList reopens the list at the position saved by the last sendMangaList.
+func (b *Bot) handleBackToList(chatID int64, userID int64, target ...*callbackEditTarget) {
+	pos, err := b.db.GetUserListPosition(userID)
+	if err != nil {
+		userLog(userID).Warn("Failed loading list position", "error", err)
+	}
+	b.sendMangaList(chatID, userID, listView(pos), target...)
+}
+
+// indexLetter buckets a title under its upper-case first letter, or "#" for titles that
+// don't start with A-Z.
+func indexLetter(title string) string {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(title))
+	r = unicode.ToUpper(r)
+	if r < 'A' || r > 'Z' {
+		return appcopy.Copy.Labels.ListIndexOther
+	}
+	return string(r)
+}
+
+// sendListIndex lists the first letters present in the view, each jumping to the page
+// where that letter starts.
+func (b *Bot) sendListIndex(chatID int64, userID int64, view listView, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{Category: view.Category, Filter: view.Filter, Sort: db.SortTitle})
+	if err != nil {
+		userLog(userID).Error("Error querying manga", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendListScopedMessage(msg, cbTarget)
+		return
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	seen := make(map[string]bool)
+	for i, m := range manga {
+		letter := indexLetter(m.Title)
+		if seen[letter] {
+			continue
+		}
+		seen[letter] = true
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(letter, cbListCategory(view.Category, view.Filter, i/listPageSize)))
+	}
+	keyboard := appendButtonsInRows(nil, buttons, 6)
+	keyboard = appendBackToMangaListRow(keyboard)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.ListIndexText, listViewLabel(view)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) sendListSearchPrompt(chatID int64, userID int64, target ...*callbackEditTarget) {
+	if err := b.db.SetUserPendingState(userID, pendingStateListSearch, ""); err != nil {
+		userLog(userID).Warn("Failed to set pending state", "error", err)
+	}
+	msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.ListSearch)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbBackToList()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, firstCallbackTarget(target...))
+}
+
+// consumeListSearchInput answers text sent while a library search is pending. The
+// search stays active until something matches.
+func (b *Bot) consumeListSearchInput(message *tgbotapi.Message) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	query := strings.TrimSpace(message.Text)
+	if query == "" {
+		b.sendListSearchPrompt(chatID, userID)
+		return
+	}
+	b.logAction(userID, "Search library", fmt.Sprintf("Query: %q", query))
+
+	sort, err := b.db.GetUserListSort(userID)
+	if err != nil {
+		userLog(userID).Warn("Failed loading list sort", "error", err)
+		sort = db.SortTitle
+	}
+	manga, err := b.db.ListMangaByUser(userID, db.ListOptions{Query: query, Sort: sort})
+	if err != nil {
+		userLog(userID).Error("Error searching manga", "error", err)
+		b.clearPendingState(userID)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendListScopedMessage(msg)
+		return
+	}
+	if len(manga) == 0 {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ListSearchNoMatch, html.EscapeString(query)))
+		msg.ParseMode = "HTML"
+		b.sendListScopedMessage(msg)
+		return
+	}
+	b.clearPendingState(userID)
+	b.sendListSearchResults(chatID, query, manga)
+}
+
+// sendListSearchResults lists the titles found for query, each opening its action menu.
+func (b *Bot) sendListSearchResults(chatID int64, query string, manga []db.Manga) {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListSearchResults, html.EscapeString(query), len(manga)))
+	if len(manga) > listSearchLimit {
+		text.WriteString(fmt.Sprintf(appcopy.Copy.Info.ListSearchMore, listSearchLimit))
+		manga = manga[:listSearchLimit]
+	}
+
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, len(manga)+1)
+	for i, m := range manga {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(listItemLabel(i+1, m), cbMangaAction(m.ID, "menu")),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.SearchAgain, cbListSearch()),
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbBackToList()),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg)
+}
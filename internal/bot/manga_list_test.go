@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestMangaList_SortPersistsAndFiltersToggle(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	first, err := database.AddManga("md-1", "Zeta", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	second, err := database.AddManga("md-2", "Alpha", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if _, err := database.Exec("UPDATE manga SET unread_count = 3 WHERE id = ?", first); err != nil {
+		t.Fatalf("Exec(): %v", err)
+	}
+
+	b.handleListManga(userID, userID)
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "Sorted by: <b>Title</b>") {
+		t.Fatalf("list=%q, want title sort", msg.Text)
+	}
+	callbacks := messageCallbacks(t, msg)
+	if !hasCallback(callbacks, cbCycleListSort("", "")) || !hasCallback(callbacks, cbListCategory("", db.FilterUnread, 0)) || !hasCallback(callbacks, cbListSearch()) {
+		t.Fatalf("list callbacks=%v, want sort, filter and search buttons", callbacks)
+	}
+	if firstItem := callbacks[indexOfMangaButton(callbacks)]; firstItem != cbMangaAction(int(second), "menu") {
+		t.Fatalf("first title=%q, want Alpha", firstItem)
+	}
+
+	b.handleCycleListSort(userID, userID, listView{})
+	if sort, err := database.GetUserListSort(userID); err != nil || sort != db.SortUnread {
+		t.Fatalf("saved sort=%q err=%v, want unread", sort, err)
+	}
+	callbacks = messageCallbacks(t, api.lastMessageConfig(t))
+	if firstItem := callbacks[indexOfMangaButton(callbacks)]; firstItem != cbMangaAction(int(first), "menu") {
+		t.Fatalf("first title=%q, want the one with unread chapters", firstItem)
+	}
+
+	// The saved sort survives reopening the list.
+	b.handleListManga(userID, userID)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Most unread") {
+		t.Fatalf("reopened list=%q, want the saved sort", got)
+	}
+
+	b.sendMangaList(userID, userID, listView{Filter: db.FilterUnread})
+	callbacks = messageCallbacks(t, api.lastMessageConfig(t))
+	if hasCallback(callbacks, cbMangaAction(int(second), "menu")) || !hasCallback(callbacks, cbListCategory("", "", 0)) {
+		t.Fatalf("filtered callbacks=%v, want only unread titles and a way to clear the filter", callbacks)
+	}
+}
+
+func TestMangaList_SearchUsesPendingState(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id, err := database.AddManga("md-1", "One Piece", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if _, err := database.AddManga("md-2", "Dandadan", userID); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	send := func(text string) {
+		t.Helper()
+		message := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}, From: &tgbotapi.User{ID: userID}, Text: text}
+		if !b.consumePendingInput(message) {
+			t.Fatalf("consumePendingInput(%q) = false, want the search to take it", text)
+		}
+	}
+
+	b.sendListSearchPrompt(userID, userID)
+	if state, _, ok, err := database.GetUserPendingState(userID); err != nil || !ok || state != pendingStateListSearch {
+		t.Fatalf("pending state=%q ok=%v err=%v, want list search", state, ok, err)
+	}
+
+	send("naruto")
+	if got := api.lastMessageText(t); !strings.Contains(got, "No titles match") {
+		t.Fatalf("no-match reply=%q", got)
+	}
+	if _, _, ok, _ := database.GetUserPendingState(userID); !ok {
+		t.Fatal("search should stay active after no match")
+	}
+
+	send("piece")
+	callbacks := messageCallbacks(t, api.lastMessageConfig(t))
+	if !hasCallback(callbacks, cbMangaAction(int(id), "menu")) || !hasCallback(callbacks, cbListSearch()) || len(callbacks) != 4 {
+		t.Fatalf("result callbacks=%v, want One Piece, search again, back and main menu", callbacks)
+	}
+	if _, _, ok, _ := database.GetUserPendingState(userID); ok {
+		t.Fatal("search should end once something matches")
+	}
+}
+
+// indexOfMangaButton returns the index of the first title button in callbacks.
+func indexOfMangaButton(callbacks []string) int {
+	for i, data := range callbacks {
+		if strings.HasPrefix(data, "manga_action:") {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestMangaList_LetterIndexAndBackKeepsPosition(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	// 12 titles under A, then one under B and one starting with a digit.
+	var ids []int
+	titles := []string{"3-gatsu no Lion", "Berserk"}
+	for i := 1; i <= 12; i++ {
+		titles = append(titles, fmt.Sprintf("A Title %02d", i))
+	}
+	for i, title := range titles {
+		id, err := database.AddManga(fmt.Sprintf("md-%d", i), title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		ids = append(ids, int(id))
+	}
+
+	b.handleListManga(userID, userID)
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbListIndex("", "")) {
+		t.Fatal("an alphabetical list spanning pages should offer the letter index")
+	}
+
+	b.sendListIndex(userID, userID, listView{})
+	callbacks := messageCallbacks(t, api.lastMessageConfig(t))
+	// "#" and "A" start on the first page, "B" on the second.
+	if !hasCallback(callbacks, cbListCategory("", "", 0)) || !hasCallback(callbacks, cbListCategory("", "", 1)) || !hasCallback(callbacks, cbBackToList()) {
+		t.Fatalf("index callbacks=%v", callbacks)
+	}
+	if got := indexLetter("3-gatsu no Lion"); got != "#" {
+		t.Fatalf("indexLetter(digit)=%q, want #", got)
+	}
+
+	b.sendMangaList(userID, userID, listView{Page: 1})
+	b.handleMangaSelection(userID, userID, ids[1], "menu")
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbBackToList()) {
+		t.Fatal("action menu should go back to the saved list position")
+	}
+	b.handleBackToList(userID, userID)
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "Page <b>2/2</b>") || !hasCallback(messageCallbacks(t, msg), cbMangaAction(ids[1], "menu")) {
+		t.Fatalf("back to list=%q, want the second page again", msg.Text)
+	}
+
+	// The main menu entry starts over from the first page.
+	b.handleListManga(userID, userID)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Page <b>1/2</b>") {
+		t.Fatalf("list from main menu=%q, want the first page", got)
+	}
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/logger"
+)
+
+// SuggestMangaMigration asks the owner to move a title to the MangaDex entry it was merged
+// into. It implements cron.MergeSuggester.
+func (b *Bot) SuggestMangaMigration(userID int64, mangaID int, title, newMangaDexID string) error {
+	newTitle := newMangaDexID
+	if b.mdClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		if data, err := b.mdClient.GetManga(ctx, newMangaDexID); err == nil {
+			newTitle = mangaTitle(data)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(userID, fmt.Sprintf(appcopy.Copy.Info.MergeSuggestion,
+		html.EscapeString(title), html.EscapeString(newTitle), html.EscapeString(newMangaDexID)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.MigrateManga, cbMangaAction(mangaID, "migrate")),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.KeepCurrentEntry, cbMangaAction(mangaID, "menu")),
+		),
+	)
+	_, err := b.api.Send(msg)
+	return err
+}
+
+func (b *Bot) handleMigrateManga(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	log := userLog(userID, mangaID)
+
+	d, err := b.db.GetMangaDetails(mangaID, userID)
+	if err != nil {
+		log.Error("Error getting manga details", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	if d.MergeCandidate == "" {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Info.NoMergePending)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.logAction(userID, "Migrate manga", fmt.Sprintf("Manga ID: %d %s -> %s", mangaID, d.MangaDexID, d.MergeCandidate))
+
+	if err := b.db.MigrateMangaDexID(mangaID, userID, d.MergeCandidate); err != nil {
+		log.Error("Error migrating MangaDex ID", logger.KeyMangaDexID, d.MergeCandidate, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotMigrateManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	log.Info("Migrated merged MangaDex entry", "old_mangadex_id", d.MangaDexID, logger.KeyMangaDexID, d.MergeCandidate)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.MigrateMangaDone, html.EscapeString(d.Title), html.EscapeString(d.MergeCandidate)))
+	msg.ParseMode = "HTML"
+	b.sendOrEditMessage(msg, cbTarget)
+	// Pull the new entry's chapter list; the sync also moves the watermark so merged-in
+	// chapters don't show up as new releases.
+	b.handleSyncAllChapters(chatID, userID, mangaID)
+}
+
+// resolveMangaDexID maps an ID that was merged away to the entry that replaced it.
+func (b *Bot) resolveMangaDexID(userID int64, mangaDexID string) string {
+	resolved, err := b.db.ResolveMangaDexAlias(mangaDexID)
+	if err != nil {
+		userLog(userID).Warn("Failed resolving MangaDex alias", logger.KeyMangaDexID, mangaDexID, "error", err)
+		return mangaDexID
+	}
+	if resolved != mangaDexID {
+		userLog(userID).Info("Resolved merged MangaDex ID", "old_mangadex_id", mangaDexID, logger.KeyMangaDexID, resolved)
+	}
+	return resolved
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuggestMangaMigration_ShowsNewEntryAndButtons(t *testing.T) {
+	b, _, api, mdID := setupBotWithMangaDexServer(t)
+
+	if err := b.SuggestMangaMigration(42, 7, "Old <Entry>", mdID); err != nil {
+		t.Fatalf("SuggestMangaMigration(): %v", err)
+	}
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "Old &lt;Entry&gt;") || !strings.Contains(msg.Text, "Dragon Ball Super") || !strings.Contains(msg.Text, mdID) {
+		t.Fatalf("suggestion=%q", msg.Text)
+	}
+	callbacks := messageCallbacks(t, msg)
+	if !hasCallback(callbacks, cbMangaAction(7, "migrate")) || !hasCallback(callbacks, cbMangaAction(7, "menu")) {
+		t.Fatalf("callbacks=%v, want migrate and keep", callbacks)
+	}
+}
+
+func TestMigrateManga_MovesTitleAndResolvesOldID(t *testing.T) {
+	b, database, api, mdID := setupBotWithMangaDexServer(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	const oldID = "11111111-2222-3333-4444-555555555555"
+	mangaID, err := database.AddManga(oldID, "Dragon Ball Super", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	b.handleMangaSelection(userID, userID, int(mangaID), "migrate")
+	if got := api.lastMessageText(t); !strings.Contains(got, "no pending move") {
+		t.Fatalf("migrate without candidate=%q", got)
+	}
+
+	if _, err := database.SetMangaMergeCandidate(int(mangaID), mdID); err != nil {
+		t.Fatalf("SetMangaMergeCandidate(): %v", err)
+	}
+	b.handleMangaDetails(userID, userID, int(mangaID))
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbMangaAction(int(mangaID), "migrate")) {
+		t.Fatal("details should offer the pending migration")
+	}
+
+	b.handleMangaSelection(userID, userID, int(mangaID), "migrate")
+	d, err := database.GetMangaDetails(int(mangaID), userID)
+	if err != nil {
+		t.Fatalf("GetMangaDetails(): %v", err)
+	}
+	if d.MangaDexID != mdID || d.MergeCandidate != "" {
+		t.Fatalf("details=%+v, want migrated", d)
+	}
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, "moved to the new MangaDex entry") })
+
+	// Adding the old URL again now lands on the new entry.
+	b.handleAddManga(userID, userID, oldID)
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbAddConfirm(mdID)) {
+		t.Fatal("adding the merged ID should resolve to the new entry")
+	}
+}
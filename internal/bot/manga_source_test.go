@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/config"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/source"
+	"releasenojutsu/internal/updater"
+)
+
+const testSourceFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+  <title>Webcomic &amp; Friends</title>
+  <item><title>Chapter 2</title><guid>ch-2</guid><pubDate>Tue, 04 Mar 2025 10:00:00 +0000</pubDate></item>
+  <item><title>Chapter 1</title><guid>ch-1</guid><pubDate>Mon, 03 Mar 2025 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+
+func TestHandleMessage_FeedURLAddsRSSTitle(t *testing.T) {
+	feedSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testSourceFeed))
+	}))
+	t.Cleanup(feedSrv.Close)
+
+	database, err := db.New(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("db.New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+
+	mdClient := mangadex.NewClient()
+	rss := source.NewRSS()
+	rss.AllowPrivateHosts = true
+	api := &fakeTelegramAPI{}
+	b := New(api, database, mdClient, &config.Config{AdminUserID: 1}, updater.New(database, mdClient, mdClient, rss))
+	b.SetSources(source.NewRegistry(source.NewMangaDex(mdClient), rss))
+
+	feedURL := feedSrv.URL + "/feed.xml"
+	b.handleMessage(&tgbotapi.Message{
+		Text: feedURL,
+		From: &tgbotapi.User{ID: userID},
+		Chat: &tgbotapi.Chat{ID: userID},
+	})
+
+	waitUntil(t, 2*time.Second, func() bool { return sentTextsContain(api, "Import complete") })
+	if !sentTextsContain(api, "Webcomic &amp; Friends") || !sentTextsContain(api, "RSS feed") {
+		t.Fatal("expected the start message to name the feed title and source")
+	}
+
+	rows, err := database.ListManga()
+	if err != nil {
+		t.Fatalf("ListManga(): %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("titles=%d, want 1", len(rows))
+	}
+	mangaID := rows[0].ID
+	if src, err := database.GetMangaSource(mangaID); err != nil || src != source.RSS {
+		t.Fatalf("source=%q, %v; want rss", src, err)
+	}
+	if unread, err := database.CountUnreadChapters(mangaID); err != nil || unread != 2 {
+		t.Fatalf("unread=%d, %v; want 2", unread, err)
+	}
+
+	b.handleMangaDetails(userID, userID, mangaID)
+	if got := api.lastMessageText(t); !strings.Contains(got, "Source: <b>RSS</b>") || !strings.Contains(got, feedURL) {
+		t.Fatalf("details=%q, want the feed as source", got)
+	}
+}
@@ -8,14 +8,20 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
 )
 
 func (b *Bot) handleMarkChapterAsRead(chatID int64, userID int64, mangaID int, chapterNumber string, target ...*callbackEditTarget) {
-	cbTarget := firstCallbackTarget(target...)
-	b.logAction(chatID, "Mark chapter as read", fmt.Sprintf("Manga ID: %d, Chapter: %s", mangaID, chapterNumber))
+	b.markChapterAsRead(chatID, userID, mangaID, chapterNumber, db.HistorySourceButton, firstCallbackTarget(target...))
+}
 
-	err := b.db.MarkChapterAsRead(mangaID, chapterNumber)
+// markChapterAsRead marks the chapter read, recording source in the reading history, and
+// confirms it with an Undo button.
+func (b *Bot) markChapterAsRead(chatID int64, userID int64, mangaID int, chapterNumber string, source string, cbTarget *callbackEditTarget) {
+	b.logAction(chatID, "Mark chapter as read", fmt.Sprintf("Manga ID: %d, Chapter: %s, Source: %s", mangaID, chapterNumber, source))
+
+	historyID, err := b.db.MarkChapterAsReadFrom(mangaID, chapterNumber, source)
 	if err != nil {
 		logger.LogMsg(logger.LogError, "Error marking chapters as read: %v", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateChapter)
@@ -24,10 +30,14 @@ func (b *Bot) handleMarkChapterAsRead(chatID int64, userID int64, mangaID int, c
 	}
 
 	mangaTitle, _ := b.db.GetMangaTitle(mangaID, userID)
-	result := fmt.Sprintf(appcopy.Copy.Info.MarkReadResult, html.EscapeString(chapterNumber), html.EscapeString(mangaTitle))
+	format := appcopy.Copy.Info.MarkReadResult
+	if exact, _ := b.db.IsExactTracking(mangaID); exact {
+		format = appcopy.Copy.Info.MarkReadResultExact
+	}
+	result := fmt.Sprintf(format, html.EscapeString(chapterNumber), html.EscapeString(mangaTitle))
 	msg := tgbotapi.NewMessage(chatID, result)
 	msg.ParseMode = "HTML"
-	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+	b.sendMangaScopedMessage(withUndoButton(msg, mangaID, historyID), mangaID, cbTarget)
 }
 
 func (b *Bot) sendMarkReadStartMenu(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
@@ -134,7 +144,7 @@ func (b *Bot) sendMarkReadDirectChaptersMenu(chatID int64, userID int64, mangaID
 		})
 	}
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.PickChapterRead, mangaTitle, lastReadLine, unreadCount))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(b.pickChapterFormat(mangaID, true), mangaTitle, lastReadLine, unreadCount))
 	keyboard = appendBackToMangaRow(keyboard, mangaID)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
@@ -375,7 +385,7 @@ func (b *Bot) sendMarkReadChaptersMenuPage(chatID int64, userID int64, mangaID i
 	}
 	keyboard = appendBackToMangaRow(keyboard, mangaID)
 
-	msgText := fmt.Sprintf(appcopy.Copy.Info.PickChapterRead, mangaTitle, lastReadLine, unreadCount)
+	msgText := fmt.Sprintf(b.pickChapterFormat(mangaID, true), mangaTitle, lastReadLine, unreadCount)
 	msgText += "\n\n" + unreadBreadcrumbLine(bucketLabel(thousandBucketStart(tenStart), 1000), bucketLabel(hundredBucketStart(tenStart), 100), bucketLabel(tenStart, 10))
 	msg := tgbotapi.NewMessage(chatID, msgText)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
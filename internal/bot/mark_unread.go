@@ -8,14 +8,19 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
 )
 
 func (b *Bot) handleMarkChapterAsUnread(chatID int64, userID int64, mangaID int, chapterNumber string, target ...*callbackEditTarget) {
-	cbTarget := firstCallbackTarget(target...)
-	b.logAction(chatID, "Mark chapter as unread", fmt.Sprintf("Manga ID: %d, Chapter: %s", mangaID, chapterNumber))
+	b.markChapterAsUnread(chatID, userID, mangaID, chapterNumber, db.HistorySourceButton, firstCallbackTarget(target...))
+}
+
+// markChapterAsUnread is markChapterAsRead for unread marks.
+func (b *Bot) markChapterAsUnread(chatID int64, userID int64, mangaID int, chapterNumber string, source string, cbTarget *callbackEditTarget) {
+	b.logAction(chatID, "Mark chapter as unread", fmt.Sprintf("Manga ID: %d, Chapter: %s, Source: %s", mangaID, chapterNumber, source))
 
-	err := b.db.MarkChapterAsUnread(mangaID, chapterNumber)
+	historyID, err := b.db.MarkChapterAsUnreadFrom(mangaID, chapterNumber, source)
 	if err != nil {
 		logger.LogMsg(logger.LogError, "Error marking chapter as unread: %v", err)
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateChapter)
@@ -27,7 +32,7 @@ func (b *Bot) handleMarkChapterAsUnread(chatID int64, userID int64, mangaID int,
 	result := fmt.Sprintf(appcopy.Copy.Info.MarkUnreadResult, html.EscapeString(chapterNumber), html.EscapeString(mangaTitle))
 	msg := tgbotapi.NewMessage(chatID, result)
 	msg.ParseMode = "HTML"
-	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+	b.sendMangaScopedMessage(withUndoButton(msg, mangaID, historyID), mangaID, cbTarget)
 }
 
 func (b *Bot) sendMarkUnreadStartMenu(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
@@ -134,7 +139,7 @@ func (b *Bot) sendMarkUnreadDirectChaptersMenu(chatID int64, userID int64, manga
 		})
 	}
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.PickChapterUnread, mangaTitle, lastReadLine, readCount))
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(b.pickChapterFormat(mangaID, false), mangaTitle, lastReadLine, readCount))
 	keyboard = appendBackToMangaRow(keyboard, mangaID)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
@@ -375,7 +380,7 @@ func (b *Bot) sendMarkUnreadChaptersMenuPage(chatID int64, userID int64, mangaID
 	}
 	keyboard = appendBackToMangaRow(keyboard, mangaID)
 
-	msgText := fmt.Sprintf(appcopy.Copy.Info.PickChapterUnread, mangaTitle, lastReadLine, readCount)
+	msgText := fmt.Sprintf(b.pickChapterFormat(mangaID, false), mangaTitle, lastReadLine, readCount)
 	msgText += "\n\n" + readBreadcrumbLine(bucketLabel(thousandBucketStart(tenStart), 1000), bucketLabel(hundredBucketStart(tenStart), 100), bucketLabel(tenStart, 10))
 	msg := tgbotapi.NewMessage(chatID, msgText)
 	msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
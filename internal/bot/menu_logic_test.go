@@ -88,7 +88,7 @@ func TestSendMangaScopedMessage_AddsContextNavigation(t *testing.T) {
 	if !hasCallback(callbacks, cbMangaAction(123, "menu")) {
 		t.Fatalf("missing back-to-manga callback: %v", callbacks)
 	}
-	if !hasCallback(callbacks, cbListManga()) {
+	if !hasCallback(callbacks, cbBackToList()) {
 		t.Fatalf("missing back-to-list callback: %v", callbacks)
 	}
 	if !hasCallback(callbacks, cbMainMenu()) {
@@ -112,7 +112,7 @@ func TestSendMangaScopedMessage_PreservesExistingInlineKeyboard(t *testing.T) {
 	if !hasCallback(callbacks, cbMangaAction(321, "menu")) {
 		t.Fatalf("missing back-to-manga callback: %v", callbacks)
 	}
-	if !hasCallback(callbacks, cbListManga()) {
+	if !hasCallback(callbacks, cbBackToList()) {
 		t.Fatalf("missing back-to-list callback: %v", callbacks)
 	}
 	if !hasCallback(callbacks, cbMainMenu()) {
@@ -127,7 +127,7 @@ func TestSendListScopedMessage_AddsListAndMainMenuNavigation(t *testing.T) {
 	b.sendListScopedMessage(msg)
 
 	callbacks := messageCallbacks(t, api.lastMessageConfig(t))
-	if !hasCallback(callbacks, cbListManga()) {
+	if !hasCallback(callbacks, cbBackToList()) {
 		t.Fatalf("missing back-to-list callback: %v", callbacks)
 	}
 	if !hasCallback(callbacks, cbMainMenu()) {
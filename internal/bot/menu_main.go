@@ -8,6 +8,7 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
 )
 
@@ -33,11 +34,23 @@ func (b *Bot) sendMainMenu(chatID int64, target ...*callbackEditTarget) {
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ListManga, cbListManga()),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.CheckAll, cbCheckAll()),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Settings, cbSettings()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Stats, cbStats()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Friends, cbFriendsReading()),
+		),
 	}
 
 	if b.isAdmin(chatID) {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.GeneratePairingCode, cbGenPair()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.PairingCodes, cbPairList()),
+		), tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.UpdateAll, cbUpdateAll()),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RunHistory, cbRunHistory()),
 		))
 	}
 
@@ -148,6 +161,14 @@ func (b *Bot) sendStatusMessage(chatID int64, userID int64) {
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatusRegisteredChats, status.UserCount))
 	}
 	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatusTotalUnread, status.UnreadTotal))
+	if len(status.CategoryCounts) > 0 {
+		bld.WriteString(appcopy.Copy.Info.StatusCategoriesTitle)
+		for _, category := range db.Categories {
+			if n := status.CategoryCounts[category]; n > 0 {
+				bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatusCategoryLine, categoryLabel(category), n))
+			}
+		}
+	}
 	if status.HasCronLastRun {
 		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatusLastRun, status.CronLastRun.Local().Format(time.RFC1123)))
 	} else {
@@ -104,7 +104,7 @@ func appendBackToMangaRow(keyboard [][]tgbotapi.InlineKeyboardButton, mangaID in
 
 func appendBackToMangaListRow(keyboard [][]tgbotapi.InlineKeyboardButton) [][]tgbotapi.InlineKeyboardButton {
 	return append(keyboard, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbListManga()),
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToList, cbBackToList()),
 	})
 }
 
@@ -1,18 +1,22 @@
 package bot
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"html"
+	"strconv"
 	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/mangadex"
 )
 
 func (b *Bot) tryHandlePairingCode(message *tgbotapi.Message) bool {
@@ -28,7 +32,7 @@ func (b *Bot) tryHandlePairingCode(message *tgbotapi.Message) bool {
 		return true
 	}
 
-	code, ok := parsePairingCode(message.Text)
+	code, ok := parsePairingCode(startPayload(message))
 	if !ok {
 		return false
 	}
@@ -41,7 +45,7 @@ func (b *Bot) tryHandlePairingCode(message *tgbotapi.Message) bool {
 
 	used, err := b.db.RedeemPairingCode(code, message.From.ID)
 	if err != nil {
-		logger.LogMsg(logger.LogWarning, "Pairing code redeem failed: %v", err)
+		userLog(message.From.ID).Warn("Pairing code redeem failed", "error", err)
 		msg := tgbotapi.NewMessage(message.Chat.ID, appcopy.Copy.Prompts.PairingInvalid)
 		_, _ = b.api.Send(msg)
 		return true
@@ -53,21 +57,121 @@ func (b *Bot) tryHandlePairingCode(message *tgbotapi.Message) bool {
 	}
 
 	_ = b.db.EnsureUser(message.From.ID, false)
-	b.authorizedCache[message.From.ID] = struct{}{}
+	b.rememberAuthorized(message.From.ID)
 	msg := tgbotapi.NewMessage(message.Chat.ID, appcopy.Copy.Prompts.PairingSuccess)
 	_, _ = b.api.Send(msg)
+	b.addStarterLibrary(message.Chat.ID, message.From.ID, code)
 	return true
 }
 
+// addStarterLibrary copies the titles attached to a pairing code into the new user's list
+// and imports their chapters in the background.
+func (b *Bot) addStarterLibrary(chatID int64, userID int64, code string) {
+	starters, err := b.db.ListPairingCodeStarters(code)
+	if err != nil {
+		userLog(userID).Warn("Failed loading starter library", "code", code, "error", err)
+		return
+	}
+	if len(starters) == 0 {
+		return
+	}
+
+	var added []string
+	var mangaIDs []int
+	for _, s := range starters {
+		mangaID, err := b.db.AddMangaWithMangaPlus(b.resolveMangaDexID(userID, s.MangaDexID), s.Title, s.IsMangaPlus, userID)
+		if err != nil {
+			userLog(userID).Warn("Failed adding starter manga", logger.KeyMangaDexID, s.MangaDexID, "error", err)
+			continue
+		}
+		b.recordMangaAdded(userID, int(mangaID), db.HistorySourceImport)
+		added = append(added, "• <b>"+html.EscapeString(s.Title)+"</b>")
+		mangaIDs = append(mangaIDs, int(mangaID))
+	}
+	if len(added) == 0 {
+		return
+	}
+	b.logAction(userID, "Starter library added", fmt.Sprintf("code=%s titles=%d", code, len(added)))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.PairingStarterAdded, strings.Join(added, "\n")))
+	msg.ParseMode = "HTML"
+	b.sendMessageWithMainMenuButton(msg)
+
+	b.syncNewTitles(userID, mangaIDs)
+}
+
+// syncNewTitles imports the chapter lists of titles that were just stored, one after the
+// other, in the background.
+func (b *Bot) syncNewTitles(userID int64, mangaIDs []int) {
+	if b.updater == nil || len(mangaIDs) == 0 {
+		return
+	}
+	go func() {
+		for _, mangaID := range mangaIDs {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			ctx = logger.WithContext(ctx, logger.KeyUserID, userID, logger.KeyMangaID, mangaID)
+			if _, _, err := b.updater.SyncAll(ctx, mangaID); err != nil {
+				logger.FromContext(ctx).Error("SyncAll failed for new title", "error", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+const (
+	defaultPairingTTL   = 48 * time.Hour
+	maxPairingTTL       = 90 * 24 * time.Hour
+	maxPairingUses      = 100
+	maxPairingLabelLen  = 64
+	maxPairingStarters  = 10
+	pairingListPageSize = 10
+)
+
+// pairingRequest describes the code an admin asked for via /genpair arguments.
+type pairingRequest struct {
+	TTL      time.Duration
+	MaxUses  int
+	Label    string
+	Starters []string
+}
+
+func defaultPairingRequest() pairingRequest {
+	return pairingRequest{TTL: defaultPairingTTL, MaxUses: 1}
+}
+
 func (b *Bot) handleGeneratePairingCode(chatID int64, userID int64, target ...*callbackEditTarget) {
-	cbTarget := firstCallbackTarget(target...)
+	b.createPairingCode(chatID, userID, defaultPairingRequest(), firstCallbackTarget(target...))
+}
+
+func (b *Bot) handleGeneratePairingCodeCommand(chatID int64, userID int64, args string) {
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+
+	req, err := parsePairingArgs(args, b.mangaInputToID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.PairingArgsInvalid, html.EscapeString(err.Error())))
+		msg.ParseMode = "HTML"
+		b.sendMessageWithMainMenuButton(msg)
+		return
+	}
+	b.createPairingCode(chatID, userID, req, nil)
+}
 
+func (b *Bot) createPairingCode(chatID int64, userID int64, req pairingRequest, cbTarget *callbackEditTarget) {
 	if !b.isAdmin(userID) {
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
 		b.sendMessageWithMainMenuButton(msg, cbTarget)
 		return
 	}
 
+	starters, ok := b.resolvePairingStarters(chatID, req.Starters, cbTarget)
+	if !ok {
+		return
+	}
+
 	code, err := generatePairingCode()
 	if err != nil {
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotGeneratePair)
@@ -75,16 +179,345 @@ func (b *Bot) handleGeneratePairingCode(chatID int64, userID int64, target ...*c
 		return
 	}
 
-	expiresAt := time.Now().UTC().Add(48 * time.Hour)
-	if err := b.db.CreatePairingCode(code, userID, expiresAt); err != nil {
+	expiresAt := time.Now().UTC().Add(req.TTL)
+	opts := db.PairingCodeOptions{
+		ExpiresAt: expiresAt,
+		MaxUses:   req.MaxUses,
+		Label:     req.Label,
+		Starters:  starters,
+	}
+	if err := b.db.CreatePairingCodeWithOptions(code, userID, opts); err != nil {
 		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotStorePair)
 		b.sendMessageWithMainMenuButton(msg, cbTarget)
 		return
 	}
+	b.logAction(userID, "Generated pairing code", fmt.Sprintf("uses=%d ttl=%s label=%q starters=%d", req.MaxUses, req.TTL, req.Label, len(starters)))
 
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeGenerated, html.EscapeString(code), html.EscapeString(expiresAt.Format(time.RFC1123))))
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeGenerated, html.EscapeString(code), html.EscapeString(expiresAt.Format(time.RFC1123))))
+	if req.Label != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeLabelLine, html.EscapeString(req.Label)))
+	}
+	if req.MaxUses > 1 {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeMultiUse, req.MaxUses))
+	} else {
+		bld.WriteString(appcopy.Copy.Prompts.PairingCodeSingleUse)
+	}
+	if len(starters) > 0 {
+		titles := make([]string, 0, len(starters))
+		for _, s := range starters {
+			titles = append(titles, "<b>"+html.EscapeString(s.Title)+"</b>")
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeStarterLine, strings.Join(titles, ", ")))
+	}
+	link := b.deepLink(code)
+	if link != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Prompts.PairingCodeLink, html.EscapeString(link)))
+		bld.WriteString(appcopy.Copy.Prompts.PairingCodeHowToJoinLink)
+	} else {
+		bld.WriteString(appcopy.Copy.Prompts.PairingCodeHowToJoin)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
 	msg.ParseMode = "HTML"
 	b.sendMessageWithMainMenuButton(msg, cbTarget)
+	if link != "" {
+		b.sendPairingQR(chatID, code, link)
+	}
+}
+
+func (b *Bot) resolvePairingStarters(chatID int64, mangaDexIDs []string, cbTarget *callbackEditTarget) ([]db.PairingStarter, bool) {
+	if len(mangaDexIDs) == 0 {
+		return nil, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	starters := make([]db.PairingStarter, 0, len(mangaDexIDs))
+	for _, id := range mangaDexIDs {
+		mangaData, err := b.mdClient.GetManga(ctx, id)
+		if err != nil {
+			userLog(chatID).Error("Error fetching starter manga", logger.KeyMangaDexID, id, "error", err)
+			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Errors.CannotLoadStarter, id))
+			b.sendMessageWithMainMenuButton(msg, cbTarget)
+			return nil, false
+		}
+		starters = append(starters, db.PairingStarter{MangaDexID: id, Title: mangaTitle(mangaData), IsMangaPlus: b.publishedOnMangaPlus(ctx, id)})
+	}
+	return starters, true
+}
+
+// publishedOnMangaPlus reports whether any of the title's recent chapters link to MANGA
+// Plus, the same signal the import uses. Lookup failures count as no.
+func (b *Bot) publishedOnMangaPlus(ctx context.Context, mangaDexID string) bool {
+	feed, err := b.mdClient.GetChapterFeed(ctx, mangaDexID)
+	if err != nil {
+		logger.L().Warn("Failed checking starter manga for MANGA Plus", logger.KeyMangaDexID, mangaDexID, "error", err)
+		return false
+	}
+	for _, chapter := range feed.Data {
+		if mangadex.IsMangaPlusURL(chapter.Attributes.ExternalURL) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bot) sendPairingCodesList(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	codes, err := b.db.ListPairingCodes(pairingListPageSize)
+	if err != nil {
+		userLog(userID).Error("Error listing pairing codes", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadPairings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	now := time.Now().UTC()
+	var bld strings.Builder
+	bld.WriteString(appcopy.Copy.Info.PairingListTitle)
+	if len(codes) == 0 {
+		bld.WriteString(appcopy.Copy.Info.PairingListEmpty)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, p := range codes {
+		if i > 0 {
+			bld.WriteString("\n\n")
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.PairingListItem, html.EscapeString(p.Code)))
+		if p.Label != "" {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.PairingListItemLabel, html.EscapeString(p.Label)))
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.PairingListUsage, p.UseCount, p.MaxUses))
+		switch {
+		case p.IsRevoked:
+			bld.WriteString(appcopy.Copy.Info.PairingListRevoked)
+		case now.After(p.ExpiresAt.UTC()):
+			bld.WriteString(appcopy.Copy.Info.PairingListExpired)
+		default:
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.PairingListExpires, html.EscapeString(p.ExpiresAt.Local().Format(time.RFC1123))))
+		}
+
+		if len(p.JoinedChatIDs) > 0 {
+			joined := make([]string, 0, len(p.JoinedChatIDs))
+			for _, chatID := range p.JoinedChatIDs {
+				joined = append(joined, strconv.FormatInt(chatID, 10))
+			}
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.PairingListJoined, strings.Join(joined, ", ")))
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		if !p.IsRevoked {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.RevokePairingCode, p.Code), cbPairRevoke(p.Code)))
+		}
+		if p.ActiveUsers > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.DisablePairingUsers, p.ActiveUsers, p.Code), cbPairDisable(p.Code)))
+		}
+		if len(row) > 0 {
+			keyboard = append(keyboard, row)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
+	msg.ParseMode = "HTML"
+	if len(keyboard) > 0 {
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) sendRevokePairingConfirm(chatID int64, userID int64, code string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	redemptions, err := b.db.ListPairingRedemptions(code)
+	if err != nil {
+		userLog(userID).Error("Error listing pairing code redemptions", "code", code, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadPairings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ConfirmRevokePairing, html.EscapeString(code), len(redemptions)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.YesRevoke, cbPairRevokeYes(code)),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbPairList()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) handleRevokePairingCode(chatID int64, userID int64, code string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Revoke pairing code", code)
+
+	if err := b.db.RevokePairingCode(code); err != nil {
+		userLog(userID).Error("Error revoking pairing code", "code", code, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotRevokePair)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.PairingRevoked, html.EscapeString(code)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.PairingCodes, cbPairList()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) sendDisablePairingUsersConfirm(chatID int64, userID int64, code string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	redemptions, err := b.db.ListPairingRedemptions(code)
+	if err != nil {
+		userLog(userID).Error("Error listing pairing code redemptions", "code", code, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadPairings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Prompts.ConfirmDisablePairingUsers, len(redemptions), html.EscapeString(code)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.YesDisable, cbPairDisableYes(code)),
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, cbPairList()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// handleDisablePairingUsers takes access away from everyone who joined with code. Their
+// lists are kept so a new code brings them back where they left off.
+func (b *Bot) handleDisablePairingUsers(chatID int64, userID int64, code string, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.AdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Disable pairing code users", code)
+
+	disabled, err := b.db.DisablePairingCodeUsers(code)
+	if err != nil {
+		userLog(userID).Error("Error disabling users of pairing code", "code", code, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotDisablePairUsers)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	for _, id := range disabled {
+		b.forgetAuthorized(id)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.PairingUsersDisabled, len(disabled), html.EscapeString(code)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.PairingCodes, cbPairList()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// parsePairingArgs reads "/genpair" arguments: key=value options (uses, ttl, starter)
+// followed by a free-form label.
+func parsePairingArgs(args string, toMangaID func(string) (string, bool)) (pairingRequest, error) {
+	req := defaultPairingRequest()
+	var labelParts []string
+	for _, field := range strings.Fields(args) {
+		key, value, hasValue := strings.Cut(field, "=")
+		if !hasValue {
+			labelParts = append(labelParts, field)
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "uses", "max":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > maxPairingUses {
+				return pairingRequest{}, fmt.Errorf("uses must be between 1 and %d", maxPairingUses)
+			}
+			req.MaxUses = n
+		case "ttl", "expires":
+			ttl, err := parsePairingTTL(value)
+			if err != nil {
+				return pairingRequest{}, err
+			}
+			req.TTL = ttl
+		case "starter", "starters":
+			for _, raw := range strings.Split(value, ",") {
+				if strings.TrimSpace(raw) == "" {
+					continue
+				}
+				id, ok := toMangaID(raw)
+				if !ok {
+					return pairingRequest{}, fmt.Errorf("invalid starter manga: %s", raw)
+				}
+				req.Starters = append(req.Starters, id)
+			}
+			if len(req.Starters) > maxPairingStarters {
+				return pairingRequest{}, fmt.Errorf("at most %d starter titles are allowed", maxPairingStarters)
+			}
+		default:
+			labelParts = append(labelParts, field)
+		}
+	}
+
+	label := strings.Join(labelParts, " ")
+	if len([]rune(label)) > maxPairingLabelLen {
+		return pairingRequest{}, fmt.Errorf("label must be at most %d characters", maxPairingLabelLen)
+	}
+	req.Label = label
+	return req, nil
+}
+
+// parsePairingTTL accepts Go durations ("36h", "90m") plus a day suffix ("7d").
+func parsePairingTTL(raw string) (time.Duration, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl: %s", raw)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl: %s", raw)
+		}
+		ttl = d
+	}
+	if ttl < time.Minute || ttl > maxPairingTTL {
+		return 0, fmt.Errorf("ttl must be between 1m and %dd", int(maxPairingTTL.Hours()/24))
+	}
+	return ttl, nil
 }
 
 func parsePairingCode(text string) (string, bool) {
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"releasenojutsu/internal/db"
+)
+
+// quickInput is a progress update typed as plain text, such as "read op 1130".
+type quickInput struct {
+	Query    string
+	Chapter  string
+	CaughtUp bool
+	// Undo takes back the title's latest change.
+	Undo bool
+}
+
+// Phrases that mean "mark everything read", at the start or end of a message. Longer
+// phrases come first, so "caught up on x" doesn't leave "on" in the title.
+var (
+	caughtUpPrefixes = [][]string{{"caught", "up", "on"}, {"caught", "up"}, {"read", "all"}, {"finished", "all"}}
+	caughtUpSuffixes = [][]string{{"caught", "up"}, {"all", "read"}, {"up", "to", "date"}}
+)
+
+// Words that mark a message as a chapter read, and the ones that may stand before its
+// number.
+var (
+	readPrefixes   = map[string]bool{"read": true, "finished": true, "done": true}
+	readSuffixes   = map[string]bool{"read": true, "finished": true, "done": true}
+	chapterMarkers = []string{"chapter", "ch", "c"}
+)
+
+func hasWordPrefix(words, prefix []string) bool {
+	if len(words) < len(prefix) {
+		return false
+	}
+	for i, w := range prefix {
+		if words[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+func hasWordSuffix(words, suffix []string) bool {
+	return len(words) >= len(suffix) && hasWordPrefix(words[len(words)-len(suffix):], suffix)
+}
+
+func isChapterMarker(word string) bool {
+	for _, m := range chapterMarkers {
+		if word == m || word == m+"." {
+			return true
+		}
+	}
+	return false
+}
+
+// quickChapter reads a chapter number such as "120", "ch120", "ch.12.5" or "#120".
+// marked reports whether the number carried a marker.
+func quickChapter(word string) (number string, marked bool) {
+	if rest, ok := strings.CutPrefix(word, "#"); ok {
+		word, marked = rest, true
+	} else {
+		for _, m := range chapterMarkers {
+			if rest, ok := strings.CutPrefix(word, m); ok {
+				word, marked = strings.TrimPrefix(rest, "."), true
+				break
+			}
+		}
+	}
+	if _, err := strconv.ParseFloat(word, 64); err != nil {
+		return "", false
+	}
+	return word, marked
+}
+
+// parseQuickInput recognises messages such as "read op 1130", "frieren 120 done",
+// "frieren ch 120", "caught up blue lock" and "undo frieren". A chapter needs a read verb
+// or a chapter marker, so ordinary text with a number in it isn't taken for progress.
+func parseQuickInput(text string) (quickInput, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '!' || r == '?'
+	})
+	if len(words) > 0 {
+		words[len(words)-1] = strings.TrimRight(words[len(words)-1], ".")
+	}
+
+	if len(words) > 1 && words[0] == "undo" {
+		return quickInput{Query: strings.Join(words[1:], " "), Undo: true}, true
+	}
+
+	for _, p := range caughtUpPrefixes {
+		if hasWordPrefix(words, p) && len(words) > len(p) {
+			return quickInput{Query: strings.Join(words[len(p):], " "), CaughtUp: true}, true
+		}
+	}
+	for _, suffix := range caughtUpSuffixes {
+		if hasWordSuffix(words, suffix) && len(words) > len(suffix) {
+			return quickInput{Query: strings.Join(words[:len(words)-len(suffix)], " "), CaughtUp: true}, true
+		}
+	}
+
+	verb := false
+	switch {
+	case len(words) > 0 && readPrefixes[words[0]]:
+		words, verb = words[1:], true
+	case len(words) > 0 && readSuffixes[words[len(words)-1]]:
+		words, verb = words[:len(words)-1], true
+	}
+	if len(words) < 2 {
+		return quickInput{}, false
+	}
+	number, marked := quickChapter(words[len(words)-1])
+	if number == "" {
+		return quickInput{}, false
+	}
+	words = words[:len(words)-1]
+	if isChapterMarker(words[len(words)-1]) {
+		words, marked = words[:len(words)-1], true
+	}
+	if len(words) == 0 || !(verb || marked) {
+		return quickInput{}, false
+	}
+	return quickInput{Query: strings.Join(words, " "), Chapter: number}, true
+}
+
+// handleQuickInput applies a plain-text progress update; it reports whether text was
+// one. The title is matched by name or alias like the text commands, and the result is
+// confirmed with an Undo button.
+func (b *Bot) handleQuickInput(chatID int64, userID int64, text string) bool {
+	in, ok := parseQuickInput(text)
+	if !ok {
+		return false
+	}
+	b.logAction(userID, "Quick input", fmt.Sprintf("Query: %q, Chapter: %q, Caught up: %t, Undo: %t", in.Query, in.Chapter, in.CaughtUp, in.Undo))
+
+	var choice func(db.Manga) string
+	switch {
+	case in.Undo:
+		// The history view offers undoing the latest change of whichever title is picked.
+		choice = mangaActionChoice("history")
+	case in.CaughtUp:
+		choice = mangaActionChoice("mark_all_read")
+	default:
+		choice = func(m db.Manga) string { return cbMarkChapterRead(m.ID, in.Chapter) }
+	}
+	m, ok := b.resolveTitle(chatID, userID, in.Query, choice)
+	if !ok {
+		return true
+	}
+	if in.Undo {
+		b.undoLatestProgress(chatID, userID, m.ID, db.HistorySourceText)
+		return true
+	}
+	if in.CaughtUp {
+		b.markAllRead(chatID, userID, m.ID, db.HistorySourceText, nil)
+		return true
+	}
+	if b.chapterExists(chatID, userID, m, in.Chapter) {
+		b.markChapterAsRead(chatID, userID, m.ID, in.Chapter, db.HistorySourceText, nil)
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestParseQuickInput(t *testing.T) {
+	tests := []struct {
+		text string
+		want quickInput
+		ok   bool
+	}{
+		{text: "read op 1130", want: quickInput{Query: "op", Chapter: "1130"}, ok: true},
+		{text: "frieren 120 done", want: quickInput{Query: "frieren", Chapter: "120"}, ok: true},
+		{text: "Frieren ch. 120.5", want: quickInput{Query: "frieren", Chapter: "120.5"}, ok: true},
+		{text: "one piece ch1130!", want: quickInput{Query: "one piece", Chapter: "1130"}, ok: true},
+		{text: "read kaiju no 8 #100", want: quickInput{Query: "kaiju no 8", Chapter: "100"}, ok: true},
+		{text: "caught up blue lock", want: quickInput{Query: "blue lock", CaughtUp: true}, ok: true},
+		{text: "caught up on Blue Lock.", want: quickInput{Query: "blue lock", CaughtUp: true}, ok: true},
+		{text: "dandadan all read", want: quickInput{Query: "dandadan", CaughtUp: true}, ok: true},
+		{text: "Undo blue lock", want: quickInput{Query: "blue lock", Undo: true}, ok: true},
+		{text: "undo", ok: false},
+		{text: "top 10", ok: false},
+		{text: "read 120", ok: false},
+		{text: "read frieren", ok: false},
+		{text: "caught up", ok: false},
+		{text: "hello there", ok: false},
+		{text: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseQuickInput(tt.text)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseQuickInput(%q) = (%+v, %v), want (%+v, %v)", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestQuickInput_UsesAliasesAndOffersUndo(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	now := time.Now()
+	ids := make(map[string]int)
+	for _, title := range []string{"Blue Lock", "Blue Period"} {
+		id64, err := database.AddManga("md-"+title, title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(%q): %v", title, err)
+		}
+		ids[title] = int(id64)
+		for _, number := range []string{"1", "2", "3"} {
+			if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+				t.Fatalf("AddChapter(%q): %v", number, err)
+			}
+		}
+	}
+	text := func(s string) *tgbotapi.Message {
+		return &tgbotapi.Message{Text: s, From: &tgbotapi.User{ID: userID}, Chat: &tgbotapi.Chat{ID: userID}}
+	}
+
+	b.handleMessage(text("read blue 2"))
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbMarkChapterRead(ids["Blue Lock"], "2")) {
+		t.Fatalf("ambiguous quick input reply=%q, want a choice of titles", api.lastMessageText(t))
+	}
+
+	b.handleMangaSelection(userID, userID, ids["Blue Lock"], "alias_add")
+	b.handleMessage(text("BL!"))
+	if got := api.lastMessageText(t); !strings.Contains(got, "<code>bl</code>") {
+		t.Fatalf("aliases view=%q, want the new alias", got)
+	}
+
+	b.handleMessage(text("bl 2 done"))
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "Blue Lock") {
+		t.Fatalf("quick input reply=%q, want a confirmation for Blue Lock", msg.Text)
+	}
+	var undo bool
+	for _, cb := range messageCallbacks(t, msg) {
+		undo = undo || strings.HasPrefix(cb, "undo:")
+	}
+	if !undo {
+		t.Fatalf("quick input confirmation has no Undo button")
+	}
+	history, err := database.ListReadingHistory(ids["Blue Lock"], 1)
+	if err != nil || len(history) != 1 || history[0].Source != db.HistorySourceText {
+		t.Fatalf("history=%+v err=%v, want one text entry", history, err)
+	}
+
+	b.handleMessage(text("caught up bl"))
+	if unread, _ := database.CountUnreadChapters(ids["Blue Lock"]); unread != 0 {
+		t.Fatalf("unread after caught up=%d, want 0", unread)
+	}
+
+	b.handleMessage(text("undo bl"))
+	if unread, _ := database.CountUnreadChapters(ids["Blue Lock"]); unread != 1 {
+		t.Fatalf("unread after undo=%d, want 1", unread)
+	}
+	history, err = database.ListReadingHistory(ids["Blue Lock"], 1)
+	if err != nil || len(history) != 1 || history[0].Action != db.HistoryActionUndo || history[0].Source != db.HistorySourceText {
+		t.Fatalf("history=%+v err=%v, want a text undo", history, err)
+	}
+	if unread, _ := database.CountUnreadChapters(ids["Blue Period"]); unread != 3 {
+		t.Fatalf("other title unread=%d, want 3", unread)
+	}
+
+	b.handleMangaSelection(userID, userID, ids["Blue Period"], "alias_add")
+	b.handleMessage(text("bl"))
+	if got := api.lastMessageText(t); !strings.Contains(got, "already an alias of <b>Blue Lock</b>") {
+		t.Fatalf("reply=%q, want the alias refused", got)
+	}
+}
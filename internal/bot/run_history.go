@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/logger"
+)
+
+const (
+	runHistoryPageSize = 10
+	// runDetailMaxTitles keeps the detail view under Telegram's message size limit.
+	runDetailMaxTitles = 40
+)
+
+func (b *Bot) sendRunHistory(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RunHistoryAdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	runs, err := b.db.ListSchedulerRuns(runHistoryPageSize)
+	if err != nil {
+		userLog(userID).Error("Error listing scheduler runs", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadRunHistory)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	var bld strings.Builder
+	bld.WriteString(appcopy.Copy.Info.RunHistoryTitle)
+	if len(runs) == 0 {
+		bld.WriteString(appcopy.Copy.Info.RunHistoryEmpty)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, r := range runs {
+		if i > 0 {
+			bld.WriteString("\n\n")
+		}
+		duration := appcopy.Copy.Info.RunHistoryRunning
+		if r.IsFinished {
+			duration = formatRunDuration(r.FinishedAt.Sub(r.StartedAt))
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunHistoryItem,
+			r.ID,
+			html.EscapeString(r.StartedAt.Local().Format("Jan 2 15:04")),
+			html.EscapeString(r.Trigger),
+			html.EscapeString(duration),
+			r.TitlesChecked, r.NewChapters, r.NotificationsSent, r.Failures,
+		))
+		if r.Error != "" {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunHistoryError, html.EscapeString(r.Error)))
+		}
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.RunDetail, r.ID), cbRunDetail(r.ID)))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
+	msg.ParseMode = "HTML"
+	if len(keyboard) > 0 {
+		msg.ReplyMarkup = tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func (b *Bot) sendRunDetail(chatID int64, userID int64, runID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	if !b.isAdmin(userID) {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.RunHistoryAdminOnly)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	run, err := b.db.GetSchedulerRun(runID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			userLog(userID).Error("Error loading scheduler run", logger.KeyRunID, runID, "error", err)
+		}
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadRunHistory)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	titles, err := b.db.ListSchedulerRunTitles(runID)
+	if err != nil {
+		userLog(userID).Error("Error loading titles for scheduler run", logger.KeyRunID, runID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadRunHistory)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	duration := appcopy.Copy.Info.RunHistoryRunning
+	if run.IsFinished {
+		duration = formatRunDuration(run.FinishedAt.Sub(run.StartedAt))
+	}
+
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailTitle, run.ID, html.EscapeString(run.Trigger)))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailSummary,
+		html.EscapeString(run.StartedAt.Local().Format(time.RFC1123)),
+		html.EscapeString(duration),
+		run.TitlesChecked, run.NewChapters, run.NotificationsSent, run.Failures,
+	))
+	if run.Error != "" {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunHistoryError, html.EscapeString(run.Error)))
+	}
+	if len(titles) == 0 {
+		bld.WriteString(appcopy.Copy.Info.RunDetailNoTitles)
+	}
+	for i, t := range titles {
+		if i == runDetailMaxTitles {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailMore, len(titles)-i))
+			break
+		}
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailTitleItem, html.EscapeString(t.Title), formatRunDuration(t.Duration)))
+		if t.NewChapters > 0 {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailTitleNew, t.NewChapters))
+		}
+		if t.Error != "" {
+			bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RunDetailTitleError, html.EscapeString(truncateRunError(t.Error))))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, bld.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.RunHistory, cbRunHistory()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+func formatRunDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+func truncateRunError(s string) string {
+	const maxLen = 200
+	r := []rune(s)
+	if len(r) <= maxLen {
+		return s
+	}
+	return string(r[:maxLen]) + "…"
+}
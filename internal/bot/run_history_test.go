@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+func TestSendRunHistory_ListsRunsWithDetailButtons(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+
+	start := time.Now().Add(-time.Minute)
+	id, err := database.StartSchedulerRun("r1", "cron", start)
+	if err != nil {
+		t.Fatalf("StartSchedulerRun(): %v", err)
+	}
+	titles := []db.SchedulerRunTitle{
+		{MangaID: 1, UserID: 1, Title: "Alpha", NewChapters: 2, Duration: time.Second},
+		{MangaID: 2, UserID: 1, Title: "Beta <b>", Error: "feed timeout"},
+	}
+	if err := database.FinishSchedulerRun(id, start.Add(3*time.Second), 1, "", titles); err != nil {
+		t.Fatalf("FinishSchedulerRun(): %v", err)
+	}
+
+	b.sendRunHistory(1, 1)
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "2 checked · 2 new · 1 sent · 1 failed") {
+		t.Fatalf("history text=%q", msg.Text)
+	}
+	if !hasCallback(messageCallbacks(t, msg), cbRunDetail(id)) {
+		t.Fatalf("missing run detail button for run %d", id)
+	}
+
+	b.sendRunDetail(1, 1, id)
+	detail := api.lastMessageText(t)
+	if !strings.Contains(detail, "Beta &lt;b&gt;") || !strings.Contains(detail, "feed timeout") {
+		t.Fatalf("detail text=%q", detail)
+	}
+	if strings.Index(detail, "Beta") > strings.Index(detail, "Alpha") {
+		t.Fatalf("failed titles should be listed first: %q", detail)
+	}
+}
+
+func TestSendRunHistory_AdminOnly(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+
+	b.sendRunHistory(2, 2)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.RunHistoryAdminOnly {
+		t.Fatalf("message=%q, want admin-only prompt", got)
+	}
+}
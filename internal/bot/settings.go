@@ -0,0 +1,262 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/updater"
+)
+
+const pendingStateUnreadWarning = "unread_warning"
+
+// maxUnreadWarning caps custom thresholds so a typo can't silently disable the warning.
+const maxUnreadWarning = 999
+
+var unreadWarningPresets = []int{3, 5, 10, 20}
+
+func unreadWarningLabel(threshold int) string {
+	if threshold <= 0 {
+		return appcopy.Copy.Labels.UnreadWarningOff
+	}
+	return fmt.Sprintf(appcopy.Copy.Labels.UnreadWarningAt, threshold)
+}
+
+// defaultUnreadWarningLabel describes the user's default threshold. Until they pick one it
+// only applies to MANGA Plus titles.
+func defaultUnreadWarningLabel(settings db.UserSettings) string {
+	label := unreadWarningLabel(settings.UnreadWarning)
+	if !settings.UnreadWarningSet && settings.UnreadWarning > 0 {
+		label = fmt.Sprintf(appcopy.Copy.Labels.UnreadWarningMangaPlusOnly, label)
+	}
+	return label
+}
+
+// unreadWarningRows renders the threshold picker. mangaID 0 edits the user's default;
+// current is -1 when a title follows that default (or the user never picked one).
+func unreadWarningRows(mangaID int, current int) [][]tgbotapi.InlineKeyboardButton {
+	mark := func(label string, value int) string {
+		if value == current {
+			return fmt.Sprintf(appcopy.Copy.Buttons.Selected, label)
+		}
+		return label
+	}
+
+	var presets []tgbotapi.InlineKeyboardButton
+	for _, v := range unreadWarningPresets {
+		label := mark(fmt.Sprintf(appcopy.Copy.Buttons.WarningPreset, v), v)
+		presets = append(presets, tgbotapi.NewInlineKeyboardButtonData(label, cbSetUnreadWarning(mangaID, v)))
+	}
+
+	var extra []tgbotapi.InlineKeyboardButton
+	if mangaID != 0 {
+		extra = append(extra, tgbotapi.NewInlineKeyboardButtonData(mark(appcopy.Copy.Buttons.UseMyDefault, -1), cbSetUnreadWarning(mangaID, -1)))
+	}
+	extra = append(extra,
+		tgbotapi.NewInlineKeyboardButtonData(mark(appcopy.Copy.Buttons.WarningOff, 0), cbSetUnreadWarning(mangaID, 0)),
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.CustomValue, cbCustomUnreadWarning(mangaID)),
+	)
+	return [][]tgbotapi.InlineKeyboardButton{presets, extra}
+}
+
+func (b *Bot) sendSettings(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	settings, err := b.db.GetUserSettings(userID)
+	if err != nil {
+		userLog(userID).Error("Error loading settings", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	report := appcopy.Copy.Labels.BacklogReportDisabled
+	reportButton := appcopy.Copy.Buttons.BacklogReportOff
+	if settings.BacklogReport {
+		report = appcopy.Copy.Labels.BacklogReportWeekly
+		reportButton = appcopy.Copy.Buttons.BacklogReportOn
+	}
+
+	current := -1
+	if settings.UnreadWarningSet {
+		current = settings.UnreadWarning
+	}
+	rows := unreadWarningRows(0, current)
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(reportButton, cbToggleBacklogReport()),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.ShowBacklog, cbShowBacklog()),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.CategoryNotifications, cbCategoryMutes()),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.SettingsText, defaultUnreadWarningLabel(settings), report))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// sendUnreadWarningMenu lets the user override the unread warning for one title.
+func (b *Bot) sendUnreadWarningMenu(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	threshold, overridden, err := b.db.GetMangaUnreadWarning(mangaID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading unread warning", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	settings, err := b.db.GetUserSettings(userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading settings", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadSettings)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	current := -1
+	currentLabel := fmt.Sprintf(appcopy.Copy.Labels.UnreadWarningDefault, unreadWarningLabel(threshold))
+	if overridden {
+		current = threshold
+		currentLabel = unreadWarningLabel(threshold)
+	}
+
+	rows := unreadWarningRows(mangaID, current)
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToManga, cbMangaAction(mangaID, "menu")),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Info.UnreadWarningMenu,
+		html.EscapeString(title), currentLabel, defaultUnreadWarningLabel(settings)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// handleSetUnreadWarning stores a threshold. mangaID 0 sets the user's default; for a
+// title a negative value goes back to that default.
+func (b *Bot) handleSetUnreadWarning(chatID int64, userID int64, mangaID int, value int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	b.logAction(userID, "Set unread warning", fmt.Sprintf("Manga ID: %d, Value: %d", mangaID, value))
+
+	if mangaID == 0 {
+		if err := b.db.SetUserUnreadWarning(userID, value); err != nil {
+			userLog(userID).Error("Error saving unread warning", "error", err)
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+			b.sendMessageWithMainMenuButton(msg, cbTarget)
+			return
+		}
+		b.sendSettings(chatID, userID, cbTarget)
+		return
+	}
+
+	if err := b.db.SetMangaUnreadWarning(mangaID, userID, value); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg, cbTarget)
+			return
+		}
+		userLog(userID, mangaID).Error("Error saving unread warning", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.sendUnreadWarningMenu(chatID, userID, mangaID, cbTarget)
+}
+
+func (b *Bot) sendCustomUnreadWarningPrompt(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	if err := b.db.SetUserPendingState(userID, pendingStateUnreadWarning, strconv.Itoa(mangaID)); err != nil {
+		userLog(userID, mangaID).Warn("Failed to set pending state", "error", err)
+	}
+
+	back := cbSettings()
+	if mangaID != 0 {
+		back = cbMangaAction(mangaID, "warn")
+	}
+	msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.UnreadWarningCustom)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Cancel, back),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, firstCallbackTarget(target...))
+}
+
+// consumeUnreadWarningInput handles the reply to sendCustomUnreadWarningPrompt.
+func (b *Bot) consumeUnreadWarningInput(message *tgbotapi.Message, payload string) {
+	chatID, userID := message.Chat.ID, message.From.ID
+	mangaID, err := strconv.Atoi(payload)
+	if err != nil {
+		userLog(userID).Warn("Invalid unread_warning payload", "payload", payload)
+		b.clearPendingState(userID)
+		return
+	}
+
+	value, err := strconv.Atoi(strings.TrimSpace(message.Text))
+	if err != nil || value < 0 || value > maxUnreadWarning {
+		// Keep pending state until the user sends a usable number.
+		b.sendCustomUnreadWarningPrompt(chatID, userID, mangaID)
+		return
+	}
+	b.clearPendingState(userID)
+	b.handleSetUnreadWarning(chatID, userID, mangaID, value)
+}
+
+func (b *Bot) handleToggleBacklogReport(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	settings, err := b.db.GetUserSettings(userID)
+	if err == nil {
+		err = b.db.SetUserBacklogReport(userID, !settings.BacklogReport)
+	}
+	if err != nil {
+		userLog(userID).Error("Error toggling backlog report", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotSaveSettings)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	b.logAction(userID, "Toggle backlog report", fmt.Sprintf("Enabled: %t", !settings.BacklogReport))
+	b.sendSettings(chatID, userID, cbTarget)
+}
+
+func (b *Bot) sendBacklog(chatID int64, userID int64, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	entries, err := b.db.ListBacklog(userID)
+	if err != nil {
+		userLog(userID).Error("Error loading backlog", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadBacklog)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(entries)+1)
+	for _, e := range entries {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(e.Title, cbMangaAction(e.MangaID, "menu")),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.BackToSettings, cbSettings()),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, updater.FormatBacklogReportHTML(entries))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestSettings_DefaultThresholdAndBacklogReport(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+
+	b.sendSettings(userID, userID)
+	msg := api.lastMessageConfig(t)
+	if !strings.Contains(msg.Text, "at 3+ unread") {
+		t.Fatalf("settings=%q, want the default threshold", msg.Text)
+	}
+	for _, want := range []string{cbSetUnreadWarning(0, 10), cbCustomUnreadWarning(0), cbToggleBacklogReport(), cbShowBacklog()} {
+		if !hasCallback(messageCallbacks(t, msg), want) {
+			t.Fatalf("missing %q", want)
+		}
+	}
+
+	b.handleSetUnreadWarning(userID, userID, 0, 10)
+	b.handleToggleBacklogReport(userID, userID)
+	settings, err := database.GetUserSettings(userID)
+	if err != nil || settings.UnreadWarning != 10 || !settings.BacklogReport {
+		t.Fatalf("settings=%+v err=%v, want 10 with the report on", settings, err)
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "at 10+ unread") || !strings.Contains(got, "every Monday") {
+		t.Fatalf("settings=%q, want the new values", got)
+	}
+}
+
+func TestUnreadWarning_TitleOverrideAndCustomInput(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	// Until a default is picked it only covers MANGA Plus titles.
+	mangaID, err := database.AddMangaWithMangaPlus("37b87be0-b1f4-4507-affa-06c99ebb27f8", "Warn Manga", true, userID)
+	if err != nil {
+		t.Fatalf("AddMangaWithMangaPlus(): %v", err)
+	}
+
+	b.handleMangaSelection(userID, userID, int(mangaID), "warn")
+	if got := api.lastMessageText(t); !strings.Contains(got, "at 3+ unread (your default)") {
+		t.Fatalf("warn menu=%q, want the inherited default", got)
+	}
+
+	b.sendCustomUnreadWarningPrompt(userID, userID, int(mangaID))
+	input := func(text string) {
+		t.Helper()
+		msg := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: userID}, From: &tgbotapi.User{ID: userID}, Text: text}
+		if !b.consumePendingInput(msg) {
+			t.Fatalf("consumePendingInput(%q) = false", text)
+		}
+	}
+	input("lots")
+	if _, _, ok, _ := database.GetUserPendingState(userID); !ok {
+		t.Fatal("invalid input should keep the pending state")
+	}
+	input("7")
+	if _, _, ok, _ := database.GetUserPendingState(userID); ok {
+		t.Fatal("pending state should be cleared")
+	}
+	if threshold, overridden, _ := database.GetMangaUnreadWarning(int(mangaID)); threshold != 7 || !overridden {
+		t.Fatalf("threshold=%d overridden=%v, want a 7 override", threshold, overridden)
+	}
+
+	b.handleSetUnreadWarning(userID, userID, int(mangaID), -1)
+	if _, overridden, _ := database.GetMangaUnreadWarning(int(mangaID)); overridden {
+		t.Fatal("override should be cleared")
+	}
+
+	// Someone else's title can't be changed.
+	b.handleSetUnreadWarning(99, 99, int(mangaID), 1)
+	if _, overridden, _ := database.GetMangaUnreadWarning(int(mangaID)); overridden {
+		t.Fatal("another user changed the threshold")
+	}
+}
@@ -0,0 +1,338 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
+)
+
+// How much the /stats view covers.
+const (
+	statsWeeks     = 12
+	statsMonths    = 12
+	statsTopTitles = 5
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a row of block characters scaled to the largest one.
+func sparkline(values []int) string {
+	maxValue := 0
+	for _, v := range values {
+		maxValue = max(maxValue, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if maxValue > 0 && v > 0 {
+			i = v * (len(sparkBlocks) - 1) / maxValue
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// civilDay returns t's calendar date (server time) as midnight UTC, so days can be
+// counted without daylight saving getting in the way.
+func civilDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dailyChapters sums the chapters read per day. Days where more was marked unread than
+// read count as zero.
+func dailyChapters(activity []db.ReadingActivity) map[time.Time]int {
+	days := make(map[time.Time]int)
+	for _, a := range activity {
+		days[civilDay(a.At)] += a.Chapters
+	}
+	for day, n := range days {
+		if n <= 0 {
+			delete(days, day)
+		}
+	}
+	return days
+}
+
+func longestStreak(days map[time.Time]int) int {
+	longest := 0
+	for day := range days {
+		if days[day.AddDate(0, 0, -1)] > 0 {
+			continue // not the start of a run
+		}
+		n := 0
+		for days[day.AddDate(0, 0, n)] > 0 {
+			n++
+		}
+		longest = max(longest, n)
+	}
+	return longest
+}
+
+// currentStreak counts the reading days up to today; a streak that reached yesterday is
+// still alive until today ends.
+func currentStreak(days map[time.Time]int, now time.Time) int {
+	day := civilDay(now)
+	if days[day] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	n := 0
+	for days[day.AddDate(0, 0, -n)] > 0 {
+		n++
+	}
+	return n
+}
+
+// weeklyChapters returns the chapters read in each of the last n weeks (Monday to
+// Sunday), oldest first.
+func weeklyChapters(days map[time.Time]int, now time.Time, n int) []int {
+	today := civilDay(now)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	weeks := make([]int, n)
+	for day, count := range days {
+		if day.After(today) {
+			continue
+		}
+		ago := 0
+		if day.Before(monday) {
+			ago = (int(monday.Sub(day).Hours()/24) + 6) / 7
+		}
+		if ago < n {
+			weeks[n-1-ago] += count
+		}
+	}
+	return weeks
+}
+
+// monthlyChapters returns the chapters read in each of the last n calendar months,
+// oldest first.
+func monthlyChapters(days map[time.Time]int, now time.Time, n int) []int {
+	today := civilDay(now)
+	months := make([]int, n)
+	for day, count := range days {
+		ago := (today.Year()*12 + int(today.Month())) - (day.Year()*12 + int(day.Month()))
+		if ago >= 0 && ago < n {
+			months[n-1-ago] += count
+		}
+	}
+	return months
+}
+
+type titleChapters struct {
+	Title    string
+	Chapters int
+}
+
+// topTitles returns the n titles with the most chapters read, most first.
+func topTitles(activity []db.ReadingActivity, n int) []titleChapters {
+	byManga := make(map[int]*titleChapters)
+	for _, a := range activity {
+		t, ok := byManga[a.MangaID]
+		if !ok {
+			t = &titleChapters{}
+			byManga[a.MangaID] = t
+		}
+		t.Title = a.Title
+		t.Chapters += a.Chapters
+	}
+	var top []titleChapters
+	for _, t := range byManga {
+		if t.Chapters > 0 {
+			top = append(top, *t)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Chapters != top[j].Chapters {
+			return top[i].Chapters > top[j].Chapters
+		}
+		return strings.ToLower(top[i].Title) < strings.ToLower(top[j].Title)
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+func lagLabel(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return appcopy.Copy.Labels.LagUnderHour
+	case d < 48*time.Hour:
+		return fmt.Sprintf(appcopy.Copy.Labels.LagHours, int(d.Hours()))
+	default:
+		return fmt.Sprintf(appcopy.Copy.Labels.LagDays, d.Hours()/24)
+	}
+}
+
+func writeTopTitles(bld *strings.Builder, top []titleChapters) {
+	if len(top) == 0 {
+		return
+	}
+	bld.WriteString(appcopy.Copy.Info.StatsTopTitle)
+	for i, t := range top {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsTopItem, i+1, html.EscapeString(t.Title), t.Chapters))
+	}
+}
+
+func writeReadLag(bld *strings.Builder, lags []db.TitleReadLag) {
+	if len(lags) == 0 {
+		return
+	}
+	bld.WriteString(appcopy.Copy.Info.StatsLagTitle)
+	for _, l := range lags {
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsLagItem, html.EscapeString(l.Title), lagLabel(l.Average), l.Chapters))
+	}
+}
+
+// formatReadingStats renders the /stats view from the user's whole reading history.
+func formatReadingStats(activity []db.ReadingActivity, lags []db.TitleReadLag, status db.Status, now time.Time) string {
+	days := dailyChapters(activity)
+	if len(days) == 0 {
+		return appcopy.Copy.Info.StatsTitle + appcopy.Copy.Info.StatsEmpty
+	}
+	weeks := weeklyChapters(days, now, statsWeeks)
+	months := monthlyChapters(days, now, statsMonths)
+
+	var bld strings.Builder
+	bld.WriteString(appcopy.Copy.Info.StatsTitle)
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsPeriods, weeks[len(weeks)-1], months[len(months)-1]))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsWeeks, statsWeeks, sparkline(weeks)))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsMonths, statsMonths, sparkline(months)))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsStreak, currentStreak(days, now), longestStreak(days)))
+	switch {
+	case status.BacklogTitles > 0:
+		avg := float64(status.UnreadTotal) / float64(status.BacklogTitles)
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsBacklog, status.UnreadTotal, status.BacklogTitles, status.MangaCount, avg))
+	case status.MangaCount > 0:
+		bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.StatsBacklogNone, status.MangaCount))
+	}
+	writeTopTitles(&bld, topTitles(activity, statsTopTitles))
+	writeReadLag(&bld, lags)
+	return bld.String()
+}
+
+// formatYearRecap renders the recap of one calendar year's reading.
+func formatYearRecap(year int, activity []db.ReadingActivity, lags []db.TitleReadLag) string {
+	days := dailyChapters(activity)
+	months := make([]int, 12)
+	total := 0
+	for day, n := range days {
+		if day.Year() == year {
+			months[day.Month()-1] += n
+			total += n
+		}
+	}
+	top := topTitles(activity, len(activity))
+	if total == 0 || len(top) == 0 {
+		return fmt.Sprintf(appcopy.Copy.Info.RecapEmpty, year)
+	}
+	busiest := 0
+	for i, n := range months {
+		if n > months[busiest] {
+			busiest = i
+		}
+	}
+
+	var bld strings.Builder
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RecapTitle, year))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RecapTotals, total, len(top)))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RecapMonths, sparkline(months)))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RecapBusiestMonth, time.Month(busiest+1).String(), months[busiest]))
+	bld.WriteString(fmt.Sprintf(appcopy.Copy.Info.RecapLongestStreak, longestStreak(days)))
+	if len(top) > statsTopTitles {
+		top = top[:statsTopTitles]
+	}
+	writeTopTitles(&bld, top)
+	writeReadLag(&bld, lags)
+	return bld.String()
+}
+
+func (b *Bot) sendStats(chatID int64, userID int64, target ...*callbackEditTarget) {
+	b.logAction(userID, "Sent stats", "")
+	cbTarget := firstCallbackTarget(target...)
+	now := time.Now()
+
+	activity, err := b.db.ListReadingActivity(userID, time.Time{}, now)
+	var lags []db.TitleReadLag
+	if err == nil {
+		lags, err = b.db.ListReleaseToReadLag(userID, time.Time{}, now, statsTopTitles)
+	}
+	var status db.Status
+	if err == nil {
+		status, err = b.db.GetStatusByUser(userID)
+	}
+	if err != nil {
+		userLog(userID).Error("Error loading reading stats", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadStats)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, formatReadingStats(activity, lags, status, now))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(appcopy.Copy.Buttons.YearRecap, now.Year()), cbYearRecap(now.Year())),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// yearRecapText loads and renders the user's recap of year.
+func (b *Bot) yearRecapText(userID int64, year int) (string, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(1, 0, 0)
+	activity, err := b.db.ListReadingActivity(userID, from, to)
+	if err != nil {
+		return "", err
+	}
+	lags, err := b.db.ListReleaseToReadLag(userID, from, to, statsTopTitles)
+	if err != nil {
+		return "", err
+	}
+	return formatYearRecap(year, activity, lags), nil
+}
+
+func (b *Bot) sendYearRecap(chatID int64, userID int64, year int, target ...*callbackEditTarget) {
+	b.logAction(userID, "Sent year recap", fmt.Sprintf("Year: %d", year))
+	cbTarget := firstCallbackTarget(target...)
+	text, err := b.yearRecapText(userID, year)
+	if err != nil {
+		userLog(userID).Error("Error loading year recap", "year", year, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotLoadStats)
+		b.sendMessageWithMainMenuButton(msg, cbTarget)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Stats, cbStats()),
+		),
+	)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// SendYearRecap sends the user their recap of year. It implements cron.RecapSender.
+func (b *Bot) SendYearRecap(userID int64, year int) error {
+	text, err := b.yearRecapText(userID, year)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(userID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.Stats, cbStats()),
+		),
+	)
+	_, err = b.api.Send(msg)
+	return err
+}
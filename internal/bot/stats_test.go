@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestSparkline_ScalesToLargestValue(t *testing.T) {
+	if got := sparkline([]int{0, 1, 4, 8}); got != "▁▁▄█" {
+		t.Fatalf("sparkline()=%q", got)
+	}
+	if got := sparkline([]int{0, 0}); got != "▁▁" {
+		t.Fatalf("sparkline(zeros)=%q", got)
+	}
+}
+
+func TestReadingStats_StreaksAndWeeks(t *testing.T) {
+	// A Wednesday at noon, server time.
+	now := time.Date(2026, time.October, 14, 12, 0, 0, 0, time.Local)
+	day := func(daysAgo, chapters int) db.ReadingActivity {
+		return db.ReadingActivity{At: now.AddDate(0, 0, -daysAgo), MangaID: 1, Title: "One", Chapters: chapters}
+	}
+	activity := []db.ReadingActivity{
+		// A four-day run, Thursday to Sunday three weeks back.
+		day(20, 1), day(19, 2), day(18, 1), day(17, 1),
+		// Yesterday and the day before; nothing yet today.
+		day(2, 3), day(1, 2),
+		// Marking more unread than read doesn't count as a reading day.
+		day(5, 2), day(5, -4),
+	}
+	days := dailyChapters(activity)
+	if got := currentStreak(days, now); got != 2 {
+		t.Fatalf("currentStreak()=%d, want 2", got)
+	}
+	if got := longestStreak(days); got != 4 {
+		t.Fatalf("longestStreak()=%d, want 4", got)
+	}
+	weeks := weeklyChapters(days, now, 4)
+	// This week (Mon–Wed) has 3+2 chapters; the run 17–20 days ago was three weeks back.
+	if want := []int{5, 0, 0, 5}; !equalInts(weeks, want) {
+		t.Fatalf("weeklyChapters()=%v, want %v", weeks, want)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStats_CommandAndYearRecap(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+
+	b.sendStats(userID, userID)
+	if got := api.lastMessageText(t); !strings.Contains(got, "No reading recorded yet") {
+		t.Fatalf("empty stats=%q", got)
+	}
+
+	id64, err := database.AddManga("md-1", "Fast Reader", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	released := time.Now().Add(-3 * 24 * time.Hour)
+	for _, number := range []string{"1", "2", "3"} {
+		if err := database.AddChapter(id64, number, "", released, released, released, released); err != nil {
+			t.Fatalf("AddChapter(%q): %v", number, err)
+		}
+	}
+	if err := database.MarkChapterAsRead(int(id64), "2"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+
+	b.sendStats(userID, userID)
+	msg := api.lastMessageConfig(t)
+	for _, want := range []string{"This week: <b>2</b>", "Streak: <b>1</b> days", "1. Fast Reader — 2", "Fast Reader — 3.0 days (2 chapters)", "1</b> unread in <b>1</b> of <b>1</b> titles (avg <b>1.0</b> per title behind)"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Fatalf("stats=%q, want %q", msg.Text, want)
+		}
+	}
+	year := time.Now().Year()
+	if !hasCallback(messageCallbacks(t, msg), cbYearRecap(year)) {
+		t.Fatalf("stats should offer this year's recap")
+	}
+
+	b.sendYearRecap(userID, userID, year)
+	if got := api.lastMessageText(t); !strings.Contains(got, "You read <b>2</b> chapters across <b>1</b> titles") {
+		t.Fatalf("recap=%q", got)
+	}
+	b.sendYearRecap(userID, userID, year-1)
+	if got := api.lastMessageText(t); !strings.Contains(got, "No chapters marked as read this year") {
+		t.Fatalf("last year's recap=%q", got)
+	}
+}
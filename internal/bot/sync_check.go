@@ -25,10 +25,11 @@ func (b *Bot) handleSyncAllChapters(chatID int64, userID int64, mangaID int, tar
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
+		ctx = logger.WithContext(ctx, logger.KeyUserID, userID, logger.KeyMangaID, mangaID)
 
 		synced, _, err := b.updater.SyncAll(ctx, mangaID)
 		if err != nil {
-			logger.LogMsg(logger.LogError, "SyncAll failed for manga %d: %v", mangaID, err)
+			logger.FromContext(ctx).Error("SyncAll failed", "error", err)
 			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(appcopy.Copy.Errors.SyncFailedSimple, html.EscapeString(mangaTitle)))
 			msg.ParseMode = "HTML"
 			b.sendMangaScopedMessage(msg, mangaID)
@@ -63,11 +64,15 @@ func (b *Bot) handleCheckNewChapters(chatID int64, mangaID int, target ...*callb
 		return
 	}
 
-	isMangaPlus, err := b.db.IsMangaPlus(mangaID)
+	window, err := b.db.MangaPlusWindowFor(mangaID)
 	if err != nil {
-		isMangaPlus = false
+		logger.L().Warn("Failed loading MANGA Plus window", logger.KeyMangaID, mangaID, "error", err)
 	}
-	message := updater.FormatNewChaptersMessageHTML(res.Title, res.NewChapters, res.UnreadCount, isMangaPlus)
+	warnAt, _, err := b.db.GetMangaUnreadWarning(mangaID)
+	if err != nil {
+		logger.L().Warn("Failed loading unread warning threshold", logger.KeyMangaID, mangaID, "error", err)
+	}
+	message := updater.FormatNewChaptersMessageHTML(res.Title, res.NewChapters, res.UnreadCount, warnAt, window)
 	msg := tgbotapi.NewMessage(chatID, message)
 	msg.ParseMode = "HTML"
 	b.sendMangaScopedMessage(msg, mangaID, cbTarget)
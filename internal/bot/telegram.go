@@ -2,14 +2,18 @@ package bot
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"releasenojutsu/internal/appcopy"
 	"releasenojutsu/internal/config"
+	"releasenojutsu/internal/cron"
 	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
 	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/source"
 	"releasenojutsu/internal/updater"
 )
 
@@ -26,42 +30,121 @@ type Bot struct {
 	api      TelegramAPI
 	db       *db.DB
 	mdClient *mangadex.Client
+	sources  *source.Registry
 	config   *config.Config
 	updater  *updater.Updater
+	runner   UpdateRunner
+	// username is the bot's @username without the "@", for t.me deep links.
+	username string
 
+	// authMu guards authorizedCache; inline queries check it off the update loop.
+	authMu          sync.Mutex
 	authorizedCache map[int64]struct{}
+
+	inlineSearches inlineSearchCache
+
+	// checkAllCooldown spaces out a user's "check all" runs; admins are exempt.
+	checkAllCooldown time.Duration
+	checkAllMu       sync.Mutex
+	lastCheckAll     map[int64]time.Time
+}
+
+// UpdateRunner runs an update on demand. *cron.Scheduler implements it.
+type UpdateRunner interface {
+	RunNow(ctx context.Context, opts cron.RunOptions) (cron.RunSummary, error)
 }
 
 // New creates a new Bot.
 
 func New(api TelegramAPI, db *db.DB, mdClient *mangadex.Client, config *config.Config, upd *updater.Updater) *Bot {
+	var sources *source.Registry
+	if mdClient != nil {
+		sources = source.NewRegistry(source.NewMangaDex(mdClient))
+	}
 	return &Bot{
 		api:      api,
 		db:       db,
 		mdClient: mdClient,
+		sources:  sources,
 		config:   config,
 		updater:  upd,
 		authorizedCache: map[int64]struct{}{
 			config.AdminUserID: {},
 		},
+		checkAllCooldown: defaultCheckAllCooldown,
+		lastCheckAll:     make(map[int64]time.Time),
 	}
 }
 
+// SetSources sets where titles can be added from, in resolution order. Without it only
+// MangaDex is available.
+func (b *Bot) SetSources(r *source.Registry) {
+	b.sources = r
+}
+
+// SetUpdateRunner enables the "check all" and global update actions.
+func (b *Bot) SetUpdateRunner(r UpdateRunner) {
+	b.runner = r
+}
+
 // Run starts the bot and listens for updates until ctx is cancelled.
 func (b *Bot) Run(ctx context.Context) error {
 	logger.LogMsg(logger.LogInfo, "Bot started")
+	b.registerCommands()
 
-	// Set bot commands
+	if b.config.UseWebhook() {
+		return b.runWebhook(ctx)
+	}
+	return b.runPolling(ctx)
+}
+
+func (b *Bot) registerCommands() {
 	commands := []tgbotapi.BotCommand{
 		{Command: appcopy.Copy.Commands.Start, Description: appcopy.Copy.Commands.StartDesc},
 		{Command: appcopy.Copy.Commands.Help, Description: appcopy.Copy.Commands.HelpDesc},
+		{Command: appcopy.Copy.Commands.Add, Description: appcopy.Copy.Commands.AddDesc},
+		{Command: appcopy.Copy.Commands.Read, Description: appcopy.Copy.Commands.ReadDesc},
+		{Command: appcopy.Copy.Commands.Unread, Description: appcopy.Copy.Commands.UnreadDesc},
+		{Command: appcopy.Copy.Commands.List, Description: appcopy.Copy.Commands.ListDesc},
+		{Command: appcopy.Copy.Commands.Check, Description: appcopy.Copy.Commands.CheckDesc},
+		{Command: appcopy.Copy.Commands.Remove, Description: appcopy.Copy.Commands.RemoveDesc},
 		{Command: appcopy.Copy.Commands.Status, Description: appcopy.Copy.Commands.StatusDesc},
-		{Command: appcopy.Copy.Commands.GenPair, Description: appcopy.Copy.Commands.GenPairDesc},
+		{Command: appcopy.Copy.Commands.Stats, Description: appcopy.Copy.Commands.StatsDesc},
+		{Command: appcopy.Copy.Commands.Export, Description: appcopy.Copy.Commands.ExportDesc},
+		{Command: appcopy.Copy.Commands.Import, Description: appcopy.Copy.Commands.ImportDesc},
 	}
 	if _, err := b.api.Request(tgbotapi.NewSetMyCommands(commands...)); err != nil {
 		logger.LogMsg(logger.LogWarning, "Failed to set bot commands: %v", err)
 	}
 
+	// Admin-only commands are only listed in the admin's own chat.
+	if b.config.AdminUserID != 0 {
+		adminCommands := append(commands,
+			tgbotapi.BotCommand{Command: appcopy.Copy.Commands.GenPair, Description: appcopy.Copy.Commands.GenPairDesc},
+			tgbotapi.BotCommand{Command: appcopy.Copy.Commands.Pairings, Description: appcopy.Copy.Commands.PairingsDesc},
+			tgbotapi.BotCommand{Command: appcopy.Copy.Commands.GroupCode, Description: appcopy.Copy.Commands.GroupCodeDesc},
+			tgbotapi.BotCommand{Command: appcopy.Copy.Commands.Runs, Description: appcopy.Copy.Commands.RunsDesc},
+			tgbotapi.BotCommand{Command: appcopy.Copy.Commands.UpdateAll, Description: appcopy.Copy.Commands.UpdateAllDesc},
+		)
+		scope := tgbotapi.NewBotCommandScopeChat(b.config.AdminUserID)
+		if _, err := b.api.Request(tgbotapi.NewSetMyCommandsWithScope(scope, adminCommands...)); err != nil {
+			logger.LogMsg(logger.LogWarning, "Failed to set admin commands: %v", err)
+		}
+	}
+
+	groupCommands := []tgbotapi.BotCommand{
+		{Command: appcopy.Copy.Commands.List, Description: appcopy.Copy.Commands.GroupListDesc},
+		{Command: appcopy.Copy.Commands.Add, Description: appcopy.Copy.Commands.GroupAddDesc},
+		{Command: appcopy.Copy.Commands.Remove, Description: appcopy.Copy.Commands.GroupRemoveDesc},
+		{Command: appcopy.Copy.Commands.Link, Description: appcopy.Copy.Commands.LinkDesc},
+		{Command: appcopy.Copy.Commands.Unlink, Description: appcopy.Copy.Commands.UnlinkDesc},
+	}
+	if _, err := b.api.Request(tgbotapi.NewSetMyCommandsWithScope(tgbotapi.NewBotCommandScopeAllGroupChats(), groupCommands...)); err != nil {
+		logger.LogMsg(logger.LogWarning, "Failed to set group chat commands: %v", err)
+	}
+}
+
+func (b *Bot) runPolling(ctx context.Context) error {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
@@ -76,37 +159,55 @@ func (b *Bot) Run(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
-			if update.Message != nil {
-				if !isPrivateChat(update.Message.Chat, update.Message.From) {
-					b.sendPrivateOnlyMessage(update.Message.Chat.ID)
-					continue
-				}
-				if !b.isAuthorized(update.Message.From.ID) {
-					if b.tryHandlePairingCode(update.Message) {
-						continue
-					}
-					b.sendUnauthorizedMessage(update.Message.Chat.ID)
-					continue
-				}
-				b.ensureUser(update.Message.Chat.ID, update.Message.From.ID, b.isAdmin(update.Message.From.ID))
-				b.handleMessage(update.Message)
-			} else if update.CallbackQuery != nil {
-				if update.CallbackQuery.Message != nil && !isPrivateChat(update.CallbackQuery.Message.Chat, update.CallbackQuery.From) {
-					b.sendPrivateOnlyMessage(update.CallbackQuery.Message.Chat.ID)
-					continue
-				}
-				if !b.isAuthorized(update.CallbackQuery.From.ID) {
-					if update.CallbackQuery.Message != nil {
-						b.sendUnauthorizedMessage(update.CallbackQuery.Message.Chat.ID)
-					}
-					continue
-				}
-				if update.CallbackQuery.Message != nil {
-					b.ensureUser(update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From.ID, b.isAdmin(update.CallbackQuery.From.ID))
-				}
-				b.handleCallbackQuery(update.CallbackQuery)
+			b.handleUpdate(update)
+		}
+	}
+}
+
+// handleUpdate applies the chat and auth gates and dispatches a single update.
+// Both polling and webhook mode feed updates through here.
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil {
+		if !isPrivateChat(update.Message.Chat, update.Message.From) {
+			if isGroupChat(update.Message.Chat) {
+				b.handleGroupMessage(update.Message)
+				return
+			}
+			b.sendPrivateOnlyMessage(update.Message.Chat.ID)
+			return
+		}
+		if !b.isAuthorized(update.Message.From.ID) {
+			if b.tryHandlePairingCode(update.Message) {
+				return
+			}
+			b.sendUnauthorizedMessage(update.Message.Chat.ID)
+			return
+		}
+		b.ensureUser(update.Message.Chat.ID, update.Message.From.ID, b.isAdmin(update.Message.From.ID))
+		b.rememberDisplayName(update.Message.Chat.ID, update.Message.From)
+		b.handleMessage(update.Message)
+	} else if update.CallbackQuery != nil {
+		if update.CallbackQuery.Message != nil && !isPrivateChat(update.CallbackQuery.Message.Chat, update.CallbackQuery.From) {
+			b.sendPrivateOnlyMessage(update.CallbackQuery.Message.Chat.ID)
+			return
+		}
+		if !b.isAuthorized(update.CallbackQuery.From.ID) {
+			if update.CallbackQuery.Message != nil {
+				b.sendUnauthorizedMessage(update.CallbackQuery.Message.Chat.ID)
 			}
+			return
+		}
+		if update.CallbackQuery.Message != nil {
+			b.ensureUser(update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From.ID, b.isAdmin(update.CallbackQuery.From.ID))
+			b.rememberDisplayName(update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.From)
 		}
+		b.handleCallbackQuery(update.CallbackQuery)
+	} else if update.InlineQuery != nil {
+		// Inline queries come from any chat; handleInlineQuery applies the auth gate. They
+		// can wait seconds on MangaDex, so they don't hold up the update loop.
+		go b.handleInlineQuery(update.InlineQuery)
+	} else if update.ChosenInlineResult != nil {
+		b.handleChosenInlineResult(update.ChosenInlineResult)
 	}
 }
 
@@ -122,7 +223,10 @@ func (b *Bot) ensureUser(chatID int64, fromUserID int64, isAdmin bool) {
 }
 
 func (b *Bot) isAuthorized(userID int64) bool {
-	if _, ok := b.authorizedCache[userID]; ok {
+	b.authMu.Lock()
+	_, cached := b.authorizedCache[userID]
+	b.authMu.Unlock()
+	if cached {
 		return true
 	}
 	if b.isAdmin(userID) {
@@ -134,11 +238,23 @@ func (b *Bot) isAuthorized(userID int64) bool {
 		return false
 	}
 	if ok {
-		b.authorizedCache[userID] = struct{}{}
+		b.rememberAuthorized(userID)
 	}
 	return ok
 }
 
+func (b *Bot) rememberAuthorized(userID int64) {
+	b.authMu.Lock()
+	defer b.authMu.Unlock()
+	b.authorizedCache[userID] = struct{}{}
+}
+
+func (b *Bot) forgetAuthorized(userID int64) {
+	b.authMu.Lock()
+	defer b.authMu.Unlock()
+	delete(b.authorizedCache, userID)
+}
+
 func (b *Bot) isAdmin(userID int64) bool {
 	return userID == b.config.AdminUserID
 }
@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"strings"
+	"unicode"
+
+	"releasenojutsu/internal/db"
+)
+
+// How well a query names a title, best last; 0 is no match.
+const (
+	matchNone = iota
+	matchFuzzy
+	matchWords
+	matchPrefix
+	matchExact
+)
+
+// normalizeTitle lower-cases s and turns everything but letters and digits into single
+// spaces, so "Frieren: Beyond Journey's End" and "frieren beyond journey s end" compare equal.
+func normalizeTitle(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// editDistance is the optimal string alignment distance between a and b: insertions,
+// deletions, substitutions and swaps of neighbouring letters each cost one.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// typoAllowance is how many typos a query word of n letters may contain.
+func typoAllowance(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// titleMatchScore rates how well query names title. A query made of the title's initials
+// ("op" for "One Piece") counts as a word match.
+func titleMatchScore(query, title string) int {
+	q, t := normalizeTitle(query), normalizeTitle(title)
+	if q == "" || t == "" {
+		return matchNone
+	}
+	compactQ, compactT := strings.ReplaceAll(q, " ", ""), strings.ReplaceAll(t, " ", "")
+	switch {
+	case q == t || compactQ == compactT:
+		return matchExact
+	case strings.HasPrefix(t, q) || strings.HasPrefix(compactT, compactQ):
+		return matchPrefix
+	}
+
+	titleWords := strings.Fields(t)
+	if len(titleWords) > 1 && !strings.Contains(q, " ") {
+		var initials strings.Builder
+		for _, w := range titleWords {
+			initials.WriteRune([]rune(w)[0])
+		}
+		if q == initials.String() {
+			return matchWords
+		}
+	}
+	words, fuzzy := true, true
+	for _, qw := range strings.Fields(q) {
+		contained, near := false, false
+		for _, tw := range titleWords {
+			if strings.Contains(tw, qw) {
+				contained, near = true, true
+				break
+			}
+			// Compare against the word's start as well, so a typo in a partial word still matches.
+			allowed := typoAllowance(len([]rune(qw)))
+			if editDistance(qw, tw) <= allowed || (len(tw) > len(qw) && editDistance(qw, string([]rune(tw)[:len([]rune(qw))])) <= allowed) {
+				near = true
+			}
+		}
+		words = words && contained
+		fuzzy = fuzzy && near
+	}
+	switch {
+	case words:
+		return matchWords
+	case fuzzy:
+		return matchFuzzy
+	default:
+		return matchNone
+	}
+}
+
+// matchTitles returns the titles that best match query by name or by one of their
+// aliases (keyed by manga ID), keeping their order. It is empty when nothing matches.
+func matchTitles(query string, manga []db.Manga, aliases map[int][]string) []db.Manga {
+	best := matchNone
+	var matches []db.Manga
+	for _, m := range manga {
+		score := titleMatchScore(query, m.Title)
+		for _, alias := range aliases[m.ID] {
+			score = max(score, titleMatchScore(query, alias))
+		}
+		switch {
+		case score == matchNone || score < best:
+			continue
+		case score > best:
+			best, matches = score, nil
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
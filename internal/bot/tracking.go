@@ -0,0 +1,152 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+)
+
+// maxExtrasShown caps the extras view; the newest ones come first.
+const maxExtrasShown = 20
+
+func trackingLabel(exact bool) string {
+	if exact {
+		return appcopy.Copy.Labels.TrackingExact
+	}
+	return appcopy.Copy.Labels.TrackingWatermark
+}
+
+// pickChapterFormat returns the chapter picker text for the title's tracking mode: with
+// the watermark a pick also covers the chapters before (read) or after (unread) it.
+func (b *Bot) pickChapterFormat(mangaID int, read bool) string {
+	exact, _ := b.db.IsExactTracking(mangaID)
+	switch {
+	case read && exact:
+		return appcopy.Copy.Info.PickChapterReadExact
+	case read:
+		return appcopy.Copy.Info.PickChapterRead
+	case exact:
+		return appcopy.Copy.Info.PickChapterUnreadExact
+	default:
+		return appcopy.Copy.Info.PickChapterUnread
+	}
+}
+
+// handleSetExactTracking switches the title's progress model and re-renders its menu.
+func (b *Bot) handleSetExactTracking(chatID int64, userID int64, mangaID int, exact bool, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	b.logAction(userID, "Set exact tracking", fmt.Sprintf("Manga ID: %d, Exact: %t", mangaID, exact))
+
+	if err := b.db.SetExactTracking(mangaID, userID, exact); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+			b.sendListScopedMessage(msg, cbTarget)
+			return
+		}
+		userLog(userID, mangaID).Error("Error setting tracking mode", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateTracking)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.sendMangaActionMenu(chatID, userID, mangaID, cbTarget)
+}
+
+// sendExtras lists the title's chapters without a number. They can only be marked read
+// with exact tracking; the watermark has nowhere to put them.
+func (b *Bot) sendExtras(chatID int64, userID int64, mangaID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	title, err := b.db.GetMangaTitle(mangaID, userID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error getting manga title", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	exact, err := b.db.IsExactTracking(mangaID)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error loading tracking mode", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	extras, err := b.db.ListExtraChapters(mangaID, maxExtrasShown)
+	if err != nil {
+		userLog(userID, mangaID).Error("Error listing extras", "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.CannotLoadManga)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+
+	text := fmt.Sprintf(appcopy.Copy.Info.ExtrasTextWatermark, html.EscapeString(title))
+	if exact {
+		text = fmt.Sprintf(appcopy.Copy.Info.ExtrasText, html.EscapeString(title))
+	}
+	if len(extras) == 0 {
+		text += appcopy.Copy.Info.ExtrasEmpty
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if exact {
+		for _, e := range extras {
+			name := strings.TrimSpace(e.Title)
+			if name == "" {
+				name = appcopy.Copy.Labels.ExtraUntitled
+			}
+			format := appcopy.Copy.Labels.ExtraUnread
+			if e.Read {
+				format = appcopy.Copy.Labels.ExtraRead
+			}
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf(format, name), cbToggleExtra(mangaID, e.ID)),
+			))
+		}
+	} else if len(extras) > 0 {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(appcopy.Copy.Buttons.UseExactTracking, cbMangaAction(mangaID, "track_exact")),
+		))
+	}
+	keyboard = appendBackToMangaRow(keyboard, mangaID)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.sendMessageWithMainMenuButton(msg, cbTarget)
+}
+
+// handleToggleExtra flips the read state of one extra and re-renders the extras view.
+func (b *Bot) handleToggleExtra(chatID int64, userID int64, mangaID int, chapterID int, target ...*callbackEditTarget) {
+	cbTarget := firstCallbackTarget(target...)
+	allowed, err := b.db.MangaBelongsToUser(mangaID, userID)
+	if err != nil || !allowed {
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Prompts.NoAccessToManga)
+		b.sendListScopedMessage(msg, cbTarget)
+		return
+	}
+
+	number, err := b.db.GetChapterNumber(mangaID, chapterID)
+	var read bool
+	if err == nil {
+		read, err = b.db.IsChapterRead(mangaID, number)
+	}
+	if err == nil {
+		if read {
+			err = b.db.MarkChapterAsUnread(mangaID, number)
+		} else {
+			err = b.db.MarkChapterAsRead(mangaID, number)
+		}
+	}
+	if err != nil {
+		userLog(userID, mangaID).Error("Error toggling extra", "chapter_id", chapterID, "error", err)
+		msg := tgbotapi.NewMessage(chatID, appcopy.Copy.Errors.CannotUpdateChapter)
+		b.sendMangaScopedMessage(msg, mangaID, cbTarget)
+		return
+	}
+	b.logAction(userID, "Toggle extra", fmt.Sprintf("Manga ID: %d, Chapter: %s, Read: %t", mangaID, number, !read))
+	b.sendExtras(chatID, userID, mangaID, cbTarget)
+}
@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExactTracking_OutOfOrderReadsAndExtras(t *testing.T) {
+	b, database, api := setupBotForMessageTests(t)
+	userID := int64(42)
+	if err := database.EnsureUser(userID, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	id64, err := database.AddManga("md-1", "Side Stories", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3", "extra:abc"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%q): %v", number, err)
+		}
+	}
+
+	b.handleMangaSelection(userID, userID, mangaID, "track_exact")
+	if !hasCallback(messageCallbacks(t, api.lastMessageConfig(t)), cbMangaAction(mangaID, "track_watermark")) {
+		t.Fatalf("action menu should offer switching back to the watermark")
+	}
+
+	b.handleMarkChapterAsRead(userID, userID, mangaID, "3")
+	if got := api.lastMessageText(t); !strings.Contains(got, "Chapter <b>3</b>") {
+		t.Fatalf("mark read reply=%q, want the exact-tracking result", got)
+	}
+	if read, err := database.IsChapterRead(mangaID, "1"); err != nil || read {
+		t.Fatalf("chapter 1 read=%t err=%v, want unread after reading chapter 3", read, err)
+	}
+
+	b.sendExtras(userID, userID, mangaID)
+	callbacks := messageCallbacks(t, api.lastMessageConfig(t))
+	var toggle string
+	for _, cb := range callbacks {
+		if strings.HasPrefix(cb, "x_toggle:") {
+			toggle = cb
+		}
+	}
+	if toggle == "" {
+		t.Fatalf("extras callbacks=%v, want a toggle for the extra", callbacks)
+	}
+	payload, err := parseCallbackData(toggle)
+	if err != nil {
+		t.Fatalf("parseCallbackData(%q): %v", toggle, err)
+	}
+	b.handleToggleExtra(userID, userID, mangaID, payload.Value)
+	if read, err := database.IsChapterRead(mangaID, "extra:abc"); err != nil || !read {
+		t.Fatalf("extra read=%t err=%v, want read", read, err)
+	}
+	if got := api.lastMessageText(t); !strings.Contains(got, "Extras") {
+		t.Fatalf("toggle reply=%q, want the extras view", got)
+	}
+}
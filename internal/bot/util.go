@@ -1,7 +1,20 @@
 package bot
 
-import "releasenojutsu/internal/logger"
+import (
+	"log/slog"
+
+	"releasenojutsu/internal/logger"
+)
 
 func (b *Bot) logAction(userID int64, action, details string) {
-	logger.LogMsg(logger.LogInfo, "[User: %d] [%s] %s", userID, action, details)
+	userLog(userID).Info(action, "details", details)
+}
+
+// userLog returns a logger tagged with the acting user and, when given, the manga row.
+func userLog(userID int64, mangaID ...int) *slog.Logger {
+	l := logger.L().With(logger.KeyUserID, userID)
+	if len(mangaID) > 0 {
+		l = l.With(logger.KeyMangaID, mangaID[0])
+	}
+	return l
 }
@@ -0,0 +1,167 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/logger"
+)
+
+const (
+	webhookSecretHeader   = "X-Telegram-Bot-Api-Secret-Token"
+	webhookMaxBodyBytes   = 1 << 20
+	webhookShutdownPeriod = 10 * time.Second
+)
+
+// WebhookAPI is implemented by *tgbotapi.BotAPI. The library's WebhookConfig has no
+// secret_token field, so setWebhook is sent as a raw request.
+type WebhookAPI interface {
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+}
+
+// runWebhook registers the webhook with Telegram, serves it until ctx is cancelled
+// and removes it again on shutdown. Updates already acknowledged with 200 are
+// dispatched before it returns, since Telegram will not resend them.
+func (b *Bot) runWebhook(ctx context.Context) error {
+	webhookURL, err := url.Parse(b.config.WebhookURL)
+	if err != nil {
+		return fmt.Errorf("parse webhook url: %w", err)
+	}
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	listener, err := net.Listen("tcp", b.config.WebhookListenAddr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", b.config.WebhookListenAddr, err)
+	}
+
+	updates := make(chan tgbotapi.Update, 100)
+	mux := http.NewServeMux()
+	mux.Handle(path, b.webhookHandler(updates))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	if err := b.setWebhook(); err != nil {
+		_ = server.Close()
+		return err
+	}
+	logger.LogMsg(logger.LogInfo, "Webhook mode: listening on %s%s", listener.Addr(), path)
+
+	defer b.shutdownWebhook(server, updates)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-serveErr:
+			if ok && err != nil {
+				return fmt.Errorf("webhook server: %w", err)
+			}
+			return nil
+		case update := <-updates:
+			b.handleUpdate(update)
+		}
+	}
+}
+
+func (b *Bot) setWebhook() error {
+	api, ok := b.api.(WebhookAPI)
+	if !ok {
+		return fmt.Errorf("telegram client does not support webhook registration")
+	}
+
+	params := tgbotapi.Params{}
+	params["url"] = b.config.WebhookURL
+	params["secret_token"] = b.config.WebhookSecret
+	if _, err := api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("setWebhook: %w", err)
+	}
+	return nil
+}
+
+// shutdownWebhook stops the server, keeps dispatching while in-flight handlers hand
+// over their updates, drains whatever is left in the buffer and only then deletes the
+// webhook.
+func (b *Bot) shutdownWebhook(server *http.Server, updates <-chan tgbotapi.Update) {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownPeriod)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.LogMsg(logger.LogWarning, "Webhook server shutdown: %v", err)
+		}
+	}()
+
+dispatch:
+	for {
+		select {
+		case update := <-updates:
+			b.handleUpdate(update)
+		case <-stopped:
+			break dispatch
+		}
+	}
+	// No handler can send any more; this goroutine is the only receiver.
+	for len(updates) > 0 {
+		b.handleUpdate(<-updates)
+	}
+
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		logger.LogMsg(logger.LogWarning, "Failed to delete webhook: %v", err)
+	}
+}
+
+// webhookHandler accepts Telegram update POSTs carrying the configured secret token and
+// forwards them to updates. Dispatch stays on the Run goroutine, same as polling.
+func (b *Bot) webhookHandler(updates chan<- tgbotapi.Update) http.Handler {
+	secret := []byte(b.config.WebhookSecret)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := []byte(r.Header.Get(webhookSecretHeader))
+		if subtle.ConstantTimeCompare(got, secret) != 1 {
+			logger.LogMsg(logger.LogWarning, "Rejected webhook request from %s: bad secret token", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webhookMaxBodyBytes)).Decode(&update); err != nil {
+			http.Error(w, "invalid update", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			// Telegram retries undelivered updates.
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	})
+}
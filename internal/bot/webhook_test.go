@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/config"
+)
+
+const testWebhookSecret = "test_secret"
+
+type webhookTelegramAPI struct {
+	runTelegramAPI
+	rawMu    sync.Mutex
+	raw      []string
+	rawParam []tgbotapi.Params
+}
+
+func (f *webhookTelegramAPI) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	f.rawMu.Lock()
+	defer f.rawMu.Unlock()
+	f.raw = append(f.raw, endpoint)
+	f.rawParam = append(f.rawParam, params)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *webhookTelegramAPI) deleteWebhookRequested() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.requested {
+		if _, ok := c.(tgbotapi.DeleteWebhookConfig); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func postUpdate(t *testing.T, url string, secret string, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest(): %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(webhookSecretHeader, secret)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", url, err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func freeLocalAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen(): %v", err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	return addr
+}
+
+func TestWebhookHandler_RejectsBadRequests(t *testing.T) {
+	b, _, _ := setupBotForMessageTests(t)
+	b.config.WebhookSecret = testWebhookSecret
+	updates := make(chan tgbotapi.Update, 1)
+	srv := httptest.NewServer(b.webhookHandler(updates))
+	t.Cleanup(srv.Close)
+
+	if code := postUpdate(t, srv.URL, "", `{"update_id":1}`); code != http.StatusForbidden {
+		t.Fatalf("missing secret status=%d, want 403", code)
+	}
+	if code := postUpdate(t, srv.URL, "wrong", `{"update_id":1}`); code != http.StatusForbidden {
+		t.Fatalf("wrong secret status=%d, want 403", code)
+	}
+	if code := postUpdate(t, srv.URL, testWebhookSecret, `{not json`); code != http.StatusBadRequest {
+		t.Fatalf("bad body status=%d, want 400", code)
+	}
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status=%d, want 405", resp.StatusCode)
+	}
+
+	select {
+	case u := <-updates:
+		t.Fatalf("unexpected update forwarded: %+v", u)
+	default:
+	}
+}
+
+func TestWebhookHandler_ForwardsUpdateToDispatch(t *testing.T) {
+	b, _, api := setupBotForMessageTests(t)
+	b.config.WebhookSecret = testWebhookSecret
+	updates := make(chan tgbotapi.Update, 1)
+	srv := httptest.NewServer(b.webhookHandler(updates))
+	t.Cleanup(srv.Close)
+
+	body := `{"update_id":7,"message":{"message_id":1,"text":"hello","from":{"id":42},"chat":{"id":-100,"type":"group"}}}`
+	if code := postUpdate(t, srv.URL, testWebhookSecret, body); code != http.StatusOK {
+		t.Fatalf("status=%d, want 200", code)
+	}
+
+	update := <-updates
+	if update.UpdateID != 7 || update.Message == nil || update.Message.Text != "hello" {
+		t.Fatalf("decoded update mismatch: %+v", update)
+	}
+	b.handleUpdate(update)
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.PrivateChatOnly {
+		t.Fatalf("message=%q, want %q", got, appcopy.Copy.Prompts.PrivateChatOnly)
+	}
+}
+
+func TestRun_WebhookModeLifecycle(t *testing.T) {
+	api := &webhookTelegramAPI{}
+	b, _ := setupBotForRunTests(t, api)
+	addr := freeLocalAddr(t)
+	b.config = &config.Config{
+		AdminUserID:       1,
+		BotMode:           config.BotModeWebhook,
+		WebhookURL:        "https://bot.example.com/tg/hook",
+		WebhookListenAddr: addr,
+		WebhookSecret:     testWebhookSecret,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Run(ctx)
+	}()
+
+	waitUntil(t, 2*time.Second, func() bool {
+		api.rawMu.Lock()
+		defer api.rawMu.Unlock()
+		return len(api.raw) > 0
+	})
+	api.rawMu.Lock()
+	if api.raw[0] != "setWebhook" || api.rawParam[0]["url"] != "https://bot.example.com/tg/hook" || api.rawParam[0]["secret_token"] != testWebhookSecret {
+		api.rawMu.Unlock()
+		t.Fatalf("setWebhook request mismatch: %v %v", api.raw, api.rawParam)
+	}
+	api.rawMu.Unlock()
+
+	body := `{"update_id":1,"message":{"message_id":1,"text":"hello","from":{"id":42},"chat":{"id":-100,"type":"group"}}}`
+	if code := postUpdate(t, "http://"+addr+"/tg/hook", testWebhookSecret, body); code != http.StatusOK {
+		t.Fatalf("status=%d, want 200", code)
+	}
+	waitUntil(t, time.Second, func() bool { return api.sentCount() > 0 })
+	if got := api.lastMessageText(t); got != appcopy.Copy.Prompts.PrivateChatOnly {
+		t.Fatalf("message=%q, want %q", got, appcopy.Copy.Prompts.PrivateChatOnly)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run(): %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not stop in time")
+	}
+	if !api.deleteWebhookRequested() {
+		t.Fatal("expected deleteWebhook on shutdown")
+	}
+}
+
+func TestRun_WebhookModeRequiresRawRequests(t *testing.T) {
+	api := &runTelegramAPI{}
+	b, _ := setupBotForRunTests(t, api)
+	b.config = &config.Config{
+		AdminUserID:       1,
+		BotMode:           config.BotModeWebhook,
+		WebhookURL:        "https://bot.example.com/hook",
+		WebhookListenAddr: freeLocalAddr(t),
+		WebhookSecret:     testWebhookSecret,
+	}
+	if err := b.Run(context.Background()); err == nil {
+		t.Fatal("Run() expected error when the client cannot register webhooks")
+	}
+}
+
+func TestShutdownWebhook_DispatchesAcknowledgedUpdates(t *testing.T) {
+	api := &webhookTelegramAPI{}
+	b, _ := setupBotForRunTests(t, api)
+
+	updates := make(chan tgbotapi.Update, 100)
+	for i := 1; i <= 3; i++ {
+		updates <- tgbotapi.Update{
+			UpdateID: i,
+			Message: &tgbotapi.Message{
+				MessageID: i,
+				Text:      "hello",
+				From:      &tgbotapi.User{ID: 42},
+				Chat:      &tgbotapi.Chat{ID: -100, Type: "group"},
+			},
+		}
+	}
+
+	b.shutdownWebhook(&http.Server{}, updates)
+
+	if got := api.sentCount(); got != 3 {
+		t.Fatalf("sent=%d, want 3 buffered updates dispatched before shutdown", got)
+	}
+	if len(updates) != 0 {
+		t.Fatalf("updates left in buffer: %d", len(updates))
+	}
+	if !api.deleteWebhookRequested() {
+		t.Fatal("expected deleteWebhook on shutdown")
+	}
+}
@@ -2,13 +2,33 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/source"
+)
+
+// Bot update modes.
+const (
+	BotModePolling = "polling"
+	BotModeWebhook = "webhook"
 )
 
+const defaultWebhookListenAddr = ":8080"
+
+// DefaultDatabasePath is where the bot keeps its SQLite database.
+const DefaultDatabasePath = "database/ReleaseNoJutsu.db"
+
+// Telegram only accepts A-Z, a-z, 0-9, _ and - in webhook secret tokens.
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 // Config holds the application configuration
 
 type Config struct {
@@ -16,6 +36,28 @@ type Config struct {
 	AllowedUsers     []int64
 	AdminUserID      int64
 	DatabasePath     string
+
+	// BotMode selects how updates are received: long polling (default) or webhook.
+	BotMode           string
+	WebhookURL        string
+	WebhookListenAddr string
+	WebhookSecret     string
+
+	// MetricsListenAddr enables the /healthz, /readyz and /metrics server when set.
+	MetricsListenAddr string
+
+	LogFormat     string
+	LogLevel      string
+	LogDir        string
+	LogMaxSizeMB  int
+	LogMaxAgeDays int
+
+	// RunHistoryDays is how long scheduler run history is kept (0 keeps it forever).
+	RunHistoryDays int
+
+	// RSSChapterPatterns are extra rules ("regex" or "host=regex") for reading chapter
+	// numbers out of RSS/Atom item titles.
+	RSSChapterPatterns []string
 }
 
 // Load loads the configuration from environment variables
@@ -46,14 +88,95 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("TELEGRAM_ALLOWED_USERS is required (at least 1 user id)")
 	}
 
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("TELEGRAM_MODE")))
+	if mode == "" {
+		mode = BotModePolling
+	}
+	listenAddr := strings.TrimSpace(os.Getenv("TELEGRAM_WEBHOOK_LISTEN"))
+	if listenAddr == "" {
+		listenAddr = defaultWebhookListenAddr
+	}
+
+	logMaxSizeMB, err := intEnv("LOG_MAX_SIZE_MB", 10)
+	if err != nil {
+		return nil, err
+	}
+	logMaxAgeDays, err := intEnv("LOG_MAX_AGE_DAYS", 14)
+	if err != nil {
+		return nil, err
+	}
+	runHistoryDays, err := intEnv("RUN_HISTORY_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+	var rssPatterns []string
+	for _, p := range strings.Split(os.Getenv("RSS_CHAPTER_PATTERNS"), ";") {
+		if p = strings.TrimSpace(p); p != "" {
+			rssPatterns = append(rssPatterns, p)
+		}
+	}
+	logDir := strings.TrimSpace(os.Getenv("LOG_DIR"))
+	if logDir == "" {
+		logDir = "logs"
+	}
+
 	return &Config{
-		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
-		AllowedUsers:     allowedUsers,
-		AdminUserID:      allowedUsers[0],
-		DatabasePath:     "database/ReleaseNoJutsu.db",
+		TelegramBotToken:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		AllowedUsers:      allowedUsers,
+		AdminUserID:       allowedUsers[0],
+		DatabasePath:      DefaultDatabasePath,
+		BotMode:           mode,
+		WebhookURL:        strings.TrimSpace(os.Getenv("TELEGRAM_WEBHOOK_URL")),
+		WebhookListenAddr: listenAddr,
+		WebhookSecret:     strings.TrimSpace(os.Getenv("TELEGRAM_WEBHOOK_SECRET")),
+		MetricsListenAddr: strings.TrimSpace(os.Getenv("METRICS_LISTEN")),
+		LogFormat:         strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))),
+		LogLevel:          strings.ToLower(strings.TrimSpace(os.Getenv("LOG_LEVEL"))),
+		LogDir:            logDir,
+		LogMaxSizeMB:      logMaxSizeMB,
+		LogMaxAgeDays:     logMaxAgeDays,
+		RunHistoryDays:    runHistoryDays,
+
+		RSSChapterPatterns: rssPatterns,
 	}, nil
 }
 
+func intEnv(key string, def int) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, raw)
+	}
+	return n, nil
+}
+
+// LoggerOptions maps the LOG_* settings onto logger.Options.
+func (c *Config) LoggerOptions() (logger.Options, error) {
+	opts := logger.DefaultOptions()
+	level, err := logger.ParseLevel(c.LogLevel)
+	if err != nil {
+		return opts, err
+	}
+	opts.Level = level
+	if c.LogFormat != "" {
+		opts.Format = c.LogFormat
+	}
+	if c.LogDir != "" {
+		opts.Dir = c.LogDir
+	}
+	opts.MaxSizeBytes = int64(c.LogMaxSizeMB) << 20
+	opts.MaxAge = time.Duration(c.LogMaxAgeDays) * 24 * time.Hour
+	return opts, nil
+}
+
+// UseWebhook reports whether updates should be received via webhook instead of long polling.
+func (c *Config) UseWebhook() bool {
+	return c.BotMode == BotModeWebhook
+}
+
 func (c *Config) Validate() error {
 	if strings.TrimSpace(c.TelegramBotToken) == "" {
 		return fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
@@ -67,5 +190,39 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.DatabasePath) == "" {
 		return fmt.Errorf("database path is required")
 	}
+	switch c.LogFormat {
+	case "", logger.FormatText, logger.FormatJSON:
+	default:
+		return fmt.Errorf("LOG_FORMAT must be %q or %q", logger.FormatText, logger.FormatJSON)
+	}
+	if _, err := logger.ParseLevel(c.LogLevel); err != nil {
+		return fmt.Errorf("LOG_LEVEL must be debug, info, warn or error")
+	}
+	if _, err := source.ParseChapterRules(c.RSSChapterPatterns); err != nil {
+		return fmt.Errorf("RSS_CHAPTER_PATTERNS: %v", err)
+	}
+	switch c.BotMode {
+	case "", BotModePolling:
+	case BotModeWebhook:
+		if err := c.validateWebhook(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("TELEGRAM_MODE must be %q or %q", BotModePolling, BotModeWebhook)
+	}
+	return nil
+}
+
+func (c *Config) validateWebhook() error {
+	u, err := url.Parse(c.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("TELEGRAM_WEBHOOK_URL must be an https URL in webhook mode")
+	}
+	if !webhookSecretPattern.MatchString(c.WebhookSecret) {
+		return fmt.Errorf("TELEGRAM_WEBHOOK_SECRET is required in webhook mode (1-256 chars of A-Z, a-z, 0-9, _ or -)")
+	}
+	if strings.TrimSpace(c.WebhookListenAddr) == "" {
+		return fmt.Errorf("TELEGRAM_WEBHOOK_LISTEN is required in webhook mode")
+	}
 	return nil
 }
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func withTempCWD(t *testing.T) string {
@@ -123,3 +125,160 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_WebhookMode(t *testing.T) {
+	withTempCWD(t)
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_ALLOWED_USERS", "123")
+	t.Setenv("TELEGRAM_MODE", "Webhook")
+	t.Setenv("TELEGRAM_WEBHOOK_URL", "https://bot.example.com/telegram")
+	t.Setenv("TELEGRAM_WEBHOOK_SECRET", "s3cret_token")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if !cfg.UseWebhook() {
+		t.Fatalf("BotMode=%q, want webhook", cfg.BotMode)
+	}
+	if cfg.WebhookListenAddr != ":8080" {
+		t.Fatalf("WebhookListenAddr=%q, want :8080", cfg.WebhookListenAddr)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+}
+
+func TestValidate_Webhook(t *testing.T) {
+	base := Config{
+		TelegramBotToken:  "token",
+		AllowedUsers:      []int64{1},
+		AdminUserID:       1,
+		DatabasePath:      "database/ReleaseNoJutsu.db",
+		BotMode:           BotModeWebhook,
+		WebhookURL:        "https://bot.example.com/hook",
+		WebhookListenAddr: ":8080",
+		WebhookSecret:     "secret",
+	}
+	if err := base.Validate(); err != nil {
+		t.Fatalf("Validate(valid): %v", err)
+	}
+
+	cases := map[string]func(c *Config){
+		"unknown mode":   func(c *Config) { c.BotMode = "push" },
+		"http url":       func(c *Config) { c.WebhookURL = "http://bot.example.com/hook" },
+		"missing url":    func(c *Config) { c.WebhookURL = "" },
+		"missing secret": func(c *Config) { c.WebhookSecret = "" },
+		"invalid secret": func(c *Config) { c.WebhookSecret = "not allowed!" },
+		"missing listen": func(c *Config) { c.WebhookListenAddr = "" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			cfg := base
+			mutate(&cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Fatalf("Validate(%s) expected error", name)
+			}
+		})
+	}
+}
+
+func TestLoad_LoggingOptions(t *testing.T) {
+	withTempCWD(t)
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_ALLOWED_USERS", "123")
+	t.Setenv("LOG_FORMAT", "JSON")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_DIR", "var/log")
+	t.Setenv("LOG_MAX_SIZE_MB", "5")
+	t.Setenv("LOG_MAX_AGE_DAYS", "3")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+	opts, err := cfg.LoggerOptions()
+	if err != nil {
+		t.Fatalf("LoggerOptions(): %v", err)
+	}
+	if opts.Format != "json" || opts.Level != slog.LevelDebug || opts.Dir != "var/log" {
+		t.Fatalf("opts=%+v", opts)
+	}
+	if opts.MaxSizeBytes != 5<<20 || opts.MaxAge != 3*24*time.Hour {
+		t.Fatalf("rotation opts=%+v", opts)
+	}
+
+	t.Setenv("LOG_MAX_SIZE_MB", "big")
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() expected error for invalid LOG_MAX_SIZE_MB")
+	}
+}
+
+func TestValidate_LoggingSettings(t *testing.T) {
+	base := Config{
+		TelegramBotToken: "token",
+		AllowedUsers:     []int64{1},
+		AdminUserID:      1,
+		DatabasePath:     "database/ReleaseNoJutsu.db",
+	}
+	bad := base
+	bad.LogFormat = "xml"
+	if err := bad.Validate(); err == nil {
+		t.Fatal("Validate() expected error for LOG_FORMAT=xml")
+	}
+	bad = base
+	bad.LogLevel = "loud"
+	if err := bad.Validate(); err == nil {
+		t.Fatal("Validate() expected error for LOG_LEVEL=loud")
+	}
+}
+
+func TestLoad_RunHistoryDays(t *testing.T) {
+	withTempCWD(t)
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_ALLOWED_USERS", "123")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if cfg.RunHistoryDays != 30 {
+		t.Fatalf("RunHistoryDays=%d, want default 30", cfg.RunHistoryDays)
+	}
+
+	t.Setenv("RUN_HISTORY_DAYS", "7")
+	if cfg, err = Load(); err != nil || cfg.RunHistoryDays != 7 {
+		t.Fatalf("RunHistoryDays=%v err=%v, want 7", cfg, err)
+	}
+
+	t.Setenv("RUN_HISTORY_DAYS", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() expected error for negative RUN_HISTORY_DAYS")
+	}
+}
+
+func TestLoad_RSSChapterPatterns(t *testing.T) {
+	withTempCWD(t)
+	t.Setenv("TELEGRAM_BOT_TOKEN", "token")
+	t.Setenv("TELEGRAM_ALLOWED_USERS", "123")
+	t.Setenv("RSS_CHAPTER_PATTERNS", ` example.com=Episode (\d+) ; ;#(\d+)`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	if len(cfg.RSSChapterPatterns) != 2 || cfg.RSSChapterPatterns[0] != `example.com=Episode (\d+)` || cfg.RSSChapterPatterns[1] != `#(\d+)` {
+		t.Fatalf("RSSChapterPatterns=%q", cfg.RSSChapterPatterns)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate(): %v", err)
+	}
+
+	cfg.RSSChapterPatterns = []string{"Chapter \\d+"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() expected error for a pattern without a capture group")
+	}
+}
@@ -0,0 +1,73 @@
+package cron
+
+import (
+	"context"
+	"time"
+
+	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/updater"
+)
+
+// BacklogReportSpec sends the weekly backlog report on Monday mornings (server time).
+const BacklogReportSpec = "0 9 * * 1"
+
+// YearRecapSpec sends the yearly reading recap on the morning of December 31 (server time).
+const YearRecapSpec = "0 10 31 12 *"
+
+// SendBacklogReports sends the backlog report to every opted-in user with at least one
+// title over its unread warning, and returns how many reports went out.
+func (s *Scheduler) SendBacklogReports(ctx context.Context) int {
+	users, err := s.DB.ListBacklogReportUsers()
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list backlog report users", "error", err)
+		return 0
+	}
+
+	sent := 0
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		entries, err := s.DB.ListBacklog(userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to load backlog", logger.KeyUserID, userID, "error", err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if err := s.Notifier.SendHTML(userID, updater.FormatBacklogReportHTML(entries)); err != nil {
+			logger.FromContext(ctx).Warn("Failed to send backlog report", logger.KeyUserID, userID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// SendYearRecaps sends the recap of now's year to every user who read something in it,
+// and returns how many recaps went out.
+func (s *Scheduler) SendYearRecaps(ctx context.Context, now time.Time) int {
+	if s.YearRecaps == nil {
+		return 0
+	}
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	users, err := s.DB.ListReadingActivityUsers(from, now)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list year recap users", "error", err)
+		return 0
+	}
+
+	sent := 0
+	for _, userID := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.YearRecaps.SendYearRecap(userID, now.Year()); err != nil {
+			logger.FromContext(ctx).Warn("Failed to send year recap", logger.KeyUserID, userID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
@@ -2,6 +2,9 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -9,10 +12,75 @@ import (
 
 	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/metrics"
 	"releasenojutsu/internal/notify"
 	"releasenojutsu/internal/updater"
 )
 
+// UpdateInterval is how often the scheduled update runs.
+const UpdateInterval = 6 * time.Hour
+
+// DefaultHistoryRetention is how long run history is kept when not configured.
+const DefaultHistoryRetention = 30 * 24 * time.Hour
+
+// Run triggers recorded in the run history.
+const (
+	TriggerStartup = "startup"
+	TriggerCron    = "cron"
+	TriggerManual  = "manual"
+	TriggerUser    = "user"
+)
+
+// runInterruptedPrefix starts the run history error of a run stopped before it finished.
+const runInterruptedPrefix = "interrupted: "
+
+// DefaultFailureAlertAfter is how many consecutive failures a title needs before its
+// owner is told about it.
+const DefaultFailureAlertAfter = 3
+
+// FailureAlerter tells a user that one of their titles keeps failing. *bot.Bot implements it.
+type FailureAlerter interface {
+	AlertTitleFailing(userID int64, mangaID int, title string, failures int, lastErr string) error
+}
+
+// MergeSuggester offers a user the migration of a title whose MangaDex entry was merged
+// into another one. *bot.Bot implements it.
+type MergeSuggester interface {
+	SuggestMangaMigration(userID int64, mangaID int, title, newMangaDexID string) error
+}
+
+// ChapterAlerter sends a new-chapters notification with buttons to mute or snooze the
+// title. *bot.Bot implements it.
+type ChapterAlerter interface {
+	AlertNewChapters(userID int64, mangaID int, html string) error
+}
+
+// RecapSender sends a user the recap of their reading in a year. *bot.Bot implements it.
+type RecapSender interface {
+	SendYearRecap(userID int64, year int) error
+}
+
+// ErrRunInProgress is returned by RunNow when another update run holds the guard.
+var ErrRunInProgress = errors.New("an update run is already in progress")
+
+// RunOptions describes an update run started outside the cron schedule.
+type RunOptions struct {
+	Trigger string
+	// UserID limits the run to one user's titles (0 checks everyone).
+	UserID int64
+	// Progress is called with the number of titles checked so far.
+	Progress func(done, total int)
+}
+
+// RunSummary holds the totals of a finished run.
+type RunSummary struct {
+	TitlesChecked     int
+	NewChapters       int
+	NotificationsSent int
+	Failures          int
+}
+
 // Scheduler manages the cron jobs.
 
 type Scheduler struct {
@@ -21,15 +89,36 @@ type Scheduler struct {
 	Updater  *updater.Updater
 	cron     *cron.Cron
 	running  int32
+
+	// queued is a scheduled run that found the guard taken (for example by a user's
+	// check-all); it starts as soon as the guard is released instead of being dropped.
+	queueMu       sync.Mutex
+	queuedCtx     context.Context
+	queuedTrigger string
+
+	// HistoryRetention prunes run history older than this after each run (0 disables).
+	HistoryRetention time.Duration
+
+	// FailureAlerts, when set, is told once per failure streak that reaches FailureAlertAfter.
+	FailureAlerts     FailureAlerter
+	FailureAlertAfter int
+	// MergeSuggestions, when set, is told once per detected merge target.
+	MergeSuggestions MergeSuggester
+	// ChapterAlerts, when set, sends new-chapter notifications instead of Notifier.
+	ChapterAlerts ChapterAlerter
+	// YearRecaps, when set, sends the yearly reading recap on YearRecapSpec.
+	YearRecaps RecapSender
 }
 
 // NewScheduler creates a new scheduler.
 
 func NewScheduler(db *db.DB, notifier notify.Notifier, upd *updater.Updater) *Scheduler {
 	return &Scheduler{
-		DB:       db,
-		Notifier: notifier,
-		Updater:  upd,
+		DB:                db,
+		Notifier:          notifier,
+		Updater:           upd,
+		HistoryRetention:  DefaultHistoryRetention,
+		FailureAlertAfter: DefaultFailureAlertAfter,
 	}
 }
 
@@ -38,18 +127,38 @@ func (s *Scheduler) Run(ctx context.Context) {
 	s.cron = cron.New()
 	logger.LogMsg(logger.LogInfo, "Scheduler started (runs immediately, then every 6 hours)")
 
-	go s.performUpdate(ctx)
+	go s.performUpdate(ctx, TriggerStartup)
 
-	_, err := s.cron.AddFunc("@every 6h", func() {
+	_, err := s.cron.AddFunc("@every "+UpdateInterval.String(), func() {
 		if ctx.Err() != nil {
 			return
 		}
-		s.performUpdate(ctx)
+		s.performUpdate(ctx, TriggerCron)
 	})
 	if err != nil {
 		logger.LogMsg(logger.LogError, "Failed to set up cron job: %v", err)
 		return
 	}
+	_, err = s.cron.AddFunc(BacklogReportSpec, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		s.SendBacklogReports(ctx)
+	})
+	if err != nil {
+		logger.LogMsg(logger.LogError, "Failed to set up backlog report job: %v", err)
+		return
+	}
+	_, err = s.cron.AddFunc(YearRecapSpec, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		s.SendYearRecaps(ctx, time.Now())
+	})
+	if err != nil {
+		logger.LogMsg(logger.LogError, "Failed to set up year recap job: %v", err)
+		return
+	}
 	s.cron.Start()
 
 	<-ctx.Done()
@@ -60,46 +169,251 @@ func (s *Scheduler) Run(ctx context.Context) {
 	}
 }
 
-func (s *Scheduler) performUpdate(ctx context.Context) {
-	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
-		logger.LogMsg(logger.LogInfo, "Scheduled update skipped (previous run still in progress)")
+func (s *Scheduler) performUpdate(ctx context.Context, trigger string) {
+	if _, err := s.run(ctx, RunOptions{Trigger: trigger}); !errors.Is(err, ErrRunInProgress) {
 		return
 	}
-	defer atomic.StoreInt32(&s.running, 0)
+	s.queueMu.Lock()
+	s.queuedCtx, s.queuedTrigger = ctx, trigger
+	s.queueMu.Unlock()
+	logger.LogMsg(logger.LogInfo, "Scheduled update queued (another run is in progress)")
+	// The other run may have finished before the update was queued.
+	s.startQueued()
+}
 
-	logger.LogMsg(logger.LogInfo, "Starting scheduled update")
+// startQueued starts the queued scheduled run, if any, once the guard is free.
+func (s *Scheduler) startQueued() {
+	if atomic.LoadInt32(&s.running) != 0 {
+		return
+	}
+	s.queueMu.Lock()
+	ctx, trigger := s.queuedCtx, s.queuedTrigger
+	s.queuedCtx, s.queuedTrigger = nil, ""
+	s.queueMu.Unlock()
+	if ctx == nil || ctx.Err() != nil {
+		return
+	}
+	go s.performUpdate(ctx, trigger)
+}
+
+// RunNow runs an update immediately and blocks until it finishes. It shares the guard
+// with scheduled runs, so it returns ErrRunInProgress instead of overlapping one; a
+// scheduled run that finds the guard taken by RunNow starts once it finishes.
+func (s *Scheduler) RunNow(ctx context.Context, opts RunOptions) (RunSummary, error) {
+	if opts.Trigger == "" {
+		opts.Trigger = TriggerManual
+	}
+	return s.run(ctx, opts)
+}
+
+func (s *Scheduler) run(ctx context.Context, opts RunOptions) (RunSummary, error) {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return RunSummary{}, ErrRunInProgress
+	}
+	defer func() {
+		atomic.StoreInt32(&s.running, 0)
+		s.startQueued()
+	}()
 
 	runCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
 	defer cancel()
+	runID := logger.NewRunID()
+	runCtx = logger.WithContext(runCtx, logger.KeyRunID, runID)
+	log := logger.FromContext(runCtx)
 
-	results, err := s.Updater.UpdateAll(runCtx)
+	log.Info("Starting scheduled update", "trigger", opts.Trigger, logger.KeyUserID, opts.UserID)
+	start := time.Now()
+	defer metrics.SchedulerRunDuration.ObserveSince(start)
+
+	historyID, err := s.DB.StartSchedulerRun(runID, opts.Trigger, start)
 	if err != nil {
-		logger.LogMsg(logger.LogError, "Error querying manga for scheduled update: %v", err)
-		return
+		log.Warn("Failed to record run start", "error", err)
+	}
+
+	results, err := s.Updater.UpdateAllWithOptions(runCtx, updater.UpdateOptions{UserID: opts.UserID, Progress: opts.Progress})
+	if err != nil {
+		metrics.SchedulerRuns.Inc("error")
+		log.Error("Error querying manga for scheduled update", "error", err)
+		s.finishHistory(log, historyID, 0, err.Error(), nil)
+		return RunSummary{}, err
 	}
+
+	summary := RunSummary{TitlesChecked: len(results)}
+	titles := make([]db.SchedulerRunTitle, 0, len(results))
 	for _, res := range results {
+		row := db.SchedulerRunTitle{
+			MangaID:     res.MangaID,
+			UserID:      res.UserID,
+			Title:       res.Title,
+			NewChapters: len(res.NewChapters),
+			Duration:    res.Duration,
+		}
+		if res.Err != nil {
+			row.Error = res.Err.Error()
+			summary.Failures++
+		}
+		summary.NewChapters += len(res.NewChapters)
+		titles = append(titles, row)
+
+		resLog := log.With(logger.KeyUserID, res.UserID, logger.KeyMangaID, res.MangaID, logger.KeyMangaDexID, res.SourceID)
 		if res.Err != nil {
-			logger.LogMsg(logger.LogError, "Update failed for manga %s (%s): %v", res.Title, res.MangaDexID, res.Err)
+			resLog.Error("Update failed", "title", res.Title, "failures", res.ConsecutiveFailures, "error", res.Err)
+			// A merge suggestion already tells the owner what to do; skip the generic alert.
+			if !s.maybeSuggestMigration(resLog, res) {
+				s.maybeAlertFailure(resLog, res)
+			}
 			continue
 		}
+		s.maybeSuggestMigration(resLog, res)
 		if len(res.NewChapters) == 0 {
 			continue
 		}
-
-		isMangaPlus, err := s.DB.IsMangaPlus(res.MangaID)
-		if err != nil {
-			isMangaPlus = false
+		// Chapters are still stored and counted; only the message is skipped.
+		if muted, err := s.DB.IsMangaMuted(res.MangaID); err != nil {
+			resLog.Warn("Failed loading category mute", "error", err)
+		} else if muted {
+			resLog.Debug("Skipped notification for muted category", "new", len(res.NewChapters))
+			continue
 		}
-		message := updater.FormatNewChaptersMessageHTML(res.Title, res.NewChapters, res.UnreadCount, isMangaPlus)
+		if batch, err := s.DB.ClaimChapterNotification(res.MangaID, len(res.NewChapters), time.Now()); err != nil {
+			resLog.Warn("Failed loading alert settings", "error", err)
+		} else if batch == 0 {
+			resLog.Debug("Skipped notification for muted, snoozed or batched title", "new", len(res.NewChapters))
+			continue
+		} else {
+			res.NewChapters = s.batchedChapters(resLog, res, batch)
+		}
+
+		message := s.newChaptersMessage(resLog, res)
 		chatID := res.UserID
 		if chatID == 0 {
 			continue
 		}
-		if err := s.Notifier.SendHTML(chatID, message); err != nil {
-			logger.LogMsg(logger.LogError, "Error sending new chapters notification to chat ID %d: %v", chatID, err)
+		if err := s.sendChapterAlert(chatID, res.MangaID, message); err != nil {
+			metrics.NotificationsSent.Inc("failed")
+			resLog.Error("Error sending new chapters notification", "error", err)
+			continue
 		}
+		metrics.NotificationsSent.Inc("sent")
+		summary.NotificationsSent++
+		resLog.Debug("Sent new chapters notification", "new", len(res.NewChapters))
+	}
+
+	// A run cut short by shutdown or its timeout left titles unchecked, so it neither
+	// counts as the last run nor reads as a clean one in the history.
+	if err := runCtx.Err(); err != nil {
+		s.finishHistory(log, historyID, summary.NotificationsSent, runInterruptedPrefix+err.Error(), titles)
+		metrics.SchedulerRuns.Inc("interrupted")
+		log.Warn("Scheduled update interrupted", "titles", len(results), "duration", time.Since(start).Round(time.Millisecond), "error", err)
+		return summary, nil
+	}
+
+	// A single user's check does not stand in for a full run in /status and /readyz.
+	if opts.UserID == 0 {
+		s.DB.UpdateCronLastRun()
+	}
+	s.finishHistory(log, historyID, summary.NotificationsSent, "", titles)
+	metrics.SchedulerRuns.Inc("ok")
+	log.Info("Scheduled update completed", "titles", len(results), "duration", time.Since(start).Round(time.Millisecond))
+	return summary, nil
+}
+
+// newChaptersMessage formats the alert for res. Titles in a group's shared library get
+// the group version, which leaves out unread counts.
+func (s *Scheduler) newChaptersMessage(log *slog.Logger, res updater.Result) string {
+	if db.IsGroupChatID(res.UserID) {
+		return updater.FormatGroupChaptersMessageHTML(res.Title, res.NewChapters)
+	}
+	window, err := s.DB.MangaPlusWindowFor(res.MangaID)
+	if err != nil {
+		log.Warn("Failed loading MANGA Plus window", "error", err)
+	}
+	warnAt, _, err := s.DB.GetMangaUnreadWarning(res.MangaID)
+	if err != nil {
+		log.Warn("Failed loading unread warning threshold", "error", err)
 	}
+	return updater.FormatNewChaptersMessageHTML(res.Title, res.NewChapters, res.UnreadCount, warnAt, window)
+}
 
-	s.DB.UpdateCronLastRun()
-	logger.LogMsg(logger.LogInfo, "Scheduled update completed")
+// batchedChapters returns the chapters an alert covering batch chapters lists. A title that
+// alerts every N chapters releases chapters collected over several runs; the earlier ones
+// are read back from the database.
+func (s *Scheduler) batchedChapters(log *slog.Logger, res updater.Result, batch int) []mangadex.ChapterInfo {
+	if batch <= len(res.NewChapters) {
+		return res.NewChapters
+	}
+	stored, err := s.DB.ListLatestChapters(res.MangaID, batch)
+	if err != nil {
+		log.Warn("Failed loading batched chapters", "error", err)
+		return res.NewChapters
+	}
+	if len(stored) < len(res.NewChapters) {
+		return res.NewChapters
+	}
+	return updater.StoredChapterInfos(stored)
+}
+
+func (s *Scheduler) sendChapterAlert(chatID int64, mangaID int, message string) error {
+	if s.ChapterAlerts != nil {
+		return s.ChapterAlerts.AlertNewChapters(chatID, mangaID, message)
+	}
+	return s.Notifier.SendHTML(chatID, message)
+}
+
+func (s *Scheduler) maybeAlertFailure(log *slog.Logger, res updater.Result) {
+	// Group libraries get no failure alerts: their buttons only work in private chats.
+	if s.FailureAlerts == nil || s.FailureAlertAfter <= 0 || res.ConsecutiveFailures < s.FailureAlertAfter || res.UserID == 0 || db.IsGroupChatID(res.UserID) {
+		return
+	}
+	claimed, err := s.DB.ClaimMangaFailureAlert(res.MangaID)
+	if err != nil {
+		log.Warn("Failed claiming failure alert", "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if err := s.FailureAlerts.AlertTitleFailing(res.UserID, res.MangaID, res.Title, res.ConsecutiveFailures, res.Err.Error()); err != nil {
+		log.Error("Error sending failure alert", "error", err)
+		return
+	}
+	log.Info("Sent failing title alert", "failures", res.ConsecutiveFailures)
+}
+
+// maybeSuggestMigration reports whether res points at a merge target the owner is (or was
+// already) asked about.
+func (s *Scheduler) maybeSuggestMigration(log *slog.Logger, res updater.Result) bool {
+	if s.MergeSuggestions == nil || res.MergedInto == "" || res.UserID == 0 || db.IsGroupChatID(res.UserID) {
+		return false
+	}
+	isNew, err := s.DB.SetMangaMergeCandidate(res.MangaID, res.MergedInto)
+	if err != nil {
+		log.Warn("Failed recording merge candidate", "error", err)
+		return false
+	}
+	if !isNew {
+		return true
+	}
+	if err := s.MergeSuggestions.SuggestMangaMigration(res.UserID, res.MangaID, res.Title, res.MergedInto); err != nil {
+		log.Error("Error sending merge suggestion", "error", err)
+		return false
+	}
+	log.Info("Suggested MangaDex migration", "merged_into", res.MergedInto)
+	return true
+}
+
+func (s *Scheduler) finishHistory(log *slog.Logger, historyID int64, notificationsSent int, runErr string, titles []db.SchedulerRunTitle) {
+	if historyID != 0 {
+		if err := s.DB.FinishSchedulerRun(historyID, time.Now(), notificationsSent, runErr, titles); err != nil {
+			log.Warn("Failed to record run history", "error", err)
+		}
+	}
+	if s.HistoryRetention > 0 {
+		pruned, err := s.DB.PruneSchedulerRuns(time.Now().Add(-s.HistoryRetention))
+		if err != nil {
+			log.Warn("Failed to prune run history", "error", err)
+		} else if pruned > 0 {
+			log.Debug("Pruned run history", "runs", pruned)
+		}
+	}
 }
@@ -3,13 +3,16 @@ package cron
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
 	"releasenojutsu/internal/mangadex"
 	"releasenojutsu/internal/updater"
 )
@@ -83,7 +86,7 @@ func TestPerformUpdate_SkipsOverlappingRun(t *testing.T) {
 
 	done1 := make(chan struct{})
 	go func() {
-		s.performUpdate(context.Background())
+		s.performUpdate(context.Background(), TriggerCron)
 		close(done1)
 	}()
 
@@ -94,7 +97,7 @@ func TestPerformUpdate_SkipsOverlappingRun(t *testing.T) {
 	}
 
 	start := time.Now()
-	s.performUpdate(context.Background())
+	s.performUpdate(context.Background(), TriggerCron)
 	if time.Since(start) > 200*time.Millisecond {
 		t.Fatalf("overlapping performUpdate should return quickly, took %s", time.Since(start))
 	}
@@ -106,3 +109,315 @@ func TestPerformUpdate_SkipsOverlappingRun(t *testing.T) {
 		t.Fatal("first performUpdate did not finish after release")
 	}
 }
+
+func TestPerformUpdate_RecordsRunHistory(t *testing.T) {
+	chTime := time.Now().UTC()
+	s, database, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{
+			Data: []mangadex.Chapter{
+				{Attributes: mangadex.ChapterAttributes{Chapter: "1", Title: "One", PublishedAt: chTime, ReadableAt: chTime, CreatedAt: chTime, UpdatedAt: chTime}},
+			},
+		})
+	})
+
+	s.performUpdate(context.Background(), TriggerManual)
+
+	runs, err := database.ListSchedulerRuns(10)
+	if err != nil {
+		t.Fatalf("ListSchedulerRuns(): %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("runs=%d, want 1", len(runs))
+	}
+	run := runs[0]
+	if run.Trigger != TriggerManual || !run.IsFinished || run.RunID == "" {
+		t.Fatalf("run=%+v", run)
+	}
+	if run.TitlesChecked != 1 || run.NewChapters != 1 || run.NotificationsSent != 1 || run.Failures != 0 {
+		t.Fatalf("run totals=%+v, want 1 checked / 1 new / 1 sent", run)
+	}
+
+	titles, err := database.ListSchedulerRunTitles(run.ID)
+	if err != nil {
+		t.Fatalf("ListSchedulerRunTitles(): %v", err)
+	}
+	if len(titles) != 1 || titles[0].Title != "Dragon Ball Super" || titles[0].NewChapters != 1 || titles[0].Error != "" {
+		t.Fatalf("titles=%+v", titles)
+	}
+}
+
+func TestRunNow_InterruptedRunIsMarkedAndNotCountedAsLastRun(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s, database, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}})
+	})
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.RunNow(ctx, RunOptions{})
+		done <- err
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("run did not start")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("RunNow(): %v", err)
+	}
+
+	runs, err := database.ListSchedulerRuns(10)
+	if err != nil {
+		t.Fatalf("ListSchedulerRuns(): %v", err)
+	}
+	if len(runs) != 1 || !runs[0].IsFinished || !strings.HasPrefix(runs[0].Error, runInterruptedPrefix) {
+		t.Fatalf("runs=%+v, want one finished run marked interrupted", runs)
+	}
+	status, err := database.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus(): %v", err)
+	}
+	if status.HasCronLastRun {
+		t.Fatalf("cron last run recorded for an interrupted run: %v", status.CronLastRun)
+	}
+}
+
+func TestRunNow_UserRunOnlyChecksThatUser(t *testing.T) {
+	chTime := time.Now().UTC()
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{
+			Data: []mangadex.Chapter{
+				{Attributes: mangadex.ChapterAttributes{Chapter: "1", Title: "One", PublishedAt: chTime, ReadableAt: chTime, CreatedAt: chTime, UpdatedAt: chTime}},
+			},
+		})
+	})
+	other := int64(77)
+	if err := database.EnsureUser(other, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if _, err := database.AddManga("a1b2c3d4-0000-0000-0000-000000000000", "Other Title", other); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	var lastDone, lastTotal int
+	summary, err := s.RunNow(context.Background(), RunOptions{
+		Trigger:  TriggerUser,
+		UserID:   chatID,
+		Progress: func(done, total int) { lastDone, lastTotal = done, total },
+	})
+	if err != nil {
+		t.Fatalf("RunNow(): %v", err)
+	}
+	if summary.TitlesChecked != 1 || summary.NewChapters != 1 || summary.NotificationsSent != 1 {
+		t.Fatalf("summary=%+v", summary)
+	}
+	if lastDone != 1 || lastTotal != 1 {
+		t.Fatalf("progress=%d/%d, want 1/1", lastDone, lastTotal)
+	}
+	n := s.Notifier.(*recordingNotifier)
+	if len(n.sent[other]) != 0 {
+		t.Fatalf("other user was notified: %v", n.sent[other])
+	}
+
+	status, err := database.GetStatus()
+	if err != nil {
+		t.Fatalf("GetStatus(): %v", err)
+	}
+	if status.HasCronLastRun {
+		t.Fatal("a single user's run should not update cron_last_run")
+	}
+	runs, err := database.ListSchedulerRuns(1)
+	if err != nil || len(runs) != 1 || runs[0].Trigger != TriggerUser {
+		t.Fatalf("runs=%+v err=%v, want one %q run", runs, err, TriggerUser)
+	}
+}
+
+func TestRunNow_ReturnsErrRunInProgress(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s, _, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		s.performUpdate(context.Background(), TriggerCron)
+		close(done)
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduled run did not start")
+	}
+
+	if _, err := s.RunNow(context.Background(), RunOptions{}); !errors.Is(err, ErrRunInProgress) {
+		t.Fatalf("RunNow() err=%v, want ErrRunInProgress", err)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestPerformUpdate_QueuesBehindUserRun(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = s.RunNow(context.Background(), RunOptions{Trigger: TriggerUser, UserID: chatID})
+		close(done)
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("user run did not start")
+	}
+
+	s.performUpdate(context.Background(), TriggerCron)
+	close(release)
+	<-done
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		runs, err := database.ListSchedulerRuns(5)
+		if err != nil {
+			t.Fatalf("ListSchedulerRuns(): %v", err)
+		}
+		if len(runs) == 2 && runs[0].Trigger == TriggerCron && runs[0].IsFinished {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("runs=%+v, want the queued cron run after the user run", runs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type recordingAlerter struct {
+	alerts []string
+}
+
+func (a *recordingAlerter) AlertTitleFailing(userID int64, mangaID int, title string, failures int, lastErr string) error {
+	a.alerts = append(a.alerts, title)
+	return nil
+}
+
+func TestPerformUpdate_AlertsOwnerOncePerFailureStreak(t *testing.T) {
+	s, database, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"ok","data":[]}`))
+	})
+	alerter := &recordingAlerter{}
+	s.FailureAlerts = alerter
+	s.FailureAlertAfter = 3
+
+	manga, err := database.ListManga()
+	if err != nil || len(manga) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", manga, err)
+	}
+	mangaID := manga[0].ID
+	// Two earlier failures; the updater's own check still succeeds against the fake feed,
+	// so simulate the streak directly and drive maybeAlertFailure.
+	for i := 0; i < 2; i++ {
+		if _, err := database.RecordMangaFailure(mangaID, "404", time.Now()); err != nil {
+			t.Fatalf("RecordMangaFailure(): %v", err)
+		}
+	}
+	res := updater.Result{MangaID: mangaID, UserID: manga[0].UserID, Title: manga[0].Title, Err: errors.New("404"), ConsecutiveFailures: 2}
+	s.maybeAlertFailure(logger.L(), res)
+	if len(alerter.alerts) != 0 {
+		t.Fatalf("alerted below the threshold: %v", alerter.alerts)
+	}
+
+	res.ConsecutiveFailures = 3
+	s.maybeAlertFailure(logger.L(), res)
+	res.ConsecutiveFailures = 4
+	s.maybeAlertFailure(logger.L(), res)
+	if len(alerter.alerts) != 1 {
+		t.Fatalf("alerts=%v, want exactly one per streak", alerter.alerts)
+	}
+}
+
+type recordingSuggester struct {
+	suggested []string
+}
+
+func (s *recordingSuggester) SuggestMangaMigration(userID int64, mangaID int, title, newMangaDexID string) error {
+	s.suggested = append(s.suggested, newMangaDexID)
+	return nil
+}
+
+func TestMaybeSuggestMigration_AsksOncePerTarget(t *testing.T) {
+	s, database, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"ok","data":[]}`))
+	})
+	suggester := &recordingSuggester{}
+	s.MergeSuggestions = suggester
+
+	manga, err := database.ListManga()
+	if err != nil || len(manga) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", manga, err)
+	}
+	res := updater.Result{MangaID: manga[0].ID, UserID: manga[0].UserID, Title: manga[0].Title, MergedInto: "md-target"}
+	for i := 0; i < 2; i++ {
+		if !s.maybeSuggestMigration(logger.L(), res) {
+			t.Fatalf("call %d: maybeSuggestMigration() = false, want handled", i)
+		}
+	}
+	res.MergedInto = "md-other-target"
+	s.maybeSuggestMigration(logger.L(), res)
+	if len(suggester.suggested) != 2 || suggester.suggested[1] != "md-other-target" {
+		t.Fatalf("suggested=%v, want one suggestion per target", suggester.suggested)
+	}
+
+	res.MergedInto = ""
+	if s.maybeSuggestMigration(logger.L(), res) {
+		t.Fatal("a result without merge target should not be handled")
+	}
+}
+
+func TestGroupLibraryResults_SkipProgressAndButtons(t *testing.T) {
+	s, _, _ := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {})
+	alerter := &recordingAlerter{}
+	s.FailureAlerts = alerter
+
+	res := updater.Result{
+		MangaID:             1,
+		UserID:              -1001,
+		Title:               "Shared Title",
+		NewChapters:         []mangadex.ChapterInfo{{Number: "5"}},
+		UnreadCount:         12,
+		Err:                 errors.New("404"),
+		ConsecutiveFailures: 5,
+	}
+	s.maybeAlertFailure(logger.L(), res)
+	if len(alerter.alerts) != 0 {
+		t.Fatalf("group title got a failure alert: %v", alerter.alerts)
+	}
+	if msg := s.newChaptersMessage(logger.L(), res); msg != updater.FormatGroupChaptersMessageHTML(res.Title, res.NewChapters) {
+		t.Fatalf("group message=%q, want the group format", msg)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,7 +67,7 @@ func TestPerformUpdate_DoesNotDeadlockOnSQLite(t *testing.T) {
 
 	done := make(chan struct{}, 1)
 	go func() {
-		s.performUpdate(context.Background())
+		s.performUpdate(context.Background(), TriggerCron)
 		done <- struct{}{}
 	}()
 
@@ -128,7 +129,7 @@ func TestPerformUpdate_SendsNotificationsToRegisteredChats(t *testing.T) {
 	upd := updater.New(database, client, client)
 	s := NewScheduler(database, n, upd)
 
-	s.performUpdate(context.Background())
+	s.performUpdate(context.Background(), TriggerCron)
 
 	if len(n.sent[chatID]) != 1 {
 		t.Fatalf("messages sent to %d = %d, want 1", chatID, len(n.sent[chatID]))
@@ -140,3 +141,243 @@ func TestPerformUpdate_SendsNotificationsToRegisteredChats(t *testing.T) {
 		t.Fatalf("notification missing title line: want contains %q", want)
 	}
 }
+
+func TestSendBacklogReports_OnlyOptedInUsersWithBacklog(t *testing.T) {
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}})
+	})
+	n := s.Notifier.(*recordingNotifier)
+
+	mangas, err := database.ListManga()
+	if err != nil || len(mangas) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", mangas, err)
+	}
+	mangaID := mangas[0].ID
+	// The default threshold only covers MANGA Plus titles until the user picks one.
+	if err := database.SetMangaPlus(mangaID, true); err != nil {
+		t.Fatalf("SetMangaPlus(): %v", err)
+	}
+	now := time.Now()
+	for _, num := range []string{"1", "2", "3", "4"} {
+		if err := database.AddChapter(int64(mangaID), num, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(): %v", err)
+		}
+	}
+	if err := database.RecalculateUnreadCount(mangaID); err != nil {
+		t.Fatalf("RecalculateUnreadCount(): %v", err)
+	}
+
+	// An opted-in user with nothing piling up gets no report.
+	emptyUser := int64(99)
+	if err := database.EnsureUser(emptyUser, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if err := database.SetUserBacklogReport(emptyUser, true); err != nil {
+		t.Fatalf("SetUserBacklogReport(): %v", err)
+	}
+
+	if sent := s.SendBacklogReports(context.Background()); sent != 0 || len(n.sent) != 0 {
+		t.Fatalf("sent=%d messages=%v, want nothing before opting in", sent, n.sent)
+	}
+
+	if err := database.SetUserBacklogReport(chatID, true); err != nil {
+		t.Fatalf("SetUserBacklogReport(): %v", err)
+	}
+	if sent := s.SendBacklogReports(context.Background()); sent != 1 {
+		t.Fatalf("sent=%d, want 1", sent)
+	}
+	if len(n.sent[chatID]) != 1 || !strings.Contains(n.sent[chatID][0], "Dragon Ball Super</b>: 4 unread") {
+		t.Fatalf("report=%v, want the piling title", n.sent[chatID])
+	}
+	if len(n.sent[emptyUser]) != 0 {
+		t.Fatalf("empty backlog user got %v", n.sent[emptyUser])
+	}
+}
+
+func TestPerformUpdate_SkipsMutedCategories(t *testing.T) {
+	chTime := time.Now().Add(-time.Hour).UTC()
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{
+			{Attributes: mangadex.ChapterAttributes{Chapter: "1", PublishedAt: chTime, ReadableAt: chTime, CreatedAt: chTime, UpdatedAt: chTime}},
+		}})
+	})
+	n := s.Notifier.(*recordingNotifier)
+
+	mangas, err := database.ListManga()
+	if err != nil || len(mangas) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", mangas, err)
+	}
+	if err := database.UpdateMangaLastSeenAt(mangas[0].ID, chTime.Add(-24*time.Hour)); err != nil {
+		t.Fatalf("UpdateMangaLastSeenAt(): %v", err)
+	}
+	if err := database.SetMangaCategory(mangas[0].ID, chatID, db.CategoryOnHold); err != nil {
+		t.Fatalf("SetMangaCategory(): %v", err)
+	}
+	if err := database.SetCategoryMuted(chatID, db.CategoryOnHold, true); err != nil {
+		t.Fatalf("SetCategoryMuted(): %v", err)
+	}
+
+	summary, err := s.RunNow(context.Background(), RunOptions{Trigger: TriggerManual})
+	if err != nil {
+		t.Fatalf("RunNow(): %v", err)
+	}
+	if summary.NewChapters != 1 || summary.NotificationsSent != 0 || len(n.sent) != 0 {
+		t.Fatalf("summary=%+v sent=%v, want the chapter stored but no message", summary, n.sent)
+	}
+	if unread, _ := database.CountUnreadChapters(mangas[0].ID); unread != 1 {
+		t.Fatalf("unread=%d, want the muted chapter counted", unread)
+	}
+}
+
+type recordingChapterAlerter struct {
+	alerts []int
+}
+
+func (a *recordingChapterAlerter) AlertNewChapters(userID int64, mangaID int, html string) error {
+	a.alerts = append(a.alerts, mangaID)
+	return nil
+}
+
+func TestPerformUpdate_RespectsNotifyEveryAndUsesChapterAlerter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		chapters []mangadex.Chapter
+	)
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: chapters, Total: len(chapters)})
+	})
+	alerter := &recordingChapterAlerter{}
+	s.ChapterAlerts = alerter
+
+	mangas, err := database.ListManga()
+	if err != nil || len(mangas) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", mangas, err)
+	}
+	mangaID := mangas[0].ID
+	base := time.Now().Add(-time.Hour).UTC()
+	if err := database.UpdateMangaLastSeenAt(mangaID, base.Add(-time.Hour)); err != nil {
+		t.Fatalf("UpdateMangaLastSeenAt(): %v", err)
+	}
+	if err := database.SetMangaNotifyEvery(mangaID, chatID, 2); err != nil {
+		t.Fatalf("SetMangaNotifyEvery(): %v", err)
+	}
+
+	release := func(num string, at time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		chapters = append([]mangadex.Chapter{{Attributes: mangadex.ChapterAttributes{
+			Chapter: num, PublishedAt: at, ReadableAt: at, CreatedAt: at, UpdatedAt: at,
+		}}}, chapters...)
+	}
+
+	release("1", base)
+	if _, err := s.RunNow(context.Background(), RunOptions{Trigger: TriggerManual}); err != nil {
+		t.Fatalf("RunNow(): %v", err)
+	}
+	if len(alerter.alerts) != 0 {
+		t.Fatalf("alerts=%v, want none after the first of two chapters", alerter.alerts)
+	}
+
+	release("2", base.Add(time.Minute))
+	if _, err := s.RunNow(context.Background(), RunOptions{Trigger: TriggerManual}); err != nil {
+		t.Fatalf("RunNow(): %v", err)
+	}
+	if len(alerter.alerts) != 1 || alerter.alerts[0] != mangaID {
+		t.Fatalf("alerts=%v, want one alert once two chapters piled up", alerter.alerts)
+	}
+	if n := s.Notifier.(*recordingNotifier); len(n.sent) != 0 {
+		t.Fatalf("notifier got %v, want the chapter alerter used instead", n.sent)
+	}
+}
+
+func TestPerformUpdate_NotifyEveryAlertListsWholeBatch(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		chapters []mangadex.Chapter
+	)
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: chapters, Total: len(chapters)})
+	})
+	n := s.Notifier.(*recordingNotifier)
+
+	mangas, err := database.ListManga()
+	if err != nil || len(mangas) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", mangas, err)
+	}
+	mangaID := mangas[0].ID
+	base := time.Now().Add(-time.Hour).UTC()
+	if err := database.UpdateMangaLastSeenAt(mangaID, base.Add(-time.Hour)); err != nil {
+		t.Fatalf("UpdateMangaLastSeenAt(): %v", err)
+	}
+	if err := database.SetMangaNotifyEvery(mangaID, chatID, 3); err != nil {
+		t.Fatalf("SetMangaNotifyEvery(): %v", err)
+	}
+
+	for i, num := range []string{"1", "2", "3"} {
+		at := base.Add(time.Duration(i) * time.Minute)
+		mu.Lock()
+		chapters = append([]mangadex.Chapter{{Attributes: mangadex.ChapterAttributes{
+			Chapter: num, PublishedAt: at, ReadableAt: at, CreatedAt: at, UpdatedAt: at,
+		}}}, chapters...)
+		mu.Unlock()
+		if _, err := s.RunNow(context.Background(), RunOptions{Trigger: TriggerManual}); err != nil {
+			t.Fatalf("RunNow(): %v", err)
+		}
+	}
+
+	if len(n.sent[chatID]) != 1 {
+		t.Fatalf("messages=%v, want one alert for the batch of three", n.sent[chatID])
+	}
+	for _, num := range []string{"Ch. 1<", "Ch. 2<", "Ch. 3<"} {
+		if !strings.Contains(n.sent[chatID][0], num) {
+			t.Fatalf("alert %q missing %q", n.sent[chatID][0], num)
+		}
+	}
+}
+
+type recordingRecaps struct {
+	sent map[int64]int
+}
+
+func (r *recordingRecaps) SendYearRecap(userID int64, year int) error {
+	r.sent[userID] = year
+	return nil
+}
+
+func TestSendYearRecaps_OnlyUsersWhoReadThisYear(t *testing.T) {
+	s, database, chatID := setupSchedulerForRunTests(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}})
+	})
+	recaps := &recordingRecaps{sent: make(map[int64]int)}
+
+	now := time.Now()
+	if sent := s.SendYearRecaps(context.Background(), now); sent != 0 {
+		t.Fatalf("SendYearRecaps() without a sender = %d, want 0", sent)
+	}
+	s.YearRecaps = recaps
+
+	mangas, err := database.ListManga()
+	if err != nil || len(mangas) != 1 {
+		t.Fatalf("ListManga()=%v err=%v", mangas, err)
+	}
+	if err := database.AddChapter(int64(mangas[0].ID), "1", "", now, now, now, now); err != nil {
+		t.Fatalf("AddChapter(): %v", err)
+	}
+	if err := database.EnsureUser(99, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if sent := s.SendYearRecaps(context.Background(), now); sent != 0 {
+		t.Fatalf("SendYearRecaps() before any reading = %d, want 0", sent)
+	}
+
+	if err := database.MarkChapterAsRead(mangas[0].ID, "1"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	if sent := s.SendYearRecaps(context.Background(), time.Now()); sent != 1 || recaps.sent[chatID] != now.Year() {
+		t.Fatalf("SendYearRecaps()=%d sent=%v, want one recap to %d", sent, recaps.sent, chatID)
+	}
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Reading categories a title can be filed under. New titles start in CategoryReading.
+const (
+	CategoryReading    = "reading"
+	CategoryPlanToRead = "plan_to_read"
+	CategoryOnHold     = "on_hold"
+	CategoryDropped    = "dropped"
+	CategoryCompleted  = "completed"
+)
+
+// Categories lists the reading categories in display order.
+var Categories = []string{CategoryReading, CategoryPlanToRead, CategoryOnHold, CategoryDropped, CategoryCompleted}
+
+// IsCategory reports whether c is one of Categories.
+func IsCategory(c string) bool {
+	for _, known := range Categories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// SetMangaCategory files one of the user's titles under category. It returns
+// sql.ErrNoRows if the title isn't the user's.
+func (db *DB) SetMangaCategory(mangaID int, userID int64, category string) error {
+	if !IsCategory(category) {
+		return fmt.Errorf("unknown category %q", category)
+	}
+	res, err := db.Exec("UPDATE manga SET category = ? WHERE id = ? AND user_id = ?", category, mangaID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (db *DB) GetMangaCategory(mangaID int) (string, error) {
+	var category string
+	err := db.QueryRow("SELECT category FROM manga WHERE id = ?", mangaID).Scan(&category)
+	return category, err
+}
+
+// CountMangaByCategory returns how many titles the user has in each category. Empty
+// categories are left out.
+func (db *DB) CountMangaByCategory(userID int64) (map[string]int, error) {
+	rows, err := db.Query("SELECT category, COUNT(*) FROM manga WHERE user_id = ? GROUP BY category", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			category string
+			n        int
+		)
+		if err := rows.Scan(&category, &n); err != nil {
+			return nil, err
+		}
+		counts[category] = n
+	}
+	return counts, rows.Err()
+}
+
+// MutedCategories returns the categories the user muted new-chapter notifications for.
+func (db *DB) MutedCategories(userID int64) (map[string]bool, error) {
+	rows, err := db.Query("SELECT category FROM category_mutes WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	muted := make(map[string]bool)
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		muted[category] = true
+	}
+	return muted, rows.Err()
+}
+
+func (db *DB) SetCategoryMuted(userID int64, category string, muted bool) error {
+	if !IsCategory(category) {
+		return fmt.Errorf("unknown category %q", category)
+	}
+	var err error
+	if muted {
+		_, err = db.Exec("INSERT OR IGNORE INTO category_mutes (user_id, category) VALUES (?, ?)", userID, category)
+	} else {
+		_, err = db.Exec("DELETE FROM category_mutes WHERE user_id = ? AND category = ?", userID, category)
+	}
+	return err
+}
+
+// IsMangaMuted reports whether the owner muted notifications for the title's category.
+func (db *DB) IsMangaMuted(mangaID int) (bool, error) {
+	var muted bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM manga
+			INNER JOIN category_mutes ON category_mutes.user_id = manga.user_id AND category_mutes.category = manga.category
+			WHERE manga.id = ?
+		)
+	`, mangaID).Scan(&muted)
+	return muted, err
+}
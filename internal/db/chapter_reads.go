@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// chapterReadSQL is true for chapters the title's owner has read. Titles with exact
+// tracking look the chapter up in chapter_reads; the rest compare it against the
+// last_read_number watermark. It expects chapters and manga in scope under those names.
+const chapterReadSQL = `(CASE WHEN manga.exact_tracking = 1
+			THEN EXISTS (SELECT 1 FROM chapter_reads WHERE chapter_reads.manga_id = chapters.manga_id AND chapter_reads.chapter_number = chapters.chapter_number)
+			ELSE CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		END)`
+
+// ExtraChapter is a chapter without a usable number (a oneshot or side story).
+type ExtraChapter struct {
+	ID     int
+	Number string
+	Title  string
+	Read   bool
+}
+
+func (db *DB) IsExactTracking(mangaID int) (bool, error) {
+	var exact bool
+	err := db.QueryRow("SELECT exact_tracking FROM manga WHERE id = ?", mangaID).Scan(&exact)
+	return exact, err
+}
+
+// SetExactTracking switches one of the user's titles between the watermark and exact
+// tracking without losing progress: switching to exact tracking records every chapter up
+// to the watermark as read, and switching back moves the watermark to the end of the
+// unbroken run of read chapters. Exact reads are kept either way, so switching back and
+// forth restores the same set. The switch is recorded in the reading history. It returns
+// sql.ErrNoRows if the title isn't the user's.
+func (db *DB) SetExactTracking(mangaID int, userID int64, exact bool) error {
+	var current bool
+	if err := db.QueryRow("SELECT exact_tracking FROM manga WHERE id = ? AND user_id = ?", mangaID, userID).Scan(&current); err != nil {
+		return err
+	}
+	if current == exact {
+		return nil
+	}
+	action := HistoryActionWatermark
+	if exact {
+		action = HistoryActionExact
+	}
+	_, err := db.trackProgress(mangaID, action, "", HistorySourceButton, func() error {
+		return db.setExactTracking(mangaID, userID, exact)
+	})
+	return err
+}
+
+func (db *DB) setExactTracking(mangaID int, userID int64, exact bool) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var current bool
+	if err = tx.QueryRow("SELECT exact_tracking FROM manga WHERE id = ? AND user_id = ?", mangaID, userID).Scan(&current); err != nil {
+		return err
+	}
+	if current == exact {
+		return nil
+	}
+
+	if exact {
+		if _, err = tx.Exec(`
+			INSERT OR IGNORE INTO chapter_reads (manga_id, chapter_number, read_at)
+			SELECT chapters.manga_id, chapters.chapter_number, ?
+			FROM chapters
+			JOIN manga ON manga.id = chapters.manga_id
+			WHERE chapters.manga_id = ?
+			  AND chapters.chapter_number GLOB '[0-9]*'
+			  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+			  AND chapters.chapter_number NOT GLOB '*.*.*'
+			  AND CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		`, time.Now().UTC(), mangaID); err != nil {
+			return err
+		}
+	} else {
+		var watermark sql.NullFloat64
+		if err = tx.QueryRow(`
+			SELECT MAX(CAST(chapter_number AS REAL))
+			FROM chapters
+			WHERE manga_id = ?
+			  AND chapter_number GLOB '[0-9]*'
+			  AND chapter_number NOT GLOB '*[^0-9.]*'
+			  AND chapter_number NOT GLOB '*.*.*'
+			  AND CAST(chapter_number AS REAL) < COALESCE((
+				SELECT MIN(CAST(unread.chapter_number AS REAL))
+				FROM chapters unread
+				WHERE unread.manga_id = ?
+				  AND unread.chapter_number GLOB '[0-9]*'
+				  AND unread.chapter_number NOT GLOB '*[^0-9.]*'
+				  AND unread.chapter_number NOT GLOB '*.*.*'
+				  AND NOT EXISTS (
+					SELECT 1 FROM chapter_reads
+					WHERE chapter_reads.manga_id = unread.manga_id AND chapter_reads.chapter_number = unread.chapter_number
+				  )
+			  ), 1e18)
+		`, mangaID, mangaID).Scan(&watermark); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("UPDATE manga SET last_read_number = ? WHERE id = ?", watermark, mangaID); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec("UPDATE manga SET exact_tracking = ? WHERE id = ?", exact, mangaID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(recalculateUnreadSQL, mangaID)
+	return err
+}
+
+// ListExtraChapters returns the title's chapters without a usable number, newest first,
+// with whether each one has been read (only exact tracking records that).
+func (db *DB) ListExtraChapters(mangaID int, limit int) ([]ExtraChapter, error) {
+	rows, err := db.Query(`
+		SELECT chapters.id, chapters.chapter_number, COALESCE(chapters.title, ''), `+chapterReadSQL+`
+		FROM chapters
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND NOT (
+			chapters.chapter_number GLOB '[0-9]*'
+			AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+			AND chapters.chapter_number NOT GLOB '*.*.*'
+		  )
+		ORDER BY COALESCE(chapters.created_at, chapters.readable_at, chapters.published_at) DESC, chapters.id DESC
+		LIMIT ?
+	`, mangaID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var extras []ExtraChapter
+	for rows.Next() {
+		var e ExtraChapter
+		if err := rows.Scan(&e.ID, &e.Number, &e.Title, &e.Read); err != nil {
+			return nil, err
+		}
+		extras = append(extras, e)
+	}
+	return extras, rows.Err()
+}
+
+// GetChapterNumber returns the chapter_number of one of the title's chapters by row ID.
+func (db *DB) GetChapterNumber(mangaID int, chapterID int) (string, error) {
+	var number string
+	err := db.QueryRow("SELECT chapter_number FROM chapters WHERE id = ? AND manga_id = ?", chapterID, mangaID).Scan(&number)
+	return number, err
+}
+
+// IsChapterRead reports whether the title's owner has read the chapter.
+func (db *DB) IsChapterRead(mangaID int, chapterNumber string) (bool, error) {
+	var read bool
+	err := db.QueryRow(`
+		SELECT `+chapterReadSQL+`
+		FROM chapters
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ? AND chapters.chapter_number = ?
+	`, mangaID, chapterNumber).Scan(&read)
+	return read, err
+}
@@ -47,7 +47,7 @@ func (db *DB) CountReadChapters(mangaID int) (int, error) {
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		  AND `+chapterReadSQL+`
 	`, mangaID).Scan(&readCount)
 	return readCount, err
 }
@@ -73,7 +73,7 @@ func (db *DB) ListUnreadBucketStarts(mangaID int, bucketSize int, rangeStart, ra
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) > COALESCE(manga.last_read_number, -1)
+		  AND NOT `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 		ORDER BY bucket_start ASC
@@ -120,7 +120,7 @@ func (db *DB) ListReadBucketStarts(mangaID int, bucketSize int, rangeStart, rang
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		  AND `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 		ORDER BY bucket_start DESC
@@ -157,7 +157,7 @@ func (db *DB) CountUnreadNumericChaptersInRange(mangaID int, start, end float64)
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) > COALESCE(manga.last_read_number, -1)
+		  AND NOT `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 	`, mangaID, start, end).Scan(&cnt)
@@ -174,7 +174,7 @@ func (db *DB) CountReadNumericChaptersInRange(mangaID int, start, end float64) (
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		  AND `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 	`, mangaID, start, end).Scan(&cnt)
@@ -190,7 +190,7 @@ func (db *DB) ListUnreadNumericChaptersInRange(mangaID int, start, end float64,
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) > COALESCE(manga.last_read_number, -1)
+		  AND NOT `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 		ORDER BY CAST(chapters.chapter_number AS REAL) ASC
@@ -230,7 +230,7 @@ func (db *DB) ListReadNumericChaptersInRange(mangaID int, start, end float64, li
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) <= COALESCE(manga.last_read_number, -1)
+		  AND `+chapterReadSQL+`
 		  AND CAST(chapters.chapter_number AS REAL) >= ?
 		  AND CAST(chapters.chapter_number AS REAL) < ?
 		ORDER BY CAST(chapters.chapter_number AS REAL) DESC
@@ -271,7 +271,7 @@ func (db *DB) CountUnreadChapters(mangaID int) (int, error) {
 		  AND chapters.chapter_number GLOB '[0-9]*'
 		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapters.chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapters.chapter_number AS REAL) > COALESCE(manga.last_read_number, -1)
+		  AND NOT `+chapterReadSQL+`
 	`, mangaID).Scan(&unreadCount)
 	return unreadCount, err
 }
@@ -280,24 +280,43 @@ func (db *DB) RecalculateUnreadCount(mangaID int) error {
 	return db.recalculateUnreadCount(mangaID)
 }
 
+// recalculateUnreadSQL refreshes manga.unread_count for the title bound to its parameter.
+const recalculateUnreadSQL = `
+	UPDATE manga
+	SET unread_count = (
+		SELECT COUNT(*)
+		FROM chapters
+		WHERE chapters.manga_id = manga.id
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND NOT ` + chapterReadSQL + `
+	)
+	WHERE id = ?
+`
+
 func (db *DB) recalculateUnreadCount(mangaID int) error {
-	_, err := db.Exec(`
-		UPDATE manga
-		SET unread_count = (
-			SELECT COUNT(*)
-			FROM chapters
-			WHERE chapters.manga_id = manga.id
-			  AND chapters.chapter_number GLOB '[0-9]*'
-			  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
-			  AND chapters.chapter_number NOT GLOB '*.*.*'
-			  AND CAST(chapters.chapter_number AS REAL) > COALESCE(manga.last_read_number, -1)
-		)
-		WHERE id = ?
-	`, mangaID)
+	_, err := db.Exec(recalculateUnreadSQL, mangaID)
 	return err
 }
 
-func (db *DB) MarkChapterAsRead(mangaID int, chapterNumber string) error {
+// markChapterAsRead records a chapter as read. With exact tracking only that chapter
+// (numeric or extra) changes; otherwise the watermark moves up to it and extras are ignored.
+func (db *DB) markChapterAsRead(mangaID int, chapterNumber string) error {
+	exact, err := db.IsExactTracking(mangaID)
+	if err != nil {
+		return err
+	}
+	if exact {
+		if _, err := db.Exec(`
+			INSERT OR IGNORE INTO chapter_reads (manga_id, chapter_number, read_at)
+			VALUES (?, ?, ?)
+		`, mangaID, chapterNumber, time.Now().UTC()); err != nil {
+			return err
+		}
+		return db.recalculateUnreadCount(mangaID)
+	}
+
 	num, err := strconv.ParseFloat(strings.TrimSpace(chapterNumber), 64)
 	if err != nil {
 		// Non-numeric chapters (extras) are not part of numeric progress tracking.
@@ -318,7 +337,20 @@ func (db *DB) MarkChapterAsRead(mangaID int, chapterNumber string) error {
 	return db.recalculateUnreadCount(mangaID)
 }
 
-func (db *DB) MarkChapterAsUnread(mangaID int, chapterNumber string) error {
+// markChapterAsUnread records a chapter as unread. With exact tracking only that chapter
+// changes; otherwise the watermark drops below it, making every later chapter unread too.
+func (db *DB) markChapterAsUnread(mangaID int, chapterNumber string) error {
+	exact, err := db.IsExactTracking(mangaID)
+	if err != nil {
+		return err
+	}
+	if exact {
+		if _, err := db.Exec("DELETE FROM chapter_reads WHERE manga_id = ? AND chapter_number = ?", mangaID, chapterNumber); err != nil {
+			return err
+		}
+		return db.recalculateUnreadCount(mangaID)
+	}
+
 	num, err := strconv.ParseFloat(strings.TrimSpace(chapterNumber), 64)
 	if err != nil {
 		// Non-numeric chapters (extras) are not part of numeric progress tracking.
@@ -348,11 +380,23 @@ func (db *DB) MarkChapterAsUnread(mangaID int, chapterNumber string) error {
 			return err
 		}
 	}
+	// Forget exact reads the rollback covers so switching to exact tracking later
+	// doesn't bring them back.
+	if _, err := db.Exec(`
+		DELETE FROM chapter_reads
+		WHERE manga_id = ?
+		  AND chapter_number GLOB '[0-9]*'
+		  AND chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapter_number NOT GLOB '*.*.*'
+		  AND CAST(chapter_number AS REAL) >= ?
+	`, mangaID, num); err != nil {
+		return err
+	}
 
 	return db.recalculateUnreadCount(mangaID)
 }
 
-func (db *DB) MarkAllChaptersAsRead(mangaID int) error {
+func (db *DB) markAllChaptersAsRead(mangaID int) error {
 	var max sql.NullFloat64
 	if err := db.QueryRow(`
 		SELECT MAX(CAST(chapter_number AS REAL))
@@ -374,6 +418,14 @@ func (db *DB) MarkAllChaptersAsRead(mangaID int) error {
 			return err
 		}
 	}
+	// Exact tracking reads every chapter, extras included.
+	if _, err := db.Exec(`
+		INSERT OR IGNORE INTO chapter_reads (manga_id, chapter_number, read_at)
+		SELECT manga_id, chapter_number, ? FROM chapters
+		WHERE manga_id = ? AND EXISTS (SELECT 1 FROM manga WHERE id = ? AND exact_tracking = 1)
+	`, time.Now().UTC(), mangaID, mangaID); err != nil {
+		return err
+	}
 
 	return db.recalculateUnreadCount(mangaID)
 }
@@ -381,16 +433,17 @@ func (db *DB) MarkAllChaptersAsRead(mangaID int) error {
 func (db *DB) GetLastReadChapter(mangaID int) (chapterNumber string, title string, ok bool, err error) {
 	var num, t string
 	err = db.QueryRow(`
-		SELECT chapter_number, COALESCE(title, '')
+		SELECT chapters.chapter_number, COALESCE(chapters.title, '')
 		FROM chapters
-		WHERE manga_id = ?
-		  AND chapter_number GLOB '[0-9]*'
-		  AND chapter_number NOT GLOB '*[^0-9.]*'
-		  AND chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapter_number AS REAL) <= COALESCE((SELECT last_read_number FROM manga WHERE id = ?), -1)
-		ORDER BY CAST(chapter_number AS REAL) DESC
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND `+chapterReadSQL+`
+		ORDER BY CAST(chapters.chapter_number AS REAL) DESC
 		LIMIT 1
-	`, mangaID, mangaID).Scan(&num, &t)
+	`, mangaID).Scan(&num, &t)
 	if err == sql.ErrNoRows {
 		return "", "", false, nil
 	}
@@ -400,18 +453,41 @@ func (db *DB) GetLastReadChapter(mangaID int) (chapterNumber string, title strin
 	return num, t, true, nil
 }
 
-func (db *DB) ListUnreadChapters(mangaID int, limit, offset int) ([]ChapterListItem, error) {
-	rows, err := db.Query(`
-		SELECT chapter_number, COALESCE(title, ''), COALESCE(created_at, readable_at, published_at) AS seen_at
+// GetLatestChapter returns the highest numbered chapter stored for the title, read or not.
+func (db *DB) GetLatestChapter(mangaID int) (chapterNumber string, ok bool, err error) {
+	var num string
+	err = db.QueryRow(`
+		SELECT chapter_number
 		FROM chapters
 		WHERE manga_id = ?
 		  AND chapter_number GLOB '[0-9]*'
 		  AND chapter_number NOT GLOB '*[^0-9.]*'
 		  AND chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapter_number AS REAL) > COALESCE((SELECT last_read_number FROM manga WHERE id = ?), -1)
-		ORDER BY CAST(chapter_number AS REAL) ASC
+		ORDER BY CAST(chapter_number AS REAL) DESC
+		LIMIT 1
+	`, mangaID).Scan(&num)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return num, true, nil
+}
+
+func (db *DB) ListUnreadChapters(mangaID int, limit, offset int) ([]ChapterListItem, error) {
+	rows, err := db.Query(`
+		SELECT chapters.chapter_number, COALESCE(chapters.title, ''), COALESCE(created_at, readable_at, published_at) AS seen_at
+		FROM chapters
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND NOT `+chapterReadSQL+`
+		ORDER BY CAST(chapters.chapter_number AS REAL) ASC
 		LIMIT ? OFFSET ?
-	`, mangaID, mangaID, limit, offset)
+	`, mangaID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -437,30 +513,65 @@ func (db *DB) ListUnreadChapters(mangaID int, limit, offset int) ([]ChapterListI
 	return items, nil
 }
 
+// ListLatestChapters returns the title's most recently seen chapters, newest first.
+func (db *DB) ListLatestChapters(mangaID int, limit int) ([]ChapterListItem, error) {
+	rows, err := db.Query(`
+		SELECT chapter_number, COALESCE(title, ''), COALESCE(created_at, readable_at, published_at, '') AS seen_at, COALESCE(external_url, '')
+		FROM chapters
+		WHERE manga_id = ?
+		ORDER BY seen_at DESC, id DESC
+		LIMIT ?
+	`, mangaID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]ChapterListItem, 0, limit)
+	for rows.Next() {
+		var it ChapterListItem
+		var seenAtStr string
+		if err := rows.Scan(&it.Number, &it.Title, &seenAtStr, &it.ExternalURL); err != nil {
+			return nil, err
+		}
+		if seenAtStr != "" {
+			seenAt, err := parseSQLiteTime(seenAtStr)
+			if err != nil {
+				return nil, err
+			}
+			it.SeenAt = seenAt
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
 func (db *DB) GetUnreadChapters(mangaID int) (*sql.Rows, error) {
 	return db.Query(`
-		SELECT chapter_number, title
+		SELECT chapters.chapter_number, chapters.title
 		FROM chapters
-		WHERE manga_id = ?
-		  AND chapter_number GLOB '[0-9]*'
-		  AND chapter_number NOT GLOB '*[^0-9.]*'
-		  AND chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapter_number AS REAL) > COALESCE((SELECT last_read_number FROM manga WHERE id = ?), -1)
-		ORDER BY CAST(chapter_number AS REAL) ASC
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND NOT `+chapterReadSQL+`
+		ORDER BY CAST(chapters.chapter_number AS REAL) ASC
 		LIMIT 3
-	`, mangaID, mangaID)
+	`, mangaID)
 }
 
 func (db *DB) GetReadChapters(mangaID int) (*sql.Rows, error) {
 	return db.Query(`
-		SELECT chapter_number, title
+		SELECT chapters.chapter_number, chapters.title
 		FROM chapters
-		WHERE manga_id = ?
-		  AND chapter_number GLOB '[0-9]*'
-		  AND chapter_number NOT GLOB '*[^0-9.]*'
-		  AND chapter_number NOT GLOB '*.*.*'
-		  AND CAST(chapter_number AS REAL) <= COALESCE((SELECT last_read_number FROM manga WHERE id = ?), -1)
-		ORDER BY CAST(chapter_number AS REAL) DESC
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND `+chapterReadSQL+`
+		ORDER BY CAST(chapters.chapter_number AS REAL) DESC
 		LIMIT 3
-	`, mangaID, mangaID)
+	`, mangaID)
 }
@@ -214,6 +214,13 @@ func TestMangaMethods_CRUDAndDetails(t *testing.T) {
 	if isPlus {
 		t.Fatal("expected manga plus disabled")
 	}
+	// Detection leaves the user's choice alone.
+	if err := database.MarkMangaPlusDetected(int(mangaID)); err != nil {
+		t.Fatalf("MarkMangaPlusDetected(): %v", err)
+	}
+	if isPlus, _ = database.IsMangaPlus(int(mangaID)); isPlus {
+		t.Fatal("expected detection to keep the manual override")
+	}
 
 	seenAt := time.Date(2025, 2, 2, 0, 0, 0, 0, time.UTC)
 	if err := database.UpdateMangaLastSeenAt(int(mangaID), seenAt); err != nil {
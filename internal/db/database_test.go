@@ -1,7 +1,12 @@
 package db
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -50,6 +55,37 @@ func TestEnsureUser_IsIdempotent(t *testing.T) {
 	}
 }
 
+func TestOpenReadOnly_ReadsButRejectsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(path)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	ensureTestUser(t, database, 42)
+	_ = database.Close()
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly(): %v", err)
+	}
+	defer func() { _ = ro.Close() }()
+
+	users, err := ro.ListUsers()
+	if err != nil || len(users) != 1 || users[0] != 42 {
+		t.Fatalf("ListUsers()=%v, %v; want [42]", users, err)
+	}
+	if err := ro.EnsureUser(43, false); err == nil {
+		t.Fatal("EnsureUser() on a read-only database succeeded")
+	}
+
+	if _, err := OpenReadOnly(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Fatal("OpenReadOnly() created a missing database")
+	}
+}
+
 func TestUserPendingStateLifecycle(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	database, err := New(dbPath)
@@ -310,6 +346,134 @@ func TestPairingCodes_OneTimeAndExpiry(t *testing.T) {
 	}
 }
 
+func TestPairingCodes_MultiUseLabelAndRevoke(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	code := "ABCD-1234"
+	opts := PairingCodeOptions{
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		MaxUses:   2,
+		Label:     "Discord friends",
+		Starters:  []PairingStarter{{MangaDexID: "md-1", Title: "Starter", IsMangaPlus: true}},
+	}
+	if err := database.CreatePairingCodeWithOptions(code, 1, opts); err != nil {
+		t.Fatalf("CreatePairingCodeWithOptions(): %v", err)
+	}
+
+	for _, chatID := range []int64{42, 43} {
+		ok, err := database.RedeemPairingCode(code, chatID)
+		if err != nil || !ok {
+			t.Fatalf("RedeemPairingCode(%d) = (%v,%v), want (true,nil)", chatID, ok, err)
+		}
+	}
+	ok, err := database.RedeemPairingCode(code, 44)
+	if err != nil || ok {
+		t.Fatalf("RedeemPairingCode(over limit) = (%v,%v), want (false,nil)", ok, err)
+	}
+
+	starters, err := database.ListPairingCodeStarters(code)
+	if err != nil {
+		t.Fatalf("ListPairingCodeStarters(): %v", err)
+	}
+	if len(starters) != 1 || starters[0].MangaDexID != "md-1" || !starters[0].IsMangaPlus {
+		t.Fatalf("starters=%+v, want md-1 with MANGA Plus", starters)
+	}
+
+	codes, err := database.ListPairingCodes(10)
+	if err != nil {
+		t.Fatalf("ListPairingCodes(): %v", err)
+	}
+	if len(codes) != 1 || codes[0].Label != "Discord friends" || codes[0].UseCount != 2 || codes[0].MaxUses != 2 {
+		t.Fatalf("codes=%+v, want labelled code used 2/2", codes)
+	}
+
+	redemptions, err := database.ListPairingRedemptions(code)
+	if err != nil {
+		t.Fatalf("ListPairingRedemptions(): %v", err)
+	}
+	if len(redemptions) != 2 || redemptions[0].ChatID != 42 || redemptions[1].ChatID != 43 {
+		t.Fatalf("redemptions=%+v, want chats 42 and 43", redemptions)
+	}
+
+	if _, err := database.AddManga("md-2", "Joined Manga", 42); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	if err := database.RevokePairingCode(code); err != nil {
+		t.Fatalf("RevokePairingCode(): %v", err)
+	}
+	if authorized, _, err := database.IsUserAuthorized(42); err != nil || !authorized {
+		t.Fatalf("IsUserAuthorized(42) after revoke = %v, %v; want the user to keep access", authorized, err)
+	}
+	codes, err = database.ListPairingCodes(10)
+	if err != nil {
+		t.Fatalf("ListPairingCodes(): %v", err)
+	}
+	if !codes[0].IsRevoked || len(codes[0].JoinedChatIDs) != 2 || codes[0].ActiveUsers != 2 {
+		t.Fatalf("code after revoke=%+v, want revoked with 2 active users", codes[0])
+	}
+
+	disabled, err := database.DisablePairingCodeUsers(code)
+	if err != nil {
+		t.Fatalf("DisablePairingCodeUsers(): %v", err)
+	}
+	if len(disabled) != 2 {
+		t.Fatalf("disabled=%v, want 2 chat IDs", disabled)
+	}
+	for _, chatID := range []int64{42, 43} {
+		authorized, _, err := database.IsUserAuthorized(chatID)
+		if err != nil {
+			t.Fatalf("IsUserAuthorized(%d): %v", chatID, err)
+		}
+		if authorized {
+			t.Fatalf("user %d still authorized after disabling", chatID)
+		}
+	}
+	manga, err := database.ListManga()
+	if err != nil {
+		t.Fatalf("ListManga(): %v", err)
+	}
+	if len(manga) != 0 {
+		t.Fatalf("ListManga()=%+v, want disabled users' titles left out of updates", manga)
+	}
+	var mangaCount int
+	if err := database.QueryRow("SELECT COUNT(*) FROM manga WHERE user_id = 42").Scan(&mangaCount); err != nil {
+		t.Fatalf("count manga: %v", err)
+	}
+	if mangaCount != 1 {
+		t.Fatalf("manga rows for disabled user=%d, want 1 (kept)", mangaCount)
+	}
+
+	if err := database.CreatePairingCode("BEEF-0001", 1, time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("CreatePairingCode(): %v", err)
+	}
+	if ok, err := database.RedeemPairingCode("BEEF-0001", 42); err != nil || !ok {
+		t.Fatalf("RedeemPairingCode(new code) = (%v,%v), want (true,nil)", ok, err)
+	}
+	if authorized, _, err := database.IsUserAuthorized(42); err != nil || !authorized {
+		t.Fatalf("IsUserAuthorized(42) after a new code = %v, %v; want access back", authorized, err)
+	}
+	if again, err := database.DisablePairingCodeUsers(code); err != nil || len(again) != 0 {
+		t.Fatalf("DisablePairingCodeUsers(old code) = %v, %v; want the re-joined user left alone", again, err)
+	}
+
+	if err := database.RevokePairingCode("FFFF-0000"); err == nil {
+		t.Fatal("RevokePairingCode(unknown) succeeded, want error")
+	}
+}
+
 func TestListUnreadBucketStartsAndRangeListing(t *testing.T) {
 	dbPath := filepath.Join(t.TempDir(), "test.db")
 	database, err := New(dbPath)
@@ -615,3 +779,1386 @@ func TestMigrate_BackfillsLastSeenFromPastPublishedAt(t *testing.T) {
 		t.Fatalf("last_seen_at=%v, want %v", lastSeenAt, published)
 	}
 }
+
+func TestSchedulerRunHistory_RecordListAndPrune(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	old := time.Now().Add(-40 * 24 * time.Hour)
+	oldID, err := database.StartSchedulerRun("old", "cron", old)
+	if err != nil {
+		t.Fatalf("StartSchedulerRun(old): %v", err)
+	}
+	if err := database.FinishSchedulerRun(oldID, old.Add(time.Minute), 0, "", []SchedulerRunTitle{{MangaID: 1, UserID: 1, Title: "Old"}}); err != nil {
+		t.Fatalf("FinishSchedulerRun(old): %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	id, err := database.StartSchedulerRun("abc123", "startup", start)
+	if err != nil {
+		t.Fatalf("StartSchedulerRun(): %v", err)
+	}
+	titles := []SchedulerRunTitle{
+		{MangaID: 1, UserID: 1, Title: "Alpha", NewChapters: 2, Duration: 1500 * time.Millisecond},
+		{MangaID: 2, UserID: 1, Title: "Beta", Duration: 300 * time.Millisecond, Error: "boom"},
+		{MangaID: 3, UserID: 1, Title: "Gamma"},
+	}
+	if err := database.FinishSchedulerRun(id, start.Add(2*time.Second), 1, "", titles); err != nil {
+		t.Fatalf("FinishSchedulerRun(): %v", err)
+	}
+
+	run, err := database.GetSchedulerRun(id)
+	if err != nil {
+		t.Fatalf("GetSchedulerRun(): %v", err)
+	}
+	if run.RunID != "abc123" || run.Trigger != "startup" || !run.IsFinished {
+		t.Fatalf("run=%+v", run)
+	}
+	if run.TitlesChecked != 3 || run.NewChapters != 2 || run.NotificationsSent != 1 || run.Failures != 1 {
+		t.Fatalf("run totals=%+v", run)
+	}
+
+	got, err := database.ListSchedulerRunTitles(id)
+	if err != nil {
+		t.Fatalf("ListSchedulerRunTitles(): %v", err)
+	}
+	if len(got) != 3 || got[0].Title != "Beta" || got[0].Error != "boom" || got[1].Title != "Alpha" {
+		t.Fatalf("titles=%+v, want failures first then most new chapters", got)
+	}
+	if got[1].Duration != 1500*time.Millisecond {
+		t.Fatalf("duration=%s, want 1.5s", got[1].Duration)
+	}
+
+	runs, err := database.ListSchedulerRuns(10)
+	if err != nil {
+		t.Fatalf("ListSchedulerRuns(): %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != id {
+		t.Fatalf("runs=%+v, want newest first", runs)
+	}
+
+	pruned, err := database.PruneSchedulerRuns(time.Now().Add(-30 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("PruneSchedulerRuns(): %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("pruned=%d, want 1", pruned)
+	}
+	if titles, err := database.ListSchedulerRunTitles(oldID); err != nil || len(titles) != 0 {
+		t.Fatalf("old titles=%+v err=%v, want pruned", titles, err)
+	}
+	if runs, err := database.ListSchedulerRuns(10); err != nil || len(runs) != 1 {
+		t.Fatalf("runs after prune=%+v err=%v", runs, err)
+	}
+}
+
+func TestMangaFailureTracking(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id, err := database.AddManga("md-1", "Broken", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id)
+
+	for want := 1; want <= 2; want++ {
+		got, err := database.RecordMangaFailure(mangaID, "404", time.Now())
+		if err != nil {
+			t.Fatalf("RecordMangaFailure(): %v", err)
+		}
+		if got != want {
+			t.Fatalf("failures=%d, want %d", got, want)
+		}
+	}
+	next := time.Now().Add(12 * time.Hour)
+	if err := database.SetMangaNextCheckAt(mangaID, next); err != nil {
+		t.Fatalf("SetMangaNextCheckAt(): %v", err)
+	}
+
+	manga, err := database.ListManga()
+	if err != nil {
+		t.Fatalf("ListManga(): %v", err)
+	}
+	if len(manga) != 1 || manga[0].ConsecutiveFailures != 2 || manga[0].NextCheckAt.IsZero() {
+		t.Fatalf("manga=%+v, want failure state loaded", manga)
+	}
+
+	if claimed, err := database.ClaimMangaFailureAlert(mangaID); err != nil || !claimed {
+		t.Fatalf("first claim=%v err=%v, want true", claimed, err)
+	}
+	if claimed, err := database.ClaimMangaFailureAlert(mangaID); err != nil || claimed {
+		t.Fatalf("second claim=%v err=%v, want false", claimed, err)
+	}
+
+	d, err := database.GetMangaDetails(mangaID, userID)
+	if err != nil {
+		t.Fatalf("GetMangaDetails(): %v", err)
+	}
+	if d.ConsecutiveFailures != 2 || d.LastError != "404" || !d.HasLastErrorAt || !d.HasNextCheckAt {
+		t.Fatalf("details=%+v", d)
+	}
+
+	if err := database.ClearMangaFailures(mangaID); err != nil {
+		t.Fatalf("ClearMangaFailures(): %v", err)
+	}
+	if claimed, err := database.ClaimMangaFailureAlert(mangaID); err != nil || !claimed {
+		t.Fatalf("claim after clear=%v err=%v, want a new streak to alert again", claimed, err)
+	}
+
+	if err := database.ReplaceMangaDexID(mangaID, userID+1, "md-2"); err == nil {
+		t.Fatal("ReplaceMangaDexID() for another user expected error")
+	}
+	if err := database.ReplaceMangaDexID(mangaID, userID, "md-2"); err != nil {
+		t.Fatalf("ReplaceMangaDexID(): %v", err)
+	}
+	if d, err = database.GetMangaDetails(mangaID, userID); err != nil || d.MangaDexID != "md-2" || d.HasNextCheckAt {
+		t.Fatalf("details after replace=%+v err=%v", d, err)
+	}
+}
+
+func TestMigrateMangaDexID_FoldsDuplicateAndRecordsAlias(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	oldID, err := database.AddManga("md-old", "Merged", userID)
+	if err != nil {
+		t.Fatalf("AddManga(old): %v", err)
+	}
+	dupID, err := database.AddManga("md-new", "Merged (new entry)", userID)
+	if err != nil {
+		t.Fatalf("AddManga(dup): %v", err)
+	}
+	now := time.Now()
+	for _, ch := range []struct {
+		mangaID int64
+		number  string
+	}{{oldID, "1"}, {oldID, "2"}, {oldID, "3"}, {dupID, "3"}, {dupID, "4"}, {dupID, "5"}} {
+		if err := database.AddChapter(ch.mangaID, ch.number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(): %v", err)
+		}
+	}
+	if err := database.MarkChapterAsRead(int(oldID), "2"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	dupHistoryID, err := database.MarkChapterAsReadFrom(int(dupID), "3", HistorySourceCommand)
+	if err != nil {
+		t.Fatalf("MarkChapterAsReadFrom(dup): %v", err)
+	}
+	if err := database.MarkChapterAsUnread(int(dupID), "3"); err != nil {
+		t.Fatalf("MarkChapterAsUnread(dup): %v", err)
+	}
+	if changed, err := database.SetMangaMergeCandidate(int(oldID), "md-new"); err != nil || !changed {
+		t.Fatalf("SetMangaMergeCandidate()=%v err=%v, want recorded", changed, err)
+	}
+	if changed, err := database.SetMangaMergeCandidate(int(oldID), "md-new"); err != nil || changed {
+		t.Fatalf("SetMangaMergeCandidate(again)=%v err=%v, want unchanged", changed, err)
+	}
+
+	if err := database.MigrateMangaDexID(int(oldID), userID+1, "md-new"); err == nil {
+		t.Fatal("MigrateMangaDexID() for another user expected error")
+	}
+	if err := database.MigrateMangaDexID(int(oldID), userID, "md-new"); err != nil {
+		t.Fatalf("MigrateMangaDexID(): %v", err)
+	}
+
+	d, err := database.GetMangaDetails(int(oldID), userID)
+	if err != nil {
+		t.Fatalf("GetMangaDetails(): %v", err)
+	}
+	if d.MangaDexID != "md-new" || d.MergeCandidate != "" || d.ChaptersTotal != 5 || d.LastReadNumber != 2 || d.UnreadCount != 3 {
+		t.Fatalf("details=%+v, want migrated title with 5 chapters, read up to 2", d)
+	}
+	if ok, err := database.MangaBelongsToUser(int(dupID), userID); err != nil || ok {
+		t.Fatalf("duplicate still present=%v err=%v", ok, err)
+	}
+	history, err := database.ListReadingHistory(int(oldID), 10)
+	if err != nil {
+		t.Fatalf("ListReadingHistory(): %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("history=%+v, want the duplicate's entries folded in", history)
+	}
+	var orphaned int
+	if err := database.QueryRow("SELECT COUNT(*) FROM reading_history WHERE manga_id = ?", dupID).Scan(&orphaned); err != nil || orphaned != 0 {
+		t.Fatalf("history rows left on the duplicate=%d err=%v", orphaned, err)
+	}
+	if mangaID, ok, err := database.UndoProgress(dupHistoryID, userID, HistorySourceButton); err != nil || ok || mangaID != int(oldID) {
+		t.Fatalf("UndoProgress(folded, superseded)=%d,%v err=%v, want refused", mangaID, ok, err)
+	}
+
+	// A later merge of the new entry extends the chain.
+	if err := database.MigrateMangaDexID(int(oldID), userID, "md-newer"); err != nil {
+		t.Fatalf("MigrateMangaDexID(second): %v", err)
+	}
+	for _, id := range []string{"md-old", "md-new"} {
+		if got, err := database.ResolveMangaDexAlias(id); err != nil || got != "md-newer" {
+			t.Fatalf("ResolveMangaDexAlias(%q)=%q err=%v, want md-newer", id, got, err)
+		}
+	}
+	if got, err := database.ResolveMangaDexAlias("md-unrelated"); err != nil || got != "md-unrelated" {
+		t.Fatalf("ResolveMangaDexAlias(unrelated)=%q err=%v", got, err)
+	}
+}
+
+func TestAddMangaFromSource(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	mdID, err := database.AddManga("md-1", "From MangaDex", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	feedID, err := database.AddMangaFromSource("rss", "https://example.com/feed.xml", "From a feed", false, userID)
+	if err != nil {
+		t.Fatalf("AddMangaFromSource(): %v", err)
+	}
+
+	if got, err := database.GetMangaSource(int(mdID)); err != nil || got != "mangadex" {
+		t.Fatalf("GetMangaSource(md)=%q, %v; want mangadex", got, err)
+	}
+	if got, err := database.GetMangaSource(int(feedID)); err != nil || got != "rss" {
+		t.Fatalf("GetMangaSource(feed)=%q, %v; want rss", got, err)
+	}
+
+	list, err := database.ListManga()
+	if err != nil {
+		t.Fatalf("ListManga(): %v", err)
+	}
+	sources := map[int]string{}
+	for _, m := range list {
+		sources[m.ID] = m.Source
+	}
+	if sources[int(feedID)] != "rss" || sources[int(mdID)] != "mangadex" {
+		t.Fatalf("ListManga sources=%v", sources)
+	}
+
+	d, err := database.GetMangaDetails(int(feedID), userID)
+	if err != nil {
+		t.Fatalf("GetMangaDetails(): %v", err)
+	}
+	if d.Source != "rss" || d.SourceID != "https://example.com/feed.xml" || d.MangaDexID != "" {
+		t.Fatalf("details=%+v, want the feed URL kept out of mangadex_id", d)
+	}
+	if got, _, _, _, err := database.GetManga(int(feedID)); err != nil || got != "https://example.com/feed.xml" {
+		t.Fatalf("GetManga(feed)=%q, %v; want the feed URL", got, err)
+	}
+	if _, err := database.AddMangaFromSource("rss", "https://example.com/other.xml", "Another feed", false, userID); err != nil {
+		t.Fatalf("AddMangaFromSource(second feed): %v", err)
+	}
+	if _, err := database.AddMangaFromSource("rss", "https://example.com/feed.xml", "Same feed", false, userID); err == nil {
+		t.Fatal("adding the same feed twice should fail")
+	}
+
+	// Titles added before source_id existed move their feed URL over on migration.
+	if _, err := database.Exec("UPDATE manga SET mangadex_id = source_id, source_id = '' WHERE id = ?", feedID); err != nil {
+		t.Fatalf("reset legacy row: %v", err)
+	}
+	if _, err := database.Exec("DROP INDEX idx_manga_user_mangadex"); err != nil {
+		t.Fatalf("drop index: %v", err)
+	}
+	if _, err := database.Exec("CREATE UNIQUE INDEX idx_manga_user_mangadex ON manga(user_id, mangadex_id)"); err != nil {
+		t.Fatalf("legacy index: %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	d, err = database.GetMangaDetails(int(feedID), userID)
+	if err != nil || d.SourceID != "https://example.com/feed.xml" || d.MangaDexID != "" {
+		t.Fatalf("migrated details=%+v err=%v", d, err)
+	}
+}
+
+func TestGetMangaPlusWindow(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	addTitle := func(title string, chapters int) int {
+		t.Helper()
+		id, err := database.AddMangaWithMangaPlus("md-"+title, title, true, userID)
+		if err != nil {
+			t.Fatalf("AddMangaWithMangaPlus(): %v", err)
+		}
+		now := time.Now()
+		for i := 1; i <= chapters; i++ {
+			if err := database.AddChapter(id, strconv.Itoa(i), "", now, now, now, now); err != nil {
+				t.Fatalf("AddChapter(): %v", err)
+			}
+		}
+		return int(id)
+	}
+
+	long := addTitle("Long", 8)
+	if err := database.SetChapterExternalURL(int64(long), "8", "https://mangaplus.shueisha.co.jp/viewer/8"); err != nil {
+		t.Fatalf("SetChapterExternalURL(): %v", err)
+	}
+	var link string
+	if err := database.QueryRow("SELECT external_url FROM chapters WHERE manga_id = ? AND chapter_number = '8'", long).Scan(&link); err != nil || link != "https://mangaplus.shueisha.co.jp/viewer/8" {
+		t.Fatalf("external_url=%q, %v", link, err)
+	}
+
+	if err := database.MarkChapterAsRead(long, "4"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	w, err := database.GetMangaPlusWindow(long)
+	if err != nil {
+		t.Fatalf("GetMangaPlusWindow(): %v", err)
+	}
+	if strings.Join(w.Free, ",") != "8,7,6" || w.Expiring != "6" || w.Paywalled != 1 {
+		t.Fatalf("window=%+v, want free 8,7,6, chapter 6 expiring and chapter 5 paywalled", w)
+	}
+
+	if err := database.MarkChapterAsRead(long, "6"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	if w, err = database.GetMangaPlusWindow(long); err != nil || w.Expiring != "" || w.Paywalled != 0 {
+		t.Fatalf("window=%+v, %v; want nothing at risk once caught up", w, err)
+	}
+
+	// The first chapters never go behind the paywall.
+	short := addTitle("Short", 4)
+	if w, err = database.GetMangaPlusWindow(short); err != nil || w.Expiring != "" || w.Paywalled != 0 {
+		t.Fatalf("short window=%+v, %v; want nothing at risk", w, err)
+	}
+
+	if err := database.SetMangaPlus(short, false); err != nil {
+		t.Fatalf("SetMangaPlus(): %v", err)
+	}
+	if got, err := database.MangaPlusWindowFor(short); err != nil || got != nil {
+		t.Fatalf("MangaPlusWindowFor(non MANGA Plus)=%+v, %v; want nil", got, err)
+	}
+}
+
+func TestUnreadWarningSettingsAndBacklog(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	settings, err := database.GetUserSettings(userID)
+	if err != nil || settings.UnreadWarning != DefaultUnreadWarningThreshold || settings.BacklogReport {
+		t.Fatalf("defaults=%+v err=%v", settings, err)
+	}
+
+	addWithUnread := func(mdID, title string, unread int) int {
+		t.Helper()
+		id, err := database.AddManga(mdID, title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		now := time.Now()
+		for i := 1; i <= unread; i++ {
+			if err := database.AddChapter(id, strconv.Itoa(i), "", now, now, now, now); err != nil {
+				t.Fatalf("AddChapter(): %v", err)
+			}
+		}
+		if err := database.RecalculateUnreadCount(int(id)); err != nil {
+			t.Fatalf("RecalculateUnreadCount(): %v", err)
+		}
+		return int(id)
+	}
+	few := addWithUnread("md-few", "Few", 2)
+	many := addWithUnread("md-many", "Many", 6)
+	quiet := addWithUnread("md-quiet", "Quiet", 9)
+	plain := addWithUnread("md-plain", "Plain", 4)
+	if err := database.SetMangaPlus(many, true); err != nil {
+		t.Fatalf("SetMangaPlus(): %v", err)
+	}
+
+	if err := database.SetMangaUnreadWarning(quiet, userID, 0); err != nil {
+		t.Fatalf("SetMangaUnreadWarning(off): %v", err)
+	}
+	if err := database.SetMangaUnreadWarning(few, userID, 2); err != nil {
+		t.Fatalf("SetMangaUnreadWarning(2): %v", err)
+	}
+	if err := database.SetMangaUnreadWarning(many, userID+1, 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SetMangaUnreadWarning(other user) err=%v, want sql.ErrNoRows", err)
+	}
+
+	backlog, err := database.ListBacklog(userID)
+	if err != nil {
+		t.Fatalf("ListBacklog(): %v", err)
+	}
+	if len(backlog) != 2 || backlog[0].Title != "Many" || backlog[0].Threshold != 3 || backlog[1].Title != "Few" || backlog[1].Threshold != 2 {
+		t.Fatalf("backlog=%+v, want Many then Few with the quiet title left out", backlog)
+	}
+	// Until the user picks a default, it only covers MANGA Plus titles.
+	if threshold, overridden, err := database.GetMangaUnreadWarning(plain); err != nil || threshold != 0 || overridden {
+		t.Fatalf("plain threshold=%d overridden=%v err=%v, want no warning before a default is set", threshold, overridden, err)
+	}
+
+	// Raising the default drops titles that follow it; the override keeps its own value.
+	if err := database.SetUserUnreadWarning(userID, 10); err != nil {
+		t.Fatalf("SetUserUnreadWarning(): %v", err)
+	}
+	if threshold, overridden, err := database.GetMangaUnreadWarning(many); err != nil || threshold != 10 || overridden {
+		t.Fatalf("many threshold=%d overridden=%v err=%v, want the user default", threshold, overridden, err)
+	}
+	if threshold, _, err := database.GetMangaUnreadWarning(plain); err != nil || threshold != 10 {
+		t.Fatalf("plain threshold=%d err=%v, want the chosen default on every title", threshold, err)
+	}
+	if settings, err := database.GetUserSettings(userID); err != nil || !settings.UnreadWarningSet {
+		t.Fatalf("settings=%+v err=%v, want the default marked as chosen", settings, err)
+	}
+	if err := database.SetMangaUnreadWarning(few, userID, -1); err != nil {
+		t.Fatalf("SetMangaUnreadWarning(reset): %v", err)
+	}
+	if backlog, _ := database.ListBacklog(userID); len(backlog) != 0 {
+		t.Fatalf("backlog=%+v, want empty at threshold 10", backlog)
+	}
+
+	if users, _ := database.ListBacklogReportUsers(); len(users) != 0 {
+		t.Fatalf("report users=%v, want none before opting in", users)
+	}
+	if err := database.SetUserBacklogReport(userID, true); err != nil {
+		t.Fatalf("SetUserBacklogReport(): %v", err)
+	}
+	if users, _ := database.ListBacklogReportUsers(); len(users) != 1 || users[0] != userID {
+		t.Fatalf("report users=%v, want [%d]", users, userID)
+	}
+}
+
+func TestMangaCategoriesAndMutes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	var ids []int
+	for i, title := range []string{"One", "Two", "Three"} {
+		id, err := database.AddManga("md-"+strconv.Itoa(i), title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		ids = append(ids, int(id))
+	}
+	if category, err := database.GetMangaCategory(ids[0]); err != nil || category != CategoryReading {
+		t.Fatalf("default category=%q err=%v, want reading", category, err)
+	}
+
+	if err := database.SetMangaCategory(ids[1], userID, CategoryOnHold); err != nil {
+		t.Fatalf("SetMangaCategory(): %v", err)
+	}
+	if err := database.SetMangaCategory(ids[2], userID, "someday"); err == nil {
+		t.Fatal("expected error for an unknown category")
+	}
+	if err := database.SetMangaCategory(ids[2], userID+1, CategoryDropped); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SetMangaCategory(other user) err=%v, want sql.ErrNoRows", err)
+	}
+
+	onHold, err := database.ListMangaByUser(userID, ListOptions{Category: CategoryOnHold})
+	if err != nil || len(onHold) != 1 || onHold[0].Title != "Two" || onHold[0].Category != CategoryOnHold {
+		t.Fatalf("on hold=%+v err=%v", onHold, err)
+	}
+	if all, _ := database.ListMangaByUser(userID, ListOptions{}); len(all) != 3 {
+		t.Fatalf("all=%+v, want 3 titles", all)
+	}
+	status, err := database.GetStatusByUser(userID)
+	if err != nil {
+		t.Fatalf("GetStatusByUser(): %v", err)
+	}
+	if status.CategoryCounts[CategoryReading] != 2 || status.CategoryCounts[CategoryOnHold] != 1 {
+		t.Fatalf("category counts=%v", status.CategoryCounts)
+	}
+
+	if err := database.SetCategoryMuted(userID, CategoryOnHold, true); err != nil {
+		t.Fatalf("SetCategoryMuted(): %v", err)
+	}
+	if muted, err := database.IsMangaMuted(ids[1]); err != nil || !muted {
+		t.Fatalf("on hold title muted=%v err=%v, want muted", muted, err)
+	}
+	if muted, _ := database.IsMangaMuted(ids[0]); muted {
+		t.Fatal("reading title should not be muted")
+	}
+
+	// Muted titles stay out of the backlog report.
+	if err := database.SetUserUnreadWarning(userID, DefaultUnreadWarningThreshold); err != nil {
+		t.Fatalf("SetUserUnreadWarning(): %v", err)
+	}
+	now := time.Now()
+	for _, id := range ids[:2] {
+		for i := 1; i <= 5; i++ {
+			if err := database.AddChapter(int64(id), strconv.Itoa(i), "", now, now, now, now); err != nil {
+				t.Fatalf("AddChapter(): %v", err)
+			}
+		}
+		if err := database.RecalculateUnreadCount(id); err != nil {
+			t.Fatalf("RecalculateUnreadCount(): %v", err)
+		}
+	}
+	if backlog, _ := database.ListBacklog(userID); len(backlog) != 1 || backlog[0].Title != "One" {
+		t.Fatalf("backlog=%+v, want only the unmuted title", backlog)
+	}
+
+	if err := database.SetCategoryMuted(userID, CategoryOnHold, false); err != nil {
+		t.Fatalf("SetCategoryMuted(false): %v", err)
+	}
+	if muted, _ := database.MutedCategories(userID); len(muted) != 0 {
+		t.Fatalf("muted=%v, want none", muted)
+	}
+}
+
+func TestClaimChapterNotification(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id64, err := database.AddManga("md-alerts", "Alerts", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+
+	claim := func(n int) int {
+		t.Helper()
+		batch, err := database.ClaimChapterNotification(mangaID, n, now)
+		if err != nil {
+			t.Fatalf("ClaimChapterNotification(): %v", err)
+		}
+		return batch
+	}
+	if claim(1) != 1 {
+		t.Fatal("default settings should alert for every chapter")
+	}
+
+	if err := database.SetMangaMuted(mangaID, userID, true); err != nil {
+		t.Fatalf("SetMangaMuted(): %v", err)
+	}
+	if claim(1) != 0 {
+		t.Fatal("muted title alerted")
+	}
+	if err := database.SetMangaMuted(mangaID, userID, false); err != nil {
+		t.Fatalf("SetMangaMuted(false): %v", err)
+	}
+
+	if err := database.SetMangaSnooze(mangaID, userID, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("SetMangaSnooze(): %v", err)
+	}
+	if claim(1) != 0 {
+		t.Fatal("snoozed title alerted")
+	}
+	if batch, _ := database.ClaimChapterNotification(mangaID, 1, now.Add(48*time.Hour)); batch != 1 {
+		t.Fatal("expired snooze should alert again")
+	}
+	if err := database.SetMangaSnooze(mangaID, userID, time.Time{}); err != nil {
+		t.Fatalf("SetMangaSnooze(clear): %v", err)
+	}
+
+	if err := database.SetMangaNotifyEvery(mangaID, userID, 3); err != nil {
+		t.Fatalf("SetMangaNotifyEvery(): %v", err)
+	}
+	if claim(1) != 0 || claim(1) != 0 {
+		t.Fatal("alerted before 3 chapters piled up")
+	}
+	if s, _ := database.GetMangaNotifySettings(mangaID); s.Pending != 2 || s.Every != 3 {
+		t.Fatalf("settings=%+v, want 2 pending of 3", s)
+	}
+	if batch := claim(1); batch != 3 {
+		t.Fatalf("third chapter alerted for %d chapters, want the batch of 3", batch)
+	}
+	if s, _ := database.GetMangaNotifySettings(mangaID); s.Pending != 0 {
+		t.Fatalf("pending=%d after alert, want 0", s.Pending)
+	}
+	if claim(4) != 4 {
+		t.Fatal("a batch of 4 should alert at once")
+	}
+
+	if err := database.SetMangaMuted(mangaID, userID+1, true); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SetMangaMuted(other user) err=%v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestListMangaByUser_SortFilterSearchAndSavedView(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+
+	var ids []int
+	for i, title := range []string{"banana Club", "Apple 100%", "cherry_pie"} {
+		id, err := database.AddManga("md-"+strconv.Itoa(i), title, userID)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		ids = append(ids, int(id))
+	}
+	now := time.Now().UTC()
+	for _, stmt := range []struct {
+		query string
+		args  []any
+	}{
+		{"UPDATE manga SET unread_count = 4, last_seen_at = ? WHERE id = ?", []any{now.Add(-48 * time.Hour), ids[0]}},
+		{"UPDATE manga SET unread_count = 9, is_manga_plus = 1 WHERE id = ?", []any{ids[1]}},
+		{"UPDATE manga SET consecutive_failures = 2, last_seen_at = ? WHERE id = ?", []any{now, ids[2]}},
+	} {
+		if _, err := database.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("Exec(%q): %v", stmt.query, err)
+		}
+	}
+
+	titles := func(opts ListOptions) string {
+		t.Helper()
+		manga, err := database.ListMangaByUser(userID, opts)
+		if err != nil {
+			t.Fatalf("ListMangaByUser(%+v): %v", opts, err)
+		}
+		var got []string
+		for _, m := range manga {
+			got = append(got, m.Title)
+		}
+		return strings.Join(got, ",")
+	}
+	for _, tc := range []struct {
+		opts ListOptions
+		want string
+	}{
+		{ListOptions{}, "Apple 100%,banana Club,cherry_pie"},
+		{ListOptions{Sort: SortUnread}, "Apple 100%,banana Club,cherry_pie"},
+		{ListOptions{Sort: SortLastRelease}, "cherry_pie,banana Club,Apple 100%"},
+		{ListOptions{Sort: SortRecent}, "cherry_pie,Apple 100%,banana Club"},
+		{ListOptions{Filter: FilterUnread}, "Apple 100%,banana Club"},
+		{ListOptions{Filter: FilterMangaPlus}, "Apple 100%"},
+		{ListOptions{Filter: FilterFailing}, "cherry_pie"},
+		{ListOptions{Query: "CLUB ban"}, "banana Club"},
+		{ListOptions{Query: "%"}, "Apple 100%"},
+		{ListOptions{Query: "_"}, "cherry_pie"},
+		{ListOptions{Query: "club apple"}, ""},
+	} {
+		if got := titles(tc.opts); got != tc.want {
+			t.Fatalf("ListMangaByUser(%+v)=%q, want %q", tc.opts, got, tc.want)
+		}
+	}
+	if _, err := database.ListMangaByUser(userID, ListOptions{Sort: "random"}); err == nil {
+		t.Fatal("unknown sort should fail")
+	}
+
+	if sort, err := database.GetUserListSort(userID); err != nil || sort != SortTitle {
+		t.Fatalf("default sort=%q err=%v, want title", sort, err)
+	}
+	if err := database.SetUserListSort(userID, SortLastRelease); err != nil {
+		t.Fatalf("SetUserListSort(): %v", err)
+	}
+	if sort, err := database.GetUserListSort(userID); err != nil || sort != SortLastRelease {
+		t.Fatalf("saved sort=%q err=%v, want release", sort, err)
+	}
+	if err := database.SetUserListSort(userID, "random"); err == nil {
+		t.Fatal("unknown sort should not be saved")
+	}
+
+	if pos, err := database.GetUserListPosition(userID); err != nil || pos != (ListPosition{}) {
+		t.Fatalf("default position=%+v err=%v, want the first page of everything", pos, err)
+	}
+	want := ListPosition{Category: CategoryOnHold, Filter: FilterUnread, Page: 3}
+	if err := database.SetUserListPosition(userID, want); err != nil {
+		t.Fatalf("SetUserListPosition(): %v", err)
+	}
+	if pos, err := database.GetUserListPosition(userID); err != nil || pos != want {
+		t.Fatalf("saved position=%+v err=%v, want %+v", pos, err, want)
+	}
+}
+
+func TestExactTracking_SwitchingIsLossless(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id64, err := database.AddManga("md-exact", "Exact", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3", "4", "5", "extra:side"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%s): %v", number, err)
+		}
+	}
+	unread := func() string {
+		t.Helper()
+		items, err := database.ListUnreadChapters(mangaID, 10, 0)
+		if err != nil {
+			t.Fatalf("ListUnreadChapters(): %v", err)
+		}
+		count, err := database.CountUnreadChapters(mangaID)
+		if err != nil || count != len(items) {
+			t.Fatalf("CountUnreadChapters()=%d err=%v, want %d", count, err, len(items))
+		}
+		var got []string
+		for _, it := range items {
+			got = append(got, it.Number)
+		}
+		return strings.Join(got, ",")
+	}
+
+	if err := database.MarkChapterAsRead(mangaID, "3"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	if err := database.SetExactTracking(mangaID, userID+1, true); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SetExactTracking(other user) err=%v, want sql.ErrNoRows", err)
+	}
+	if err := database.SetExactTracking(mangaID, userID, true); err != nil {
+		t.Fatalf("SetExactTracking(true): %v", err)
+	}
+	if got := unread(); got != "4,5" {
+		t.Fatalf("unread after switching=%q, want 4,5", got)
+	}
+
+	// Out-of-order reads only touch the chapter itself, extras included.
+	for _, number := range []string{"5", "extra:side"} {
+		if err := database.MarkChapterAsRead(mangaID, number); err != nil {
+			t.Fatalf("MarkChapterAsRead(%s): %v", number, err)
+		}
+	}
+	if err := database.MarkChapterAsUnread(mangaID, "2"); err != nil {
+		t.Fatalf("MarkChapterAsUnread(): %v", err)
+	}
+	if got := unread(); got != "2,4" {
+		t.Fatalf("unread with exact tracking=%q, want 2,4", got)
+	}
+	extras, err := database.ListExtraChapters(mangaID, 10)
+	if err != nil || len(extras) != 1 || !extras[0].Read {
+		t.Fatalf("extras=%+v err=%v, want the side story read", extras, err)
+	}
+	if number, err := database.GetChapterNumber(mangaID, extras[0].ID); err != nil || number != "extra:side" {
+		t.Fatalf("GetChapterNumber()=%q err=%v", number, err)
+	}
+
+	// The watermark stops before the first gap and switching back restores the set.
+	if err := database.SetExactTracking(mangaID, userID, false); err != nil {
+		t.Fatalf("SetExactTracking(false): %v", err)
+	}
+	if n, ok, err := database.GetLastReadNumber(mangaID); err != nil || !ok || n != 1 {
+		t.Fatalf("watermark=%v ok=%v err=%v, want 1", n, ok, err)
+	}
+	if got := unread(); got != "2,3,4,5" {
+		t.Fatalf("unread with watermark=%q", got)
+	}
+	if err := database.SetExactTracking(mangaID, userID, true); err != nil {
+		t.Fatalf("SetExactTracking(true): %v", err)
+	}
+	if got := unread(); got != "2,4" {
+		t.Fatalf("unread after round trip=%q, want 2,4", got)
+	}
+	var stored int
+	if err := database.QueryRow("SELECT unread_count FROM manga WHERE id = ?", mangaID).Scan(&stored); err != nil || stored != 2 {
+		t.Fatalf("unread_count=%d err=%v, want 2", stored, err)
+	}
+
+	// Rolling the watermark back forgets exact reads past it.
+	if err := database.SetExactTracking(mangaID, userID, false); err != nil {
+		t.Fatalf("SetExactTracking(false): %v", err)
+	}
+	if err := database.MarkChapterAsUnread(mangaID, "1"); err != nil {
+		t.Fatalf("MarkChapterAsUnread(): %v", err)
+	}
+	if err := database.SetExactTracking(mangaID, userID, true); err != nil {
+		t.Fatalf("SetExactTracking(true): %v", err)
+	}
+	if got := unread(); got != "1,2,3,4,5" {
+		t.Fatalf("unread after rollback=%q, want every chapter", got)
+	}
+}
+
+func TestReadingHistory_RecordsChangesAndUndoesLatest(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id64, err := database.AddManga("md-history", "History", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3", "4", "5"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%s): %v", number, err)
+		}
+	}
+
+	if addID, err := database.RecordMangaAdded(mangaID, HistorySourceImport); err != nil || addID == 0 {
+		t.Fatalf("RecordMangaAdded()=%d err=%v, want an entry even though progress didn't change", addID, err)
+	}
+	readID, err := database.MarkChapterAsReadFrom(mangaID, "2", HistorySourceButton)
+	if err != nil || readID == 0 {
+		t.Fatalf("MarkChapterAsReadFrom()=%d err=%v, want a history entry", readID, err)
+	}
+	if again, err := database.MarkChapterAsReadFrom(mangaID, "1", HistorySourceButton); err != nil || again != 0 {
+		t.Fatalf("re-marking a read chapter=%d err=%v, want no entry", again, err)
+	}
+	allID, err := database.MarkAllChaptersAsReadFrom(mangaID, HistorySourceText)
+	if err != nil || allID == 0 {
+		t.Fatalf("MarkAllChaptersAsReadFrom()=%d err=%v", allID, err)
+	}
+
+	// Only the latest change can be undone.
+	if _, ok, err := database.UndoProgress(readID, userID, HistorySourceButton); err != nil || ok {
+		t.Fatalf("undo of a superseded entry ok=%t err=%v, want refused", ok, err)
+	}
+	if _, _, err := database.UndoProgress(allID, 7, HistorySourceButton); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("undo by another user err=%v, want sql.ErrNoRows", err)
+	}
+	gotManga, ok, err := database.UndoProgress(allID, userID, HistorySourceText)
+	if err != nil || !ok || gotManga != mangaID {
+		t.Fatalf("UndoProgress()=(%d, %t) err=%v", gotManga, ok, err)
+	}
+	if unread, err := database.CountUnreadChapters(mangaID); err != nil || unread != 3 {
+		t.Fatalf("unread after undo=%d err=%v, want 3", unread, err)
+	}
+	if _, ok, _ := database.UndoProgress(allID, userID, HistorySourceButton); ok {
+		t.Fatalf("second undo should be refused")
+	}
+
+	entries, err := database.ListReadingHistory(mangaID, 10)
+	if err != nil {
+		t.Fatalf("ListReadingHistory(): %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%s/%s/%d/%t", e.Action, e.Source, e.ReadDelta, e.Undone))
+	}
+	want := "undo/text/-3/false,read_all/text/3/true,read/button/2/false,add/import/0/false"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("history=%v, want %s", got, want)
+	}
+}
+
+func TestReadingHistory_TrackingSwitchBlocksUndo(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id64, err := database.AddManga("md-switch", "Switch", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%s): %v", number, err)
+		}
+	}
+
+	readID, err := database.MarkChapterAsReadFrom(mangaID, "2", HistorySourceButton)
+	if err != nil || readID == 0 {
+		t.Fatalf("MarkChapterAsReadFrom()=%d err=%v", readID, err)
+	}
+	if err := database.SetExactTracking(mangaID, userID, true); err != nil {
+		t.Fatalf("SetExactTracking(): %v", err)
+	}
+	if _, ok, err := database.UndoProgress(readID, userID, HistorySourceButton); err != nil || ok {
+		t.Fatalf("undo across a tracking switch ok=%t err=%v, want refused", ok, err)
+	}
+	if unread, err := database.CountUnreadChapters(mangaID); err != nil || unread != 1 {
+		t.Fatalf("unread=%d err=%v, want progress kept", unread, err)
+	}
+
+	entries, err := database.ListReadingHistory(mangaID, 10)
+	if err != nil || len(entries) != 2 || entries[0].Action != HistoryActionExact {
+		t.Fatalf("history=%+v err=%v, want the switch recorded", entries, err)
+	}
+	if _, ok, err := database.UndoProgress(entries[0].ID, userID, HistorySourceButton); err != nil || ok {
+		t.Fatalf("undo of a tracking switch ok=%t err=%v, want refused", ok, err)
+	}
+	activity, err := database.ListReadingActivity(userID, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil || len(activity) != 1 || activity[0].Chapters != 2 {
+		t.Fatalf("activity=%+v err=%v, want only the read", activity, err)
+	}
+}
+
+func TestListReadingActivity_LeavesOutUndoneAndBulkChanges(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	userID := int64(42)
+	ensureTestUser(t, database, userID)
+	id64, err := database.AddManga("md-activity", "Activity", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+	now := time.Now()
+	for _, number := range []string{"1", "2", "3"} {
+		if err := database.AddChapter(id64, number, "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(%s): %v", number, err)
+		}
+	}
+	if err := database.MarkChapterAsRead(mangaID, "1"); err != nil {
+		t.Fatalf("MarkChapterAsRead(): %v", err)
+	}
+	allID, err := database.MarkAllChaptersAsReadFrom(mangaID, HistorySourceButton)
+	if err != nil {
+		t.Fatalf("MarkAllChaptersAsReadFrom(): %v", err)
+	}
+	if _, ok, err := database.UndoProgress(allID, userID, HistorySourceButton); err != nil || !ok {
+		t.Fatalf("UndoProgress() ok=%t err=%v", ok, err)
+	}
+	// A bulk catch-up isn't reading either.
+	if _, err := database.MarkAllChaptersAsReadFrom(mangaID, HistorySourceButton); err != nil {
+		t.Fatalf("MarkAllChaptersAsReadFrom(): %v", err)
+	}
+
+	activity, err := database.ListReadingActivity(userID, time.Time{}, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("ListReadingActivity(): %v", err)
+	}
+	if len(activity) != 1 || activity[0].Chapters != 1 || activity[0].Title != "Activity" {
+		t.Fatalf("activity=%+v, want only the single read", activity)
+	}
+	users, err := database.ListReadingActivityUsers(now.Add(-time.Hour), time.Now().Add(time.Minute))
+	if err != nil || len(users) != 1 || users[0] != userID {
+		t.Fatalf("ListReadingActivityUsers()=%v err=%v", users, err)
+	}
+}
+
+func TestTitleAliases_AddListRemoveAndCleanup(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	userID, otherID := int64(42), int64(43)
+	ensureTestUser(t, database, userID)
+	ensureTestUser(t, database, otherID)
+	id64, err := database.AddManga("md-1", "One Piece", userID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	mangaID := int(id64)
+
+	for _, alias := range []string{"op", "one p"} {
+		if added, err := database.AddTitleAlias(mangaID, userID, alias); err != nil || !added {
+			t.Fatalf("AddTitleAlias(%q)=%v err=%v", alias, added, err)
+		}
+	}
+	if added, err := database.AddTitleAlias(mangaID, userID, "op"); err != nil || added {
+		t.Fatalf("AddTitleAlias(duplicate)=%v err=%v, want false", added, err)
+	}
+	if _, err := database.AddTitleAlias(mangaID, otherID, "mine"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("AddTitleAlias(other user) err=%v, want sql.ErrNoRows", err)
+	}
+
+	aliases, err := database.ListTitleAliases(mangaID)
+	if err != nil || strings.Join(aliases, ",") != "one p,op" {
+		t.Fatalf("ListTitleAliases()=%v err=%v", aliases, err)
+	}
+	byUser, err := database.ListTitleAliasesByUser(otherID)
+	if err != nil || len(byUser) != 0 {
+		t.Fatalf("ListTitleAliasesByUser(other)=%v err=%v", byUser, err)
+	}
+
+	if err := database.RemoveTitleAlias(mangaID, otherID, "op"); err != nil {
+		t.Fatalf("RemoveTitleAlias(other user): %v", err)
+	}
+	if err := database.RemoveTitleAlias(mangaID, userID, "one p"); err != nil {
+		t.Fatalf("RemoveTitleAlias(): %v", err)
+	}
+	byUser, err = database.ListTitleAliasesByUser(userID)
+	if err != nil || len(byUser[mangaID]) != 1 || byUser[mangaID][0] != "op" {
+		t.Fatalf("ListTitleAliasesByUser()=%v err=%v", byUser, err)
+	}
+
+	if err := database.DeleteManga(mangaID, userID); err != nil {
+		t.Fatalf("DeleteManga(): %v", err)
+	}
+	if aliases, err := database.ListTitleAliases(mangaID); err != nil || len(aliases) != 0 {
+		t.Fatalf("aliases after delete=%v err=%v", aliases, err)
+	}
+}
+
+func TestGroupChats_LinkMoveAndUnlink(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	const (
+		groupID      = int64(-100)
+		supergroupID = int64(-1001234)
+		code         = "ABCD-1234"
+	)
+	if err := database.CreateGroupLinkCode(code, 1, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("CreateGroupLinkCode(): %v", err)
+	}
+	if err := database.CreateGroupLinkCode("DEAD-BEEF", 1, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("CreateGroupLinkCode(expired): %v", err)
+	}
+	if linked, err := database.LinkGroupChat("DEAD-BEEF", groupID, "Club", 42); err != nil || linked {
+		t.Fatalf("LinkGroupChat(expired) = %v, %v; want false", linked, err)
+	}
+	if linked, err := database.LinkGroupChat(code, groupID, "Club", 42); err != nil || !linked {
+		t.Fatalf("LinkGroupChat() = %v, %v; want true", linked, err)
+	}
+	if linked, err := database.LinkGroupChat(code, -200, "Other", 42); err != nil || linked {
+		t.Fatalf("LinkGroupChat(used) = %v, %v; want false", linked, err)
+	}
+
+	mangaID, err := database.AddManga("md-1", "Shared Title", groupID)
+	if err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	if err := database.AddChapter(mangaID, "1", "", time.Now(), time.Now(), time.Now(), time.Now()); err != nil {
+		t.Fatalf("AddChapter(): %v", err)
+	}
+
+	if err := database.MoveGroupChat(groupID, supergroupID); err != nil {
+		t.Fatalf("MoveGroupChat(): %v", err)
+	}
+	if linked, _ := database.IsGroupChatLinked(groupID); linked {
+		t.Fatal("old group ID is still linked after the move")
+	}
+	g, err := database.GetGroupChat(supergroupID)
+	if err != nil || g.Title != "Club" || g.LinkedBy != 42 {
+		t.Fatalf("GetGroupChat() = %+v, %v; want the moved group", g, err)
+	}
+	if owned, _ := database.MangaBelongsToUser(int(mangaID), supergroupID); !owned {
+		t.Fatal("shared title did not move with the group")
+	}
+	if authorized, _, _ := database.IsUserAuthorized(groupID); authorized {
+		t.Fatal("old group ID still has a users row")
+	}
+
+	if err := database.UnlinkGroupChat(supergroupID); err != nil {
+		t.Fatalf("UnlinkGroupChat(): %v", err)
+	}
+	var remaining int
+	if err := database.QueryRow("SELECT COUNT(*) FROM manga WHERE user_id = ?", supergroupID).Scan(&remaining); err != nil || remaining != 0 {
+		t.Fatalf("group manga after unlink = %d, %v; want 0", remaining, err)
+	}
+	if err := database.UnlinkGroupChat(supergroupID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("UnlinkGroupChat(again) err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestFriendsReading_OnlySharedLibrariesAndProgress(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+
+	const (
+		me      = int64(10)
+		alice   = int64(20)
+		bob     = int64(30)
+		private = int64(40)
+		groupID = int64(-100)
+	)
+	now := time.Now()
+	for _, u := range []struct {
+		id            int64
+		name          string
+		shareProgress bool
+	}{
+		{me, "Me", false},
+		{alice, "Alice", true},
+		{bob, "Bob", false},
+		{private, "Private", false},
+	} {
+		ensureTestUser(t, database, u.id)
+		if err := database.SetUserDisplayName(u.id, u.name); err != nil {
+			t.Fatalf("SetUserDisplayName(): %v", err)
+		}
+		if u.id != private {
+			if err := database.SetUserShareLibrary(u.id, true); err != nil {
+				t.Fatalf("SetUserShareLibrary(): %v", err)
+			}
+		}
+		if err := database.SetUserShareProgress(u.id, u.shareProgress); err != nil {
+			t.Fatalf("SetUserShareProgress(): %v", err)
+		}
+		mangaID, err := database.AddManga("md-frieren", "Frieren", u.id)
+		if err != nil {
+			t.Fatalf("AddManga(): %v", err)
+		}
+		if err := database.AddChapter(mangaID, "12", "", now, now, now, now); err != nil {
+			t.Fatalf("AddChapter(): %v", err)
+		}
+		if err := database.MarkChapterAsRead(int(mangaID), "12"); err != nil {
+			t.Fatalf("MarkChapterAsRead(): %v", err)
+		}
+		if u.id == alice {
+			// Exact reads leave last_read_number behind; progress follows the reads.
+			if err := database.SetExactTracking(int(mangaID), alice, true); err != nil {
+				t.Fatalf("SetExactTracking(): %v", err)
+			}
+			for _, number := range []string{"13", "14"} {
+				if err := database.AddChapter(mangaID, number, "", now, now, now, now); err != nil {
+					t.Fatalf("AddChapter(): %v", err)
+				}
+			}
+			if err := database.MarkChapterAsRead(int(mangaID), "14"); err != nil {
+				t.Fatalf("MarkChapterAsRead(): %v", err)
+			}
+		}
+	}
+	ensureTestUser(t, database, groupID)
+	if err := database.SetUserShareLibrary(groupID, true); err != nil {
+		t.Fatalf("SetUserShareLibrary(group): %v", err)
+	}
+	if _, err := database.AddManga("md-frieren", "Frieren", groupID); err != nil {
+		t.Fatalf("AddManga(group): %v", err)
+	}
+	if _, err := database.AddManga("md-blue-lock", "Blue Lock", bob); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+
+	titles, err := database.ListFriendsReading(me)
+	if err != nil {
+		t.Fatalf("ListFriendsReading(): %v", err)
+	}
+	if len(titles) != 2 || titles[0].Title != "Frieren" || titles[1].Title != "Blue Lock" {
+		t.Fatalf("titles=%+v, want Frieren then Blue Lock", titles)
+	}
+	want := []FriendReader{
+		{ChatID: alice, Name: "Alice", HasLastRead: true, LastRead: 14},
+		{ChatID: bob, Name: "Bob"},
+	}
+	if len(titles[0].Readers) != len(want) {
+		t.Fatalf("readers=%+v, want %+v", titles[0].Readers, want)
+	}
+	for i, r := range titles[0].Readers {
+		if r != want[i] {
+			t.Fatalf("reader %d=%+v, want %+v", i, r, want[i])
+		}
+	}
+
+	friends, err := database.ListFriends(me)
+	if err != nil {
+		t.Fatalf("ListFriends(): %v", err)
+	}
+	// Private and the admin don't share their lists, and the group isn't a person.
+	if len(friends) != 2 || friends[0].Name != "Alice" || friends[1].Name != "Bob" {
+		t.Fatalf("friends=%+v, want only Alice and Bob", friends)
+	}
+	for id, want := range map[int64]bool{alice: true, private: false, groupID: false, me: false} {
+		if got, err := database.IsFriend(me, id); err != nil || got != want {
+			t.Fatalf("IsFriend(%d)=%t err=%v, want %t", id, got, err, want)
+		}
+	}
+}
+
+func TestRecommendations_CreateAddAndCleanUp(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	database, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	ensureTestUser(t, database, 10)
+	ensureTestUser(t, database, 20)
+	if err := database.SetUserDisplayName(10, "Alice"); err != nil {
+		t.Fatalf("SetUserDisplayName(): %v", err)
+	}
+
+	id, err := database.CreateRecommendation(10, 20, "md-frieren", "Frieren", true)
+	if err != nil {
+		t.Fatalf("CreateRecommendation(): %v", err)
+	}
+	if n, err := database.CountRecommendationsSince(10, 20, time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Fatalf("CountRecommendationsSince()=%d err=%v, want 1", n, err)
+	}
+	if n, err := database.CountRecommendationsSince(20, 10, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("CountRecommendationsSince(reverse)=%d err=%v, want 0", n, err)
+	}
+	rec, err := database.GetRecommendation(id)
+	if err != nil {
+		t.Fatalf("GetRecommendation(): %v", err)
+	}
+	if rec.FromName != "Alice" || rec.ToName != "" || rec.ToUserID != 20 || !rec.IsMangaPlus || rec.Added {
+		t.Fatalf("recommendation=%+v", rec)
+	}
+	if err := database.MarkRecommendationAdded(id); err != nil {
+		t.Fatalf("MarkRecommendationAdded(): %v", err)
+	}
+	if rec, err = database.GetRecommendation(id); err != nil || !rec.Added {
+		t.Fatalf("recommendation after add=%+v err=%v, want added", rec, err)
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatalf("Begin(): %v", err)
+	}
+	if err := deleteChatData(tx, 20); err != nil {
+		_ = tx.Rollback()
+		t.Fatalf("deleteChatData(): %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit(): %v", err)
+	}
+	if _, err := database.GetRecommendation(id); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetRecommendation() after removal err=%v, want sql.ErrNoRows", err)
+	}
+}
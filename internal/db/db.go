@@ -27,6 +27,24 @@ func New(path string) (*DB, error) {
 	return &DB{db}, nil
 }
 
+// OpenReadOnly opens an existing database for reading only. Unlike New it never creates
+// the file or touches the journal mode, so it is safe to use next to a running bot.
+func OpenReadOnly(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &DB{db}, nil
+}
+
 // Close closes the database connection.
 func (db *DB) Close() error {
 	return db.DB.Close()
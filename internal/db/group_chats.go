@@ -0,0 +1,167 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IsGroupChatID reports whether chatID belongs to a group chat. Telegram gives groups
+// negative IDs, so a manga row owned by one is part of a group's shared library.
+func IsGroupChatID(chatID int64) bool {
+	return chatID < 0
+}
+
+// CreateGroupLinkCode stores a single-use code that links a group chat.
+func (db *DB) CreateGroupLinkCode(code string, adminChatID int64, expiresAt time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO group_link_codes (code, created_by_admin, created_at, expires_at)
+		VALUES (?, ?, ?, ?)
+	`, code, adminChatID, time.Now().UTC(), expiresAt.UTC())
+	return err
+}
+
+// LinkGroupChat consumes code and links chatID, registering the group as the owner of
+// its shared library. It returns false when the code is unknown, used or expired.
+func (db *DB) LinkGroupChat(code string, chatID int64, title string, linkedBy int64) (linked bool, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	now := time.Now().UTC()
+	res, err := tx.Exec(`
+		UPDATE group_link_codes
+		SET used_by_chat_id = ?, used_at = ?
+		WHERE code = ? AND used_at IS NULL AND expires_at > ?
+	`, chatID, now, code, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec(`
+		INSERT OR IGNORE INTO users (chat_id, is_admin, created_at)
+		VALUES (?, 0, CURRENT_TIMESTAMP)
+	`, chatID); err != nil {
+		return false, err
+	}
+	if _, err = tx.Exec(`
+		INSERT INTO group_chats (chat_id, title, linked_by, linked_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id) DO UPDATE SET title = excluded.title
+	`, chatID, title, linkedBy, now); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetGroupChat returns the linked group chatID, or sql.ErrNoRows when it isn't linked.
+func (db *DB) GetGroupChat(chatID int64) (GroupChat, error) {
+	g := GroupChat{ChatID: chatID}
+	err := db.QueryRow("SELECT title, linked_by, linked_at FROM group_chats WHERE chat_id = ?", chatID).
+		Scan(&g.Title, &g.LinkedBy, &g.LinkedAt)
+	return g, err
+}
+
+// IsGroupChatLinked reports whether chatID is a linked group.
+func (db *DB) IsGroupChatLinked(chatID int64) (bool, error) {
+	_, err := db.GetGroupChat(chatID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UnlinkGroupChat removes a linked group together with its shared library. It returns
+// sql.ErrNoRows when chatID isn't linked.
+func (db *DB) UnlinkGroupChat(chatID int64) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	res, err := tx.Exec("DELETE FROM group_chats WHERE chat_id = ?", chatID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		err = sql.ErrNoRows
+		return err
+	}
+	return deleteChatData(tx, chatID)
+}
+
+// MoveGroupChat moves a linked group and its shared library to newChatID, for groups
+// Telegram upgraded to a supergroup. It does nothing when oldChatID isn't linked.
+func (db *DB) MoveGroupChat(oldChatID, newChatID int64) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	for _, query := range []string{
+		"INSERT OR IGNORE INTO users (chat_id, is_admin, created_at) SELECT ?, 0, CURRENT_TIMESTAMP FROM group_chats WHERE chat_id = ?",
+		"INSERT OR IGNORE INTO group_chats (chat_id, title, linked_by, linked_at) SELECT ?, title, linked_by, linked_at FROM group_chats WHERE chat_id = ?",
+		"UPDATE manga SET user_id = ? WHERE user_id = ? AND EXISTS (SELECT 1 FROM group_chats WHERE chat_id = manga.user_id)",
+	} {
+		if _, err = tx.Exec(query, newChatID, oldChatID); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.Exec("DELETE FROM group_chats WHERE chat_id = ?", oldChatID); err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM users WHERE chat_id = ? AND NOT EXISTS (SELECT 1 FROM manga WHERE user_id = ?)", oldChatID, oldChatID)
+	return err
+}
+
+// deleteChatData removes a chat's user row together with its tracked manga and reading
+// data.
+func deleteChatData(tx *sql.Tx, chatID int64) error {
+	for _, query := range []string{
+		"DELETE FROM chapters WHERE manga_id IN (SELECT id FROM manga WHERE user_id = ?)",
+		"DELETE FROM chapter_reads WHERE manga_id IN (SELECT id FROM manga WHERE user_id = ?)",
+		"DELETE FROM title_aliases WHERE manga_id IN (SELECT id FROM manga WHERE user_id = ?)",
+		"DELETE FROM manga WHERE user_id = ?",
+		"DELETE FROM reading_history WHERE user_id = ?",
+		"DELETE FROM category_mutes WHERE user_id = ?",
+		"DELETE FROM recommendations WHERE from_user_id = ?1 OR to_user_id = ?1",
+		"DELETE FROM users WHERE chat_id = ?",
+	} {
+		if _, err := tx.Exec(query, chatID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LibraryFormatVersion is the version /export writes and /import accepts.
+const LibraryFormatVersion = 1
+
+// LibraryExport is a user's library as /export writes it and /import reads it back.
+type LibraryExport struct {
+	Version         int            `json:"version"`
+	ExportedAt      time.Time      `json:"exported_at"`
+	MutedCategories []string       `json:"muted_categories"`
+	Titles          []LibraryTitle `json:"titles"`
+}
+
+// LibraryTitle is one exported title. ID is the title's ID on Source: the MangaDex ID,
+// or the feed URL for RSS.
+type LibraryTitle struct {
+	Source   string `json:"source"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+}
+
+// ExportLibrary returns the user's titles, sorted by title, with their categories and the
+// categories the user muted.
+func (db *DB) ExportLibrary(userID int64) (LibraryExport, error) {
+	manga, err := db.ListMangaByUser(userID, ListOptions{Sort: SortTitle})
+	if err != nil {
+		return LibraryExport{}, err
+	}
+	muted, err := db.MutedCategories(userID)
+	if err != nil {
+		return LibraryExport{}, err
+	}
+
+	lib := LibraryExport{
+		Version:         LibraryFormatVersion,
+		ExportedAt:      time.Now().UTC(),
+		MutedCategories: []string{},
+		Titles:          make([]LibraryTitle, 0, len(manga)),
+	}
+	for _, category := range Categories {
+		if muted[category] {
+			lib.MutedCategories = append(lib.MutedCategories, category)
+		}
+	}
+	for _, m := range manga {
+		lib.Titles = append(lib.Titles, LibraryTitle{
+			Source:   m.Source,
+			ID:       m.TrackedID(),
+			Title:    m.Title,
+			Category: m.Category,
+		})
+	}
+	return lib, nil
+}
+
+// ImportLibraryTitle adds t to the user's library, or files it under t's category if the
+// user already tracks it. An empty category means CategoryReading. It returns the title's
+// row ID and whether the title was added.
+func (db *DB) ImportLibraryTitle(userID int64, t LibraryTitle) (mangaID int64, added bool, err error) {
+	category := t.Category
+	if category == "" {
+		category = CategoryReading
+	}
+	if !IsCategory(category) {
+		return 0, false, fmt.Errorf("unknown category %q", t.Category)
+	}
+
+	idColumn := "source_id"
+	if t.Source == "mangadex" {
+		idColumn = "mangadex_id"
+	}
+	err = db.QueryRow("SELECT id FROM manga WHERE user_id = ? AND source = ? AND "+idColumn+" = ?", userID, t.Source, t.ID).Scan(&mangaID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if mangaID, err = db.AddMangaFromSource(t.Source, t.ID, t.Title, false, userID); err != nil {
+			return 0, false, err
+		}
+		added = true
+	case err != nil:
+		return 0, false, err
+	}
+
+	if _, err := db.Exec("UPDATE manga SET category = ? WHERE id = ?", category, mangaID); err != nil {
+		return mangaID, added, err
+	}
+	return mangaID, added, nil
+}
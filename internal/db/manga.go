@@ -16,18 +16,34 @@ func (db *DB) AddManga(mangaID, title string, userID int64) (int64, error) {
 }
 
 func (db *DB) AddMangaWithMangaPlus(mangaID, title string, isMangaPlus bool, userID int64) (int64, error) {
+	return db.AddMangaFromSource("mangadex", mangaID, title, isMangaPlus, userID)
+}
+
+// AddMangaFromSource adds a title tracked on source, where sourceID is its ID there.
+func (db *DB) AddMangaFromSource(source, sourceID, title string, isMangaPlus bool, userID int64) (int64, error) {
 	val := 0
 	if isMangaPlus {
 		val = 1
 	}
-	result, err := db.Exec("INSERT INTO manga (user_id, mangadex_id, title, is_manga_plus, last_checked) VALUES (?, ?, ?, ?, ?)",
-		userID, mangaID, title, val, time.Now().UTC())
+	mangaDexID := ""
+	if source == "mangadex" {
+		mangaDexID, sourceID = sourceID, ""
+	}
+	result, err := db.Exec("INSERT INTO manga (user_id, mangadex_id, source, source_id, title, is_manga_plus, last_checked) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		userID, mangaDexID, source, sourceID, title, val, time.Now().UTC())
 	if err != nil {
 		return 0, err
 	}
 	return result.LastInsertId()
 }
 
+// GetMangaSource returns where the title is tracked.
+func (db *DB) GetMangaSource(mangaID int) (string, error) {
+	var source string
+	err := db.QueryRow("SELECT source FROM manga WHERE id = ?", mangaID).Scan(&source)
+	return source, err
+}
+
 func (db *DB) IsMangaPlus(mangaID int) (bool, error) {
 	var v int
 	if err := db.QueryRow("SELECT is_manga_plus FROM manga WHERE id = ?", mangaID).Scan(&v); err != nil {
@@ -36,28 +52,38 @@ func (db *DB) IsMangaPlus(mangaID int) (bool, error) {
 	return v != 0, nil
 }
 
+// SetMangaPlus records the user's choice for the MANGA Plus flag. Detection never overrides it.
 func (db *DB) SetMangaPlus(mangaID int, isMangaPlus bool) error {
 	val := 0
 	if isMangaPlus {
 		val = 1
 	}
-	_, err := db.Exec("UPDATE manga SET is_manga_plus = ? WHERE id = ?", val, mangaID)
+	_, err := db.Exec("UPDATE manga SET is_manga_plus = ?, manga_plus_set = 1 WHERE id = ?", val, mangaID)
 	return err
 }
 
+// MarkMangaPlusDetected flags a title whose chapters turned out to be on MANGA Plus, unless
+// the user has set the flag themselves.
+func (db *DB) MarkMangaPlusDetected(mangaID int) error {
+	_, err := db.Exec("UPDATE manga SET is_manga_plus = 1 WHERE id = ? AND manga_plus_set = 0", mangaID)
+	return err
+}
+
+// GetManga returns the title's ID on its source, its title, and when it was last checked
+// and last saw a new chapter.
 func (db *DB) GetManga(mangaID int) (string, string, time.Time, time.Time, error) {
-	var mangadexID, title string
+	var trackedID, title string
 	var lastChecked time.Time
 	var lastSeenAt sql.NullTime
-	err := db.QueryRow("SELECT mangadex_id, title, last_checked, last_seen_at FROM manga WHERE id = ?", mangaID).
-		Scan(&mangadexID, &title, &lastChecked, &lastSeenAt)
+	err := db.QueryRow("SELECT CASE WHEN source_id != '' THEN source_id ELSE mangadex_id END, title, last_checked, last_seen_at FROM manga WHERE id = ?", mangaID).
+		Scan(&trackedID, &title, &lastChecked, &lastSeenAt)
 	if err != nil {
 		return "", "", time.Time{}, time.Time{}, err
 	}
 	if lastSeenAt.Valid {
-		return mangadexID, title, lastChecked, lastSeenAt.Time, nil
+		return trackedID, title, lastChecked, lastSeenAt.Time, nil
 	}
-	return mangadexID, title, lastChecked, time.Time{}, nil
+	return trackedID, title, lastChecked, time.Time{}, nil
 }
 
 func (db *DB) UpdateMangaLastChecked(mangaID int) error {
@@ -74,7 +100,12 @@ func (db *DB) UpdateMangaLastSeenAt(mangaID int, seenAt time.Time) error {
 
 func (db *DB) GetAllManga() (*sql.Rows, error) {
 	// Use GetAllMangaByUser in normal flows to avoid accidental cross-user leakage.
-	return db.Query("SELECT id, user_id, mangadex_id, title, is_manga_plus, last_checked, last_seen_at, last_read_number, unread_count FROM manga")
+	// Titles of disabled users aren't checked; their lists are kept for when they return.
+	return db.Query(`
+		SELECT id, user_id, mangadex_id, source, source_id, title, is_manga_plus, last_checked, last_seen_at, last_read_number, unread_count, consecutive_failures, next_check_at
+		FROM manga
+		WHERE user_id NOT IN (SELECT chat_id FROM users WHERE disabled_at IS NOT NULL)
+	`)
 }
 
 func (db *DB) GetAllMangaByUser(userID int64) (*sql.Rows, error) {
@@ -94,9 +125,13 @@ func (db *DB) ListManga() ([]Manga, error) {
 		var isMangaPlus int
 		var lastSeenAt sql.NullTime
 		var lastReadNumber sql.NullFloat64
-		if err := rows.Scan(&row.ID, &row.UserID, &row.MangaDexID, &row.Title, &isMangaPlus, &row.LastChecked, &lastSeenAt, &lastReadNumber, &row.UnreadCount); err != nil {
+		var nextCheckAt sql.NullTime
+		if err := rows.Scan(&row.ID, &row.UserID, &row.MangaDexID, &row.Source, &row.SourceID, &row.Title, &isMangaPlus, &row.LastChecked, &lastSeenAt, &lastReadNumber, &row.UnreadCount, &row.ConsecutiveFailures, &nextCheckAt); err != nil {
 			return nil, err
 		}
+		if nextCheckAt.Valid {
+			row.NextCheckAt = nextCheckAt.Time
+		}
 		row.IsMangaPlus = isMangaPlus != 0
 		if lastSeenAt.Valid {
 			row.LastSeenAt = lastSeenAt.Time
@@ -120,6 +155,8 @@ func (db *DB) GetMangaDetails(mangaID int, userID int64) (MangaDetails, error) {
 		lastReadNum    sql.NullFloat64
 		minNum         sql.NullFloat64
 		maxNum         sql.NullFloat64
+		lastErrorAtStr string
+		nextCheckAtStr string
 	)
 
 	var d MangaDetails
@@ -128,6 +165,8 @@ func (db *DB) GetMangaDetails(mangaID int, userID int64) (MangaDetails, error) {
 			m.id,
 			m.user_id,
 			m.mangadex_id,
+			m.source,
+			m.source_id,
 			m.title,
 			m.is_manga_plus,
 			COALESCE(CAST(m.last_checked AS TEXT), ''),
@@ -137,13 +176,20 @@ func (db *DB) GetMangaDetails(mangaID int, userID int64) (MangaDetails, error) {
 			(SELECT COUNT(*) FROM chapters c WHERE c.manga_id = m.id),
 			(SELECT COUNT(*) FROM chapters c WHERE c.manga_id = m.id AND c.chapter_number GLOB '[0-9]*' AND c.chapter_number NOT GLOB '*[^0-9.]*' AND c.chapter_number NOT GLOB '*.*.*'),
 			(SELECT MIN(CAST(c.chapter_number AS REAL)) FROM chapters c WHERE c.manga_id = m.id AND c.chapter_number GLOB '[0-9]*' AND c.chapter_number NOT GLOB '*[^0-9.]*' AND c.chapter_number NOT GLOB '*.*.*'),
-			(SELECT MAX(CAST(c.chapter_number AS REAL)) FROM chapters c WHERE c.manga_id = m.id AND c.chapter_number GLOB '[0-9]*' AND c.chapter_number NOT GLOB '*[^0-9.]*' AND c.chapter_number NOT GLOB '*.*.*')
+			(SELECT MAX(CAST(c.chapter_number AS REAL)) FROM chapters c WHERE c.manga_id = m.id AND c.chapter_number GLOB '[0-9]*' AND c.chapter_number NOT GLOB '*[^0-9.]*' AND c.chapter_number NOT GLOB '*.*.*'),
+			m.consecutive_failures,
+			COALESCE(m.last_error, ''),
+			COALESCE(CAST(m.last_error_at AS TEXT), ''),
+			COALESCE(CAST(m.next_check_at AS TEXT), ''),
+			COALESCE(m.merge_candidate, '')
 		FROM manga m
 		WHERE m.id = ? AND m.user_id = ?
 	`, mangaID, userID).Scan(
 		&d.ID,
 		&d.UserID,
 		&d.MangaDexID,
+		&d.Source,
+		&d.SourceID,
 		&d.Title,
 		&isMangaPlus,
 		&lastCheckedStr,
@@ -154,6 +200,11 @@ func (db *DB) GetMangaDetails(mangaID int, userID int64) (MangaDetails, error) {
 		&d.NumericChaptersTotal,
 		&minNum,
 		&maxNum,
+		&d.ConsecutiveFailures,
+		&d.LastError,
+		&lastErrorAtStr,
+		&nextCheckAtStr,
+		&d.MergeCandidate,
 	)
 	if err != nil {
 		return MangaDetails{}, err
@@ -172,6 +223,18 @@ func (db *DB) GetMangaDetails(mangaID int, userID int64) (MangaDetails, error) {
 			d.HasLastSeenAt = true
 		}
 	}
+	if strings.TrimSpace(lastErrorAtStr) != "" {
+		if t, err := parseSQLiteTime(lastErrorAtStr); err == nil {
+			d.LastErrorAt = t
+			d.HasLastErrorAt = true
+		}
+	}
+	if strings.TrimSpace(nextCheckAtStr) != "" {
+		if t, err := parseSQLiteTime(nextCheckAtStr); err == nil {
+			d.NextCheckAt = t
+			d.HasNextCheckAt = true
+		}
+	}
 	if lastReadNum.Valid {
 		d.LastReadNumber = lastReadNum.Float64
 		d.HasLastReadNumber = true
@@ -210,11 +273,19 @@ func (db *DB) DeleteManga(mangaID int, userID int64) error {
 		}
 	}()
 
-	// Delete associated chapters first
+	// Delete associated chapters and read records first
 	_, err = tx.Exec("DELETE FROM chapters WHERE manga_id = ? AND manga_id IN (SELECT id FROM manga WHERE id = ? AND user_id = ?)", mangaID, mangaID, userID)
 	if err != nil {
 		return err
 	}
+	_, err = tx.Exec("DELETE FROM chapter_reads WHERE manga_id = ? AND manga_id IN (SELECT id FROM manga WHERE id = ? AND user_id = ?)", mangaID, mangaID, userID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM title_aliases WHERE manga_id = ? AND manga_id IN (SELECT id FROM manga WHERE id = ? AND user_id = ?)", mangaID, mangaID, userID)
+	if err != nil {
+		return err
+	}
 
 	// Delete the manga
 	_, err = tx.Exec("DELETE FROM manga WHERE id = ? AND user_id = ?", mangaID, userID)
@@ -236,3 +307,9 @@ func (db *DB) MangaBelongsToUser(mangaID int, userID int64) (bool, error) {
 	}
 	return true, nil
 }
+
+func (db *DB) CountMangaByUser(userID int64) (int, error) {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM manga WHERE user_id = ?", userID).Scan(&n)
+	return n, err
+}
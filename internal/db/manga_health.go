@@ -0,0 +1,71 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RecordMangaFailure bumps the title's consecutive failure count, stores the error and
+// returns the new count.
+func (db *DB) RecordMangaFailure(mangaID int, errText string, failedAt time.Time) (int, error) {
+	if _, err := db.Exec(`
+		UPDATE manga
+		SET consecutive_failures = consecutive_failures + 1, last_error = ?, last_error_at = ?
+		WHERE id = ?
+	`, errText, failedAt.UTC(), mangaID); err != nil {
+		return 0, err
+	}
+	var failures int
+	err := db.QueryRow("SELECT consecutive_failures FROM manga WHERE id = ?", mangaID).Scan(&failures)
+	return failures, err
+}
+
+// SetMangaNextCheckAt defers scheduled checks of the title until at.
+func (db *DB) SetMangaNextCheckAt(mangaID int, at time.Time) error {
+	_, err := db.Exec("UPDATE manga SET next_check_at = ? WHERE id = ?", at.UTC(), mangaID)
+	return err
+}
+
+// ClearMangaFailures resets failure tracking after a successful check. The last error is
+// kept for reference.
+func (db *DB) ClearMangaFailures(mangaID int) error {
+	_, err := db.Exec(`
+		UPDATE manga
+		SET consecutive_failures = 0, next_check_at = NULL, failure_alerted = 0
+		WHERE id = ? AND (consecutive_failures > 0 OR next_check_at IS NOT NULL OR failure_alerted != 0)
+	`, mangaID)
+	return err
+}
+
+// ClaimMangaFailureAlert marks the current failure streak as alerted. It returns false if
+// the owner was already told about this streak.
+func (db *DB) ClaimMangaFailureAlert(mangaID int) (bool, error) {
+	res, err := db.Exec("UPDATE manga SET failure_alerted = 1 WHERE id = ? AND failure_alerted = 0", mangaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReplaceMangaDexID points an existing title at a different MangaDex entry (moving it to
+// the MangaDex source if it came from elsewhere) and clears its failure state so it is
+// checked on the next run.
+func (db *DB) ReplaceMangaDexID(mangaID int, userID int64, mangaDexID string) error {
+	res, err := db.Exec(`
+		UPDATE manga
+		SET mangadex_id = ?, source = 'mangadex', source_id = '', consecutive_failures = 0, next_check_at = NULL, failure_alerted = 0
+		WHERE id = ? AND user_id = ?
+	`, mangaDexID, mangaID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
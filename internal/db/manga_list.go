@@ -0,0 +1,174 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sort orders for the followed-manga list.
+const (
+	SortTitle       = "title"
+	SortUnread      = "unread"
+	SortLastRelease = "release"
+	SortRecent      = "recent"
+)
+
+// ListSorts lists the sort orders in the order the list cycles through them.
+var ListSorts = []string{SortTitle, SortUnread, SortLastRelease, SortRecent}
+
+// Filters for the followed-manga list.
+const (
+	FilterUnread    = "unread"
+	FilterMangaPlus = "plus"
+	FilterFailing   = "failing"
+)
+
+// ListFilters lists the list filters in display order.
+var ListFilters = []string{FilterUnread, FilterMangaPlus, FilterFailing}
+
+var listOrderBy = map[string]string{
+	SortTitle:       "title COLLATE NOCASE, id",
+	SortUnread:      "unread_count DESC, title COLLATE NOCASE, id",
+	SortLastRelease: "last_seen_at IS NULL, last_seen_at DESC, title COLLATE NOCASE, id",
+	SortRecent:      "id DESC",
+}
+
+var listFilterWhere = map[string]string{
+	FilterUnread:    "unread_count > 0",
+	FilterMangaPlus: "is_manga_plus = 1",
+	FilterFailing:   "consecutive_failures > 0",
+}
+
+func IsListSort(s string) bool {
+	_, ok := listOrderBy[s]
+	return ok
+}
+
+func IsListFilter(f string) bool {
+	_, ok := listFilterWhere[f]
+	return ok
+}
+
+// ListOptions narrows and orders ListMangaByUser. Empty fields don't filter; an empty
+// Sort means SortTitle.
+type ListOptions struct {
+	Category string
+	Filter   string
+	Sort     string
+	// Query keeps titles containing every word of it, ignoring case.
+	Query string
+}
+
+// ListMangaByUser returns the user's titles matching opts.
+func (db *DB) ListMangaByUser(userID int64, opts ListOptions) ([]Manga, error) {
+	where := []string{"user_id = ?"}
+	args := []any{userID}
+	if opts.Category != "" {
+		where = append(where, "category = ?")
+		args = append(args, opts.Category)
+	}
+	if opts.Filter != "" {
+		cond, ok := listFilterWhere[opts.Filter]
+		if !ok {
+			return nil, fmt.Errorf("unknown list filter %q", opts.Filter)
+		}
+		where = append(where, cond)
+	}
+	for _, word := range strings.Fields(opts.Query) {
+		where = append(where, `title LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(word)+"%")
+	}
+	sort := opts.Sort
+	if sort == "" {
+		sort = SortTitle
+	}
+	orderBy, ok := listOrderBy[sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown list sort %q", sort)
+	}
+
+	rows, err := db.Query(`
+		SELECT id, mangadex_id, source, source_id, title, is_manga_plus, last_seen_at, unread_count, category, consecutive_failures
+		FROM manga
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+orderBy, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var manga []Manga
+	for rows.Next() {
+		row := Manga{UserID: userID}
+		var (
+			isMangaPlus int
+			lastSeenAt  sql.NullTime
+		)
+		if err := rows.Scan(&row.ID, &row.MangaDexID, &row.Source, &row.SourceID, &row.Title, &isMangaPlus, &lastSeenAt, &row.UnreadCount, &row.Category, &row.ConsecutiveFailures); err != nil {
+			return nil, err
+		}
+		row.IsMangaPlus = isMangaPlus != 0
+		if lastSeenAt.Valid {
+			row.LastSeenAt = lastSeenAt.Time
+		}
+		manga = append(manga, row)
+	}
+	return manga, rows.Err()
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetUserListSort returns the user's saved list sort (SortTitle if never set).
+func (db *DB) GetUserListSort(userID int64) (string, error) {
+	var sort string
+	err := db.QueryRow("SELECT list_sort FROM users WHERE chat_id = ?", userID).Scan(&sort)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !IsListSort(sort)) {
+		return SortTitle, nil
+	}
+	return sort, err
+}
+
+func (db *DB) SetUserListSort(userID int64, sort string) error {
+	if !IsListSort(sort) {
+		return fmt.Errorf("unknown list sort %q", sort)
+	}
+	_, err := db.Exec("UPDATE users SET list_sort = ? WHERE chat_id = ?", sort, userID)
+	return err
+}
+
+// ListPosition is where a user last was in the followed-manga list.
+type ListPosition struct {
+	Category string
+	Filter   string
+	Page     int
+}
+
+// GetUserListPosition returns the user's last list position. Positions pointing at a
+// category or filter that no longer exists fall back to the first page of everything.
+func (db *DB) GetUserListPosition(userID int64) (ListPosition, error) {
+	var pos ListPosition
+	err := db.QueryRow("SELECT list_category, list_filter, list_page FROM users WHERE chat_id = ?", userID).
+		Scan(&pos.Category, &pos.Filter, &pos.Page)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ListPosition{}, nil
+	}
+	if err != nil {
+		return ListPosition{}, err
+	}
+	if (pos.Category != "" && !IsCategory(pos.Category)) || (pos.Filter != "" && !IsListFilter(pos.Filter)) {
+		return ListPosition{}, nil
+	}
+	pos.Page = max(pos.Page, 0)
+	return pos, nil
+}
+
+func (db *DB) SetUserListPosition(userID int64, pos ListPosition) error {
+	_, err := db.Exec("UPDATE users SET list_category = ?, list_filter = ?, list_page = ? WHERE chat_id = ?",
+		pos.Category, pos.Filter, max(pos.Page, 0), userID)
+	return err
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// maxAliasHops bounds alias chains (A merged into B, later B into C).
+const maxAliasHops = 5
+
+// SetMangaMergeCandidate remembers the entry a title appears to have been merged into. It
+// returns false if that candidate was already recorded, so the owner is only asked once.
+func (db *DB) SetMangaMergeCandidate(mangaID int, mangaDexID string) (bool, error) {
+	res, err := db.Exec(`
+		UPDATE manga SET merge_candidate = ?
+		WHERE id = ? AND COALESCE(merge_candidate, '') != ?
+	`, mangaDexID, mangaID, mangaDexID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MigrateMangaDexID moves a title to the entry its MangaDex ID was merged into. Reading
+// progress and stored chapters stay on the title; if the user already tracks the new entry
+// separately, that row is folded in, reading history included. The old ID is recorded as an alias of the new one.
+func (db *DB) MigrateMangaDexID(mangaID int, userID int64, newID string) error {
+	if err := db.migrateMangaDexID(mangaID, userID, newID); err != nil {
+		return err
+	}
+	return db.recalculateUnreadCount(mangaID)
+}
+
+func (db *DB) migrateMangaDexID(mangaID int, userID int64, newID string) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	var (
+		oldID    string
+		lastRead sql.NullFloat64
+	)
+	if err = tx.QueryRow("SELECT mangadex_id, last_read_number FROM manga WHERE id = ? AND user_id = ?", mangaID, userID).Scan(&oldID, &lastRead); err != nil {
+		return err
+	}
+
+	var (
+		dupID       int
+		dupLastRead sql.NullFloat64
+	)
+	err = tx.QueryRow("SELECT id, last_read_number FROM manga WHERE user_id = ? AND mangadex_id = ? AND id != ?", userID, newID, mangaID).Scan(&dupID, &dupLastRead)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = nil
+	case err != nil:
+		return err
+	default:
+		// Keep our chapter rows and take over the ones only the duplicate has.
+		if _, err = tx.Exec(`
+			UPDATE chapters SET manga_id = ?
+			WHERE manga_id = ? AND chapter_number NOT IN (SELECT chapter_number FROM chapters WHERE manga_id = ?)
+		`, mangaID, dupID, mangaID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("DELETE FROM chapters WHERE manga_id = ?", dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(`
+			INSERT OR IGNORE INTO chapter_reads (manga_id, chapter_number, read_at)
+			SELECT ?, chapter_number, read_at FROM chapter_reads WHERE manga_id = ?
+		`, mangaID, dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("DELETE FROM chapter_reads WHERE manga_id = ?", dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(`
+			INSERT OR IGNORE INTO title_aliases (manga_id, alias, created_at)
+			SELECT ?, alias, created_at FROM title_aliases WHERE manga_id = ?
+		`, mangaID, dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("DELETE FROM title_aliases WHERE manga_id = ?", dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("UPDATE reading_history SET manga_id = ? WHERE manga_id = ?", mangaID, dupID); err != nil {
+			return err
+		}
+		if _, err = tx.Exec("DELETE FROM manga WHERE id = ?", dupID); err != nil {
+			return err
+		}
+		if dupLastRead.Valid && (!lastRead.Valid || dupLastRead.Float64 > lastRead.Float64) {
+			lastRead = dupLastRead
+		}
+	}
+
+	if _, err = tx.Exec(`
+		UPDATE manga
+		SET mangadex_id = ?, last_read_number = ?, merge_candidate = NULL,
+			consecutive_failures = 0, next_check_at = NULL, failure_alerted = 0
+		WHERE id = ?
+	`, newID, lastRead, mangaID); err != nil {
+		return err
+	}
+
+	if oldID == newID {
+		return nil
+	}
+	// Re-point older aliases at the new entry and drop any that would now loop.
+	if _, err = tx.Exec("UPDATE manga_aliases SET new_mangadex_id = ? WHERE new_mangadex_id = ?", newID, oldID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec("DELETE FROM manga_aliases WHERE old_mangadex_id = ?", newID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO manga_aliases (old_mangadex_id, new_mangadex_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(old_mangadex_id) DO UPDATE SET new_mangadex_id = excluded.new_mangadex_id, created_at = excluded.created_at
+	`, oldID, newID, time.Now().UTC())
+	return err
+}
+
+// ResolveMangaDexAlias returns the entry a merged MangaDex ID now lives under, or id
+// itself when no alias is recorded.
+func (db *DB) ResolveMangaDexAlias(id string) (string, error) {
+	current := id
+	for i := 0; i < maxAliasHops; i++ {
+		var next string
+		err := db.QueryRow("SELECT new_mangadex_id FROM manga_aliases WHERE old_mangadex_id = ?", current).Scan(&next)
+		if errors.Is(err, sql.ErrNoRows) {
+			return current, nil
+		}
+		if err != nil {
+			return id, err
+		}
+		current = next
+	}
+	return current, nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"sort"
+	"strconv"
+)
+
+// MangaPlusFreeChapters is how many of a series' first and latest chapters MANGA Plus
+// keeps free to read.
+const MangaPlusFreeChapters = 3
+
+// MangaPlusWindow describes which of a MANGA Plus title's chapters are currently free.
+type MangaPlusWindow struct {
+	// Free lists the latest chapters, newest first.
+	Free []string
+	// Expiring is the oldest free chapter when it is unread: the next release pushes it
+	// behind the paywall.
+	Expiring string
+	// Paywalled counts unread chapters that have already left the free window.
+	Paywalled int
+}
+
+// SetChapterExternalURL stores where an officially published chapter can be read.
+func (db *DB) SetChapterExternalURL(mangaID int64, chapterNumber, externalURL string) error {
+	_, err := db.Exec("UPDATE chapters SET external_url = ? WHERE manga_id = ? AND chapter_number = ?", externalURL, mangaID, chapterNumber)
+	return err
+}
+
+// GetMangaPlusWindow works out the title's free window from its stored chapters and the
+// user's reading progress.
+func (db *DB) GetMangaPlusWindow(mangaID int) (MangaPlusWindow, error) {
+	rows, err := db.Query(`
+		SELECT chapters.chapter_number, `+chapterReadSQL+`
+		FROM chapters
+		JOIN manga ON manga.id = chapters.manga_id
+		WHERE chapters.manga_id = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+	`, mangaID)
+	if err != nil {
+		return MangaPlusWindow{}, err
+	}
+	type numbered struct {
+		label string
+		num   float64
+		read  bool
+	}
+	var chapters []numbered
+	for rows.Next() {
+		var (
+			label string
+			read  bool
+		)
+		if err := rows.Scan(&label, &read); err != nil {
+			_ = rows.Close()
+			return MangaPlusWindow{}, err
+		}
+		if n, err := strconv.ParseFloat(label, 64); err == nil {
+			chapters = append(chapters, numbered{label: label, num: n, read: read})
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return MangaPlusWindow{}, err
+	}
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].num > chapters[j].num })
+
+	var w MangaPlusWindow
+	for i, ch := range chapters {
+		firstChapters := i >= len(chapters)-MangaPlusFreeChapters
+		switch {
+		case i < MangaPlusFreeChapters:
+			w.Free = append(w.Free, ch.label)
+			// The first chapters stay free for good, so only a later one can expire.
+			if i == MangaPlusFreeChapters-1 && !firstChapters && !ch.read {
+				w.Expiring = ch.label
+			}
+		case !firstChapters && !ch.read:
+			w.Paywalled++
+		}
+	}
+	return w, nil
+}
+
+// MangaPlusWindowFor returns the title's free window, or nil when it is not a MANGA Plus
+// title.
+func (db *DB) MangaPlusWindowFor(mangaID int) (*MangaPlusWindow, error) {
+	isMangaPlus, err := db.IsMangaPlus(mangaID)
+	if err != nil || !isMangaPlus {
+		return nil, err
+	}
+	w, err := db.GetMangaPlusWindow(mangaID)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
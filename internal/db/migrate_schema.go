@@ -20,6 +20,30 @@ func (db *DB) migrateSchema(adminUserID int64) (migrationFlags, error) {
 	if err := db.ensurePairingCodesSchema(); err != nil {
 		return flags, err
 	}
+	if err := db.ensureRunHistorySchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureMangaAliasesSchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureChapterReadsSchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureCategoryMutesSchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureReadingHistorySchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureTitleAliasesSchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureGroupChatsSchema(); err != nil {
+		return flags, err
+	}
+	if err := db.ensureRecommendationsSchema(); err != nil {
+		return flags, err
+	}
 	if err := db.ensureMigrationIndexes(); err != nil {
 		return flags, err
 	}
@@ -84,6 +108,102 @@ func (db *DB) ensureUsersSchema(adminUserID int64) error {
 		}
 	}
 
+	hasUsersJoinedWithCode, err := db.hasColumn("users", "joined_with_code")
+	if err != nil {
+		return err
+	}
+	if !hasUsersJoinedWithCode {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN joined_with_code TEXT"); err != nil {
+			return err
+		}
+	}
+
+	hasUsersUnreadWarning, err := db.hasColumn("users", "unread_warning_threshold")
+	if err != nil {
+		return err
+	}
+	if !hasUsersUnreadWarning {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN unread_warning_threshold INTEGER NOT NULL DEFAULT 3"); err != nil {
+			return err
+		}
+	}
+
+	// 1 once the user picked a default threshold; until then only MANGA Plus titles warn.
+	hasUsersUnreadWarningSet, err := db.hasColumn("users", "unread_warning_set")
+	if err != nil {
+		return err
+	}
+	if !hasUsersUnreadWarningSet {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN unread_warning_set INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+
+	hasUsersBacklogReport, err := db.hasColumn("users", "backlog_report")
+	if err != nil {
+		return err
+	}
+	if !hasUsersBacklogReport {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN backlog_report INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+
+	hasUsersListSort, err := db.hasColumn("users", "list_sort")
+	if err != nil {
+		return err
+	}
+	if !hasUsersListSort {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN list_sort TEXT NOT NULL DEFAULT 'title'"); err != nil {
+			return err
+		}
+	}
+
+	// Where the user last was in the followed-manga list.
+	for _, col := range []struct{ name, def string }{
+		{"list_category", "TEXT NOT NULL DEFAULT ''"},
+		{"list_filter", "TEXT NOT NULL DEFAULT ''"},
+		{"list_page", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		has, err := db.hasColumn("users", col.name)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err := db.Exec("ALTER TABLE users ADD COLUMN " + col.name + " " + col.def); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Set when the admin takes a user's access away; their data is kept.
+	hasUsersDisabledAt, err := db.hasColumn("users", "disabled_at")
+	if err != nil {
+		return err
+	}
+	if !hasUsersDisabledAt {
+		if _, err := db.Exec("ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP"); err != nil {
+			return err
+		}
+	}
+
+	// What paired users may see of each other.
+	for _, col := range []struct{ name, def string }{
+		{"display_name", "TEXT NOT NULL DEFAULT ''"},
+		{"share_library", "INTEGER NOT NULL DEFAULT 0"},
+		{"share_progress", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		has, err := db.hasColumn("users", col.name)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err := db.Exec("ALTER TABLE users ADD COLUMN " + col.name + " " + col.def); err != nil {
+				return err
+			}
+		}
+	}
+
 	if adminUserID > 0 {
 		if _, err := db.Exec(`
 			INSERT OR IGNORE INTO users (chat_id, is_admin, created_at)
@@ -169,9 +289,84 @@ func (db *DB) ensureMangaSchema(adminUserID int64, flags *migrationFlags) error
 		}
 	}
 
+	hasMangaSource, err := db.hasColumn("manga", "source")
+	if err != nil {
+		return err
+	}
+	if !hasMangaSource {
+		if _, err := db.Exec("ALTER TABLE manga ADD COLUMN source TEXT NOT NULL DEFAULT 'mangadex'"); err != nil {
+			return err
+		}
+	}
+	if err := db.ensureMangaSourceID(); err != nil {
+		return err
+	}
+
+	// Per-title failure tracking and backoff.
+	for _, col := range []struct{ name, def string }{
+		{"consecutive_failures", "INTEGER NOT NULL DEFAULT 0"},
+		{"last_error", "TEXT"},
+		{"last_error_at", "TIMESTAMP"},
+		{"next_check_at", "TIMESTAMP"},
+		{"failure_alerted", "INTEGER NOT NULL DEFAULT 0"},
+		{"merge_candidate", "TEXT"},
+		// NULL follows the owner's default unread warning threshold.
+		{"unread_warning_threshold", "INTEGER"},
+		{"category", "TEXT NOT NULL DEFAULT 'reading'"},
+		// Per-title alert settings.
+		{"notify_muted", "INTEGER NOT NULL DEFAULT 0"},
+		{"snoozed_until", "TIMESTAMP"},
+		{"notify_every", "INTEGER NOT NULL DEFAULT 1"},
+		{"notify_pending", "INTEGER NOT NULL DEFAULT 0"},
+		// 1 tracks progress per chapter in chapter_reads instead of last_read_number.
+		{"exact_tracking", "INTEGER NOT NULL DEFAULT 0"},
+		// 1 once the user toggled is_manga_plus; detection stops touching it after that.
+		{"manga_plus_set", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		has, err := db.hasColumn("manga", col.name)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if _, err := db.Exec("ALTER TABLE manga ADD COLUMN " + col.name + " " + col.def); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
+// ensureMangaSourceID gives titles on other sources their own ID column (a feed URL for RSS),
+// so mangadex_id only ever holds MangaDex IDs. Titles added before it moved their ID over.
+func (db *DB) ensureMangaSourceID() error {
+	hasMangaSourceID, err := db.hasColumn("manga", "source_id")
+	if err != nil {
+		return err
+	}
+	if !hasMangaSourceID {
+		if _, err := db.Exec("ALTER TABLE manga ADD COLUMN source_id TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+
+	// Several titles of one user now share an empty mangadex_id, so the unique index only
+	// covers rows that have one (ensureMigrationIndexes recreates it).
+	var indexSQL sql.NullString
+	err = db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = 'idx_manga_user_mangadex'").Scan(&indexSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && !strings.Contains(strings.ToUpper(indexSQL.String), "WHERE") {
+		if _, err := db.Exec("DROP INDEX idx_manga_user_mangadex"); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec("UPDATE manga SET source_id = mangadex_id, mangadex_id = '' WHERE source != 'mangadex' AND mangadex_id != ''")
+	return err
+}
+
 func (db *DB) ensureChaptersSchema() error {
 	hasChaptersReadableAt, err := db.hasColumn("chapters", "readable_at")
 	if err != nil {
@@ -203,6 +398,17 @@ func (db *DB) ensureChaptersSchema() error {
 		}
 	}
 
+	// Official releases (MANGA Plus and other publishers) link out instead of hosting pages.
+	hasChaptersExternalURL, err := db.hasColumn("chapters", "external_url")
+	if err != nil {
+		return err
+	}
+	if !hasChaptersExternalURL {
+		if _, err := db.Exec("ALTER TABLE chapters ADD COLUMN external_url TEXT"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -225,16 +431,270 @@ func (db *DB) ensurePairingCodesSchema() error {
 			return err
 		}
 	}
+
+	hasLabel, err := db.hasColumn("pairing_codes", "label")
+	if err != nil {
+		return err
+	}
+	if !hasLabel {
+		if _, err := db.Exec("ALTER TABLE pairing_codes ADD COLUMN label TEXT"); err != nil {
+			return err
+		}
+	}
+
+	hasMaxUses, err := db.hasColumn("pairing_codes", "max_uses")
+	if err != nil {
+		return err
+	}
+	if !hasMaxUses {
+		if _, err := db.Exec("ALTER TABLE pairing_codes ADD COLUMN max_uses INTEGER NOT NULL DEFAULT 1"); err != nil {
+			return err
+		}
+	}
+
+	hasUseCount, err := db.hasColumn("pairing_codes", "use_count")
+	if err != nil {
+		return err
+	}
+	if !hasUseCount {
+		if _, err := db.Exec("ALTER TABLE pairing_codes ADD COLUMN use_count INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		// Legacy codes were single-use; carry over their redemption.
+		if _, err := db.Exec("UPDATE pairing_codes SET use_count = 1 WHERE used_at IS NOT NULL"); err != nil {
+			return err
+		}
+	}
+
+	hasRevokedAt, err := db.hasColumn("pairing_codes", "revoked_at")
+	if err != nil {
+		return err
+	}
+	if !hasRevokedAt {
+		if _, err := db.Exec("ALTER TABLE pairing_codes ADD COLUMN revoked_at TIMESTAMP"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS pairing_redemptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			code TEXT NOT NULL,
+			chat_id INTEGER NOT NULL,
+			redeemed_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (code) REFERENCES pairing_codes (code)
+		)
+	`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS pairing_code_starters (
+			code TEXT NOT NULL,
+			mangadex_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			is_manga_plus INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (code, mangadex_id),
+			FOREIGN KEY (code) REFERENCES pairing_codes (code)
+		)
+	`); err != nil {
+		return err
+	}
+
+	// Backfill the redemption log for codes used before it existed.
+	if _, err := db.Exec(`
+		INSERT INTO pairing_redemptions (code, chat_id, redeemed_at)
+		SELECT p.code, p.used_by_chat_id, p.used_at
+		FROM pairing_codes p
+		WHERE p.used_by_chat_id IS NOT NULL
+		  AND p.used_at IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM pairing_redemptions r WHERE r.code = p.code)
+	`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`
+		UPDATE users
+		SET joined_with_code = (
+			SELECT p.code FROM pairing_codes p WHERE p.used_by_chat_id = users.chat_id ORDER BY p.used_at LIMIT 1
+		)
+		WHERE joined_with_code IS NULL
+	`); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (db *DB) ensureMigrationIndexes() error {
-	if _, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_manga_user_mangadex ON manga(user_id, mangadex_id)"); err != nil {
+	if _, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_manga_user_mangadex ON manga(user_id, mangadex_id) WHERE mangadex_id != ''"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_manga_user_source ON manga(user_id, source, source_id) WHERE source_id != ''"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_pairing_redemptions_code ON pairing_redemptions(code)"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_scheduler_run_titles_run ON scheduler_run_titles(run_id)"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_scheduler_runs_started ON scheduler_runs(started_at)"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *DB) ensureRunHistorySchema() error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduler_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			triggered_by TEXT NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP,
+			titles_checked INTEGER NOT NULL DEFAULT 0,
+			new_chapters INTEGER NOT NULL DEFAULT 0,
+			notifications_sent INTEGER NOT NULL DEFAULT 0,
+			failures INTEGER NOT NULL DEFAULT 0,
+			error TEXT
+		)
+	`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS scheduler_run_titles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			manga_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			new_chapters INTEGER NOT NULL DEFAULT 0,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			error TEXT,
+			FOREIGN KEY (run_id) REFERENCES scheduler_runs (id)
+		)
+	`); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (db *DB) ensureMangaAliasesSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS manga_aliases (
+			old_mangadex_id TEXT PRIMARY KEY,
+			new_mangadex_id TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)
+	`)
+	return err
+}
+
+func (db *DB) ensureCategoryMutesSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS category_mutes (
+			user_id INTEGER NOT NULL,
+			category TEXT NOT NULL,
+			PRIMARY KEY (user_id, category),
+			FOREIGN KEY (user_id) REFERENCES users (chat_id)
+		)
+	`)
+	return err
+}
+
+func (db *DB) ensureChapterReadsSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS chapter_reads (
+			manga_id INTEGER NOT NULL,
+			chapter_number TEXT NOT NULL,
+			read_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (manga_id, chapter_number),
+			FOREIGN KEY (manga_id) REFERENCES manga (id)
+		)
+	`)
+	return err
+}
+
+func (db *DB) ensureReadingHistorySchema() error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS reading_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			manga_id INTEGER NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			chapter_number TEXT NOT NULL DEFAULT '',
+			old_last_read REAL,
+			new_last_read REAL,
+			added_reads TEXT NOT NULL DEFAULT '',
+			removed_reads TEXT NOT NULL DEFAULT '',
+			read_delta INTEGER NOT NULL DEFAULT 0,
+			source TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			undone_at TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_reading_history_manga ON reading_history(manga_id, id)"); err != nil {
+		return err
+	}
+	_, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_reading_history_user ON reading_history(user_id, created_at)")
+	return err
+}
+
+func (db *DB) ensureTitleAliasesSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS title_aliases (
+			manga_id INTEGER NOT NULL,
+			alias TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (manga_id, alias),
+			FOREIGN KEY (manga_id) REFERENCES manga (id)
+		)
+	`)
+	return err
+}
+
+func (db *DB) ensureGroupChatsSchema() error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS group_chats (
+			chat_id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL DEFAULT '',
+			linked_by INTEGER NOT NULL,
+			linked_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (chat_id) REFERENCES users (chat_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS group_link_codes (
+			code TEXT PRIMARY KEY,
+			created_by_admin INTEGER NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_by_chat_id INTEGER,
+			used_at TIMESTAMP
+		)
+	`)
+	return err
+}
+
+func (db *DB) ensureRecommendationsSchema() error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS recommendations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			from_user_id INTEGER NOT NULL,
+			to_user_id INTEGER NOT NULL,
+			mangadex_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			is_manga_plus INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			added_at TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+	_, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_recommendations_to_user ON recommendations(to_user_id, mangadex_id)")
+	return err
+}
+
 func (db *DB) mangaTableNeedsRebuild() (bool, error) {
 	row := db.QueryRow("SELECT sql FROM sqlite_master WHERE type='table' AND name='manga'")
 	var sqlText string
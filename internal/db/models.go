@@ -3,36 +3,59 @@ package db
 import "time"
 
 type Manga struct {
-	ID             int
-	UserID         int64
-	MangaDexID     string
+	ID         int
+	UserID     int64
+	MangaDexID string
+	// Source is where the title is tracked ("mangadex", "rss"). MangaDex titles have a
+	// MangaDexID; titles on other sources have a SourceID (the feed URL for RSS) instead.
+	Source         string
+	SourceID       string
 	Title          string
 	IsMangaPlus    bool
 	LastChecked    time.Time
 	LastSeenAt     time.Time
 	LastReadNumber float64
 	UnreadCount    int
+	Category       string
+
+	ConsecutiveFailures int
+	NextCheckAt         time.Time
+}
+
+// TrackedID is the title's ID on its source.
+func (m Manga) TrackedID() string {
+	if m.SourceID != "" {
+		return m.SourceID
+	}
+	return m.MangaDexID
 }
 
 type Status struct {
-	MangaCount     int
-	ChapterCount   int
-	UserCount      int
-	UnreadTotal    int
+	MangaCount   int
+	ChapterCount int
+	UserCount    int
+	UnreadTotal  int
+	// BacklogTitles is the number of titles with at least one unread chapter.
+	BacklogTitles  int
 	CronLastRun    time.Time
 	HasCronLastRun bool
+	// CategoryCounts holds the number of titles per reading category.
+	CategoryCounts map[string]int
 }
 
 type ChapterListItem struct {
-	Number string
-	Title  string
-	SeenAt time.Time
+	Number      string
+	Title       string
+	SeenAt      time.Time
+	ExternalURL string
 }
 
 type MangaDetails struct {
 	ID                   int
 	UserID               int64
 	MangaDexID           string
+	Source               string
+	SourceID             string
 	Title                string
 	IsMangaPlus          bool
 	HasLastChecked       bool
@@ -48,4 +71,146 @@ type MangaDetails struct {
 	MinNumber            float64
 	HasMaxNumber         bool
 	MaxNumber            float64
+	ConsecutiveFailures  int
+	LastError            string
+	HasLastErrorAt       bool
+	LastErrorAt          time.Time
+	HasNextCheckAt       bool
+	NextCheckAt          time.Time
+	// MergeCandidate is the MangaDex entry this title appears to have been merged into.
+	MergeCandidate string
+}
+
+type PairingCodeOptions struct {
+	ExpiresAt time.Time
+	MaxUses   int
+	Label     string
+	Starters  []PairingStarter
+}
+
+type PairingStarter struct {
+	MangaDexID  string
+	Title       string
+	IsMangaPlus bool
+}
+
+type PairingCode struct {
+	Code      string
+	Label     string
+	MaxUses   int
+	UseCount  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RevokedAt time.Time
+	IsRevoked bool
+	// JoinedChatIDs are the chats that redeemed the code, in no particular order.
+	JoinedChatIDs []int64
+	// ActiveUsers counts the users who joined with the code and haven't been disabled.
+	ActiveUsers int
+}
+
+// GroupChat is a group chat linked to a shared library.
+type GroupChat struct {
+	ChatID   int64
+	Title    string
+	LinkedBy int64
+	LinkedAt time.Time
+}
+
+// Friend is another paired user, as shown to the people they can share with.
+type Friend struct {
+	ChatID int64
+	Name   string
+}
+
+// Recommendation is a title one paired user sent to another.
+type Recommendation struct {
+	ID          int64
+	FromUserID  int64
+	FromName    string
+	ToUserID    int64
+	ToName      string
+	MangaDexID  string
+	Title       string
+	IsMangaPlus bool
+	CreatedAt   time.Time
+	Added       bool
+}
+
+// FriendReader is a friend following a title. LastRead is only set when they share their
+// progress.
+type FriendReader struct {
+	ChatID      int64
+	Name        string
+	HasLastRead bool
+	LastRead    float64
+}
+
+// FriendTitle is a title followed by friends who share their library.
+type FriendTitle struct {
+	Source     string
+	MangaDexID string
+	SourceID   string
+	Title      string
+	Readers    []FriendReader
+}
+
+type PairingRedemption struct {
+	Code       string
+	ChatID     int64
+	RedeemedAt time.Time
+}
+
+type SchedulerRun struct {
+	ID                int64
+	RunID             string
+	Trigger           string
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	IsFinished        bool
+	TitlesChecked     int
+	NewChapters       int
+	NotificationsSent int
+	Failures          int
+	Error             string
+}
+
+type SchedulerRunTitle struct {
+	MangaID     int
+	UserID      int64
+	Title       string
+	NewChapters int
+	Duration    time.Duration
+	Error       string
+}
+
+// HistoryEntry is one change to a title's reading progress.
+type HistoryEntry struct {
+	ID      int64
+	MangaID int
+	Title   string
+	// Action is one of the HistoryAction constants; ChapterNumber is empty for HistoryActionReadAll.
+	Action        string
+	ChapterNumber string
+	// ReadDelta is how many numbered chapters became read (negative when they became unread).
+	ReadDelta int
+	Source    string
+	CreatedAt time.Time
+	Undone    bool
+}
+
+// ReadingActivity is one progress change as reading statistics see it.
+type ReadingActivity struct {
+	At      time.Time
+	MangaID int
+	Title   string
+	// Chapters is how many numbered chapters became read (negative when they became unread).
+	Chapters int
+}
+
+// TitleReadLag is the average time between a chapter's release and the user reading it.
+type TitleReadLag struct {
+	Title    string
+	Chapters int
+	Average  time.Duration
 }
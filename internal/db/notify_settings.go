@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// NotifySettings controls the new-chapter alerts for one title. Muted and snoozed titles
+// are still checked and counted as unread.
+type NotifySettings struct {
+	Muted        bool
+	SnoozedUntil time.Time
+	// Every sends an alert once this many new chapters have piled up (1 alerts every time).
+	Every int
+	// Pending is how many new chapters arrived since the last alert.
+	Pending int
+}
+
+// IsSnoozed reports whether alerts are snoozed at now.
+func (s NotifySettings) IsSnoozed(now time.Time) bool {
+	return !s.SnoozedUntil.IsZero() && now.Before(s.SnoozedUntil)
+}
+
+func (db *DB) GetMangaNotifySettings(mangaID int) (NotifySettings, error) {
+	var (
+		s       NotifySettings
+		muted   int
+		snoozed sql.NullTime
+	)
+	err := db.QueryRow("SELECT notify_muted, snoozed_until, notify_every, notify_pending FROM manga WHERE id = ?", mangaID).
+		Scan(&muted, &snoozed, &s.Every, &s.Pending)
+	if err != nil {
+		return NotifySettings{}, err
+	}
+	s.Muted = muted != 0
+	if snoozed.Valid {
+		s.SnoozedUntil = snoozed.Time
+	}
+	s.Every = max(s.Every, 1)
+	return s, nil
+}
+
+// SetMangaMuted mutes or unmutes alerts for one of the user's titles. It returns
+// sql.ErrNoRows if the title isn't the user's.
+func (db *DB) SetMangaMuted(mangaID int, userID int64, muted bool) error {
+	val := 0
+	if muted {
+		val = 1
+	}
+	return db.updateOwnedManga("UPDATE manga SET notify_muted = ? WHERE id = ? AND user_id = ?", val, mangaID, userID)
+}
+
+// SetMangaSnooze snoozes alerts for one of the user's titles until the given time; a zero
+// time ends the snooze.
+func (db *DB) SetMangaSnooze(mangaID int, userID int64, until time.Time) error {
+	var val any
+	if !until.IsZero() {
+		val = until.UTC()
+	}
+	return db.updateOwnedManga("UPDATE manga SET snoozed_until = ? WHERE id = ? AND user_id = ?", val, mangaID, userID)
+}
+
+// SetMangaNotifyEvery makes the title alert once every n new chapters and starts the
+// count over.
+func (db *DB) SetMangaNotifyEvery(mangaID int, userID int64, n int) error {
+	return db.updateOwnedManga("UPDATE manga SET notify_every = ?, notify_pending = 0 WHERE id = ? AND user_id = ?", max(n, 1), mangaID, userID)
+}
+
+// ClaimChapterNotification records newChapters for the title and returns how many chapters
+// an alert sent now should cover, or 0 if the owner shouldn't be alerted yet. Muted and
+// snoozed titles never alert; titles that alert every N chapters collect chapters until N
+// have arrived and then cover the whole batch.
+func (db *DB) ClaimChapterNotification(mangaID int, newChapters int, now time.Time) (int, error) {
+	s, err := db.GetMangaNotifySettings(mangaID)
+	if err != nil {
+		return 0, err
+	}
+	if s.Muted || s.IsSnoozed(now) {
+		return 0, nil
+	}
+	pending := s.Pending + newChapters
+	if pending < s.Every {
+		_, err := db.Exec("UPDATE manga SET notify_pending = ? WHERE id = ?", pending, mangaID)
+		return 0, err
+	}
+	if s.Pending > 0 {
+		if _, err := db.Exec("UPDATE manga SET notify_pending = 0 WHERE id = ?", mangaID); err != nil {
+			return 0, err
+		}
+	}
+	return pending, nil
+}
+
+func (db *DB) updateOwnedManga(query string, args ...any) error {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -1,54 +1,297 @@
 package db
 
-import "time"
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
 
 func (db *DB) CreatePairingCode(code string, adminChatID int64, expiresAt time.Time) error {
-	_, err := db.Exec(`
-		INSERT INTO pairing_codes (code, expires_at, created_by_admin, created_at)
-		VALUES (?, ?, ?, ?)
-	`, code, expiresAt.UTC(), adminChatID, time.Now().UTC())
-	return err
+	return db.CreatePairingCodeWithOptions(code, adminChatID, PairingCodeOptions{ExpiresAt: expiresAt, MaxUses: 1})
 }
 
-func (db *DB) RedeemPairingCode(code string, chatID int64) (bool, error) {
+func (db *DB) CreatePairingCodeWithOptions(code string, adminChatID int64, opts PairingCodeOptions) (err error) {
+	maxUses := opts.MaxUses
+	if maxUses <= 0 {
+		maxUses = 1
+	}
+	var label any
+	if l := strings.TrimSpace(opts.Label); l != "" {
+		label = l
+	}
+
 	tx, err := db.Begin()
 	if err != nil {
-		return false, err
+		return err
 	}
 	defer func() {
 		if err != nil {
 			_ = tx.Rollback()
 		} else {
-			_ = tx.Commit()
+			err = tx.Commit()
+		}
+	}()
+
+	_, err = tx.Exec(`
+		INSERT INTO pairing_codes (code, expires_at, created_by_admin, created_at, label, max_uses, use_count)
+		VALUES (?, ?, ?, ?, ?, ?, 0)
+	`, code, opts.ExpiresAt.UTC(), adminChatID, time.Now().UTC(), label, maxUses)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range opts.Starters {
+		isMangaPlus := 0
+		if s.IsMangaPlus {
+			isMangaPlus = 1
+		}
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO pairing_code_starters (code, mangadex_id, title, is_manga_plus)
+			VALUES (?, ?, ?, ?)
+		`, code, s.MangaDexID, s.Title, isMangaPlus)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RedeemPairingCode consumes one use of code for chatID. It returns false when the code can no
+// longer be used (expired, revoked or out of uses). On success, the redemption is logged and the
+// user is registered with the code they joined with.
+func (db *DB) RedeemPairingCode(code string, chatID int64) (redeemed bool, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else if err = tx.Commit(); err != nil {
+			redeemed = false
 		}
 	}()
 
-	var usedAt *time.Time
+	var revokedAt *time.Time
 	var expiresAt time.Time
+	var useCount, maxUses int
 	err = tx.QueryRow(`
-		SELECT used_at, expires_at
+		SELECT revoked_at, expires_at, use_count, max_uses
 		FROM pairing_codes
 		WHERE code = ?
-	`, code).Scan(&usedAt, &expiresAt)
+	`, code).Scan(&revokedAt, &expiresAt, &useCount, &maxUses)
 	if err != nil {
 		return false, err
 	}
 
-	if usedAt != nil {
+	if revokedAt != nil {
 		return false, nil
 	}
-	if time.Now().UTC().After(expiresAt.UTC()) {
+	if useCount >= maxUses {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	if now.After(expiresAt.UTC()) {
 		return false, nil
 	}
 
-	_, err = tx.Exec(`
+	res, err := tx.Exec(`
 		UPDATE pairing_codes
-		SET used_by_chat_id = ?, used_at = ?
-		WHERE code = ? AND used_at IS NULL
-	`, chatID, time.Now().UTC(), code)
+		SET use_count = use_count + 1,
+			used_by_chat_id = COALESCE(used_by_chat_id, ?),
+			used_at = COALESCE(used_at, ?)
+		WHERE code = ? AND use_count < max_uses AND revoked_at IS NULL
+	`, chatID, now, code)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
 	if err != nil {
 		return false, err
 	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec("INSERT INTO pairing_redemptions (code, chat_id, redeemed_at) VALUES (?, ?, ?)", code, chatID, now); err != nil {
+		return false, err
+	}
+	if _, err = tx.Exec(`
+		INSERT OR IGNORE INTO users (chat_id, is_admin, created_at)
+		VALUES (?, 0, CURRENT_TIMESTAMP)
+	`, chatID); err != nil {
+		return false, err
+	}
+	// A disabled user joining again starts over with the new code.
+	if _, err = tx.Exec(`
+		UPDATE users
+		SET joined_with_code = CASE WHEN disabled_at IS NULL THEN COALESCE(joined_with_code, ?) ELSE ? END,
+			disabled_at = NULL
+		WHERE chat_id = ?
+	`, code, code, chatID); err != nil {
+		return false, err
+	}
 
 	return true, nil
 }
+
+func (db *DB) ListPairingCodeStarters(code string) ([]PairingStarter, error) {
+	rows, err := db.Query(`
+		SELECT mangadex_id, title, is_manga_plus
+		FROM pairing_code_starters
+		WHERE code = ?
+		ORDER BY title COLLATE NOCASE
+	`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var starters []PairingStarter
+	for rows.Next() {
+		var s PairingStarter
+		var isMangaPlus int
+		if err := rows.Scan(&s.MangaDexID, &s.Title, &isMangaPlus); err != nil {
+			return nil, err
+		}
+		s.IsMangaPlus = isMangaPlus != 0
+		starters = append(starters, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return starters, nil
+}
+
+// ListPairingCodes returns the most recently created codes first, with who joined with
+// each of them.
+func (db *DB) ListPairingCodes(limit int) ([]PairingCode, error) {
+	rows, err := db.Query(`
+		SELECT pairing_codes.code, COALESCE(pairing_codes.label, ''), pairing_codes.max_uses,
+			pairing_codes.use_count, pairing_codes.expires_at, pairing_codes.created_at,
+			pairing_codes.revoked_at, COALESCE(GROUP_CONCAT(pairing_redemptions.chat_id), ''),
+			(SELECT COUNT(*) FROM users
+			 WHERE users.joined_with_code = pairing_codes.code AND users.is_admin = 0 AND users.disabled_at IS NULL)
+		FROM pairing_codes
+		LEFT JOIN pairing_redemptions ON pairing_redemptions.code = pairing_codes.code
+		GROUP BY pairing_codes.code
+		ORDER BY pairing_codes.created_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var codes []PairingCode
+	for rows.Next() {
+		var p PairingCode
+		var revokedAt sql.NullTime
+		var joined string
+		if err := rows.Scan(&p.Code, &p.Label, &p.MaxUses, &p.UseCount, &p.ExpiresAt, &p.CreatedAt, &revokedAt, &joined, &p.ActiveUsers); err != nil {
+			return nil, err
+		}
+		if revokedAt.Valid {
+			p.RevokedAt = revokedAt.Time
+			p.IsRevoked = true
+		}
+		for _, id := range strings.Split(joined, ",") {
+			if chatID, err := strconv.ParseInt(id, 10, 64); err == nil {
+				p.JoinedChatIDs = append(p.JoinedChatIDs, chatID)
+			}
+		}
+		codes = append(codes, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (db *DB) ListPairingRedemptions(code string) ([]PairingRedemption, error) {
+	rows, err := db.Query(`
+		SELECT code, chat_id, redeemed_at
+		FROM pairing_redemptions
+		WHERE code = ?
+		ORDER BY redeemed_at, id
+	`, code)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var redemptions []PairingRedemption
+	for rows.Next() {
+		var r PairingRedemption
+		if err := rows.Scan(&r.Code, &r.ChatID, &r.RedeemedAt); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+// RevokePairingCode stops code from being redeemed again. Users who already joined with it
+// keep their access; see DisablePairingCodeUsers. It returns sql.ErrNoRows for an unknown
+// code.
+func (db *DB) RevokePairingCode(code string) error {
+	res, err := db.Exec("UPDATE pairing_codes SET revoked_at = COALESCE(revoked_at, ?) WHERE code = ?", time.Now().UTC(), code)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DisablePairingCodeUsers takes access away from every non-admin user who joined with
+// code. Their data is kept, and redeeming a new code enables them again. It returns the
+// chat IDs that were disabled.
+func (db *DB) DisablePairingCodeUsers(code string) (chatIDs []int64, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	rows, err := tx.Query("SELECT chat_id FROM users WHERE joined_with_code = ? AND is_admin = 0 AND disabled_at IS NULL", code)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var chatID int64
+		if err = rows.Scan(&chatID); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	if err = rows.Err(); err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	_ = rows.Close()
+
+	now := time.Now().UTC()
+	for _, chatID := range chatIDs {
+		if _, err = tx.Exec("UPDATE users SET disabled_at = ? WHERE chat_id = ?", now, chatID); err != nil {
+			return nil, err
+		}
+	}
+	return chatIDs, nil
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Where a progress change came from.
+const (
+	HistorySourceButton  = "button"
+	HistorySourceCommand = "command"
+	HistorySourceText    = "text"
+	// HistorySourceNotification is a tap on a message the bot sent unprompted, such as a
+	// friend's recommendation.
+	HistorySourceNotification = "notification"
+	// HistorySourceImport is a title that came in with a starter library or a library file.
+	HistorySourceImport = "import"
+)
+
+// What a progress change did.
+const (
+	HistoryActionRead    = "read"
+	HistoryActionUnread  = "unread"
+	HistoryActionReadAll = "read_all"
+	HistoryActionUndo    = "undo"
+	// Tracking mode switches. They can't be undone, but they keep older changes from
+	// being undone on top of a different progress model.
+	HistoryActionExact     = "exact_tracking"
+	HistoryActionWatermark = "watermark_tracking"
+	// A title joining the library, with the progress it started from. It can't be undone
+	// either.
+	HistoryActionAdd = "add"
+)
+
+// progressSnapshot is the part of a title's state that read and unread marks change.
+type progressSnapshot struct {
+	lastRead  sql.NullFloat64
+	reads     map[string]bool
+	readCount int
+}
+
+func (db *DB) snapshotProgress(mangaID int) (progressSnapshot, error) {
+	s := progressSnapshot{reads: make(map[string]bool)}
+	if err := db.QueryRow("SELECT last_read_number FROM manga WHERE id = ?", mangaID).Scan(&s.lastRead); err != nil {
+		return s, err
+	}
+	rows, err := db.Query("SELECT chapter_number FROM chapter_reads WHERE manga_id = ?", mangaID)
+	if err != nil {
+		return s, err
+	}
+	for rows.Next() {
+		var number string
+		if err := rows.Scan(&number); err != nil {
+			_ = rows.Close()
+			return s, err
+		}
+		s.reads[number] = true
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return s, err
+	}
+	_ = rows.Close()
+
+	s.readCount, err = db.CountReadChapters(mangaID)
+	return s, err
+}
+
+// readsDiff returns the chapter numbers only in a, sorted so stored diffs are stable.
+func readsDiff(a, b map[string]bool) []string {
+	var only []string
+	for number := range a {
+		if !b[number] {
+			only = append(only, number)
+		}
+	}
+	sort.Strings(only)
+	return only
+}
+
+// trackProgress runs change and records what it did to the title's progress. It returns
+// the history entry's ID, or 0 when nothing changed. Markers are always recorded.
+func (db *DB) trackProgress(mangaID int, action, chapterNumber, source string, change func() error) (int64, error) {
+	before, err := db.snapshotProgress(mangaID)
+	if err != nil {
+		return 0, err
+	}
+	if err := change(); err != nil {
+		return 0, err
+	}
+	after, err := db.snapshotProgress(mangaID)
+	if err != nil {
+		return 0, err
+	}
+
+	added, removed := readsDiff(after.reads, before.reads), readsDiff(before.reads, after.reads)
+	if before.lastRead == after.lastRead && len(added) == 0 && len(removed) == 0 && !isMarker(action) {
+		return 0, nil
+	}
+	res, err := db.Exec(`
+		INSERT INTO reading_history (user_id, manga_id, title, action, chapter_number, old_last_read, new_last_read, added_reads, removed_reads, read_delta, source, created_at)
+		SELECT user_id, id, title, ?, ?, ?, ?, ?, ?, ?, ?, ? FROM manga WHERE id = ?
+	`, action, chapterNumber, before.lastRead, after.lastRead, strings.Join(added, "\n"), strings.Join(removed, "\n"),
+		after.readCount-before.readCount, source, time.Now().UTC(), mangaID)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// isMarker reports whether action only marks a point in the title's history: a tracking
+// mode switch or the title being added. Markers are recorded even when progress didn't
+// change and can't be undone.
+func isMarker(action string) bool {
+	return action == HistoryActionExact || action == HistoryActionWatermark || action == HistoryActionAdd
+}
+
+// Undoable reports whether the entry is one UndoProgress may undo, as far as the entry
+// itself tells: changes that aren't undone yet, undos and markers excluded.
+func (e HistoryEntry) Undoable() bool {
+	return !e.Undone && e.Action != HistoryActionUndo && !isMarker(e.Action)
+}
+
+// RecordMangaAdded records that a title joined the library, and how, as the first entry
+// of its history.
+func (db *DB) RecordMangaAdded(mangaID int, source string) (int64, error) {
+	return db.trackProgress(mangaID, HistoryActionAdd, "", source, func() error { return nil })
+}
+
+// MarkChapterAsRead is MarkChapterAsReadFrom for a button press.
+func (db *DB) MarkChapterAsRead(mangaID int, chapterNumber string) error {
+	_, err := db.MarkChapterAsReadFrom(mangaID, chapterNumber, HistorySourceButton)
+	return err
+}
+
+// MarkChapterAsReadFrom marks a chapter read and records the change in the reading
+// history. It returns the entry's ID for UndoProgress, or 0 when nothing changed.
+func (db *DB) MarkChapterAsReadFrom(mangaID int, chapterNumber, source string) (int64, error) {
+	return db.trackProgress(mangaID, HistoryActionRead, chapterNumber, source, func() error {
+		return db.markChapterAsRead(mangaID, chapterNumber)
+	})
+}
+
+// MarkChapterAsUnread is MarkChapterAsUnreadFrom for a button press.
+func (db *DB) MarkChapterAsUnread(mangaID int, chapterNumber string) error {
+	_, err := db.MarkChapterAsUnreadFrom(mangaID, chapterNumber, HistorySourceButton)
+	return err
+}
+
+// MarkChapterAsUnreadFrom marks a chapter unread and records the change like
+// MarkChapterAsReadFrom.
+func (db *DB) MarkChapterAsUnreadFrom(mangaID int, chapterNumber, source string) (int64, error) {
+	return db.trackProgress(mangaID, HistoryActionUnread, chapterNumber, source, func() error {
+		return db.markChapterAsUnread(mangaID, chapterNumber)
+	})
+}
+
+// MarkAllChaptersAsRead is MarkAllChaptersAsReadFrom for a button press.
+func (db *DB) MarkAllChaptersAsRead(mangaID int) error {
+	_, err := db.MarkAllChaptersAsReadFrom(mangaID, HistorySourceButton)
+	return err
+}
+
+// MarkAllChaptersAsReadFrom marks every chapter read and records the change like
+// MarkChapterAsReadFrom.
+func (db *DB) MarkAllChaptersAsReadFrom(mangaID int, source string) (int64, error) {
+	return db.trackProgress(mangaID, HistoryActionReadAll, "", source, func() error {
+		return db.markAllChaptersAsRead(mangaID)
+	})
+}
+
+// UndoProgress restores the progress a history entry replaced and records the undo, with
+// the source that asked for it, as a new entry. Only the title's latest change can be
+// undone; ok is false when the entry was already undone, progress changed since, or the
+// entry is a marker. It returns sql.ErrNoRows if the entry isn't the user's.
+func (db *DB) UndoProgress(historyID int64, userID int64, source string) (mangaID int, ok bool, err error) {
+	var (
+		action         string
+		chapterNumber  string
+		oldLastRead    sql.NullFloat64
+		added, removed string
+		undone         bool
+	)
+	if err := db.QueryRow(`
+		SELECT manga_id, action, chapter_number, old_last_read, added_reads, removed_reads, undone_at IS NOT NULL
+		FROM reading_history
+		WHERE id = ? AND user_id = ? AND manga_id IN (SELECT id FROM manga WHERE user_id = ?)
+	`, historyID, userID, userID).Scan(&mangaID, &action, &chapterNumber, &oldLastRead, &added, &removed, &undone); err != nil {
+		return 0, false, err
+	}
+	var latest int64
+	if err := db.QueryRow("SELECT MAX(id) FROM reading_history WHERE manga_id = ?", mangaID).Scan(&latest); err != nil {
+		return mangaID, false, err
+	}
+	if undone || action == HistoryActionUndo || isMarker(action) || latest != historyID {
+		return mangaID, false, nil
+	}
+
+	_, err = db.trackProgress(mangaID, HistoryActionUndo, chapterNumber, source, func() (err error) {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				_ = tx.Rollback()
+			} else {
+				err = tx.Commit()
+			}
+		}()
+
+		if _, err = tx.Exec("UPDATE manga SET last_read_number = ? WHERE id = ?", oldLastRead, mangaID); err != nil {
+			return err
+		}
+		for _, number := range strings.Split(added, "\n") {
+			if number == "" {
+				continue
+			}
+			if _, err = tx.Exec("DELETE FROM chapter_reads WHERE manga_id = ? AND chapter_number = ?", mangaID, number); err != nil {
+				return err
+			}
+		}
+		now := time.Now().UTC()
+		for _, number := range strings.Split(removed, "\n") {
+			if number == "" {
+				continue
+			}
+			if _, err = tx.Exec("INSERT OR IGNORE INTO chapter_reads (manga_id, chapter_number, read_at) VALUES (?, ?, ?)", mangaID, number, now); err != nil {
+				return err
+			}
+		}
+		if _, err = tx.Exec(recalculateUnreadSQL, mangaID); err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE reading_history SET undone_at = ? WHERE id = ?", now, historyID)
+		return err
+	})
+	if err != nil {
+		return mangaID, false, err
+	}
+	return mangaID, true, nil
+}
+
+// ListReadingHistory returns the title's progress changes, newest first.
+func (db *DB) ListReadingHistory(mangaID int, limit int) ([]HistoryEntry, error) {
+	rows, err := db.Query(`
+		SELECT id, manga_id, title, action, chapter_number, read_delta, source, created_at, undone_at IS NOT NULL
+		FROM reading_history
+		WHERE manga_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, mangaID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var e HistoryEntry
+		if err := rows.Scan(&e.ID, &e.MangaID, &e.Title, &e.Action, &e.ChapterNumber, &e.ReadDelta, &e.Source, &e.CreatedAt, &e.Undone); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,111 @@
+package db
+
+import "time"
+
+// readingActivityWhere keeps the history entries that count towards reading statistics:
+// undone changes and the undos themselves cancel out and are left out, tracking mode
+// switches only move progress between models, adding a title reads nothing, and "mark all
+// read" is catching up on a backlog rather than reading it that day.
+const readingActivityWhere = `undone_at IS NULL AND action NOT IN ('` + HistoryActionUndo + `', '` +
+	HistoryActionExact + `', '` + HistoryActionWatermark + `', '` + HistoryActionAdd + `', '` + HistoryActionReadAll + `')`
+
+// ListReadingActivity returns the user's progress changes in [from, to), oldest first.
+// Entries of removed titles are kept so statistics don't shrink.
+func (db *DB) ListReadingActivity(userID int64, from, to time.Time) ([]ReadingActivity, error) {
+	rows, err := db.Query(`
+		SELECT created_at, manga_id, title, read_delta
+		FROM reading_history
+		WHERE user_id = ? AND created_at >= ? AND created_at < ? AND `+readingActivityWhere+`
+		ORDER BY created_at, id
+	`, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var activity []ReadingActivity
+	for rows.Next() {
+		var a ReadingActivity
+		if err := rows.Scan(&a.At, &a.MangaID, &a.Title, &a.Chapters); err != nil {
+			return nil, err
+		}
+		activity = append(activity, a)
+	}
+	return activity, rows.Err()
+}
+
+// ListReadingActivityUsers returns the users who read at least one chapter in [from, to).
+func (db *DB) ListReadingActivityUsers(from, to time.Time) ([]int64, error) {
+	rows, err := db.Query(`
+		SELECT user_id
+		FROM reading_history
+		WHERE created_at >= ? AND created_at < ? AND `+readingActivityWhere+`
+		GROUP BY user_id
+		HAVING SUM(read_delta) > 0
+		ORDER BY user_id
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var users []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		users = append(users, userID)
+	}
+	return users, rows.Err()
+}
+
+// ListReleaseToReadLag returns, per title, how long after release the user read the
+// chapters they marked read in [from, to), most-read titles first. A single mark covers
+// every chapter the watermark passed, or just the chapter itself with exact tracking.
+// "Mark all read" and chapters without a release date are skipped.
+func (db *DB) ListReleaseToReadLag(userID int64, from, to time.Time, limit int) ([]TitleReadLag, error) {
+	rows, err := db.Query(`
+		SELECT reading_history.title, COUNT(*),
+			AVG(julianday(reading_history.created_at) - julianday(COALESCE(chapters.readable_at, chapters.published_at)))
+		FROM reading_history
+		JOIN chapters ON chapters.manga_id = reading_history.manga_id
+		WHERE reading_history.user_id = ?
+		  AND reading_history.created_at >= ?
+		  AND reading_history.created_at < ?
+		  AND reading_history.undone_at IS NULL
+		  AND reading_history.action = ?
+		  AND chapters.chapter_number GLOB '[0-9]*'
+		  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+		  AND chapters.chapter_number NOT GLOB '*.*.*'
+		  AND (
+			(reading_history.new_last_read IS NOT NULL
+			  AND CAST(chapters.chapter_number AS REAL) > COALESCE(reading_history.old_last_read, -1)
+			  AND CAST(chapters.chapter_number AS REAL) <= reading_history.new_last_read)
+			OR (reading_history.action = ? AND chapters.chapter_number = reading_history.chapter_number)
+		  )
+		  AND COALESCE(chapters.readable_at, chapters.published_at) IS NOT NULL
+		  AND julianday(COALESCE(chapters.readable_at, chapters.published_at)) <= julianday(reading_history.created_at)
+		GROUP BY reading_history.manga_id
+		ORDER BY COUNT(*) DESC, reading_history.title COLLATE NOCASE
+		LIMIT ?
+	`, userID, from.UTC(), to.UTC(), HistoryActionRead, HistoryActionRead, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var lags []TitleReadLag
+	for rows.Next() {
+		var (
+			l    TitleReadLag
+			days float64
+		)
+		if err := rows.Scan(&l.Title, &l.Chapters, &days); err != nil {
+			return nil, err
+		}
+		l.Average = time.Duration(days * float64(24*time.Hour))
+		lags = append(lags, l)
+	}
+	return lags, rows.Err()
+}
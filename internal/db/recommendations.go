@@ -0,0 +1,186 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// SetUserDisplayName stores the name friends see for the user.
+func (db *DB) SetUserDisplayName(chatID int64, name string) error {
+	_, err := db.Exec("UPDATE users SET display_name = ? WHERE chat_id = ? AND display_name != ?", name, chatID, name)
+	return err
+}
+
+// SetUserShareLibrary opts the user in or out of showing friends which titles they follow.
+func (db *DB) SetUserShareLibrary(userID int64, enabled bool) error {
+	val := 0
+	if enabled {
+		val = 1
+	}
+	_, err := db.Exec("UPDATE users SET share_library = ? WHERE chat_id = ?", val, userID)
+	return err
+}
+
+// SetUserShareProgress opts the user in or out of showing friends how far they've read.
+func (db *DB) SetUserShareProgress(userID int64, enabled bool) error {
+	val := 0
+	if enabled {
+		val = 1
+	}
+	_, err := db.Exec("UPDATE users SET share_progress = ? WHERE chat_id = ?", val, userID)
+	return err
+}
+
+// friendWhere keeps the users someone else may see and recommend titles to: other people
+// (group chats aren't) with access who opted in by sharing their list.
+const friendWhere = `users.chat_id > 0 AND users.chat_id != ? AND users.disabled_at IS NULL AND users.share_library = 1`
+
+// ListFriends returns the other paired users who share their list, by name.
+func (db *DB) ListFriends(userID int64) ([]Friend, error) {
+	rows, err := db.Query(`
+		SELECT chat_id, display_name FROM users
+		WHERE `+friendWhere+`
+		ORDER BY display_name = '', display_name COLLATE NOCASE, chat_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var friends []Friend
+	for rows.Next() {
+		var f Friend
+		if err := rows.Scan(&f.ChatID, &f.Name); err != nil {
+			return nil, err
+		}
+		friends = append(friends, f)
+	}
+	return friends, rows.Err()
+}
+
+// IsFriend reports whether friendID is one of the users ListFriends returns for userID.
+func (db *DB) IsFriend(userID, friendID int64) (bool, error) {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM users WHERE "+friendWhere+" AND users.chat_id = ?", userID, friendID).Scan(&n)
+	return n > 0, err
+}
+
+// CountRecommendationsSince returns how many recommendations fromUserID sent toUserID
+// since the given time.
+func (db *DB) CountRecommendationsSince(fromUserID, toUserID int64, since time.Time) (int, error) {
+	var n int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM recommendations
+		WHERE from_user_id = ? AND to_user_id = ? AND created_at >= ?
+	`, fromUserID, toUserID, since.UTC()).Scan(&n)
+	return n, err
+}
+
+// CreateRecommendation records that fromUserID recommended a MangaDex title to toUserID.
+func (db *DB) CreateRecommendation(fromUserID, toUserID int64, mangaDexID, title string, isMangaPlus bool) (int64, error) {
+	plusVal := 0
+	if isMangaPlus {
+		plusVal = 1
+	}
+	res, err := db.Exec(`
+		INSERT INTO recommendations (from_user_id, to_user_id, mangadex_id, title, is_manga_plus, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, fromUserID, toUserID, mangaDexID, title, plusVal, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetRecommendation returns a recommendation, or sql.ErrNoRows when it doesn't exist.
+func (db *DB) GetRecommendation(id int64) (Recommendation, error) {
+	var (
+		r           Recommendation
+		isMangaPlus int
+		addedAt     sql.NullTime
+	)
+	err := db.QueryRow(`
+		SELECT recommendations.from_user_id, COALESCE(sender.display_name, ''),
+			recommendations.to_user_id, COALESCE(recipient.display_name, ''),
+			recommendations.mangadex_id, recommendations.title, recommendations.is_manga_plus,
+			recommendations.created_at, recommendations.added_at
+		FROM recommendations
+		LEFT JOIN users AS sender ON sender.chat_id = recommendations.from_user_id
+		LEFT JOIN users AS recipient ON recipient.chat_id = recommendations.to_user_id
+		WHERE recommendations.id = ?
+	`, id).Scan(&r.FromUserID, &r.FromName, &r.ToUserID, &r.ToName, &r.MangaDexID, &r.Title, &isMangaPlus, &r.CreatedAt, &addedAt)
+	if err != nil {
+		return Recommendation{}, err
+	}
+	r.ID = id
+	r.IsMangaPlus = isMangaPlus != 0
+	r.Added = addedAt.Valid
+	return r, nil
+}
+
+// MarkRecommendationAdded records that the recipient added the recommended title.
+func (db *DB) MarkRecommendationAdded(id int64) error {
+	_, err := db.Exec("UPDATE recommendations SET added_at = ? WHERE id = ? AND added_at IS NULL", time.Now().UTC(), id)
+	return err
+}
+
+// ListFriendsReading returns the titles followed by other users who share their library,
+// most followed first. Reading progress is only included for users who also share it; it
+// is the highest numbered chapter they've read, whichever way the title is tracked.
+func (db *DB) ListFriendsReading(userID int64) ([]FriendTitle, error) {
+	rows, err := db.Query(`
+		SELECT manga.source, manga.mangadex_id, manga.source_id, manga.title, users.chat_id, users.display_name,
+			users.share_progress, (
+				SELECT MAX(CAST(chapters.chapter_number AS REAL))
+				FROM chapters
+				WHERE chapters.manga_id = manga.id
+				  AND chapters.chapter_number GLOB '[0-9]*'
+				  AND chapters.chapter_number NOT GLOB '*[^0-9.]*'
+				  AND chapters.chapter_number NOT GLOB '*.*.*'
+				  AND `+chapterReadSQL+`
+			)
+		FROM manga
+		JOIN users ON users.chat_id = manga.user_id
+		WHERE `+friendWhere+`
+		ORDER BY manga.title COLLATE NOCASE, users.display_name COLLATE NOCASE
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var titles []FriendTitle
+	index := make(map[[3]string]int)
+	for rows.Next() {
+		var (
+			src, mdID, srcID, title, name string
+			chatID                        int64
+			shareProgress                 int
+			lastRead                      sql.NullFloat64
+		)
+		if err := rows.Scan(&src, &mdID, &srcID, &title, &chatID, &name, &shareProgress, &lastRead); err != nil {
+			return nil, err
+		}
+		reader := FriendReader{ChatID: chatID, Name: name}
+		if shareProgress != 0 && lastRead.Valid {
+			reader.HasLastRead = true
+			reader.LastRead = lastRead.Float64
+		}
+		key := [3]string{src, mdID, srcID}
+		i, ok := index[key]
+		if !ok {
+			i = len(titles)
+			index[key] = i
+			titles = append(titles, FriendTitle{Source: src, MangaDexID: mdID, SourceID: srcID, Title: title})
+		}
+		titles[i].Readers = append(titles[i].Readers, reader)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(titles, func(i, j int) bool {
+		return len(titles[i].Readers) > len(titles[j].Readers)
+	})
+	return titles, nil
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// StartSchedulerRun records the start of an update run and returns its row ID.
+func (db *DB) StartSchedulerRun(runID, trigger string, startedAt time.Time) (int64, error) {
+	res, err := db.Exec(`
+		INSERT INTO scheduler_runs (run_id, triggered_by, started_at)
+		VALUES (?, ?, ?)
+	`, runID, trigger, startedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FinishSchedulerRun stores the run totals and per-title outcomes. Titles checked, new
+// chapters and failures are derived from titles.
+func (db *DB) FinishSchedulerRun(id int64, finishedAt time.Time, notificationsSent int, runErr string, titles []SchedulerRunTitle) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	newChapters, failures := 0, 0
+	for _, t := range titles {
+		var titleErr any
+		if t.Error != "" {
+			titleErr = t.Error
+			failures++
+		}
+		newChapters += t.NewChapters
+		if _, err = tx.Exec(`
+			INSERT INTO scheduler_run_titles (run_id, manga_id, user_id, title, new_chapters, duration_ms, error)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, t.MangaID, t.UserID, t.Title, t.NewChapters, t.Duration.Milliseconds(), titleErr); err != nil {
+			return err
+		}
+	}
+
+	var errText any
+	if runErr != "" {
+		errText = runErr
+	}
+	_, err = tx.Exec(`
+		UPDATE scheduler_runs
+		SET finished_at = ?, titles_checked = ?, new_chapters = ?, notifications_sent = ?, failures = ?, error = ?
+		WHERE id = ?
+	`, finishedAt.UTC(), len(titles), newChapters, notificationsSent, failures, errText, id)
+	return err
+}
+
+// ListSchedulerRuns returns the most recent runs first.
+func (db *DB) ListSchedulerRuns(limit int) ([]SchedulerRun, error) {
+	rows, err := db.Query(`
+		SELECT id, run_id, triggered_by, started_at, finished_at, titles_checked, new_chapters, notifications_sent, failures, COALESCE(error, '')
+		FROM scheduler_runs
+		ORDER BY started_at DESC, id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var runs []SchedulerRun
+	for rows.Next() {
+		r, err := scanSchedulerRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (db *DB) GetSchedulerRun(id int64) (SchedulerRun, error) {
+	row := db.QueryRow(`
+		SELECT id, run_id, triggered_by, started_at, finished_at, titles_checked, new_chapters, notifications_sent, failures, COALESCE(error, '')
+		FROM scheduler_runs
+		WHERE id = ?
+	`, id)
+	return scanSchedulerRun(row)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedulerRun(row rowScanner) (SchedulerRun, error) {
+	var r SchedulerRun
+	var finishedAt sql.NullTime
+	if err := row.Scan(&r.ID, &r.RunID, &r.Trigger, &r.StartedAt, &finishedAt, &r.TitlesChecked, &r.NewChapters, &r.NotificationsSent, &r.Failures, &r.Error); err != nil {
+		return SchedulerRun{}, err
+	}
+	if finishedAt.Valid {
+		r.FinishedAt = finishedAt.Time
+		r.IsFinished = true
+	}
+	return r, nil
+}
+
+// ListSchedulerRunTitles returns a run's per-title rows, failures first.
+func (db *DB) ListSchedulerRunTitles(runID int64) ([]SchedulerRunTitle, error) {
+	rows, err := db.Query(`
+		SELECT manga_id, user_id, title, new_chapters, duration_ms, COALESCE(error, '')
+		FROM scheduler_run_titles
+		WHERE run_id = ?
+		ORDER BY (error IS NULL), new_chapters DESC, title COLLATE NOCASE
+	`, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var titles []SchedulerRunTitle
+	for rows.Next() {
+		var t SchedulerRunTitle
+		var durationMS int64
+		if err := rows.Scan(&t.MangaID, &t.UserID, &t.Title, &t.NewChapters, &durationMS, &t.Error); err != nil {
+			return nil, err
+		}
+		t.Duration = time.Duration(durationMS) * time.Millisecond
+		titles = append(titles, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return titles, nil
+}
+
+// PruneSchedulerRuns deletes runs (and their title rows) that started before cutoff.
+func (db *DB) PruneSchedulerRuns(cutoff time.Time) (pruned int64, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	if _, err = tx.Exec(`
+		DELETE FROM scheduler_run_titles
+		WHERE run_id IN (SELECT id FROM scheduler_runs WHERE started_at < ?)
+	`, cutoff.UTC()); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec("DELETE FROM scheduler_runs WHERE started_at < ?", cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
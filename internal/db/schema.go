@@ -7,12 +7,28 @@ func (db *DB) CreateTables() error {
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			user_id INTEGER NOT NULL,
 			mangadex_id TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT 'mangadex',
+			source_id TEXT NOT NULL DEFAULT '',
 			title TEXT NOT NULL,
 			is_manga_plus INTEGER NOT NULL DEFAULT 0,
 			last_checked TIMESTAMP,
 			last_seen_at TIMESTAMP,
 			last_read_number REAL,
 			unread_count INTEGER DEFAULT 0,
+			consecutive_failures INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			last_error_at TIMESTAMP,
+			next_check_at TIMESTAMP,
+			failure_alerted INTEGER NOT NULL DEFAULT 0,
+			merge_candidate TEXT,
+			unread_warning_threshold INTEGER,
+			category TEXT NOT NULL DEFAULT 'reading',
+			notify_muted INTEGER NOT NULL DEFAULT 0,
+			snoozed_until TIMESTAMP,
+			notify_every INTEGER NOT NULL DEFAULT 1,
+			notify_pending INTEGER NOT NULL DEFAULT 0,
+			exact_tracking INTEGER NOT NULL DEFAULT 0,
+			manga_plus_set INTEGER NOT NULL DEFAULT 0,
 			FOREIGN KEY (user_id) REFERENCES users (chat_id)
 		);
 
@@ -25,6 +41,7 @@ func (db *DB) CreateTables() error {
 			readable_at TIMESTAMP,
 			created_at TIMESTAMP,
 			updated_at TIMESTAMP,
+			external_url TEXT,
 			FOREIGN KEY (manga_id) REFERENCES manga (id)
 		);
 
@@ -33,7 +50,19 @@ func (db *DB) CreateTables() error {
 			is_admin INTEGER NOT NULL DEFAULT 0,
 			created_at TIMESTAMP,
 			pending_state TEXT,
-			pending_payload TEXT
+			pending_payload TEXT,
+			joined_with_code TEXT,
+			unread_warning_threshold INTEGER NOT NULL DEFAULT 3,
+			unread_warning_set INTEGER NOT NULL DEFAULT 0,
+			backlog_report INTEGER NOT NULL DEFAULT 0,
+			list_sort TEXT NOT NULL DEFAULT 'title',
+			list_category TEXT NOT NULL DEFAULT '',
+			list_filter TEXT NOT NULL DEFAULT '',
+			list_page INTEGER NOT NULL DEFAULT 0,
+			display_name TEXT NOT NULL DEFAULT '',
+			share_library INTEGER NOT NULL DEFAULT 0,
+			share_progress INTEGER NOT NULL DEFAULT 0,
+			disabled_at TIMESTAMP
 		);
 
 		CREATE TABLE IF NOT EXISTS pairing_codes (
@@ -42,9 +71,129 @@ func (db *DB) CreateTables() error {
 			used_by_chat_id INTEGER,
 			used_at TIMESTAMP,
 			created_by_admin INTEGER NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			label TEXT,
+			max_uses INTEGER NOT NULL DEFAULT 1,
+			use_count INTEGER NOT NULL DEFAULT 0,
+			revoked_at TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS pairing_redemptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			code TEXT NOT NULL,
+			chat_id INTEGER NOT NULL,
+			redeemed_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (code) REFERENCES pairing_codes (code)
+		);
+
+		CREATE TABLE IF NOT EXISTS pairing_code_starters (
+			code TEXT NOT NULL,
+			mangadex_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			is_manga_plus INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (code, mangadex_id),
+			FOREIGN KEY (code) REFERENCES pairing_codes (code)
+		);
+
+		CREATE TABLE IF NOT EXISTS scheduler_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			triggered_by TEXT NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP,
+			titles_checked INTEGER NOT NULL DEFAULT 0,
+			new_chapters INTEGER NOT NULL DEFAULT 0,
+			notifications_sent INTEGER NOT NULL DEFAULT 0,
+			failures INTEGER NOT NULL DEFAULT 0,
+			error TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS scheduler_run_titles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			manga_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			title TEXT NOT NULL,
+			new_chapters INTEGER NOT NULL DEFAULT 0,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			error TEXT,
+			FOREIGN KEY (run_id) REFERENCES scheduler_runs (id)
+		);
+
+		CREATE TABLE IF NOT EXISTS manga_aliases (
+			old_mangadex_id TEXT PRIMARY KEY,
+			new_mangadex_id TEXT NOT NULL,
 			created_at TIMESTAMP NOT NULL
 		);
 
+		CREATE TABLE IF NOT EXISTS chapter_reads (
+			manga_id INTEGER NOT NULL,
+			chapter_number TEXT NOT NULL,
+			read_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (manga_id, chapter_number),
+			FOREIGN KEY (manga_id) REFERENCES manga (id)
+		);
+
+		CREATE TABLE IF NOT EXISTS reading_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			manga_id INTEGER NOT NULL,
+			title TEXT NOT NULL DEFAULT '',
+			action TEXT NOT NULL,
+			chapter_number TEXT NOT NULL DEFAULT '',
+			old_last_read REAL,
+			new_last_read REAL,
+			added_reads TEXT NOT NULL DEFAULT '',
+			removed_reads TEXT NOT NULL DEFAULT '',
+			read_delta INTEGER NOT NULL DEFAULT 0,
+			source TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			undone_at TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS title_aliases (
+			manga_id INTEGER NOT NULL,
+			alias TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (manga_id, alias),
+			FOREIGN KEY (manga_id) REFERENCES manga (id)
+		);
+
+		CREATE TABLE IF NOT EXISTS group_chats (
+			chat_id INTEGER PRIMARY KEY,
+			title TEXT NOT NULL DEFAULT '',
+			linked_by INTEGER NOT NULL,
+			linked_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (chat_id) REFERENCES users (chat_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS group_link_codes (
+			code TEXT PRIMARY KEY,
+			created_by_admin INTEGER NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_by_chat_id INTEGER,
+			used_at TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS recommendations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			from_user_id INTEGER NOT NULL,
+			to_user_id INTEGER NOT NULL,
+			mangadex_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			is_manga_plus INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL,
+			added_at TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS category_mutes (
+			user_id INTEGER NOT NULL,
+			category TEXT NOT NULL,
+			PRIMARY KEY (user_id, category),
+			FOREIGN KEY (user_id) REFERENCES users (chat_id)
+		);
+
 		CREATE TABLE IF NOT EXISTS system_status (
 			key TEXT PRIMARY KEY,
 			last_update TIMESTAMP
@@ -59,9 +59,14 @@ func (db *DB) GetStatusByUser(userID int64) (Status, error) {
 	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE chat_id = ?", userID).Scan(&s.UserCount); err != nil {
 		return Status{}, err
 	}
-	if err := db.QueryRow("SELECT COALESCE(SUM(unread_count), 0) FROM manga WHERE user_id = ?", userID).Scan(&s.UnreadTotal); err != nil {
+	if err := db.QueryRow("SELECT COALESCE(SUM(unread_count), 0), COUNT(CASE WHEN unread_count > 0 THEN 1 END) FROM manga WHERE user_id = ?", userID).Scan(&s.UnreadTotal, &s.BacklogTitles); err != nil {
 		return Status{}, err
 	}
+	counts, err := db.CountMangaByCategory(userID)
+	if err != nil {
+		return Status{}, err
+	}
+	s.CategoryCounts = counts
 
 	var lastRun sql.NullTime
 	if err := db.QueryRow("SELECT last_update FROM system_status WHERE key = 'cron_last_run'").Scan(&lastRun); err != nil && err != sql.ErrNoRows {
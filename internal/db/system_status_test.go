@@ -110,6 +110,19 @@ func TestUsersListAndAuthorization(t *testing.T) {
 	if len(users) != 2 {
 		t.Fatalf("ListUsers len=%d, want 2", len(users))
 	}
+	// Group chats and disabled users aren't listed.
+	if err := database.EnsureUser(-100, false); err != nil {
+		t.Fatalf("EnsureUser(group): %v", err)
+	}
+	if err := database.EnsureUser(43, false); err != nil {
+		t.Fatalf("EnsureUser(disabled): %v", err)
+	}
+	if _, err := database.Exec("UPDATE users SET disabled_at = CURRENT_TIMESTAMP WHERE chat_id = 43"); err != nil {
+		t.Fatalf("disable user: %v", err)
+	}
+	if users, err = database.ListUsers(); err != nil || len(users) != 2 || users[0] != 1 || users[1] != 42 {
+		t.Fatalf("ListUsers()=%v err=%v, want [1 42]", users, err)
+	}
 
 	ok, isAdmin, err := database.IsUserAuthorized(1)
 	if err != nil || !ok || !isAdmin {
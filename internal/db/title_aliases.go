@@ -0,0 +1,90 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AddTitleAlias gives one of the user's titles an extra name to match quick input
+// against. It reports false when the title already has the alias, and returns
+// sql.ErrNoRows when the title isn't the user's.
+func (db *DB) AddTitleAlias(mangaID int, userID int64, alias string) (bool, error) {
+	res, err := db.Exec(`
+		INSERT OR IGNORE INTO title_aliases (manga_id, alias, created_at)
+		SELECT id, ?, ? FROM manga WHERE id = ? AND user_id = ?
+	`, alias, time.Now().UTC(), mangaID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+	owned, err := db.MangaBelongsToUser(mangaID, userID)
+	if err != nil {
+		return false, err
+	}
+	if !owned {
+		return false, sql.ErrNoRows
+	}
+	return false, nil
+}
+
+// RemoveTitleAlias drops one of the user's aliases for a title.
+func (db *DB) RemoveTitleAlias(mangaID int, userID int64, alias string) error {
+	_, err := db.Exec(`
+		DELETE FROM title_aliases
+		WHERE manga_id = ? AND alias = ? AND manga_id IN (SELECT id FROM manga WHERE id = ? AND user_id = ?)
+	`, mangaID, alias, mangaID, userID)
+	return err
+}
+
+// ListTitleAliases returns a title's aliases in alphabetical order.
+func (db *DB) ListTitleAliases(mangaID int) ([]string, error) {
+	rows, err := db.Query("SELECT alias FROM title_aliases WHERE manga_id = ? ORDER BY alias", mangaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []string
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// ListTitleAliasesByUser returns the aliases of all the user's titles, keyed by manga ID.
+func (db *DB) ListTitleAliasesByUser(userID int64) (map[int][]string, error) {
+	rows, err := db.Query(`
+		SELECT title_aliases.manga_id, title_aliases.alias
+		FROM title_aliases
+		JOIN manga ON manga.id = title_aliases.manga_id
+		WHERE manga.user_id = ?
+		ORDER BY title_aliases.manga_id, title_aliases.alias
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make(map[int][]string)
+	for rows.Next() {
+		var (
+			mangaID int
+			alias   string
+		)
+		if err := rows.Scan(&mangaID, &alias); err != nil {
+			return nil, err
+		}
+		aliases[mangaID] = append(aliases[mangaID], alias)
+	}
+	return aliases, rows.Err()
+}
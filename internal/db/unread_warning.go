@@ -0,0 +1,162 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// DefaultUnreadWarningThreshold is the unread count that triggers the backlog warning for
+// users who never changed it.
+const DefaultUnreadWarningThreshold = 3
+
+// unreadWarningSQL is a title's effective unread warning threshold, with manga joined to its
+// owner in users. Until the owner picks a default, only MANGA Plus titles warn (at the
+// default threshold), as they did before thresholds were configurable.
+const unreadWarningSQL = `COALESCE(
+	manga.unread_warning_threshold,
+	CASE WHEN users.unread_warning_set = 1 THEN users.unread_warning_threshold END,
+	CASE WHEN manga.is_manga_plus = 1 THEN COALESCE(users.unread_warning_threshold, ?) ELSE 0 END
+)`
+
+// UserSettings holds a user's notification and sharing preferences. An UnreadWarning of
+// 0 turns the backlog warning off; while UnreadWarningSet is false it only covers MANGA
+// Plus titles. ShareProgress only matters with ShareLibrary on.
+type UserSettings struct {
+	UnreadWarning    int
+	UnreadWarningSet bool
+	BacklogReport    bool
+	ShareLibrary     bool
+	ShareProgress    bool
+}
+
+// BacklogEntry is a title at or above its unread warning threshold.
+type BacklogEntry struct {
+	MangaID   int
+	Title     string
+	Unread    int
+	Threshold int
+}
+
+// GetUserSettings returns the user's preferences, or the defaults for an unknown user.
+func (db *DB) GetUserSettings(userID int64) (UserSettings, error) {
+	var (
+		s                                       UserSettings
+		warningSet, report, shareLibrary, share int
+	)
+	err := db.QueryRow(`
+		SELECT unread_warning_threshold, unread_warning_set, backlog_report, share_library, share_progress
+		FROM users WHERE chat_id = ?
+	`, userID).Scan(&s.UnreadWarning, &warningSet, &report, &shareLibrary, &share)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UserSettings{UnreadWarning: DefaultUnreadWarningThreshold}, nil
+	}
+	if err != nil {
+		return UserSettings{}, err
+	}
+	s.UnreadWarningSet = warningSet != 0
+	s.BacklogReport = report != 0
+	s.ShareLibrary = shareLibrary != 0
+	s.ShareProgress = share != 0
+	return s, nil
+}
+
+// SetUserUnreadWarning sets the user's default threshold (0 disables the warning). From then
+// on it applies to every title, not only MANGA Plus ones.
+func (db *DB) SetUserUnreadWarning(userID int64, threshold int) error {
+	_, err := db.Exec("UPDATE users SET unread_warning_threshold = ?, unread_warning_set = 1 WHERE chat_id = ?", max(threshold, 0), userID)
+	return err
+}
+
+// SetUserBacklogReport opts the user in or out of the weekly backlog report.
+func (db *DB) SetUserBacklogReport(userID int64, enabled bool) error {
+	val := 0
+	if enabled {
+		val = 1
+	}
+	_, err := db.Exec("UPDATE users SET backlog_report = ? WHERE chat_id = ?", val, userID)
+	return err
+}
+
+// GetMangaUnreadWarning returns the threshold that applies to the title and whether it is
+// a per-title override rather than the owner's default.
+func (db *DB) GetMangaUnreadWarning(mangaID int) (threshold int, overridden bool, err error) {
+	err = db.QueryRow(`
+		SELECT `+unreadWarningSQL+`, manga.unread_warning_threshold IS NOT NULL
+		FROM manga
+		LEFT JOIN users ON users.chat_id = manga.user_id
+		WHERE manga.id = ?
+	`, DefaultUnreadWarningThreshold, mangaID).Scan(&threshold, &overridden)
+	return threshold, overridden, err
+}
+
+// SetMangaUnreadWarning overrides the threshold for one of the user's titles. A negative
+// threshold goes back to the user's default; 0 disables the warning for the title.
+func (db *DB) SetMangaUnreadWarning(mangaID int, userID int64, threshold int) error {
+	var value any
+	if threshold >= 0 {
+		value = threshold
+	}
+	res, err := db.Exec("UPDATE manga SET unread_warning_threshold = ? WHERE id = ? AND user_id = ?", value, mangaID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListBacklog returns the user's titles at or above their unread warning threshold, most
+// unread first. Titles in muted categories are left out.
+func (db *DB) ListBacklog(userID int64) ([]BacklogEntry, error) {
+	rows, err := db.Query(`
+		SELECT manga.id, manga.title, manga.unread_count, `+unreadWarningSQL+` AS threshold
+		FROM manga
+		LEFT JOIN users ON users.chat_id = manga.user_id
+		WHERE manga.user_id = ?
+		  AND threshold > 0
+		  AND manga.unread_count >= threshold
+		  AND NOT EXISTS (
+			SELECT 1 FROM category_mutes
+			WHERE category_mutes.user_id = manga.user_id AND category_mutes.category = manga.category
+		  )
+		ORDER BY manga.unread_count DESC, manga.title COLLATE NOCASE
+	`, DefaultUnreadWarningThreshold, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var entries []BacklogEntry
+	for rows.Next() {
+		var e BacklogEntry
+		if err := rows.Scan(&e.MangaID, &e.Title, &e.Unread, &e.Threshold); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListBacklogReportUsers returns the users who asked for the weekly backlog report.
+func (db *DB) ListBacklogReportUsers() ([]int64, error) {
+	rows, err := db.Query("SELECT chat_id FROM users WHERE backlog_report = 1 AND disabled_at IS NULL ORDER BY chat_id")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var users []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		users = append(users, id)
+	}
+	return users, rows.Err()
+}
@@ -2,10 +2,13 @@ package db
 
 import "database/sql"
 
+// GetAllUsers returns the chat IDs of the people using the bot. Disabled users and group
+// chats (negative IDs) are left out.
 func (db *DB) GetAllUsers() (*sql.Rows, error) {
-	return db.Query("SELECT chat_id FROM users")
+	return db.Query("SELECT chat_id FROM users WHERE disabled_at IS NULL AND chat_id > 0 ORDER BY chat_id")
 }
 
+// ListUsers is GetAllUsers as a slice.
 func (db *DB) ListUsers() ([]int64, error) {
 	rows, err := db.GetAllUsers()
 	if err != nil {
@@ -45,15 +48,21 @@ func (db *DB) EnsureUser(chatID int64, isAdmin bool) error {
 	return err
 }
 
+// IsUserAuthorized reports whether chatID may use the bot and whether it is an admin.
+// Disabled users aren't authorized.
 func (db *DB) IsUserAuthorized(chatID int64) (bool, bool, error) {
 	var isAdmin int
-	err := db.QueryRow("SELECT is_admin FROM users WHERE chat_id = ?", chatID).Scan(&isAdmin)
+	var disabledAt sql.NullTime
+	err := db.QueryRow("SELECT is_admin, disabled_at FROM users WHERE chat_id = ?", chatID).Scan(&isAdmin, &disabledAt)
 	if err == sql.ErrNoRows {
 		return false, false, nil
 	}
 	if err != nil {
 		return false, false, err
 	}
+	if disabledAt.Valid && isAdmin == 0 {
+		return false, false, nil
+	}
 	return true, isAdmin != 0, nil
 }
 
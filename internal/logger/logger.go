@@ -1,46 +1,183 @@
 package logger
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	AppName = "ReleaseNoJutsu"
 
+	LogDebug   = "DEBUG"
 	LogError   = "ERROR"
 	LogInfo    = "INFO"
 	LogWarning = "WARN"
 )
 
+// Structured field keys shared by bot, updater and cron so one user or one run can be
+// followed across packages.
+const (
+	KeyUserID     = "user_id"
+	KeyMangaID    = "manga_id"
+	KeyMangaDexID = "mangadex_id"
+	KeyRunID      = "run_id"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Options configures Init.
+type Options struct {
+	Format string
+	Level  slog.Level
+	Dir    string
+	// MaxSizeBytes rotates the log file once it grows past this size.
+	MaxSizeBytes int64
+	// RotateEvery rotates the log file once it has been open this long.
+	RotateEvery time.Duration
+	// MaxAge prunes rotated files older than this.
+	MaxAge time.Duration
+}
+
+// DefaultOptions returns the settings used by InitLogger.
+func DefaultOptions() Options {
+	return Options{
+		Format:       FormatText,
+		Level:        slog.LevelInfo,
+		Dir:          "logs",
+		MaxSizeBytes: 10 << 20,
+		RotateEvery:  24 * time.Hour,
+		MaxAge:       14 * 24 * time.Hour,
+	}
+}
+
 var (
-	logger *log.Logger
+	mu      sync.RWMutex
+	current *slog.Logger
+	logFile *rotatingFile
 )
 
 func InitLogger() {
-	err := os.MkdirAll("logs", 0o755)
+	if err := Init(DefaultOptions()); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+}
+
+// Init builds the process logger writing to stdout and a rotating file in opts.Dir.
+func Init(opts Options) error {
+	file, err := newRotatingFile(opts.Dir, AppName, opts.MaxSizeBytes, opts.RotateEvery, opts.MaxAge)
 	if err != nil {
-		log.Fatalf("Failed to create logs folder: %v", err)
+		return err
 	}
 
-	logFile, err := os.OpenFile(filepath.Join("logs", AppName+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	handler, err := newHandler(io.MultiWriter(os.Stdout, file), opts)
 	if err != nil {
-		log.Fatalf("Failed to open log file: %v", err)
+		_ = file.Close()
+		return err
 	}
 
-	multiWriter := io.MultiWriter(os.Stdout, logFile)
-	logger = log.New(multiWriter, "", log.Ldate|log.Ltime|log.Lshortfile)
+	mu.Lock()
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = file
+	current = slog.New(handler)
+	mu.Unlock()
+
 	LogMsg(LogInfo, "Application started")
+	return nil
+}
+
+func newHandler(w io.Writer, opts Options) (slog.Handler, error) {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		return slog.NewTextHandler(w, handlerOpts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, handlerOpts), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+}
+
+// ParseLevel accepts debug, info, warn/warning and error (case-insensitive).
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// L returns the process logger. Before Init it falls back to slog's default logger.
+func L() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return slog.Default()
+	}
+	return current
+}
+
+type ctxKey struct{}
+
+// WithContext returns a context whose logger carries the given attributes in addition to
+// any already attached to ctx.
+func WithContext(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).With(args...))
 }
 
+// FromContext returns the logger attached by WithContext, or L().
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return L()
+}
+
+// NewRunID returns a short identifier for correlating the log lines of one job run.
+func NewRunID() string {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// LogMsg logs a printf-style message at one of the Log* levels.
 func LogMsg(level string, format string, v ...interface{}) {
-	msg := fmt.Sprintf(format, v...)
-	if logger == nil {
-		log.Printf("[%s] %s", level, msg)
-		return
+	L().Log(context.Background(), slogLevel(level), fmt.Sprintf(format, v...))
+}
+
+func slogLevel(level string) slog.Level {
+	switch level {
+	case LogDebug:
+		return slog.LevelDebug
+	case LogWarning:
+		return slog.LevelWarn
+	case LogError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
 	}
-	logger.Printf("[%s] %s", level, msg)
 }
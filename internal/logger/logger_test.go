@@ -1,10 +1,14 @@
 package logger
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestInitLoggerAndLogMsg(t *testing.T) {
@@ -34,3 +38,95 @@ func TestInitLoggerAndLogMsg(t *testing.T) {
 		t.Fatalf("log missing message line: %q", text)
 	}
 }
+
+func TestInit_JSONFormatLevelAndContextFields(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.Dir = dir
+	opts.Format = FormatJSON
+	opts.Level = slog.LevelWarn
+	if err := Init(opts); err != nil {
+		t.Fatalf("Init(): %v", err)
+	}
+	t.Cleanup(func() { _ = Init(Options{Dir: t.TempDir()}) })
+
+	LogMsg(LogInfo, "filtered out")
+	ctx := WithContext(context.Background(), KeyRunID, "run-1")
+	ctx = WithContext(ctx, KeyUserID, int64(42))
+	FromContext(ctx).Warn("scoped warning", KeyMangaID, 7)
+
+	content, err := os.ReadFile(filepath.Join(dir, AppName+".log"))
+	if err != nil {
+		t.Fatalf("ReadFile(): %v", err)
+	}
+	text := string(content)
+	if strings.Contains(text, "filtered out") {
+		t.Fatalf("info line written despite warn level: %q", text)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &line); err != nil {
+		t.Fatalf("log line is not JSON: %v (%q)", err, text)
+	}
+	if line["msg"] != "scoped warning" || line[KeyRunID] != "run-1" || line[KeyUserID] != float64(42) || line[KeyMangaID] != float64(7) {
+		t.Fatalf("unexpected JSON fields: %v", line)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "DEBUG": slog.LevelDebug, "warning": slog.LevelWarn, "error": slog.LevelError} {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = (%v,%v), want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("ParseLevel(verbose) expected error")
+	}
+}
+
+func TestRotatingFile_RotatesBySizeAndAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r, err := newRotatingFile(dir, "app", 10, time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("newRotatingFile(): %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	r.now = func() time.Time { return now }
+
+	// Leftover rotated file past retention gets pruned on the next rotation.
+	stale := filepath.Join(dir, "app-20200101T000000.000.log")
+	if err := os.WriteFile(stale, []byte("old"), 0o600); err != nil {
+		t.Fatalf("WriteFile(stale): %v", err)
+	}
+	if err := os.Chtimes(stale, now.Add(-48*time.Hour), now.Add(-48*time.Hour)); err != nil {
+		t.Fatalf("Chtimes(): %v", err)
+	}
+
+	mustWrite := func(s string) {
+		t.Helper()
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatalf("Write(): %v", err)
+		}
+	}
+	mustWrite("12345678")
+	mustWrite("abcdef") // exceeds 10 bytes -> rotate
+	now = now.Add(2 * time.Hour)
+	mustWrite("x") // older than an hour -> rotate
+
+	rotated, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(rotated) != 2 {
+		t.Fatalf("rotated files=%v, want 2", rotated)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale rotated file not pruned: %v", err)
+	}
+	current, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatalf("ReadFile(app.log): %v", err)
+	}
+	if string(current) != "x" {
+		t.Fatalf("current log=%q, want x", current)
+	}
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// rotatingFile is an io.Writer that moves the active log aside once it exceeds a size or
+// age limit, and prunes rotated files past their retention.
+type rotatingFile struct {
+	mu          sync.Mutex
+	dir         string
+	name        string
+	maxSize     int64
+	rotateEvery time.Duration
+	maxAge      time.Duration
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func newRotatingFile(dir, name string, maxSize int64, rotateEvery, maxAge time.Duration) (*rotatingFile, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create log folder: %w", err)
+	}
+	r := &rotatingFile{
+		dir:         dir,
+		name:        name,
+		maxSize:     maxSize,
+		rotateEvery: rotateEvery,
+		maxAge:      maxAge,
+		now:         time.Now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) path() string {
+	return filepath.Join(r.dir, r.name+".log")
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = r.now()
+	if r.size > 0 {
+		// Age an existing file from when it was last written, so restarts don't reset it.
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) shouldRotate(incoming int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+incoming > r.maxSize {
+		return true
+	}
+	return r.rotateEvery > 0 && r.now().Sub(r.openedAt) >= r.rotateEvery
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	rotated := filepath.Join(r.dir, fmt.Sprintf("%s-%s.log", r.name, r.now().UTC().Format("20060102T150405.000")))
+	if err := os.Rename(r.path(), rotated); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	r.prune()
+	return r.open()
+}
+
+// prune removes rotated files older than maxAge.
+func (r *rotatingFile) prune() {
+	if r.maxAge <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(r.dir, r.name+"-*.log"))
+	if err != nil {
+		return
+	}
+	cutoff := r.now().Add(-r.maxAge)
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			_ = os.Remove(m)
+		}
+	}
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -3,6 +3,7 @@ package mangadex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"releasenojutsu/internal/logger"
+	"releasenojutsu/internal/metrics"
 )
 
 const (
@@ -19,6 +21,10 @@ const (
 	appName = "ReleaseNoJutsu"
 )
 
+// ErrNotFound is returned when MangaDex answers 404. It is not retried: the entry is gone
+// (deleted or merged into another one).
+var ErrNotFound = errors.New("not found on MangaDex")
+
 // Client is a client for the MangaDex API.
 
 type Client struct {
@@ -53,6 +59,7 @@ func (c *Client) FetchJSON(ctx context.Context, url string) ([]byte, error) {
 
 	for i := 0; i < maxRetries; i++ {
 		if i > 0 {
+			metrics.MangaDexRetries.Inc()
 			sleepDuration := time.Duration(1<<uint(i)) * time.Second
 			logger.LogMsg(logger.LogInfo, "Retry %d/%d for URL: %s", i+1, maxRetries, url)
 			if err := sleepWithContext(ctx, sleepDuration); err != nil {
@@ -69,8 +76,11 @@ func (c *Client) FetchJSON(ctx context.Context, url string) ([]byte, error) {
 		req.Header.Set("User-Agent", fmt.Sprintf("%s/1.0", appName))
 		req.Header.Set("Accept", "application/json")
 
+		start := time.Now()
 		resp, err := c.HTTPClient.Do(req)
+		metrics.MangaDexRequestDuration.ObserveSince(start)
 		if err != nil {
+			metrics.MangaDexRequests.Inc("error")
 			lastErr = fmt.Errorf("error making request: %v", err)
 			if ctx.Err() != nil {
 				break
@@ -78,6 +88,8 @@ func (c *Client) FetchJSON(ctx context.Context, url string) ([]byte, error) {
 			continue
 		}
 
+		metrics.MangaDexRequests.Inc(strconv.Itoa(resp.StatusCode))
+
 		body, readErr := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
 		if readErr != nil {
@@ -85,9 +97,13 @@ func (c *Client) FetchJSON(ctx context.Context, url string) ([]byte, error) {
 			continue
 		}
 
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("API returned 404 for %s: %w", url, ErrNotFound)
+		}
 		if resp.StatusCode != http.StatusOK {
 			lastErr = fmt.Errorf("API returned non-200 status code %d: %s", resp.StatusCode, string(body))
 			if resp.StatusCode == 429 { // Too Many Requests
+				metrics.MangaDexRateLimited.Inc()
 				retryAfter := retryAfterDuration(resp.Header.Get("Retry-After"))
 				if retryAfter > 0 {
 					logger.LogMsg(logger.LogWarning, "Rate limit hit, retrying after %s", retryAfter)
@@ -165,6 +181,113 @@ func (c *Client) GetManga(ctx context.Context, mangaID string) (*MangaResponse,
 	return &mangaData, nil
 }
 
+// SearchMangaByTitle returns up to limit entries matching title, with their cover art.
+func (c *Client) SearchMangaByTitle(ctx context.Context, title string, limit int) (*MangaListResponse, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	q := url.Values{}
+	q.Set("title", title)
+	q.Set("limit", strconv.Itoa(limit))
+	q.Add("includes[]", "cover_art")
+	return c.fetchMangaList(ctx, q)
+}
+
+// GetMangaByIDs returns the entries with the given IDs, with their cover art. Entries
+// that no longer exist are left out.
+func (c *Client) GetMangaByIDs(ctx context.Context, ids []string) (*MangaListResponse, error) {
+	if len(ids) == 0 {
+		return &MangaListResponse{}, nil
+	}
+	q := url.Values{}
+	for _, id := range ids {
+		q.Add("ids[]", id)
+	}
+	q.Set("limit", strconv.Itoa(len(ids)))
+	q.Add("includes[]", "cover_art")
+	return c.fetchMangaList(ctx, q)
+}
+
+func (c *Client) fetchMangaList(ctx context.Context, q url.Values) (*MangaListResponse, error) {
+	u, err := url.Parse(fmt.Sprintf("%s/manga", c.BaseURL))
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = q.Encode()
+
+	body, err := c.FetchJSON(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+	var list MangaListResponse
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// mergeRelations are the manga relationship kinds MangaDex leaves on a superseded entry
+// pointing at the one that replaced it. Colored or official versions use the same kind, so
+// a related entry only counts when it also has the title and chapters of its own.
+var mergeRelations = map[string]bool{
+	"alternate_version": true,
+}
+
+// FindMergeTarget looks for the entry mangaID was merged into. If the old entry still
+// exists it follows its relationships; if it is gone it searches for an exact title match.
+// Either way the candidate must carry title and have chapters. It returns "" when there is
+// no single clear candidate.
+func (c *Client) FindMergeTarget(ctx context.Context, mangaID, title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", nil
+	}
+
+	var list *MangaListResponse
+	manga, err := c.GetManga(ctx, mangaID)
+	switch {
+	case err == nil:
+		var related []string
+		for _, rel := range manga.Data.Relationships {
+			if rel.Type != "manga" || !mergeRelations[rel.Related] || rel.ID == mangaID {
+				continue
+			}
+			related = append(related, rel.ID)
+		}
+		if len(related) == 0 {
+			return "", nil
+		}
+		list, err = c.GetMangaByIDs(ctx, related)
+	case errors.Is(err, ErrNotFound):
+		list, err = c.SearchMangaByTitle(ctx, title, 10)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var target string
+	for _, m := range list.Data {
+		if m.Id == mangaID || !m.HasTitle(title) {
+			continue
+		}
+		if target != "" && target != m.Id {
+			return "", nil
+		}
+		target = m.Id
+	}
+	if target == "" {
+		return "", nil
+	}
+	feed, err := c.GetChapterFeedPage(ctx, target, 1, 0)
+	if err != nil {
+		return "", err
+	}
+	if len(feed.Data) == 0 {
+		return "", nil
+	}
+	return target, nil
+}
+
 func (c *Client) GetChapterFeed(ctx context.Context, mangaID string) (*ChapterFeedResponse, error) {
 	return c.GetChapterFeedPage(ctx, mangaID, 100, 0)
 }
@@ -3,11 +3,14 @@ package mangadex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"releasenojutsu/internal/metrics"
 )
 
 func TestNewClientWithLanguages(t *testing.T) {
@@ -71,6 +74,34 @@ func TestFetchJSON_429ThenContextCancel(t *testing.T) {
 	}
 }
 
+func TestFetchJSON_RecordsMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	before429 := metrics.MangaDexRequests.Value("429")
+	beforeLimited := metrics.MangaDexRateLimited.Value()
+	beforeObserved := metrics.MangaDexRequestDuration.Count()
+
+	c := NewClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := c.FetchJSON(ctx, srv.URL); err == nil {
+		t.Fatal("FetchJSON expected error")
+	}
+
+	if got := metrics.MangaDexRequests.Value("429") - before429; got < 1 {
+		t.Fatalf("429 request count delta=%v, want >=1", got)
+	}
+	if got := metrics.MangaDexRateLimited.Value() - beforeLimited; got < 1 {
+		t.Fatalf("rate limited delta=%v, want >=1", got)
+	}
+	if metrics.MangaDexRequestDuration.Count() <= beforeObserved {
+		t.Fatal("expected request latency to be observed")
+	}
+}
+
 func TestGetManga_ErrorPath(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "boom", http.StatusInternalServerError)
@@ -127,3 +158,117 @@ func TestGetChapterFeedPage_AppliesLanguageQueryAndBounds(t *testing.T) {
 		t.Fatalf("query missing default limit/offset: %q", gotQuery)
 	}
 }
+
+func TestFetchJSON_NotFoundIsNotRetried(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, `{"result":"error"}`, http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	_, err := c.FetchJSON(context.Background(), srv.URL)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("FetchJSON() err=%v, want ErrNotFound", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls=%d, want 1 (no retries on 404)", calls)
+	}
+}
+
+func TestFindMergeTarget(t *testing.T) {
+	const (
+		oldID = "11111111-1111-1111-1111-111111111111"
+		newID = "22222222-2222-2222-2222-222222222222"
+	)
+	var gone bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/manga/"+oldID && gone:
+			http.Error(w, "gone", http.StatusNotFound)
+		case r.URL.Path == "/manga/"+oldID:
+			_, _ = w.Write([]byte(`{"data":{"id":"` + oldID + `","relationships":[
+				{"id":"33333333-3333-3333-3333-333333333333","type":"manga","related":"sequel"},
+				{"id":"` + newID + `","type":"manga","related":"alternate_version"},
+				{"id":"44444444-4444-4444-4444-444444444444","type":"author"}]}}`))
+		case r.URL.Path == "/manga/"+newID+"/feed":
+			_, _ = w.Write([]byte(`{"data":[{"id":"c1","attributes":{"chapter":"1"}}]}`))
+		case r.URL.Path == "/manga" && r.URL.Query().Get("ids[]") == newID:
+			_, _ = w.Write([]byte(`{"data":[{"id":"` + newID + `","attributes":{"title":{"ja-ro":"Dandadan"}}}]}`))
+		case r.URL.Path == "/manga":
+			if r.URL.Query().Get("title") != "Dandadan" {
+				t.Errorf("search title=%q", r.URL.Query().Get("title"))
+			}
+			_, _ = w.Write([]byte(`{"data":[
+				{"id":"55555555-5555-5555-5555-555555555555","attributes":{"title":{"en":"Dandadan Spin-off"}}},
+				{"id":"` + newID + `","attributes":{"title":{"ja-ro":"Dandadan"},"altTitles":[{"en":"DAN DA DAN"}]}}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	c.BaseURL = srv.URL
+
+	got, err := c.FindMergeTarget(context.Background(), oldID, "Dandadan")
+	if err != nil || got != newID {
+		t.Fatalf("FindMergeTarget(relationship)=%q err=%v, want %q", got, err, newID)
+	}
+
+	// Once the old entry is deleted, fall back to an exact title search.
+	gone = true
+	got, err = c.FindMergeTarget(context.Background(), oldID, "Dandadan")
+	if err != nil || got != newID {
+		t.Fatalf("FindMergeTarget(search)=%q err=%v, want %q", got, err, newID)
+	}
+
+	got, err = c.FindMergeTarget(context.Background(), oldID, "")
+	if err != nil || got != "" {
+		t.Fatalf("FindMergeTarget(no title)=%q err=%v, want no candidate", got, err)
+	}
+}
+
+func TestFindMergeTarget_IgnoresAlternateVersions(t *testing.T) {
+	const (
+		oldID     = "11111111-1111-1111-1111-111111111111"
+		coloredID = "22222222-2222-2222-2222-222222222222"
+		emptyID   = "33333333-3333-3333-3333-333333333333"
+	)
+	related := coloredID
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/manga/" + oldID:
+			_, _ = w.Write([]byte(`{"data":{"id":"` + oldID + `","relationships":[
+				{"id":"` + related + `","type":"manga","related":"alternate_version"}]}}`))
+		case "/manga":
+			titles := map[string]string{coloredID: "Dandadan (Official Colored)", emptyID: "Dandadan"}
+			id := r.URL.Query().Get("ids[]")
+			_, _ = w.Write([]byte(`{"data":[{"id":"` + id + `","attributes":{"title":{"en":"` + titles[id] + `"}}}]}`))
+		case "/manga/" + coloredID + "/feed":
+			_, _ = w.Write([]byte(`{"data":[{"id":"c1","attributes":{"chapter":"1"}}]}`))
+		case "/manga/" + emptyID + "/feed":
+			_, _ = w.Write([]byte(`{"data":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	c.BaseURL = srv.URL
+
+	// A removed title whose colored version lives on is not a merge.
+	got, err := c.FindMergeTarget(context.Background(), oldID, "Dandadan")
+	if err != nil || got != "" {
+		t.Fatalf("FindMergeTarget(colored version)=%q err=%v, want no candidate", got, err)
+	}
+
+	// Neither is a same-titled entry without chapters.
+	related = emptyID
+	got, err = c.FindMergeTarget(context.Background(), oldID, "Dandadan")
+	if err != nil || got != "" {
+		t.Fatalf("FindMergeTarget(empty target)=%q err=%v, want no candidate", got, err)
+	}
+}
@@ -119,3 +119,40 @@ func TestGetChapterFeed_UsesClientBaseURLAndParsesPublishAt(t *testing.T) {
 		t.Fatalf("PublishedAt=%v, want %v", got.Data[0].Attributes.PublishedAt.UTC(), wantPublishedAt)
 	}
 }
+
+func TestGetMangaByIDs_IncludesCoverArt(t *testing.T) {
+	t.Parallel()
+
+	const id = "37b87be0-b1f4-4507-affa-06c99ebb27f8"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/manga" || strings.Join(q["ids[]"], ",") != id || q.Get("includes[]") != "cover_art" || q.Get("limit") != "1" {
+			t.Fatalf("unexpected request %s", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[{"id":"` + id + `","attributes":{"title":{"en":"Dragon Ball"},"status":"completed","lastChapter":"519"},
+			"relationships":[{"id":"a","type":"author"},{"id":"c","type":"cover_art","attributes":{"fileName":"cover.jpg"}}]}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewClient()
+	c.BaseURL = srv.URL
+	list, err := c.GetMangaByIDs(context.Background(), []string{id})
+	if err != nil {
+		t.Fatalf("GetMangaByIDs(): %v", err)
+	}
+	if len(list.Data) != 1 {
+		t.Fatalf("entries=%d, want 1", len(list.Data))
+	}
+	m := list.Data[0]
+	if m.Attributes.Status != "completed" || m.Attributes.LastChapter != "519" {
+		t.Fatalf("attributes=%+v", m.Attributes)
+	}
+	if got, want := m.CoverURL(), "https://uploads.mangadex.org/covers/"+id+"/cover.jpg.256.jpg"; got != want {
+		t.Fatalf("CoverURL()=%q, want %q", got, want)
+	}
+
+	if list, err := c.GetMangaByIDs(context.Background(), nil); err != nil || len(list.Data) != 0 {
+		t.Fatalf("GetMangaByIDs(nil)=%v err=%v, want no request and no entries", list, err)
+	}
+}
@@ -1,16 +1,96 @@
 package mangadex
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
 
 // MangaResponse represents the response for a single manga from the MangaDex API.
 
 type MangaResponse struct {
-	Data struct {
-		Id         string `json:"id"`
-		Attributes struct {
-			Title map[string]string `json:"title"`
-		} `json:"attributes"`
-	} `json:"data"`
+	Data MangaData `json:"data"`
+}
+
+// MangaListResponse represents a page of manga search results.
+
+type MangaListResponse struct {
+	Data  []MangaData `json:"data"`
+	Total int         `json:"total"`
+}
+
+type MangaData struct {
+	Id         string `json:"id"`
+	Attributes struct {
+		Title     map[string]string   `json:"title"`
+		AltTitles []map[string]string `json:"altTitles"`
+		// Status is the publication status: "ongoing", "completed", "hiatus" or "cancelled".
+		Status string `json:"status"`
+		// LastChapter is the final chapter's number, usually only set once a series ends.
+		LastChapter string `json:"lastChapter"`
+	} `json:"attributes"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// Relationship links an entry to another one; Related is set for manga-to-manga links
+// (e.g. "sequel", "alternate_version").
+
+type Relationship struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Related string `json:"related"`
+	// Attributes is only filled for included relationships, e.g. cover art.
+	Attributes struct {
+		FileName string `json:"fileName"`
+	} `json:"attributes"`
+}
+
+// TitleURL is the entry's page on the MangaDex website.
+func TitleURL(mangaID string) string {
+	return "https://mangadex.org/title/" + mangaID
+}
+
+// CoverURL returns a 256px thumbnail of the entry's cover, or "" when the cover art
+// wasn't included in the response.
+func (m MangaData) CoverURL() string {
+	for _, rel := range m.Relationships {
+		if rel.Type == "cover_art" && rel.Attributes.FileName != "" {
+			return fmt.Sprintf("https://uploads.mangadex.org/covers/%s/%s.256.jpg", m.Id, rel.Attributes.FileName)
+		}
+	}
+	return ""
+}
+
+// PreferredTitle returns the English title when available, falling back to any other
+// non-empty title ("" if there is none).
+func (m MangaData) PreferredTitle() string {
+	if en := strings.TrimSpace(m.Attributes.Title["en"]); en != "" {
+		return en
+	}
+	for _, t := range m.Attributes.Title {
+		if t = strings.TrimSpace(t); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// HasTitle reports whether title matches one of the entry's titles, ignoring case.
+func (m MangaData) HasTitle(title string) bool {
+	for _, t := range m.Attributes.Title {
+		if strings.EqualFold(strings.TrimSpace(t), title) {
+			return true
+		}
+	}
+	for _, alt := range m.Attributes.AltTitles {
+		for _, t := range alt {
+			if strings.EqualFold(strings.TrimSpace(t), title) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type ChapterAttributes struct {
@@ -21,6 +101,8 @@ type ChapterAttributes struct {
 	ReadableAt  time.Time `json:"readableAt"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	// ExternalURL is set for official releases hosted elsewhere (e.g. MANGA Plus).
+	ExternalURL string `json:"externalUrl"`
 }
 
 type Chapter struct {
@@ -40,6 +122,17 @@ type ChapterFeedResponse struct {
 // ChapterInfo holds simplified chapter information.
 
 type ChapterInfo struct {
-	Number string
-	Title  string
+	Number      string
+	Title       string
+	ExternalURL string
+}
+
+// IsMangaPlusURL reports whether an external chapter link points at MANGA Plus.
+func IsMangaPlusURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host == "mangaplus.shueisha.co.jp" || strings.HasSuffix(host, ".mangaplus.shueisha.co.jp")
 }
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
+)
+
+// StatusSource is the subset of *db.DB the HTTP endpoints need.
+type StatusSource interface {
+	PingContext(ctx context.Context) error
+	GetStatus() (db.Status, error)
+}
+
+// Server exposes /healthz, /readyz and /metrics.
+type Server struct {
+	DB StatusSource
+	// MaxCronAge is how old cron_last_run may be before /readyz fails. Until the first run
+	// completes, the process is considered ready for MaxCronAge after StartedAt.
+	MaxCronAge time.Duration
+	StartedAt  time.Time
+
+	now func() time.Time
+}
+
+func NewServer(source StatusSource, maxCronAge time.Duration) *Server {
+	return &Server{
+		DB:         source,
+		MaxCronAge: maxCronAge,
+		StartedAt:  time.Now().UTC(),
+		now:        time.Now,
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+// ListenAndServe serves the endpoints on addr until ctx is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	logger.LogMsg(logger.LogInfo, "Metrics server listening on %s", addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if err := s.pingDB(r.Context()); err != nil {
+		http.Error(w, "database unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if err := s.pingDB(r.Context()); err != nil {
+		http.Error(w, "database unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	status, err := s.DB.GetStatus()
+	if err != nil {
+		http.Error(w, "status query failed: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err := s.checkScheduler(status); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) checkScheduler(status db.Status) error {
+	if s.MaxCronAge <= 0 {
+		return nil
+	}
+	now := s.now()
+	if !status.HasCronLastRun {
+		if now.Sub(s.StartedAt) > s.MaxCronAge {
+			return fmt.Errorf("scheduler has not completed a run since %s", s.StartedAt.Format(time.RFC3339))
+		}
+		return nil
+	}
+	if age := now.Sub(status.CronLastRun); age > s.MaxCronAge {
+		return fmt.Errorf("scheduler last ran %s ago (limit %s)", age.Round(time.Second), s.MaxCronAge)
+	}
+	return nil
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	up := 1.0
+	if err := s.pingDB(r.Context()); err != nil {
+		up = 0
+	} else if status, err := s.DB.GetStatus(); err != nil {
+		logger.LogMsg(logger.LogWarning, "Metrics: status query failed: %v", err)
+	} else {
+		_ = writeGauge(&buf, "releasenojutsu_tracked_titles", "Titles tracked across all users.", float64(status.MangaCount))
+		_ = writeGauge(&buf, "releasenojutsu_users", "Registered users.", float64(status.UserCount))
+		_ = writeGauge(&buf, "releasenojutsu_chapters", "Chapters stored.", float64(status.ChapterCount))
+		_ = writeGauge(&buf, "releasenojutsu_unread_chapters", "Unread chapters across all users.", float64(status.UnreadTotal))
+		if status.HasCronLastRun {
+			_ = writeGauge(&buf, "releasenojutsu_scheduler_last_run_timestamp_seconds", "Unix time of the last completed scheduled run.", float64(status.CronLastRun.Unix()))
+		}
+	}
+	_ = writeGauge(&buf, "releasenojutsu_database_up", "Whether the database answered a ping.", up)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (s *Server) pingDB(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return s.DB.PingContext(ctx)
+}
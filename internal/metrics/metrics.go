@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Process-wide metrics, rendered in Prometheus text format by WriteText.
+var (
+	MangaDexRequests = NewCounter("releasenojutsu_mangadex_requests_total",
+		"MangaDex HTTP requests by status code (\"error\" for transport failures).", "code")
+	MangaDexRequestDuration = NewHistogram("releasenojutsu_mangadex_request_duration_seconds",
+		"MangaDex HTTP request latency.", []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+	MangaDexRateLimited = NewCounter("releasenojutsu_mangadex_rate_limited_total",
+		"MangaDex responses with status 429.")
+	MangaDexRetries = NewCounter("releasenojutsu_mangadex_retries_total",
+		"MangaDex request retries.")
+
+	UpdaterTitlesChecked = NewCounter("releasenojutsu_updater_titles_checked_total",
+		"Titles checked for new chapters by result.", "result")
+	UpdaterNewChapters = NewCounter("releasenojutsu_updater_new_chapters_total",
+		"New chapters found by the updater.")
+
+	SchedulerRuns = NewCounter("releasenojutsu_scheduler_runs_total",
+		"Scheduled update runs by result.", "result")
+	SchedulerRunDuration = NewHistogram("releasenojutsu_scheduler_run_duration_seconds",
+		"Duration of scheduled update runs.", []float64{1, 5, 15, 30, 60, 120, 300, 600})
+	NotificationsSent = NewCounter("releasenojutsu_notifications_total",
+		"New chapter notifications by result.", "result")
+)
+
+var registry = []collector{
+	MangaDexRequests,
+	MangaDexRequestDuration,
+	MangaDexRateLimited,
+	MangaDexRetries,
+	UpdaterTitlesChecked,
+	UpdaterNewChapters,
+	SchedulerRuns,
+	SchedulerRunDuration,
+	NotificationsSent,
+}
+
+type collector interface {
+	writeText(w io.Writer) error
+}
+
+// Counter is a monotonically increasing value, optionally split by one label.
+type Counter struct {
+	name   string
+	help   string
+	label  string
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func NewCounter(name, help string, label ...string) *Counter {
+	c := &Counter{name: name, help: help, values: map[string]float64{}}
+	if len(label) > 0 {
+		c.label = label[0]
+	}
+	return c
+}
+
+// Inc adds one. For labelled counters, pass the label value.
+func (c *Counter) Inc(labelValue ...string) {
+	c.Add(1, labelValue...)
+}
+
+func (c *Counter) Add(v float64, labelValue ...string) {
+	key := ""
+	if len(labelValue) > 0 {
+		key = labelValue[0]
+	}
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// Value returns the current value for a label value (or the unlabelled value).
+func (c *Counter) Value(labelValue ...string) float64 {
+	key := ""
+	if len(labelValue) > 0 {
+		key = labelValue[0]
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *Counter) writeText(w io.Writer) error {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]float64, len(keys))
+	for i, k := range keys {
+		values[i] = c.values[k]
+	}
+	c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	if c.label == "" {
+		_, err := fmt.Fprintf(w, "%s %s\n", c.name, formatFloat(c.Value()))
+		return err
+	}
+	for i, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s{%s=\"%s\"} %s\n", c.name, c.label, escapeLabel(k), formatFloat(values[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram counts observations into fixed cumulative buckets.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	mu      sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) writeText(w io.Writer) error {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	for i, b := range h.buckets {
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(b), counts[i]); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", h.name, count, h.name, formatFloat(sum), h.name, count)
+	return err
+}
+
+// writeGauge renders a single unlabelled gauge sample.
+func writeGauge(w io.Writer, name, help string, v float64) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatFloat(v))
+	return err
+}
+
+// WriteText renders all process-wide metrics in Prometheus text exposition format.
+func WriteText(w io.Writer) error {
+	for _, c := range registry {
+		if err := c.writeText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"releasenojutsu/internal/db"
+)
+
+func TestCounterAndHistogramText(t *testing.T) {
+	c := NewCounter("test_requests_total", "Requests.", "code")
+	c.Inc("200")
+	c.Inc("200")
+	c.Inc("429")
+	h := NewHistogram("test_duration_seconds", "Duration.", []float64{0.5, 1})
+	h.Observe(0.2)
+	h.Observe(0.7)
+	h.Observe(3)
+
+	var buf bytes.Buffer
+	if err := c.writeText(&buf); err != nil {
+		t.Fatalf("counter writeText(): %v", err)
+	}
+	if err := h.writeText(&buf); err != nil {
+		t.Fatalf("histogram writeText(): %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{code="200"} 2`,
+		`test_requests_total{code="429"} 1`,
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{le="0.5"} 1`,
+		`test_duration_seconds_bucket{le="1"} 2`,
+		`test_duration_seconds_bucket{le="+Inf"} 3`,
+		"test_duration_seconds_sum 3.9",
+		"test_duration_seconds_count 3",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func setupTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("db.New(): %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := database.CreateTables(); err != nil {
+		t.Fatalf("CreateTables(): %v", err)
+	}
+	if err := database.Migrate(1); err != nil {
+		t.Fatalf("Migrate(): %v", err)
+	}
+	return database
+}
+
+func get(t *testing.T, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_HealthAndReadiness(t *testing.T) {
+	database := setupTestDB(t)
+	s := NewServer(database, time.Hour)
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+
+	if code, _ := get(t, srv, "/healthz"); code != http.StatusOK {
+		t.Fatalf("/healthz status=%d, want 200", code)
+	}
+	// No run yet, but still inside the startup window.
+	if code, body := get(t, srv, "/readyz"); code != http.StatusOK {
+		t.Fatalf("/readyz (startup) status=%d body=%q, want 200", code, body)
+	}
+
+	s.StartedAt = time.Now().Add(-2 * time.Hour)
+	if code, _ := get(t, srv, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz (no run) status=%d, want 503", code)
+	}
+
+	database.UpdateCronLastRun()
+	if code, body := get(t, srv, "/readyz"); code != http.StatusOK {
+		t.Fatalf("/readyz (fresh run) status=%d body=%q, want 200", code, body)
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if code, body := get(t, srv, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "scheduler last ran") {
+		t.Fatalf("/readyz (stale run) status=%d body=%q, want 503", code, body)
+	}
+}
+
+type downDB struct{}
+
+func (downDB) PingContext(context.Context) error { return errors.New("disk gone") }
+func (downDB) GetStatus() (db.Status, error)     { return db.Status{}, errors.New("disk gone") }
+
+func TestServer_DatabaseDown(t *testing.T) {
+	srv := httptest.NewServer(NewServer(downDB{}, time.Hour).Handler())
+	t.Cleanup(srv.Close)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if code, _ := get(t, srv, path); code != http.StatusServiceUnavailable {
+			t.Fatalf("%s status=%d, want 503", path, code)
+		}
+	}
+	if _, body := get(t, srv, "/metrics"); !strings.Contains(body, "releasenojutsu_database_up 0") {
+		t.Fatalf("/metrics missing database_up 0:\n%s", body)
+	}
+}
+
+func TestServer_MetricsIncludesLibraryGauges(t *testing.T) {
+	database := setupTestDB(t)
+	if err := database.EnsureUser(42, false); err != nil {
+		t.Fatalf("EnsureUser(): %v", err)
+	}
+	if _, err := database.AddManga("md-1", "Test", 42); err != nil {
+		t.Fatalf("AddManga(): %v", err)
+	}
+	NotificationsSent.Inc("sent")
+
+	srv := httptest.NewServer(NewServer(database, time.Hour).Handler())
+	t.Cleanup(srv.Close)
+
+	code, body := get(t, srv, "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("/metrics status=%d, want 200", code)
+	}
+	for _, want := range []string{
+		"releasenojutsu_tracked_titles 1",
+		"releasenojutsu_users 2", // admin seeded by Migrate plus user 42
+		"releasenojutsu_unread_chapters 0",
+		"releasenojutsu_database_up 1",
+		`releasenojutsu_notifications_total{result="sent"}`,
+		"# TYPE releasenojutsu_mangadex_request_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("/metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package source
+
+import (
+	"context"
+	"strings"
+
+	"releasenojutsu/internal/mangadex"
+)
+
+// MangaDexSource tracks titles on mangadex.org.
+type MangaDexSource struct {
+	client *mangadex.Client
+}
+
+func NewMangaDex(client *mangadex.Client) *MangaDexSource {
+	return &MangaDexSource{client: client}
+}
+
+func (s *MangaDexSource) Name() string { return MangaDex }
+
+// ResolveURL accepts https://mangadex.org/title/<uuid>/... URLs and raw UUIDs.
+func (s *MangaDexSource) ResolveURL(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", false
+	}
+	if id, err := s.client.ExtractMangaIDFromURL(input); err == nil {
+		return id, true
+	}
+	// MangaDex IDs are UUIDs: 36 chars with 4 hyphens.
+	if len(input) == 36 && strings.Count(input, "-") == 4 {
+		return input, true
+	}
+	return "", false
+}
+
+func (s *MangaDexSource) FetchMetadata(ctx context.Context, id string) (Metadata, error) {
+	data, err := s.client.GetManga(ctx, id)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Metadata{Title: data.Data.PreferredTitle()}, nil
+}
+
+func (s *MangaDexSource) GetChapterFeedPage(ctx context.Context, id string, limit, offset int) (*FeedPage, error) {
+	return s.client.GetChapterFeedPage(ctx, id, limit, offset)
+}
+
+// FindMergeTarget lets the updater detect merged entries (see updater.MergeResolver).
+func (s *MangaDexSource) FindMergeTarget(ctx context.Context, id, title string) (string, error) {
+	return s.client.FindMergeTarget(ctx, id, title)
+}
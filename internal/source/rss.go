@@ -0,0 +1,448 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"releasenojutsu/internal/mangadex"
+)
+
+// maxFeedBytes caps how much of a feed is read; chapter feeds are small.
+const maxFeedBytes = 5 << 20
+
+// maxFeedFutureSkew is how far ahead of now an item may be dated before it is treated as
+// scheduled rather than released.
+const maxFeedFutureSkew = time.Hour
+
+// defaultChapterPattern pulls the chapter number out of titles like "Chapter 12",
+// "Ch. 12.5" or "Vol. 3 Ch 27".
+var defaultChapterPattern = regexp.MustCompile(`(?i)\b(?:chapter|chap|ch)\.?\s*#?\s*(\d+(?:\.\d+)?)`)
+
+// ChapterRule extracts a chapter number from an item title. The first capture group that
+// matched is the number. Rules with a Host only apply to feeds served from that host.
+type ChapterRule struct {
+	Host    string
+	Pattern *regexp.Regexp
+}
+
+var ruleHostPattern = regexp.MustCompile(`^[a-z0-9.-]+$`)
+
+// ParseChapterRules compiles rule specs of the form "regex" or "host=regex".
+func ParseChapterRules(specs []string) ([]ChapterRule, error) {
+	rules := make([]ChapterRule, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		rule := ChapterRule{}
+		expr := spec
+		if host, rest, ok := strings.Cut(spec, "="); ok && ruleHostPattern.MatchString(strings.ToLower(host)) && strings.Contains(host, ".") {
+			rule.Host = strings.ToLower(host)
+			expr = rest
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapter pattern %q: %v", spec, err)
+		}
+		if re.NumSubexp() == 0 {
+			return nil, fmt.Errorf("chapter pattern %q needs a capture group for the number", spec)
+		}
+		rule.Pattern = re
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// RSSSource tracks any site that publishes an RSS or Atom feed of its chapters. The
+// title's ID is the feed URL.
+type RSSSource struct {
+	HTTPClient *http.Client
+	// Rules are tried before the built-in "Chapter N" pattern; host-specific rules first.
+	Rules []ChapterRule
+	// AllowPrivateHosts lets feeds live on loopback or private networks. It is off by
+	// default because any user can make the bot fetch a URL.
+	AllowPrivateHosts bool
+
+	mu    sync.Mutex
+	cache map[string]*cachedFeed
+}
+
+// cachedFeed is the last good copy of a feed. Its validators make polling a conditional
+// GET, and a 304 replays the cached items so every caller still sees the whole feed.
+type cachedFeed struct {
+	etag         string
+	lastModified string
+	feed         *parsedFeed
+	// firstSeen stamps undated items with when they first showed up (zero for items that
+	// were already there on the first fetch).
+	firstSeen map[string]time.Time
+}
+
+func NewRSS(rules ...ChapterRule) *RSSSource {
+	s := &RSSSource{
+		Rules: rules,
+		cache: make(map[string]*cachedFeed),
+	}
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if s.AllowPrivateHosts {
+				return nil
+			}
+			return publicOnlyControl(network, address, c)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Feeds are dialled directly so the address check sees the real destination.
+	transport.Proxy = nil
+	s.HTTPClient = &http.Client{Timeout: 15 * time.Second, Transport: transport}
+	return s
+}
+
+func (s *RSSSource) Name() string { return RSS }
+
+// ResolveURL accepts any http(s) URL except MangaDex ones and obviously private hosts;
+// whether it really is a public feed is only known once it is fetched.
+func (s *RSSSource) ResolveURL(input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	u, err := url.Parse(input)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "mangadex.org" || strings.HasSuffix(host, ".mangadex.org") {
+		return "", false
+	}
+	if !s.AllowPrivateHosts && isPrivateHostname(host) {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func (s *RSSSource) FetchMetadata(ctx context.Context, id string) (Metadata, error) {
+	feed, err := s.fetch(ctx, id)
+	if err != nil {
+		return Metadata{}, err
+	}
+	return Metadata{Title: feed.title}, nil
+}
+
+func (s *RSSSource) GetChapterFeedPage(ctx context.Context, id string, limit, offset int) (*FeedPage, error) {
+	feed, err := s.fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	chapters := releasedChapters(feed.chapters, time.Now().UTC())
+	// Feeds are not paged: everything comes back on the first page.
+	if offset > 0 {
+		return &FeedPage{Limit: limit, Offset: offset, Total: len(chapters)}, nil
+	}
+	return &FeedPage{Data: chapters, Limit: len(chapters), Total: len(chapters)}, nil
+}
+
+// releasedChapters drops items dated in the future: some feeds list scheduled releases,
+// and letting them through would push the updater's watermark past real chapters. They
+// come back once their date has passed.
+func releasedChapters(chapters []Chapter, now time.Time) []Chapter {
+	out := make([]Chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		if ch.Attributes.CreatedAt.After(now.Add(maxFeedFutureSkew)) {
+			continue
+		}
+		out = append(out, ch)
+	}
+	return out
+}
+
+type parsedFeed struct {
+	title    string
+	chapters []Chapter
+}
+
+func (s *RSSSource) fetch(ctx context.Context, feedURL string) (*parsedFeed, error) {
+	s.mu.Lock()
+	cached := s.cache[feedURL]
+	s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	if !s.AllowPrivateHosts {
+		if err := checkPublicHost(ctx, net.DefaultResolver, req.URL.Hostname()); err != nil {
+			return nil, fmt.Errorf("error checking feed host: %w", err)
+		}
+	}
+	req.Header.Set("User-Agent", "ReleaseNoJutsu/1.0")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.feed, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBytes))
+	if err != nil {
+		return nil, fmt.Errorf("error reading feed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status code %d", resp.StatusCode)
+	}
+	feed, err := parseFeed(body, s.rulesFor(feedURL))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cachedFeed{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		feed:         feed,
+		firstSeen:    make(map[string]time.Time, len(feed.chapters)),
+	}
+	stampUndated(feed, entry.firstSeen, cached, time.Now().UTC())
+	s.mu.Lock()
+	s.cache[feedURL] = entry
+	s.mu.Unlock()
+	return feed, nil
+}
+
+// rulesFor returns the chapter rules that apply to feedURL, host-specific ones first.
+func (s *RSSSource) rulesFor(feedURL string) []*regexp.Regexp {
+	host := ""
+	if u, err := url.Parse(feedURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	var scoped, global []*regexp.Regexp
+	for _, r := range s.Rules {
+		switch {
+		case r.Host == "":
+			global = append(global, r.Pattern)
+		case host == r.Host || strings.HasSuffix(host, "."+r.Host):
+			scoped = append(scoped, r.Pattern)
+		}
+	}
+	return append(append(scoped, global...), defaultChapterPattern)
+}
+
+// stampUndated gives items without a date the time they first appeared in the feed, so new
+// ones still pass the updater's watermark. Items present on the first fetch stay undated:
+// they are imported by a full sync but never announced, which keeps a restart from
+// re-announcing a whole feed.
+func stampUndated(feed *parsedFeed, firstSeen map[string]time.Time, prev *cachedFeed, now time.Time) {
+	for i := range feed.chapters {
+		ch := &feed.chapters[i]
+		if !ch.Attributes.CreatedAt.IsZero() {
+			continue
+		}
+		var seen time.Time
+		if prev != nil {
+			if t, ok := prev.firstSeen[ch.ID]; ok {
+				seen = t
+			} else {
+				seen = now
+			}
+		}
+		firstSeen[ch.ID] = seen
+		ch.Attributes.PublishedAt = seen
+		ch.Attributes.ReadableAt = seen
+		ch.Attributes.CreatedAt = seen
+		ch.Attributes.UpdatedAt = seen
+	}
+	sortNewestFirst(feed.chapters)
+}
+
+// feedDoc covers RSS 2.0 (<rss><channel>), RSS 1.0 (<rdf:RDF>, items at the root) and Atom
+// (<feed>).
+type feedDoc struct {
+	XMLName xml.Name
+	Channel struct {
+		Title    string    `xml:"title"`
+		Language string    `xml:"language"`
+		Items    []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+
+	Title   string      `xml:"title"`
+	Lang    string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	DCDate  string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomEntry struct {
+	Title string `xml:"title"`
+	ID    string `xml:"id"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+func parseFeed(body []byte, patterns []*regexp.Regexp) (*parsedFeed, error) {
+	var doc feedDoc
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	// Feeds in the wild declare all sorts of encodings; read them as-is rather than fail.
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("not an RSS or Atom feed: %v", err)
+	}
+
+	feed := &parsedFeed{}
+	switch strings.ToLower(doc.XMLName.Local) {
+	case "rss", "rdf":
+		feed.title = strings.TrimSpace(doc.Channel.Title)
+		lang := feedLanguage(doc.Channel.Language)
+		items := append(doc.Channel.Items, doc.Items...)
+		for _, it := range items {
+			id := firstNonEmpty(it.GUID, it.Link, it.Title)
+			when := parseFeedTime(firstNonEmpty(it.PubDate, it.DCDate))
+			feed.chapters = append(feed.chapters, feedChapter(id, it.Title, lang, when, when, patterns))
+		}
+	case "feed":
+		feed.title = strings.TrimSpace(doc.Title)
+		lang := feedLanguage(doc.Lang)
+		for _, e := range doc.Entries {
+			link := ""
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			id := firstNonEmpty(e.ID, link, e.Title)
+			published := parseFeedTime(e.Published)
+			updated := parseFeedTime(e.Updated)
+			if published.IsZero() {
+				published = updated
+			}
+			feed.chapters = append(feed.chapters, feedChapter(id, e.Title, lang, published, updated, patterns))
+		}
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed: root element <%s>", doc.XMLName.Local)
+	}
+
+	sortNewestFirst(feed.chapters)
+	return feed, nil
+}
+
+func sortNewestFirst(chapters []Chapter) {
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Attributes.CreatedAt.After(chapters[j].Attributes.CreatedAt)
+	})
+}
+
+func feedChapter(id, title, lang string, published, updated time.Time, patterns []*regexp.Regexp) Chapter {
+	title = strings.TrimSpace(title)
+	if updated.Before(published) {
+		updated = published
+	}
+	return Chapter{
+		ID: strings.TrimSpace(id),
+		Attributes: mangadex.ChapterAttributes{
+			Chapter:     chapterNumber(title, patterns),
+			Title:       title,
+			Language:    lang,
+			PublishedAt: published,
+			ReadableAt:  published,
+			CreatedAt:   published,
+			UpdatedAt:   updated,
+		},
+	}
+}
+
+// chapterNumber returns the first capture group matched by the first pattern that matches.
+func chapterNumber(title string, patterns []*regexp.Regexp) string {
+	for _, re := range patterns {
+		m := re.FindStringSubmatch(title)
+		if m == nil {
+			continue
+		}
+		for _, g := range m[1:] {
+			if g = strings.TrimSpace(g); g != "" {
+				return g
+			}
+		}
+	}
+	return ""
+}
+
+// feedLanguage keeps the primary subtag ("en-us" → "en"). Feeds without a language are
+// treated as English, the updater's default.
+func feedLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return "en"
+	}
+	return lang
+}
+
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	time.RFC822Z,
+	time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
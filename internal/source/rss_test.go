@@ -0,0 +1,326 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves testdata feeds with an ETag and answers conditional requests with
+// 304, like a well-behaved feed host.
+type fixtureServer struct {
+	mu       sync.Mutex
+	file     string
+	requests int
+	notMod   int
+	srv      *httptest.Server
+}
+
+func newFixtureServer(t *testing.T, file string) *fixtureServer {
+	t.Helper()
+	f := &fixtureServer{file: file}
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests++
+		body, err := os.ReadFile(filepath.Join("testdata", f.file))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		etag := `"` + f.file + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			f.notMod++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fixtureServer) serve(file string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.file = file
+}
+
+func (f *fixtureServer) counts() (requests, notModified int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests, f.notMod
+}
+
+// localRSS is an RSS source allowed to fetch from the httptest servers on loopback.
+func localRSS(rules ...ChapterRule) *RSSSource {
+	s := NewRSS(rules...)
+	s.AllowPrivateHosts = true
+	return s
+}
+
+func firstPage(t *testing.T, s *RSSSource, feedURL string) *FeedPage {
+	t.Helper()
+	page, err := s.GetChapterFeedPage(context.Background(), feedURL, 100, 0)
+	if err != nil {
+		t.Fatalf("GetChapterFeedPage(): %v", err)
+	}
+	return page
+}
+
+func TestRSSSource_ParsesRSSFeed(t *testing.T) {
+	s := localRSS()
+	feedURL := newFixtureServer(t, "rss_scanlation.xml").srv.URL
+
+	meta, err := s.FetchMetadata(context.Background(), feedURL)
+	if err != nil {
+		t.Fatalf("FetchMetadata(): %v", err)
+	}
+	if meta.Title != "Some Series" {
+		t.Fatalf("title=%q, want Some Series", meta.Title)
+	}
+
+	// The 2099 preview is a scheduled release and stays hidden until its date.
+	page := firstPage(t, s, feedURL)
+	if len(page.Data) != 2 || page.Total != 2 {
+		t.Fatalf("page=%+v, want 2 released chapters", page)
+	}
+	// Newest first.
+	if got := page.Data[0].Attributes; got.Chapter != "12.5" || got.Language != "en" {
+		t.Fatalf("first chapter=%+v, want 12.5 in en", got)
+	}
+	if got := page.Data[1]; got.Attributes.Chapter != "11" || got.ID != "https://example.com/some-series/11" {
+		t.Fatalf("second chapter=%+v, want 11 keyed by guid", got)
+	}
+
+	next, err := s.GetChapterFeedPage(context.Background(), feedURL, 100, 2)
+	if err != nil {
+		t.Fatalf("GetChapterFeedPage(offset 2): %v", err)
+	}
+	if len(next.Data) != 0 {
+		t.Fatalf("offset page=%+v, want empty", next)
+	}
+}
+
+func TestRSSSource_ParsesAtomFeed(t *testing.T) {
+	s := localRSS()
+	page := firstPage(t, s, newFixtureServer(t, "atom_publisher.xml").srv.URL)
+	if len(page.Data) != 2 {
+		t.Fatalf("page=%+v, want 2 entries", page)
+	}
+	extra, ch3 := page.Data[0], page.Data[1]
+	if extra.Attributes.Chapter != "" {
+		t.Fatalf("extra chapter number=%q, want none", extra.Attributes.Chapter)
+	}
+	if extra.Attributes.UpdatedAt.Before(extra.Attributes.PublishedAt) {
+		t.Fatalf("extra updated=%s before published=%s", extra.Attributes.UpdatedAt, extra.Attributes.PublishedAt)
+	}
+	if ch3.Attributes.Chapter != "3" || ch3.Attributes.Language != "fr" || ch3.Attributes.CreatedAt.IsZero() {
+		t.Fatalf("chapter 3=%+v, want number 3 in fr dated from <updated>", ch3.Attributes)
+	}
+}
+
+func TestRSSSource_ChapterRules(t *testing.T) {
+	fixture := newFixtureServer(t, "rss_episodes.xml")
+
+	// Without rules "Episode 42 - Chapter 7 of the arc" reads as chapter 7.
+	page := firstPage(t, localRSS(), fixture.srv.URL)
+	if page.Data[0].Attributes.Chapter != "7" || page.Data[1].Attributes.Chapter != "" {
+		t.Fatalf("default numbers=%q,%q", page.Data[0].Attributes.Chapter, page.Data[1].Attributes.Chapter)
+	}
+
+	rules, err := ParseChapterRules([]string{`otherhost.com=(\d+)`, `127.0.0.1=Episode (\d+)`})
+	if err != nil {
+		t.Fatalf("ParseChapterRules(): %v", err)
+	}
+	if rules[0].Host != "otherhost.com" || rules[1].Host != "127.0.0.1" {
+		t.Fatalf("rules=%+v, want host-scoped rules", rules)
+	}
+	page = firstPage(t, localRSS(rules...), fixture.srv.URL)
+	if page.Data[0].Attributes.Chapter != "42" || page.Data[1].Attributes.Chapter != "41" {
+		t.Fatalf("rule numbers=%q,%q, want 42,41", page.Data[0].Attributes.Chapter, page.Data[1].Attributes.Chapter)
+	}
+
+	if _, err := ParseChapterRules([]string{"Episode \\d+"}); err == nil {
+		t.Fatal("expected error for a rule without a capture group")
+	}
+	if _, err := ParseChapterRules([]string{"Episode (\\d+"}); err == nil {
+		t.Fatal("expected error for an invalid regex")
+	}
+}
+
+func TestRSSSource_ConditionalGet(t *testing.T) {
+	s := localRSS()
+	fixture := newFixtureServer(t, "rss_scanlation.xml")
+
+	first := firstPage(t, s, fixture.srv.URL)
+	second := firstPage(t, s, fixture.srv.URL)
+	requests, notModified := fixture.counts()
+	if requests != 2 || notModified != 1 {
+		t.Fatalf("requests=%d notModified=%d, want the second poll to be a 304", requests, notModified)
+	}
+	// A 304 replays the cached feed so callers still see every chapter.
+	if len(second.Data) != len(first.Data) || second.Data[0].ID != first.Data[0].ID {
+		t.Fatalf("304 page=%+v, want the cached chapters", second)
+	}
+
+	// A fresh source (e.g. after a restart) has no validators and fetches in full.
+	firstPage(t, localRSS(), fixture.srv.URL)
+	if _, notModified := fixture.counts(); notModified != 1 {
+		t.Fatalf("notModified=%d, a new source should not send validators", notModified)
+	}
+}
+
+func TestRSSSource_StampsUndatedItemsWhenFirstSeen(t *testing.T) {
+	s := localRSS()
+	fixture := newFixtureServer(t, "rss_undated.xml")
+
+	page := firstPage(t, s, fixture.srv.URL)
+	for _, ch := range page.Data {
+		if !ch.Attributes.CreatedAt.IsZero() {
+			t.Fatalf("chapter %s dated %s on first fetch, want undated", ch.Attributes.Chapter, ch.Attributes.CreatedAt)
+		}
+	}
+
+	before := time.Now().UTC()
+	fixture.serve("rss_undated_next.xml")
+	page = firstPage(t, s, fixture.srv.URL)
+	if len(page.Data) != 3 || page.Data[0].Attributes.Chapter != "3" {
+		t.Fatalf("page=%+v, want the new chapter first", page)
+	}
+	if page.Data[0].Attributes.CreatedAt.Before(before) {
+		t.Fatalf("new chapter created_at=%s, want stamped with first-seen time", page.Data[0].Attributes.CreatedAt)
+	}
+	for _, ch := range page.Data[1:] {
+		if !ch.Attributes.CreatedAt.IsZero() {
+			t.Fatalf("chapter %s dated %s, items from the first fetch stay undated", ch.Attributes.Chapter, ch.Attributes.CreatedAt)
+		}
+	}
+}
+
+func TestRSSSource_RejectsNonFeeds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html><body>hi</body></html>"))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := localRSS()
+	if _, err := s.FetchMetadata(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected error for an HTML page")
+	}
+	if _, ok := s.ResolveURL("https://mangadex.org/title/abc"); ok {
+		t.Fatal("MangaDex URLs should be left to the MangaDex source")
+	}
+	if _, ok := s.ResolveURL("ftp://example.com/feed"); ok {
+		t.Fatal("only http(s) URLs are feeds")
+	}
+}
+
+func TestRSSSource_RefusesPrivateHosts(t *testing.T) {
+	fixture := newFixtureServer(t, "rss.xml")
+	s := NewRSS()
+
+	for _, input := range []string{
+		"http://localhost/feed.xml",
+		"http://127.0.0.1:8080/feed.xml",
+		"http://10.0.0.5/feed.xml",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/feed.xml",
+		"http://0.0.0.0/feed.xml",
+	} {
+		if _, ok := s.ResolveURL(input); ok {
+			t.Errorf("ResolveURL(%q) accepted a private host", input)
+		}
+	}
+	if _, ok := s.ResolveURL("https://example.com/feed.xml"); !ok {
+		t.Error("ResolveURL() rejected a public host")
+	}
+
+	if _, err := s.FetchMetadata(context.Background(), fixture.srv.URL); !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("FetchMetadata(loopback) err=%v, want ErrPrivateAddress", err)
+	}
+	if requests, _ := fixture.counts(); requests != 0 {
+		t.Fatalf("requests=%d, want the feed never fetched", requests)
+	}
+}
+
+func TestPublicOnlyControl(t *testing.T) {
+	for address, want := range map[string]bool{
+		"93.184.216.34:443":  true,
+		"[2606:4700::1]:443": true,
+		"127.0.0.1:80":       false,
+		"192.168.1.10:80":    false,
+		"172.16.0.1:80":      false,
+		"169.254.169.254:80": false,
+		"[fe80::1]:80":       false,
+		"[::]:80":            false,
+	} {
+		if got := publicOnlyControl("tcp", address, nil) == nil; got != want {
+			t.Errorf("publicOnlyControl(%q) allowed=%v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"100.63.255.255", true},
+		{"100.128.0.1", true},
+		{"2606:4700::1", true},
+		{"::ffff:93.184.216.34", true},
+		{"64:ff9b::5db8:d822", true}, // NAT64 of 93.184.216.34
+
+		{"0.1.2.3", false},
+		{"10.1.2.3", false},
+		{"100.64.0.1", false}, // carrier-grade NAT
+		{"100.127.255.254", false},
+		{"127.0.0.1", false},
+		{"169.254.169.254", false},
+		{"172.31.0.1", false},
+		{"192.0.0.8", false},
+		{"192.0.2.1", false},
+		{"192.168.1.1", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"203.0.113.7", false},
+		{"224.0.0.1", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+
+		{"::", false},
+		{"::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:100.64.0.1", false},
+		{"64:ff9b::7f00:1", false},    // NAT64 of 127.0.0.1
+		{"64:ff9b::a9fe:a9fe", false}, // NAT64 of 169.254.169.254
+		{"64:ff9b:1::1", false},
+		{"2001:db8::1", false},
+		{"2002:7f00:1::1", false},
+		{"fc00::1", false},
+		{"fd12:3456::1", false},
+		{"fe80::1", false},
+		{"ff02::1", false},
+	} {
+		ip := net.ParseIP(tc.ip)
+		if ip == nil {
+			t.Fatalf("ParseIP(%q) failed", tc.ip)
+		}
+		if got := isPublicIP(ip); got != tc.want {
+			t.Errorf("isPublicIP(%s)=%v, want %v", tc.ip, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package source abstracts where a title's chapters come from (MangaDex, RSS/Atom feeds, ...).
+package source
+
+import (
+	"context"
+
+	"releasenojutsu/internal/mangadex"
+)
+
+// Source names stored in manga.source.
+const (
+	MangaDex = "mangadex"
+	RSS      = "rss"
+)
+
+// FeedPage and Chapter are the common chapter format every source maps to. It is MangaDex's
+// shape because the updater's watermark and timestamp handling were built around it.
+type (
+	FeedPage = mangadex.ChapterFeedResponse
+	Chapter  = mangadex.Chapter
+)
+
+// Metadata describes a title on a source.
+type Metadata struct {
+	Title string
+}
+
+// Source is somewhere chapters can be tracked from.
+type Source interface {
+	// Name is the value stored in manga.source.
+	Name() string
+	// ResolveURL turns user input (a URL or a raw ID) into this source's ID for the title.
+	ResolveURL(input string) (id string, ok bool)
+	// FetchMetadata looks the title up on the source.
+	FetchMetadata(ctx context.Context, id string) (Metadata, error)
+	// GetChapterFeedPage returns chapters newest first. Sources without paging return
+	// everything at offset 0 and an empty page after that.
+	GetChapterFeedPage(ctx context.Context, id string, limit, offset int) (*FeedPage, error)
+}
+
+// Registry holds the configured sources in resolution order.
+type Registry struct {
+	sources []Source
+}
+
+// NewRegistry returns a registry that tries sources in the given order.
+func NewRegistry(sources ...Source) *Registry {
+	return &Registry{sources: sources}
+}
+
+// Get returns the source with the given name. An empty name means MangaDex, which is what
+// titles added before sources existed use.
+func (r *Registry) Get(name string) (Source, bool) {
+	if name == "" {
+		name = MangaDex
+	}
+	if r == nil {
+		return nil, false
+	}
+	for _, s := range r.sources {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Resolve returns the first source that recognises input, along with the title's ID there.
+func (r *Registry) Resolve(input string) (Source, string, bool) {
+	if r == nil {
+		return nil, "", false
+	}
+	for _, s := range r.sources {
+		if id, ok := s.ResolveURL(input); ok {
+			return s, id, true
+		}
+	}
+	return nil, "", false
+}
@@ -0,0 +1,50 @@
+package source
+
+import (
+	"testing"
+
+	"releasenojutsu/internal/mangadex"
+)
+
+func TestRegistry_ResolveAndGet(t *testing.T) {
+	md := NewMangaDex(mangadex.NewClient())
+	rss := NewRSS()
+	r := NewRegistry(md, rss)
+
+	const mdID = "a1c7c817-4e59-43b7-9365-09675a149a6f"
+	tests := []struct {
+		input  string
+		name   string
+		id     string
+		wantOK bool
+	}{
+		{input: "https://mangadex.org/title/" + mdID + "/one-piece", name: MangaDex, id: mdID, wantOK: true},
+		{input: mdID, name: MangaDex, id: mdID, wantOK: true},
+		{input: "https://example.com/series/feed.xml", name: RSS, id: "https://example.com/series/feed.xml", wantOK: true},
+		{input: "https://mangadex.org/not-a-title", wantOK: false},
+		{input: "one piece", wantOK: false},
+	}
+	for _, tt := range tests {
+		src, id, ok := r.Resolve(tt.input)
+		if ok != tt.wantOK {
+			t.Fatalf("Resolve(%q) ok=%v, want %v", tt.input, ok, tt.wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if src.Name() != tt.name || id != tt.id {
+			t.Fatalf("Resolve(%q)=(%s, %q), want (%s, %q)", tt.input, src.Name(), id, tt.name, tt.id)
+		}
+	}
+
+	if s, ok := r.Get(""); !ok || s.Name() != MangaDex {
+		t.Fatalf("Get(\"\") should default to MangaDex, got %v %v", s, ok)
+	}
+	if _, ok := r.Get("nope"); ok {
+		t.Fatal("Get(nope) should fail")
+	}
+	var nilRegistry *Registry
+	if _, _, ok := nilRegistry.Resolve(mdID); ok {
+		t.Fatal("nil registry should not resolve")
+	}
+}
@@ -6,21 +6,81 @@ import (
 	"strings"
 
 	"releasenojutsu/internal/appcopy"
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/mangadex"
 )
 
-func FormatNewChaptersMessageHTML(mangaTitle string, newChapters []mangadex.ChapterInfo, unreadCount int, warnOnThreePlus bool) string {
+// StoredChapterInfos turns stored chapters back into the form the alerts list them in.
+func StoredChapterInfos(items []db.ChapterListItem) []mangadex.ChapterInfo {
+	infos := make([]mangadex.ChapterInfo, 0, len(items))
+	for _, it := range items {
+		number := it.Number
+		if strings.HasPrefix(number, "extra:") {
+			number = appcopy.Copy.Labels.ExtraChapterNumber
+		}
+		infos = append(infos, mangadex.ChapterInfo{Number: number, Title: it.Title, ExternalURL: it.ExternalURL})
+	}
+	return infos
+}
+
+// FormatNewChaptersMessageHTML builds the new-chapters notification. warnAt is the title's
+// unread warning threshold (0 disables it); window is set for MANGA Plus titles and adds
+// the free-window warnings.
+func FormatNewChaptersMessageHTML(mangaTitle string, newChapters []mangadex.ChapterInfo, unreadCount int, warnAt int, window *db.MangaPlusWindow) string {
 	var b strings.Builder
+	writeNewChapters(&b, mangaTitle, newChapters)
+	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertUnread, unreadCount))
+	if warnAt > 0 && unreadCount >= warnAt {
+		b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertWarning, unreadCount))
+	}
+	if window != nil {
+		if window.Expiring != "" {
+			b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertExpiring, html.EscapeString(window.Expiring)))
+		}
+		if window.Paywalled > 0 {
+			b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertPaywalled, window.Paywalled))
+		}
+	}
+	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertFooter, appcopy.Copy.Commands.Start))
+	return b.String()
+}
+
+// FormatGroupChaptersMessageHTML builds the new-chapters notification for a group's
+// shared library. Groups have no reading progress, so it only lists the chapters.
+func FormatGroupChaptersMessageHTML(mangaTitle string, newChapters []mangadex.ChapterInfo) string {
+	var b strings.Builder
+	writeNewChapters(&b, mangaTitle, newChapters)
+	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertGroupFooter, appcopy.Copy.Commands.List))
+	return b.String()
+}
+
+func writeNewChapters(b *strings.Builder, mangaTitle string, newChapters []mangadex.ChapterInfo) {
 	b.WriteString(appcopy.Copy.Info.NewChapterAlertTitle)
 	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertHeader, html.EscapeString(mangaTitle)))
 	for _, chapter := range newChapters {
 		// Keep chapter number unescaped for readability, but escape anyway to be safe.
 		label := fmt.Sprintf(appcopy.Copy.Labels.ChapterPrefix, html.EscapeString(chapter.Number))
-		b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertItem, label, html.EscapeString(chapter.Title)))
+		link := ""
+		if chapter.ExternalURL != "" {
+			format := appcopy.Copy.Info.NewChapterAlertExternal
+			if mangadex.IsMangaPlusURL(chapter.ExternalURL) {
+				format = appcopy.Copy.Info.NewChapterAlertMangaPlus
+			}
+			link = fmt.Sprintf(format, html.EscapeString(chapter.ExternalURL))
+		}
+		b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertItem, label, html.EscapeString(chapter.Title), link))
 	}
-	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertUnread, unreadCount))
-	if warnOnThreePlus && unreadCount >= 3 {
-		b.WriteString(appcopy.Copy.Info.NewChapterAlertWarning)
+}
+
+// FormatBacklogReportHTML lists the titles at or above their unread warning threshold.
+func FormatBacklogReportHTML(entries []db.BacklogEntry) string {
+	if len(entries) == 0 {
+		return appcopy.Copy.Info.BacklogReportEmpty
+	}
+	var b strings.Builder
+	b.WriteString(appcopy.Copy.Info.BacklogReportTitle)
+	for _, e := range entries {
+		b.WriteString(fmt.Sprintf(appcopy.Copy.Info.BacklogReportItem, html.EscapeString(e.Title), e.Unread, e.Threshold))
 	}
 	b.WriteString(fmt.Sprintf(appcopy.Copy.Info.NewChapterAlertFooter, appcopy.Copy.Commands.Start))
 	return b.String()
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/mangadex"
 )
 
@@ -14,7 +15,8 @@ func TestFormatNewChaptersMessageHTML_EscapesDynamicContent(t *testing.T) {
 			{Number: `1`, Title: `Title with <script>alert(1)</script> & stuff`},
 		},
 		1,
-		false,
+		0,
+		nil,
 	)
 
 	if strings.Contains(msg, "<script>") {
@@ -51,3 +53,55 @@ func TestFormatNewChaptersMessage_PlainTextIncludesWarningAndFooter(t *testing.T
 		t.Fatalf("missing footer line: %q", msg)
 	}
 }
+
+func TestFormatNewChaptersMessageHTML_MangaPlusLinkAndWindow(t *testing.T) {
+	msg := FormatNewChaptersMessageHTML(
+		"One Piece",
+		[]mangadex.ChapterInfo{
+			{Number: "1101", Title: "Bonney", ExternalURL: "https://mangaplus.shueisha.co.jp/viewer/1000001"},
+			{Number: "1100", Title: "Elsewhere", ExternalURL: "https://example.com/read?c=1&d=2"},
+		},
+		5,
+		0,
+		&db.MangaPlusWindow{Free: []string{"1101", "1100", "1099"}, Expiring: "1099", Paywalled: 2},
+	)
+
+	if !strings.Contains(msg, `<a href="https://mangaplus.shueisha.co.jp/viewer/1000001">available on MANGA Plus</a>`) {
+		t.Fatalf("missing MANGA Plus link: %q", msg)
+	}
+	if !strings.Contains(msg, `<a href="https://example.com/read?c=1&amp;d=2">read it here</a>`) {
+		t.Fatalf("missing escaped external link: %q", msg)
+	}
+	if !strings.Contains(msg, "Chapter <b>1099</b> is the oldest free chapter") || !strings.Contains(msg, "2 unread chapter(s) already left") {
+		t.Fatalf("missing free window warnings: %q", msg)
+	}
+
+	plain := FormatNewChaptersMessageHTML("One Piece", []mangadex.ChapterInfo{{Number: "1101"}}, 5, 0, nil)
+	if strings.Contains(plain, "MANGA Plus") {
+		t.Fatalf("non-MANGA Plus titles should not mention the free window: %q", plain)
+	}
+}
+
+func TestFormatNewChaptersMessageHTML_UnreadWarningThreshold(t *testing.T) {
+	chapters := []mangadex.ChapterInfo{{Number: "12"}}
+	if msg := FormatNewChaptersMessageHTML("Binge", chapters, 9, 10, nil); strings.Contains(msg, "piling up") {
+		t.Fatalf("below threshold should not warn: %q", msg)
+	}
+	if msg := FormatNewChaptersMessageHTML("Binge", chapters, 10, 10, nil); !strings.Contains(msg, "You have 10 unread chapters piling up") {
+		t.Fatalf("at threshold should warn: %q", msg)
+	}
+	if msg := FormatNewChaptersMessageHTML("Binge", chapters, 50, 0, nil); strings.Contains(msg, "piling up") {
+		t.Fatalf("threshold 0 disables the warning: %q", msg)
+	}
+}
+
+func TestFormatGroupChaptersMessageHTML_LeavesOutProgress(t *testing.T) {
+	msg := FormatGroupChaptersMessageHTML("Dragon Ball", []mangadex.ChapterInfo{{Number: "104", Title: "Saiyaman X"}})
+
+	if !strings.Contains(msg, "Ch. 104") || !strings.Contains(msg, "shared list") {
+		t.Fatalf("message=%q, want the chapter and the group footer", msg)
+	}
+	if strings.Contains(msg, "unread") {
+		t.Fatalf("group message mentions unread chapters: %q", msg)
+	}
+}
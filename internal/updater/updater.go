@@ -2,46 +2,74 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strings"
 	"time"
 
 	"releasenojutsu/internal/appcopy"
 	"releasenojutsu/internal/db"
+	"releasenojutsu/internal/logger"
 	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/metrics"
+	"releasenojutsu/internal/source"
 )
 
 type Store interface {
 	ListManga() ([]db.Manga, error)
-	GetManga(mangaID int) (mangaDexID string, title string, lastChecked time.Time, lastSeenAt time.Time, err error)
+	GetManga(mangaID int) (sourceID string, title string, lastChecked time.Time, lastSeenAt time.Time, err error)
+	GetMangaSource(mangaID int) (string, error)
 
 	AddChapter(mangaID int64, chapterNumber, title string, publishedAt, readableAt, createdAt, updatedAt time.Time) error
 	UpdateMangaLastChecked(mangaID int) error
 	UpdateMangaLastSeenAt(mangaID int, seenAt time.Time) error
 	CountUnreadChapters(mangaID int) (int, error)
 	RecalculateUnreadCount(mangaID int) error
+	SetChapterExternalURL(mangaID int64, chapterNumber, externalURL string) error
+	MarkMangaPlusDetected(mangaID int) error
+
+	RecordMangaFailure(mangaID int, errText string, failedAt time.Time) (int, error)
+	SetMangaNextCheckAt(mangaID int, at time.Time) error
+	ClearMangaFailures(mangaID int) error
 }
 
-type MangaDex interface {
+// ChapterFeed fetches pages of a title's chapters, newest first. Every source.Source is one.
+type ChapterFeed interface {
 	GetChapterFeedPage(ctx context.Context, mangaID string, limit, offset int) (*mangadex.ChapterFeedResponse, error)
 }
 
+// MergeResolver is implemented by MangaDex clients that can find the entry a stale ID was
+// merged into (see mangadex.Client.FindMergeTarget).
+type MergeResolver interface {
+	FindMergeTarget(ctx context.Context, mangaID, title string) (string, error)
+}
+
 type Updater struct {
 	store        Store
-	mangadex     MangaDex
-	syncMangaDex MangaDex
+	mangadex     ChapterFeed
+	syncMangaDex ChapterFeed
+	// sources holds the non-MangaDex sources by name.
+	sources map[string]ChapterFeed
 }
 
 type Result struct {
-	MangaID     int
-	UserID      int64
-	MangaDexID  string
+	MangaID int
+	UserID  int64
+	// SourceID is the title's ID on its source (see db.Manga.TrackedID).
+	SourceID    string
 	Title       string
 	NewChapters []mangadex.ChapterInfo
 	UnreadCount int
 	LastSeenAt  time.Time
+	Duration    time.Duration
 	Err         error
+	// ConsecutiveFailures is the title's failure streak including this check (0 on success).
+	ConsecutiveFailures int
+	// MergedInto is set when the MangaDex entry looks merged into another one: the old ID
+	// 404s, or its feed came back empty while it points at a replacement.
+	MergedInto string
 }
 
 type normalizedChapterTimes struct {
@@ -54,37 +82,100 @@ type normalizedChapterTimes struct {
 
 const maxFutureTimestampSkew = 24 * time.Hour
 
-func New(store Store, md MangaDex, syncMD MangaDex) *Updater {
-	return &Updater{
+// A title that keeps failing is checked less often: the wait doubles from
+// FailureBackoffBase with every failure, up to FailureBackoffMax.
+const (
+	FailureBackoffBase = 6 * time.Hour
+	FailureBackoffMax  = 7 * 24 * time.Hour
+	// backoffSlack lets a title whose wait ends shortly after a run starts join that run.
+	backoffSlack = 30 * time.Minute
+)
+
+// FailureBackoff returns how long to wait before checking a title again after its
+// failures-th consecutive failure.
+func FailureBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	d := FailureBackoffBase
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if d >= FailureBackoffMax {
+			return FailureBackoffMax
+		}
+	}
+	return d
+}
+
+// New creates an updater for MangaDex titles (md for incremental checks, syncMD for full
+// syncs) plus any other sources titles can be tracked on.
+func New(store Store, md ChapterFeed, syncMD ChapterFeed, sources ...source.Source) *Updater {
+	u := &Updater{
 		store:        store,
 		mangadex:     md,
 		syncMangaDex: syncMD,
+		sources:      make(map[string]ChapterFeed, len(sources)),
 	}
+	for _, src := range sources {
+		if src.Name() != source.MangaDex {
+			u.sources[src.Name()] = src
+		}
+	}
+	return u
+}
+
+// feedFor returns the feed for a title tracked on sourceName; sync selects the
+// all-languages MangaDex client used for full syncs.
+func (u *Updater) feedFor(sourceName string, sync bool) (ChapterFeed, error) {
+	if sourceName == "" || sourceName == source.MangaDex {
+		if sync {
+			return u.syncMangaDex, nil
+		}
+		return u.mangadex, nil
+	}
+	feed, ok := u.sources[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", sourceName)
+	}
+	return feed, nil
 }
 
 func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeenAt time.Time, err error) {
-	mangaDexID, _, _, currentLastSeenAt, err := u.store.GetManga(mangaID)
+	sourceID, _, _, currentLastSeenAt, err := u.store.GetManga(mangaID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	sourceName, err := u.store.GetMangaSource(mangaID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	feed, err := u.feedFor(sourceName, true)
 	if err != nil {
 		return 0, time.Time{}, err
 	}
 
+	log := logger.FromContext(ctx).With(logger.KeyMangaID, mangaID, logger.KeyMangaDexID, sourceID)
+	log.Debug("Starting full chapter sync")
+
 	const pageLimit = 500
 	now := time.Now().UTC()
 	offset := 0
 	maxSeenAt = currentLastSeenAt
 	// Keep only one entry per chapter key to avoid duplicates across languages/groups.
 	seen := make(map[string]mangadex.Chapter, 512)
+	// Official links are kept whichever language version carried them.
+	links := make(map[string]string)
 
 	for {
-		feed, err := u.syncMangaDex.GetChapterFeedPage(ctx, mangaDexID, pageLimit, offset)
+		page, err := feed.GetChapterFeedPage(ctx, sourceID, pageLimit, offset)
 		if err != nil {
 			return synced, maxSeenAt, err
 		}
-		if len(feed.Data) == 0 {
+		if len(page.Data) == 0 {
 			break
 		}
 
-		for _, chapter := range feed.Data {
+		for _, chapter := range page.Data {
 			times := normalizeChapterTimes(chapter.Attributes, now)
 			seenAt := times.SeenAt
 			if !seenAt.IsZero() && maxSeenAt.Before(seenAt) {
@@ -92,6 +183,9 @@ func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeen
 			}
 
 			key := chapterKey(chapter)
+			if link := strings.TrimSpace(chapter.Attributes.ExternalURL); link != "" && !mangadex.IsMangaPlusURL(links[key]) {
+				links[key] = link
+			}
 			if cur, ok := seen[key]; ok {
 				// Prefer French titles, then English, then anything else.
 				if chapterLanguageScore(chapter) < chapterLanguageScore(cur) {
@@ -108,13 +202,13 @@ func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeen
 		}
 
 		// Stop if we reached the end of the feed.
-		if feed.Total > 0 && offset+len(feed.Data) >= feed.Total {
+		if page.Total > 0 && offset+len(page.Data) >= page.Total {
 			break
 		}
-		if len(feed.Data) < pageLimit {
+		if len(page.Data) < pageLimit {
 			break
 		}
-		offset += len(feed.Data)
+		offset += len(page.Data)
 	}
 
 	keys := make([]string, 0, len(seen))
@@ -123,6 +217,7 @@ func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeen
 	}
 	sort.Strings(keys)
 
+	mangaPlus := false
 	for _, key := range keys {
 		chapter := seen[key]
 		times := normalizeChapterTimes(chapter.Attributes, now)
@@ -134,8 +229,14 @@ func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeen
 		if err := u.store.AddChapter(int64(mangaID), key, title, times.PublishedAt, times.ReadableAt, times.CreatedAt, times.UpdatedAt); err != nil {
 			return synced, maxSeenAt, err
 		}
+		if u.storeExternalURL(log, mangaID, key, links[key]) {
+			mangaPlus = true
+		}
 		synced++
 	}
+	if mangaPlus {
+		u.markMangaPlus(log, mangaID)
+	}
 
 	_ = u.store.UpdateMangaLastChecked(mangaID)
 	if currentLastSeenAt.IsZero() || maxSeenAt.After(currentLastSeenAt) {
@@ -143,45 +244,143 @@ func (u *Updater) SyncAll(ctx context.Context, mangaID int) (synced int, maxSeen
 	}
 	_ = u.store.RecalculateUnreadCount(mangaID)
 
+	log.Info("Full chapter sync completed", "synced", synced)
 	return synced, maxSeenAt, nil
 }
 
+// UpdateOptions narrows an UpdateAll run and reports its progress.
+type UpdateOptions struct {
+	// UserID limits the run to one user's titles (0 checks everyone).
+	UserID int64
+	// Progress, when set, is called with done=0 before the first title and after each title.
+	Progress func(done, total int)
+}
+
 func (u *Updater) UpdateAll(ctx context.Context) ([]Result, error) {
+	return u.UpdateAllWithOptions(ctx, UpdateOptions{})
+}
+
+func (u *Updater) UpdateAllWithOptions(ctx context.Context, opts UpdateOptions) ([]Result, error) {
 	manga, err := u.store.ListManga()
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	due := manga[:0]
+	for _, m := range manga {
+		if opts.UserID != 0 && m.UserID != opts.UserID {
+			continue
+		}
+		if !m.NextCheckAt.IsZero() && m.NextCheckAt.After(now.Add(backoffSlack)) {
+			logger.FromContext(ctx).Debug("Skipping title in failure backoff", logger.KeyMangaID, m.ID, "failures", m.ConsecutiveFailures, "next_check_at", m.NextCheckAt)
+			continue
+		}
+		due = append(due, m)
+	}
+	manga = due
+	if opts.Progress != nil {
+		opts.Progress(0, len(manga))
+	}
 
 	results := make([]Result, 0, len(manga))
 	for _, m := range manga {
-		res, err := u.updateManga(ctx, m.ID, m.MangaDexID, m.Title, m.LastSeenAt)
+		if ctx.Err() != nil {
+			break
+		}
+		start := time.Now()
+		titleCtx := logger.WithContext(ctx, logger.KeyUserID, m.UserID)
+		res, err := u.updateManga(titleCtx, m.ID, m.Source, m.TrackedID(), m.Title, m.LastSeenAt)
+		if err != nil && ctx.Err() != nil {
+			// The run was cancelled or timed out; that says nothing about the title.
+			break
+		}
 		if err != nil {
+			metrics.UpdaterTitlesChecked.Inc("error")
+			// The run is still alive, so a timeout here belongs to this title's request.
+			failures := u.recordFailure(titleCtx, m.ID, err)
 			res = Result{
-				MangaID:    m.ID,
-				UserID:     m.UserID,
-				MangaDexID: m.MangaDexID,
-				Title:      m.Title,
-				LastSeenAt: m.LastSeenAt,
-				Err:        err,
+				MangaID:             m.ID,
+				UserID:              m.UserID,
+				SourceID:            m.TrackedID(),
+				Title:               m.Title,
+				LastSeenAt:          m.LastSeenAt,
+				Err:                 err,
+				ConsecutiveFailures: failures,
+				MergedInto:          res.MergedInto,
 			}
 		} else {
+			metrics.UpdaterTitlesChecked.Inc("ok")
+			metrics.UpdaterNewChapters.Add(float64(len(res.NewChapters)))
 			res.UserID = m.UserID
+			if m.ConsecutiveFailures > 0 || !m.NextCheckAt.IsZero() {
+				u.clearFailures(titleCtx, m.ID)
+			}
 		}
+		res.Duration = time.Since(start)
 		results = append(results, res)
+		if opts.Progress != nil {
+			opts.Progress(len(results), len(manga))
+		}
+	}
+	if len(results) < len(manga) {
+		logger.FromContext(ctx).Warn("Update run interrupted", "checked", len(results), "total", len(manga), "error", ctx.Err())
 	}
 
 	return results, nil
 }
 
+// UpdateOne checks a single title right away, ignoring any failure backoff.
 func (u *Updater) UpdateOne(ctx context.Context, mangaID int) (Result, error) {
-	mangaDexID, title, _, lastSeenAt, err := u.store.GetManga(mangaID)
+	sourceID, title, _, lastSeenAt, err := u.store.GetManga(mangaID)
+	if err != nil {
+		return Result{}, err
+	}
+	sourceName, err := u.store.GetMangaSource(mangaID)
 	if err != nil {
 		return Result{}, err
 	}
-	return u.updateManga(ctx, mangaID, mangaDexID, title, lastSeenAt)
+	res, err := u.updateManga(ctx, mangaID, sourceName, sourceID, title, lastSeenAt)
+	if err != nil {
+		// A cancelled run says nothing about the title; a timed out request does.
+		if ctx.Err() == nil {
+			u.recordFailure(ctx, mangaID, err)
+		}
+		return res, err
+	}
+	u.clearFailures(ctx, mangaID)
+	return res, nil
 }
 
-func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, title string, lastSeenAt time.Time) (Result, error) {
+// recordFailure stores a failed check and schedules the next one; it returns the new streak.
+func (u *Updater) recordFailure(ctx context.Context, mangaID int, checkErr error) int {
+	log := logger.FromContext(ctx).With(logger.KeyMangaID, mangaID)
+	now := time.Now()
+	failures, err := u.store.RecordMangaFailure(mangaID, checkErr.Error(), now)
+	if err != nil {
+		log.Warn("Failed recording title failure", "error", err)
+		return 0
+	}
+	if err := u.store.SetMangaNextCheckAt(mangaID, now.Add(FailureBackoff(failures))); err != nil {
+		log.Warn("Failed scheduling title backoff", "error", err)
+	}
+	return failures
+}
+
+func (u *Updater) clearFailures(ctx context.Context, mangaID int) {
+	if err := u.store.ClearMangaFailures(mangaID); err != nil {
+		logger.FromContext(ctx).Warn("Failed clearing title failures", logger.KeyMangaID, mangaID, "error", err)
+	}
+}
+
+func (u *Updater) updateManga(ctx context.Context, mangaID int, sourceName, sourceID, title string, lastSeenAt time.Time) (Result, error) {
+	log := logger.FromContext(ctx).With(logger.KeyMangaID, mangaID, logger.KeyMangaDexID, sourceID)
+	log.Debug("Checking for new chapters", "source", sourceName, "last_seen_at", lastSeenAt)
+
+	feed, err := u.feedFor(sourceName, false)
+	if err != nil {
+		return Result{}, err
+	}
+
 	const pageLimit = 100
 	now := time.Now().UTC()
 
@@ -192,21 +391,31 @@ func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, titl
 
 	var newChaptersWithTimes []chapterWithSeenAt
 	maxSeenAt := lastSeenAt
+	mergedInto := ""
+	mangaPlus := false
 
 	offset := 0
 	for {
-		feed, err := u.mangadex.GetChapterFeedPage(ctx, mangaDexID, pageLimit, offset)
+		page, err := feed.GetChapterFeedPage(ctx, sourceID, pageLimit, offset)
 		if err != nil {
+			if offset == 0 && errors.Is(err, mangadex.ErrNotFound) {
+				return Result{MergedInto: findMergeTarget(ctx, feed, sourceID, title)}, err
+			}
 			return Result{}, err
 		}
 
-		if len(feed.Data) == 0 {
+		if len(page.Data) == 0 {
+			// A title we have seen chapters for should not go quiet entirely; this is how a
+			// merged entry usually looks before MangaDex deletes it.
+			if offset == 0 && !lastSeenAt.IsZero() {
+				mergedInto = findMergeTarget(ctx, feed, sourceID, title)
+			}
 			break
 		}
 
 		pageHasAnyNew := false
 		pageHasUnknownSeenAt := false
-		for _, chapter := range feed.Data {
+		for _, chapter := range page.Data {
 			times := normalizeChapterTimes(chapter.Attributes, now)
 			seenAt := times.SeenAt
 			if !seenAt.IsZero() && maxSeenAt.Before(seenAt) {
@@ -228,11 +437,16 @@ func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, titl
 			if err := u.store.AddChapter(int64(mangaID), key, chapter.Attributes.Title, times.PublishedAt, times.ReadableAt, times.CreatedAt, times.UpdatedAt); err != nil {
 				return Result{}, err
 			}
+			link := strings.TrimSpace(chapter.Attributes.ExternalURL)
+			if u.storeExternalURL(log, mangaID, key, link) {
+				mangaPlus = true
+			}
 
 			newChaptersWithTimes = append(newChaptersWithTimes, chapterWithSeenAt{
 				info: mangadex.ChapterInfo{
-					Number: displayChapterNumber(chapter),
-					Title:  chapter.Attributes.Title,
+					Number:      displayChapterNumber(chapter),
+					Title:       chapter.Attributes.Title,
+					ExternalURL: link,
 				},
 				seenAt: seenAt,
 			})
@@ -244,14 +458,14 @@ func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, titl
 		}
 
 		// Stop if we reached the end of the feed.
-		if feed.Total > 0 && offset+len(feed.Data) >= feed.Total {
+		if page.Total > 0 && offset+len(page.Data) >= page.Total {
 			break
 		}
-		if len(feed.Data) < pageLimit {
+		if len(page.Data) < pageLimit {
 			break
 		}
 
-		offset += len(feed.Data)
+		offset += len(page.Data)
 	}
 
 	sort.Slice(newChaptersWithTimes, func(i, j int) bool {
@@ -262,6 +476,9 @@ func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, titl
 		newChapters = append(newChapters, c.info)
 	}
 
+	if mangaPlus {
+		u.markMangaPlus(log, mangaID)
+	}
 	_ = u.store.UpdateMangaLastChecked(mangaID)
 	if maxSeenAt.After(lastSeenAt) {
 		_ = u.store.UpdateMangaLastSeenAt(mangaID, maxSeenAt)
@@ -272,17 +489,59 @@ func (u *Updater) updateManga(ctx context.Context, mangaID int, mangaDexID, titl
 	if err != nil {
 		unreadCount = len(newChapters)
 	}
+	if len(newChapters) > 0 {
+		log.Info("Found new chapters", "new", len(newChapters), "unread", unreadCount)
+	} else {
+		log.Debug("No new chapters")
+	}
 
 	return Result{
 		MangaID:     mangaID,
-		MangaDexID:  mangaDexID,
+		SourceID:    sourceID,
 		Title:       title,
 		NewChapters: newChapters,
 		UnreadCount: unreadCount,
 		LastSeenAt:  maxSeenAt,
+		MergedInto:  mergedInto,
 	}, nil
 }
 
+// storeExternalURL saves a chapter's official link and reports whether it is a MANGA Plus one.
+func (u *Updater) storeExternalURL(log *slog.Logger, mangaID int, key, link string) bool {
+	if link == "" {
+		return false
+	}
+	if err := u.store.SetChapterExternalURL(int64(mangaID), key, link); err != nil {
+		log.Warn("Failed storing chapter link", "chapter", key, "error", err)
+	}
+	return mangadex.IsMangaPlusURL(link)
+}
+
+// markMangaPlus flags a title once its chapters turn out to be published on MANGA Plus, unless
+// the user already picked a value for it.
+func (u *Updater) markMangaPlus(log *slog.Logger, mangaID int) {
+	if err := u.store.MarkMangaPlusDetected(mangaID); err != nil {
+		log.Warn("Failed flagging title as MANGA Plus", "error", err)
+	}
+}
+
+func findMergeTarget(ctx context.Context, feed ChapterFeed, mangaDexID, title string) string {
+	resolver, ok := feed.(MergeResolver)
+	if !ok {
+		return ""
+	}
+	log := logger.FromContext(ctx).With(logger.KeyMangaDexID, mangaDexID)
+	target, err := resolver.FindMergeTarget(ctx, mangaDexID, title)
+	if err != nil {
+		log.Warn("Failed looking for merged MangaDex entry", "error", err)
+		return ""
+	}
+	if target != "" {
+		log.Info("MangaDex entry looks merged", "merged_into", target)
+	}
+	return target
+}
+
 func FormatNewChaptersMessage(mangaTitle string, newChapters []mangadex.ChapterInfo, unreadCount int, warnOnThreePlus bool) string {
 	var messageBuilder strings.Builder
 	messageBuilder.WriteString(appcopy.Copy.Info.NewChapterAlertTitlePlain)
@@ -3,22 +3,33 @@ package updater
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
 	"time"
 
 	"releasenojutsu/internal/db"
 	"releasenojutsu/internal/mangadex"
+	"releasenojutsu/internal/source"
 )
 
 type fakeStore struct {
 	mangaDexID string
+	source     string
 	title      string
 	lastSeenAt time.Time
 
 	list    []db.Manga
 	listErr error
 	added   []mangadex.ChapterAttributes
+
+	failures    map[int]int
+	nextCheckAt map[int]time.Time
+
+	links     map[string]string
+	mangaPlus bool
 }
 
 func (s *fakeStore) ListManga() ([]db.Manga, error) {
@@ -32,6 +43,8 @@ func (s *fakeStore) GetManga(mangaID int) (string, string, time.Time, time.Time,
 	return s.mangaDexID, s.title, time.Time{}, s.lastSeenAt, nil
 }
 
+func (s *fakeStore) GetMangaSource(mangaID int) (string, error) { return s.source, nil }
+
 func (s *fakeStore) AddChapter(mangaID int64, chapterNumber, title string, publishedAt, readableAt, createdAt, updatedAt time.Time) error {
 	s.added = append(s.added, mangadex.ChapterAttributes{
 		Chapter:     chapterNumber,
@@ -55,13 +68,66 @@ func (s *fakeStore) CountUnreadChapters(mangaID int) (int, error) { return len(s
 
 func (s *fakeStore) RecalculateUnreadCount(mangaID int) error { return nil }
 
+func (s *fakeStore) SetChapterExternalURL(mangaID int64, chapterNumber, externalURL string) error {
+	if s.links == nil {
+		s.links = make(map[string]string)
+	}
+	s.links[chapterNumber] = externalURL
+	return nil
+}
+
+func (s *fakeStore) MarkMangaPlusDetected(mangaID int) error {
+	s.mangaPlus = true
+	return nil
+}
+
+func (s *fakeStore) RecordMangaFailure(mangaID int, errText string, failedAt time.Time) (int, error) {
+	if s.failures == nil {
+		s.failures = map[int]int{}
+	}
+	s.failures[mangaID]++
+	return s.failures[mangaID], nil
+}
+
+func (s *fakeStore) SetMangaNextCheckAt(mangaID int, at time.Time) error {
+	if s.nextCheckAt == nil {
+		s.nextCheckAt = map[int]time.Time{}
+	}
+	s.nextCheckAt[mangaID] = at
+	return nil
+}
+
+func (s *fakeStore) ClearMangaFailures(mangaID int) error {
+	delete(s.failures, mangaID)
+	delete(s.nextCheckAt, mangaID)
+	return nil
+}
+
 type fakeMangaDex struct {
 	feed  *mangadex.ChapterFeedResponse
 	pages map[int]*mangadex.ChapterFeedResponse
 	total int
+	errs  map[string]error
+
+	mergeTargets map[string]string
+	mergeLookups []string
+
+	// onFeed, when set, is called before each feed request.
+	onFeed func(mangaID string)
+}
+
+func (m *fakeMangaDex) FindMergeTarget(ctx context.Context, mangaID, title string) (string, error) {
+	m.mergeLookups = append(m.mergeLookups, mangaID)
+	return m.mergeTargets[mangaID], nil
 }
 
 func (m *fakeMangaDex) GetChapterFeedPage(ctx context.Context, mangaID string, limit, offset int) (*mangadex.ChapterFeedResponse, error) {
+	if m.onFeed != nil {
+		m.onFeed(mangaID)
+	}
+	if err := m.errs[mangaID]; err != nil {
+		return nil, err
+	}
 	if m.pages != nil {
 		if page, ok := m.pages[offset]; ok {
 			cp := *page
@@ -411,3 +477,330 @@ func TestUpdateAll_PropagatesUserIDsInResults(t *testing.T) {
 		t.Fatalf("unexpected user ids in results: %+v", results)
 	}
 }
+
+func TestUpdateAllWithOptions_FiltersByUserAndReportsProgress(t *testing.T) {
+	lastSeen := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, MangaDexID: "md-1", Title: "One", LastSeenAt: lastSeen},
+			{ID: 2, UserID: 84, MangaDexID: "md-2", Title: "Two", LastSeenAt: lastSeen},
+			{ID: 3, UserID: 42, MangaDexID: "md-3", Title: "Three", LastSeenAt: lastSeen},
+		},
+	}
+	md := &fakeMangaDex{feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}}}
+	u := New(store, md, md)
+
+	var calls [][2]int
+	results, err := u.UpdateAllWithOptions(context.Background(), UpdateOptions{
+		UserID:   42,
+		Progress: func(done, total int) { calls = append(calls, [2]int{done, total}) },
+	})
+	if err != nil {
+		t.Fatalf("UpdateAllWithOptions(): %v", err)
+	}
+	if len(results) != 2 || results[0].MangaID != 1 || results[1].MangaID != 3 {
+		t.Fatalf("results=%+v, want only user 42's titles", results)
+	}
+	want := [][2]int{{0, 2}, {1, 2}, {2, 2}}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Fatalf("progress calls=%v, want %v", calls, want)
+	}
+}
+
+func TestFailureBackoff_DoublesUpToMax(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, FailureBackoffBase},
+		{2, 2 * FailureBackoffBase},
+		{3, 4 * FailureBackoffBase},
+		{10, FailureBackoffMax},
+	}
+	for _, tt := range tests {
+		if got := FailureBackoff(tt.failures); got != tt.want {
+			t.Fatalf("FailureBackoff(%d)=%s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateAll_TracksFailuresAndSkipsTitlesInBackoff(t *testing.T) {
+	lastSeen := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, MangaDexID: "md-broken", Title: "Broken", LastSeenAt: lastSeen},
+			{ID: 2, UserID: 42, MangaDexID: "md-waiting", Title: "Waiting", LastSeenAt: lastSeen, ConsecutiveFailures: 2, NextCheckAt: time.Now().Add(12 * time.Hour)},
+			{ID: 3, UserID: 42, MangaDexID: "md-recovered", Title: "Recovered", LastSeenAt: lastSeen, ConsecutiveFailures: 1, NextCheckAt: time.Now().Add(-time.Minute)},
+		},
+		failures:    map[int]int{3: 1},
+		nextCheckAt: map[int]time.Time{3: time.Now().Add(-time.Minute)},
+	}
+	md := &fakeMangaDex{
+		feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}},
+		errs: map[string]error{"md-broken": errors.New("404 not found")},
+	}
+	u := New(store, md, md)
+
+	results, err := u.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateAll(): %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results=%+v, want the title in backoff skipped", results)
+	}
+	if results[0].Err == nil || results[0].ConsecutiveFailures != 1 {
+		t.Fatalf("broken result=%+v, want first failure recorded", results[0])
+	}
+	if got := store.nextCheckAt[1]; time.Until(got) < FailureBackoffBase-time.Minute {
+		t.Fatalf("next check=%s, want about %s from now", got, FailureBackoffBase)
+	}
+	if _, ok := store.failures[3]; ok {
+		t.Fatal("a successful check should clear the failure streak")
+	}
+}
+
+func TestUpdateAll_CancelledRunRecordsNoFailures(t *testing.T) {
+	lastSeen := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, MangaDexID: "md-first", Title: "First", LastSeenAt: lastSeen},
+			{ID: 2, UserID: 42, MangaDexID: "md-cut", Title: "Cut", LastSeenAt: lastSeen},
+			{ID: 3, UserID: 42, MangaDexID: "md-never", Title: "Never", LastSeenAt: lastSeen},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	md := &fakeMangaDex{
+		feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}},
+		errs: map[string]error{"md-cut": context.Canceled},
+		onFeed: func(mangaID string) {
+			if mangaID == "md-cut" {
+				cancel()
+			}
+		},
+	}
+	u := New(store, md, md)
+
+	results, err := u.UpdateAll(ctx)
+	if err != nil {
+		t.Fatalf("UpdateAll(): %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("results=%+v, want only the title checked before the cancel", results)
+	}
+	if len(store.failures) != 0 || len(store.nextCheckAt) != 0 {
+		t.Fatalf("failures=%v next checks=%v, want none recorded", store.failures, store.nextCheckAt)
+	}
+
+	store.mangaDexID = "md-cut"
+	if _, err := u.UpdateOne(ctx, 2); err == nil {
+		t.Fatal("UpdateOne() on a cancelled context should fail")
+	}
+	if len(store.failures) != 0 {
+		t.Fatalf("failures=%v, want UpdateOne not to record the cancellation", store.failures)
+	}
+}
+
+func TestUpdateAll_RequestTimeoutCountsAsFailure(t *testing.T) {
+	lastSeen := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, MangaDexID: "md-slow", Title: "Slow", LastSeenAt: lastSeen},
+			{ID: 2, UserID: 42, MangaDexID: "md-fine", Title: "Fine", LastSeenAt: lastSeen},
+		},
+	}
+	md := &fakeMangaDex{
+		feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}},
+		errs: map[string]error{"md-slow": fmt.Errorf("feed: %w", context.DeadlineExceeded)},
+	}
+	u := New(store, md, md)
+
+	results, err := u.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateAll(): %v", err)
+	}
+	if len(results) != 2 || results[0].Err == nil || results[0].ConsecutiveFailures != 1 {
+		t.Fatalf("results=%+v, want the timed out title to start a failure streak", results)
+	}
+	if store.failures[1] != 1 {
+		t.Fatalf("failures=%v, want the timeout recorded", store.failures)
+	}
+
+	store.mangaDexID = "md-slow"
+	if _, err := u.UpdateOne(context.Background(), 1); err == nil {
+		t.Fatal("UpdateOne() expected the timeout error")
+	}
+	if store.failures[1] != 2 {
+		t.Fatalf("failures=%v, want UpdateOne to extend the streak", store.failures)
+	}
+}
+
+func TestUpdateAll_ReportsMergedEntries(t *testing.T) {
+	lastSeen := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, MangaDexID: "md-deleted", Title: "Deleted", LastSeenAt: lastSeen},
+			{ID: 2, UserID: 42, MangaDexID: "md-emptied", Title: "Emptied", LastSeenAt: lastSeen},
+			{ID: 3, UserID: 42, MangaDexID: "md-new", Title: "Never seen"},
+		},
+	}
+	md := &fakeMangaDex{
+		feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{}},
+		errs: map[string]error{"md-deleted": fmt.Errorf("feed: %w", mangadex.ErrNotFound)},
+		mergeTargets: map[string]string{
+			"md-deleted": "md-deleted-target",
+			"md-emptied": "md-emptied-target",
+		},
+	}
+	u := New(store, md, md)
+
+	results, err := u.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateAll(): %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results=%+v", results)
+	}
+	if results[0].Err == nil || results[0].MergedInto != "md-deleted-target" || results[0].ConsecutiveFailures != 1 {
+		t.Fatalf("deleted result=%+v, want failure with merge target", results[0])
+	}
+	if results[1].Err != nil || results[1].MergedInto != "md-emptied-target" {
+		t.Fatalf("emptied result=%+v, want success with merge target", results[1])
+	}
+	if results[2].MergedInto != "" {
+		t.Fatalf("new title result=%+v, an empty feed on a title without chapters is not a merge", results[2])
+	}
+	if len(md.mergeLookups) != 2 {
+		t.Fatalf("merge lookups=%v, want only the two stale titles", md.mergeLookups)
+	}
+}
+
+// fakeSource is a non-MangaDex source backed by a fakeMangaDex feed.
+type fakeSource struct {
+	*fakeMangaDex
+	name string
+}
+
+func (s *fakeSource) Name() string                           { return s.name }
+func (s *fakeSource) ResolveURL(input string) (string, bool) { return input, true }
+func (s *fakeSource) FetchMetadata(ctx context.Context, id string) (source.Metadata, error) {
+	return source.Metadata{Title: id}, nil
+}
+
+func TestUpdateAll_DispatchesToTitleSource(t *testing.T) {
+	created := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		list: []db.Manga{
+			{ID: 1, UserID: 42, Source: source.RSS, MangaDexID: "https://example.com/feed.xml", Title: "Feed title"},
+			{ID: 2, UserID: 42, Source: "unknown", MangaDexID: "x", Title: "Orphan"},
+		},
+	}
+	md := &fakeMangaDex{errs: map[string]error{"https://example.com/feed.xml": errors.New("asked MangaDex for a feed title")}}
+	rss := &fakeSource{name: source.RSS, fakeMangaDex: &fakeMangaDex{feed: &mangadex.ChapterFeedResponse{
+		Data: []mangadex.Chapter{
+			{ID: "c5", Attributes: mangadex.ChapterAttributes{Chapter: "5", Language: "en", CreatedAt: created, PublishedAt: created, ReadableAt: created, UpdatedAt: created}},
+		},
+		Total: 1,
+	}}}
+	u := New(store, md, md, rss)
+
+	results, err := u.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("UpdateAll(): %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results=%+v", results)
+	}
+	if results[0].Err != nil || len(results[0].NewChapters) != 1 {
+		t.Fatalf("rss result=%+v, want one new chapter from the feed", results[0])
+	}
+	if results[1].Err == nil {
+		t.Fatalf("unknown source result=%+v, want error", results[1])
+	}
+}
+
+func TestUpdateOne_RSSFeedUsesWatermark(t *testing.T) {
+	item := func(n int, day int) string {
+		return fmt.Sprintf(`<item><title>Chapter %d</title><guid>c%d</guid><pubDate>%s</pubDate></item>`,
+			n, n, time.Date(2025, 3, day, 12, 0, 0, 0, time.UTC).Format(time.RFC1123Z))
+	}
+	items := item(2, 2) + item(1, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"%d"`, len(items))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = fmt.Fprintf(w, `<rss version="2.0"><channel><title>Feed</title>%s</channel></rss>`, items)
+	}))
+	t.Cleanup(srv.Close)
+
+	store := &fakeStore{mangaDexID: srv.URL, source: source.RSS, title: "Feed"}
+	md := &fakeMangaDex{}
+	rss := source.NewRSS()
+	rss.AllowPrivateHosts = true
+	u := New(store, md, md, rss)
+
+	synced, _, err := u.SyncAll(context.Background(), 1)
+	if err != nil || synced != 2 {
+		t.Fatalf("SyncAll()=%d, %v; want 2 chapters", synced, err)
+	}
+
+	items = item(3, 3) + items
+	res, err := u.UpdateOne(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("UpdateOne(): %v", err)
+	}
+	if len(res.NewChapters) != 1 || res.NewChapters[0].Number != "3" {
+		t.Fatalf("new chapters=%+v, want only chapter 3", res.NewChapters)
+	}
+
+	// Unchanged feed (304): nothing new past the watermark.
+	res, err = u.UpdateOne(context.Background(), 1)
+	if err != nil || len(res.NewChapters) != 0 {
+		t.Fatalf("UpdateOne() again=%+v, %v; want no new chapters", res.NewChapters, err)
+	}
+}
+
+func TestUpdater_DetectsMangaPlusFromExternalURLs(t *testing.T) {
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fresh := old.Add(48 * time.Hour)
+	chapter := func(id, num, lang, link string, at time.Time) mangadex.Chapter {
+		return mangadex.Chapter{ID: id, Attributes: mangadex.ChapterAttributes{
+			Chapter: num, Language: lang, ExternalURL: link,
+			PublishedAt: at, ReadableAt: at, CreatedAt: at, UpdatedAt: at,
+		}}
+	}
+
+	// A French scan wins the chapter row, but the English MANGA Plus link is still kept.
+	store := &fakeStore{mangaDexID: "md-1", title: "Official"}
+	md := &fakeMangaDex{feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{
+		chapter("fr-1", "1", "fr", "", old),
+		chapter("en-1", "1", "en", "https://mangaplus.shueisha.co.jp/viewer/1", old),
+	}, Total: 2}}
+	u := New(store, md, md)
+	if _, _, err := u.SyncAll(context.Background(), 1); err != nil {
+		t.Fatalf("SyncAll(): %v", err)
+	}
+	if !store.mangaPlus || store.links["1"] != "https://mangaplus.shueisha.co.jp/viewer/1" {
+		t.Fatalf("mangaPlus=%v links=%v, want the title flagged and the link stored", store.mangaPlus, store.links)
+	}
+
+	store = &fakeStore{mangaDexID: "md-2", title: "Publisher", lastSeenAt: old}
+	md = &fakeMangaDex{feed: &mangadex.ChapterFeedResponse{Data: []mangadex.Chapter{
+		chapter("en-2", "2", "en", "https://example.com/read/2", fresh),
+	}, Total: 1}}
+	u = New(store, md, md)
+	res, err := u.UpdateOne(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("UpdateOne(): %v", err)
+	}
+	if len(res.NewChapters) != 1 || res.NewChapters[0].ExternalURL != "https://example.com/read/2" {
+		t.Fatalf("new chapters=%+v, want the external link carried along", res.NewChapters)
+	}
+	if store.mangaPlus {
+		t.Fatal("a non-MANGA Plus link should not flag the title")
+	}
+}